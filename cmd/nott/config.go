@@ -1,6 +1,8 @@
 package main
 
 import (
+	"time"
+
 	_ "github.com/joho/godotenv/autoload" // load env vars from .env file
 	"github.com/kelseyhightower/envconfig"
 )
@@ -15,6 +17,9 @@ type config struct {
 
 	// Port to listen on
 	Port int `envconfig:"PORT" default:"8080"`
+	// Internal address for debug handlers, e.g. 127.0.0.1:6060,
+	// they are disabled if it's empty
+	DebugAddr string `envconfig:"DEBUG_ADDR"`
 
 	// PostgreSQL server
 	PGHost       string `envconfig:"POSTGRES_HOST" required:"true"`
@@ -25,8 +30,73 @@ type config struct {
 	PGPassword   string `envconfig:"POSTGRES_PASSWORD" required:"true"`
 	PGMigrations string `envconfig:"POSTGRES_MIGRATIONS" required:"true"`
 
-	// Secret key for signing JWT.
+	// How long deleted objects are kept in trash (0 to keep forever)
+	TrashRetention time.Duration `envconfig:"TRASH_RETENTION" default:"720h"`
+
+	// Storage for attached files: filesystem or s3
+	AttachmentsStorage string `envconfig:"ATTACHMENTS_STORAGE" default:"filesystem"`
+	// Directory for attached files when filesystem storage is used
+	AttachmentsDir string `envconfig:"ATTACHMENTS_DIR" default:"attachments"`
+	// Maximum size of an attached file in bytes
+	AttachmentMaxSize int64 `envconfig:"ATTACHMENT_MAX_SIZE" default:"10485760"`
+	// Maximum total size of user's attachments in bytes (0 for no limit)
+	AttachmentsQuota int64 `envconfig:"ATTACHMENTS_QUOTA" default:"104857600"`
+
+	// Maximum size of an imported file in bytes
+	ImportMaxSize int64 `envconfig:"IMPORT_MAX_SIZE" default:"104857600"`
+
+	// Cache of rendered notes: memory, postgres or none
+	RenderCache string `envconfig:"RENDER_CACHE" default:"memory"`
+	// Maximum number of cached notes, the postgres cache is trimmed
+	// to it every 10 minutes and can be larger in between
+	RenderCacheSize int `envconfig:"RENDER_CACHE_SIZE" default:"1000"`
+	// Maximum size of a preview request in bytes
+	RenderMaxSize int64 `envconfig:"RENDER_MAX_SIZE" default:"1048576"`
+	// Maximum number of preview requests of a user per minute
+	RenderRateLimit int `envconfig:"RENDER_RATE_LIMIT" default:"120"`
+
+	// Maximum number of requests for a shared note or notepad
+	// from a client per minute
+	PublicRateLimit int `envconfig:"PUBLIC_RATE_LIMIT" default:"30"`
+
+	// S3-compatible storage for attached files
+	S3Endpoint  string `envconfig:"S3_ENDPOINT" default:"https://s3.amazonaws.com"`
+	S3Region    string `envconfig:"S3_REGION" default:"us-east-1"`
+	S3Bucket    string `envconfig:"S3_BUCKET"`
+	S3AccessKey string `envconfig:"S3_ACCESS_KEY"`
+	S3SecretKey string `envconfig:"S3_SECRET_KEY"`
+	S3PathStyle bool   `envconfig:"S3_PATH_STYLE" default:"false"`
+
+	// Secret key for signing JWT and links to attachments.
 	SignKey string `envconfig:"SIGN_KEY" required:"true"`
+	// Files and directories with RSA and Ed25519 keys for signing JWT
+	// instead of the secret key, ID of the key that signs new tokens
+	// (the last key by name if empty), and how long tokens signed with
+	// other keys are accepted (access token TTL if zero)
+	JWTKeys       []string      `envconfig:"JWT_KEYS"`
+	JWTSigningKey string        `envconfig:"JWT_SIGNING_KEY"`
+	JWTKeysGrace  time.Duration `envconfig:"JWT_KEYS_GRACE" default:"0"`
+	// Time to live of access tokens and refresh tokens
+	AccessTokenTTL  time.Duration `envconfig:"ACCESS_TOKEN_TTL" default:"15m"`
+	RefreshTokenTTL time.Duration `envconfig:"REFRESH_TOKEN_TTL" default:"720h"`
+
+	// Address of the page for setting new password (HOST/reset-password
+	// if empty), the link with reset token is sent to users
+	PasswordResetURL string `envconfig:"PASSWORD_RESET_URL"`
+	// Maximum number of password reset requests from a client per hour
+	PasswordResetRateLimit int `envconfig:"PASSWORD_RESET_RATE_LIMIT" default:"10"`
+
+	// Mailer: smtp, file or log
+	Mailer string `envconfig:"MAILER" default:"log"`
+	// Address of the sender
+	MailFrom string `envconfig:"MAIL_FROM" default:"nott@localhost"`
+	// Directory for messages when file mailer is used
+	MailDir string `envconfig:"MAIL_DIR" default:"mail"`
+	// SMTP server, authentication is used if username is set
+	SMTPHost     string `envconfig:"SMTP_HOST"`
+	SMTPPort     int    `envconfig:"SMTP_PORT" default:"587"`
+	SMTPUsername string `envconfig:"SMTP_USERNAME"`
+	SMTPPassword string `envconfig:"SMTP_PASSWORD"`
 
 	// OAuth: GitHub
 	GithubClientID     string `envconfig:"GITHUB_CLIENT_ID" required:"true"`
@@ -7,7 +7,11 @@ import (
 
 	"github.com/tetafro/nott-backend-go/internal/application"
 	"github.com/tetafro/nott-backend-go/internal/auth"
+	"github.com/tetafro/nott-backend-go/internal/mail"
+	"github.com/tetafro/nott-backend-go/internal/storage"
+	"github.com/tetafro/nott-backend-go/internal/storage/filesystem"
 	"github.com/tetafro/nott-backend-go/internal/storage/postgres"
+	"github.com/tetafro/nott-backend-go/internal/storage/s3"
 )
 
 func main() {
@@ -37,8 +41,39 @@ func main() {
 		"github": auth.NewGithubProvider(cfg.Host, cfg.GithubClientID, cfg.GithubClientSecret),
 	}
 
-	addr := fmt.Sprintf(":%d", cfg.Port)
-	app, err := application.New(db, addr, cfg.SignKey, providers, log)
+	blobs, err := initBlobStore(cfg)
+	if err != nil {
+		log.Fatalf("Failed to init attachments storage: %v", err)
+	}
+
+	mailer, err := initMailer(cfg, log)
+	if err != nil {
+		log.Fatalf("Failed to init mailer: %v", err)
+	}
+
+	app, err := application.New(db, blobs, mailer, providers, application.Config{
+		Addr:              fmt.Sprintf(":%d", cfg.Port),
+		DebugAddr:         cfg.DebugAddr,
+		Host:              cfg.Host,
+		SignKey:           cfg.SignKey,
+		JWTKeys:           cfg.JWTKeys,
+		JWTSigningKey:     cfg.JWTSigningKey,
+		JWTKeysGrace:      cfg.JWTKeysGrace,
+		AccessTokenTTL:    cfg.AccessTokenTTL,
+		RefreshTokenTTL:   cfg.RefreshTokenTTL,
+		TrashRetention:    cfg.TrashRetention,
+		AttachmentMaxSize: cfg.AttachmentMaxSize,
+		AttachmentsQuota:  cfg.AttachmentsQuota,
+		ImportMaxSize:     cfg.ImportMaxSize,
+		RenderCache:       cfg.RenderCache,
+		RenderCacheSize:   cfg.RenderCacheSize,
+		RenderMaxSize:     cfg.RenderMaxSize,
+		RenderRateLimit:   cfg.RenderRateLimit,
+		PublicRateLimit:   cfg.PublicRateLimit,
+		PasswordResetURL:  cfg.PasswordResetURL,
+
+		PasswordResetRateLimit: cfg.PasswordResetRateLimit,
+	}, log)
 	if err != nil {
 		log.Fatalf("Failed to init the application: %v", err)
 	}
@@ -48,6 +83,43 @@ func main() {
 	}
 }
 
+func initBlobStore(cfg *config) (storage.BlobStore, error) {
+	switch cfg.AttachmentsStorage {
+	case "filesystem":
+		return filesystem.NewBlobStore(cfg.AttachmentsDir)
+	case "s3":
+		return s3.NewBlobStore(s3.Config{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+			PathStyle: cfg.S3PathStyle,
+		})
+	default:
+		return nil, fmt.Errorf("unknown storage %q", cfg.AttachmentsStorage)
+	}
+}
+
+func initMailer(cfg *config, log logrus.FieldLogger) (mail.Mailer, error) {
+	switch cfg.Mailer {
+	case "smtp":
+		return mail.NewSMTPMailer(mail.SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.MailFrom,
+		})
+	case "file":
+		return mail.NewFileMailer(cfg.MailDir, cfg.MailFrom)
+	case "log":
+		return mail.NewLogMailer(log), nil
+	default:
+		return nil, fmt.Errorf("unknown mailer %q", cfg.Mailer)
+	}
+}
+
 func initLogger(debug bool) *logrus.Logger {
 	log := logrus.New()
 	log.Formatter = &logrus.TextFormatter{}
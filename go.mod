@@ -1,22 +1,34 @@
 module github.com/tetafro/nott-backend-go
 
+go 1.27.1
+
 require (
-	github.com/Depado/bfchroma v1.1.1
-	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/alecthomas/chroma v0.4.0
 	github.com/dgrijalva/jwt-go v3.2.0+incompatible
 	github.com/go-chi/chi v3.3.2+incompatible
 	github.com/golang-migrate/migrate v3.4.0+incompatible
 	github.com/golang/mock v1.1.1
 	github.com/jinzhu/gorm v1.9.1
-	github.com/jinzhu/inflection v0.0.0-20180308033659-04140366298a // indirect
 	github.com/joho/godotenv v1.3.0
 	github.com/kelseyhightower/envconfig v1.3.0
-	github.com/lib/pq v0.0.0-20180327071824-d34b9ff171c2 // indirect
 	github.com/pkg/errors v0.8.1
+	github.com/pmezard/go-difflib v1.0.0
 	github.com/sirupsen/logrus v1.0.5
 	github.com/stretchr/testify v1.2.2
+	github.com/yuin/goldmark v1.4.12
 	golang.org/x/crypto v0.0.0-20180515001509-1a580b3eff78
+	golang.org/x/net v0.0.0-20180724234803-3673e40ba225
 	golang.org/x/oauth2 v0.0.0-20181203162652-d668ce993890
+)
+
+require (
+	github.com/danwakefield/fnmatch v0.0.0-20160403171240-cbb64ac3d964 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/dlclark/regexp2 v1.1.6 // indirect
+	github.com/golang/protobuf v1.2.0 // indirect
+	github.com/jinzhu/inflection v0.0.0-20180308033659-04140366298a // indirect
+	github.com/lib/pq v0.0.0-20180327071824-d34b9ff171c2 // indirect
+	golang.org/x/sys v0.0.0-20180715085529-ac767d655b30 // indirect
+	golang.org/x/text v0.3.0 // indirect
 	google.golang.org/appengine v1.4.0 // indirect
-	gopkg.in/russross/blackfriday.v2 v2.0.0
 )
@@ -2,6 +2,7 @@ package application
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi"
 	"github.com/go-chi/chi/middleware"
@@ -10,6 +11,9 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/tetafro/nott-backend-go/internal/auth"
+	"github.com/tetafro/nott-backend-go/internal/mail"
+	"github.com/tetafro/nott-backend-go/internal/markdown"
+	"github.com/tetafro/nott-backend-go/internal/storage"
 	"github.com/tetafro/nott-backend-go/internal/storage/postgres"
 	httpapi "github.com/tetafro/nott-backend-go/internal/transport/http"
 )
@@ -19,69 +23,334 @@ type Application struct {
 	addr   string
 	router *chi.Mux
 	log    logrus.FieldLogger
+
+	// debugAddr is an internal address for debug handlers,
+	// they are disabled if it's empty
+	debugAddr string
+
+	trash          storage.TrashRepo
+	trashRetention time.Duration
+
+	attachments       storage.AttachmentsRepo
+	attachmentMaxSize int64
+	attachmentsQuota  int64
+	blobs             storage.BlobStore
+
+	folders storage.FoldersRepo
+	imports storage.ImportsRepo
+
+	renderer    *markdown.CachedRenderer
+	renderCache *postgres.RenderCache
+
+	sessions      storage.SessionsRepo
+	refreshTokens storage.RefreshTokensRepo
+	denylist      *postgres.Denylist
+	resets        storage.PasswordResetsRepo
+	keys          *auth.Keyring
+}
+
+// Config contains application settings.
+type Config struct {
+	// Address to listen on
+	Addr string
+	// Internal address for debug handlers, they are disabled if it's empty
+	DebugAddr string
+	// External host of the server (proto://host:port)
+	Host string
+	// Secret key for signing tokens and links
+	SignKey string
+	// Files and directories with keys for signing tokens instead
+	// of the secret key, ID of the signing key, and how long tokens
+	// signed with retired keys are accepted
+	JWTKeys       []string
+	JWTSigningKey string
+	JWTKeysGrace  time.Duration
+	// Time to live of access tokens and refresh tokens
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	// How long deleted objects are kept in trash (0 to keep forever)
+	TrashRetention time.Duration
+	// Maximum size of an attached file and total size
+	// of user's attachments (0 for no limit)
+	AttachmentMaxSize int64
+	AttachmentsQuota  int64
+	// Maximum size of an imported file
+	ImportMaxSize int64
+	// Cache of rendered notes: memory, postgres or none,
+	// and maximum number of cached notes (at least 1)
+	RenderCache     string
+	RenderCacheSize int
+	// Maximum size of a preview request, and maximum number
+	// of preview requests of a user per minute
+	RenderMaxSize   int64
+	RenderRateLimit int
+	// Maximum number of requests for a share from a client per minute
+	PublicRateLimit int
+	// Address of the page for setting new password, the link is sent
+	// to users who forgot the password (host/reset-password if empty)
+	PasswordResetURL string
+	// Maximum number of password reset requests from a client per hour
+	PasswordResetRateLimit int
 }
 
 // New creates main application instance that handles all requests.
 func New(
 	db *gorm.DB,
-	addr string,
-	signKey string,
+	blobs storage.BlobStore,
+	mailer mail.Mailer,
 	providers map[string]*auth.OAuthProvider,
+	cfg Config,
 	log logrus.FieldLogger,
 ) (*Application, error) {
-	app := &Application{addr: addr, log: log}
+	app := &Application{
+		addr:           cfg.Addr,
+		debugAddr:      cfg.DebugAddr,
+		log:            log,
+		trashRetention: cfg.TrashRetention,
 
-	foldersRepo := postgres.NewFoldersRepo(db)
-	foldersController := httpapi.NewFoldersController(foldersRepo, log)
+		attachmentMaxSize: cfg.AttachmentMaxSize,
+		attachmentsQuota:  cfg.AttachmentsQuota,
+		blobs:             blobs,
+	}
+
+	app.folders = postgres.NewFoldersRepo(db)
+	foldersController := httpapi.NewFoldersController(app.folders, log)
 
 	notepadsRepo := postgres.NewNotepadsRepo(db)
 	notepadsController := httpapi.NewNotepadsController(notepadsRepo, log)
 
+	links := httpapi.NewAttachmentLinks(auth.NewLinkSigner(cfg.SignKey), cfg.Host+"/api/v1")
+
+	if cfg.RenderCache != "none" && cfg.RenderCacheSize < 1 {
+		return nil, errors.Errorf("invalid render cache size %d", cfg.RenderCacheSize)
+	}
+	var cache markdown.Cache
+	switch cfg.RenderCache {
+	case "memory":
+		cache = markdown.NewMemoryCache(cfg.RenderCacheSize)
+	case "postgres":
+		app.renderCache = postgres.NewRenderCache(db, cfg.RenderCacheSize)
+		cache = app.renderCache
+	case "none":
+	default:
+		return nil, errors.Errorf("unknown render cache %q", cfg.RenderCache)
+	}
+	commonMark := markdown.NewCommonMark(markdown.DefaultConfig())
+	renderer := markdown.NewCachedRenderer(commonMark, cache)
+	app.renderer = renderer
+
 	notesRepo := postgres.NewNotesRepo(db)
-	notesController := httpapi.NewNotesController(notesRepo, log)
+	notesController := httpapi.NewNotesController(notesRepo, links, renderer, log)
+
+	app.attachments = postgres.NewAttachmentsRepo(db)
+	attachmentsController := httpapi.NewAttachmentsController(
+		app.attachments,
+		blobs,
+		links,
+		httpapi.AttachmentLimits{MaxSize: cfg.AttachmentMaxSize, Quota: cfg.AttachmentsQuota},
+		log,
+	)
+
+	exportController := httpapi.NewExportController(
+		app.folders,
+		notepadsRepo,
+		notesRepo,
+		app.attachments,
+		blobs,
+		log,
+	)
+
+	app.imports = postgres.NewImportsRepo(db)
+	importsController := httpapi.NewImportsController(app.imports, blobs, cfg.ImportMaxSize, log)
+
+	grantsRepo := postgres.NewGrantsRepo(db)
+	grantsController := httpapi.NewGrantsController(grantsRepo, log)
+
+	sharesRepo := postgres.NewSharesRepo(db)
+	sharesController := httpapi.NewSharesController(
+		sharesRepo,
+		notepadsRepo,
+		notesRepo,
+		app.attachments,
+		blobs,
+		links,
+		renderer,
+		cfg.Host+"/api/v1",
+		log,
+	)
+
+	renderController := httpapi.NewRenderController(commonMark, cfg.RenderMaxSize, log)
+	themesController := httpapi.NewThemesController(log)
+
+	tagsRepo := postgres.NewTagsRepo(db)
+	tagsController := httpapi.NewTagsController(tagsRepo, log)
+
+	app.trash = postgres.NewTrashRepo(db)
+	trashController := httpapi.NewTrashController(app.trash, log)
 
 	usersRepo := postgres.NewUsersRepo(db)
-	tokener := auth.NewJWTokener(signKey)
-	authController := httpapi.NewAuthController(usersRepo, tokener, log)
+	app.sessions = postgres.NewSessionsRepo(db)
+	app.refreshTokens = postgres.NewRefreshTokensRepo(db)
+	app.denylist = postgres.NewDenylist(db)
+	denylist := auth.NewCachedDenylist(app.denylist, auth.DefaultDenylistTTL)
+	keys, err := newKeyring(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "load keys")
+	}
+	app.keys = keys
+	keysController := httpapi.NewKeysController(keys, log)
+	tokener := auth.NewJWTokener(keys, cfg.AccessTokenTTL, cfg.RefreshTokenTTL)
+	sessions := httpapi.NewSessions(app.sessions, app.refreshTokens, denylist, tokener, cfg.AccessTokenTTL)
+	authController := httpapi.NewAuthController(usersRepo, sessions, log)
+	sessionsController := httpapi.NewSessionsController(app.sessions, sessions, log)
 
-	oauthController := httpapi.NewOAuthController(providers, usersRepo, tokener, log)
+	personalTokensRepo := postgres.NewPersonalTokensRepo(db)
+	tokensController := httpapi.NewTokensController(personalTokensRepo, log)
 
-	mwAuth := httpapi.NewAuthMiddleware(tokener, log)
+	app.resets = postgres.NewPasswordResetsRepo(db)
+	resetURL := cfg.PasswordResetURL
+	if resetURL == "" {
+		resetURL = cfg.Host + "/reset-password"
+	}
+	passwordsController := httpapi.NewPasswordsController(
+		usersRepo,
+		app.resets,
+		personalTokensRepo,
+		sessions,
+		mailer,
+		resetURL,
+		log,
+	)
+
+	oauthController := httpapi.NewOAuthController(providers, usersRepo, sessions, log)
+
+	mwAuth := httpapi.NewAuthMiddleware(tokener, personalTokensRepo, denylist, log)
+	// Personal access tokens only have access to routes of their scopes,
+	// and account is managed only with sessions
+	mwSession := httpapi.NewScopesMiddleware()
+	mwFoldersRead := httpapi.NewScopesMiddleware(auth.ScopeFoldersRead)
+	mwFoldersWrite := httpapi.NewScopesMiddleware(auth.ScopeFoldersWrite)
+	mwNotesRead := httpapi.NewScopesMiddleware(auth.ScopeNotesRead)
+	mwNotesWrite := httpapi.NewScopesMiddleware(auth.ScopeNotesWrite)
+	mwTagsRead := httpapi.NewScopesMiddleware(auth.ScopeTagsRead)
+	mwTagsWrite := httpapi.NewScopesMiddleware(auth.ScopeTagsWrite)
+	mwExport := httpapi.NewScopesMiddleware(auth.ScopeFoldersRead, auth.ScopeNotesRead)
+	mwImport := httpapi.NewScopesMiddleware(auth.ScopeFoldersWrite, auth.ScopeNotesWrite)
+	mwRenderLimit := httpapi.NewRateLimitMiddleware(cfg.RenderRateLimit, time.Minute, httpapi.ByUser)
+	mwPublicLimit := httpapi.NewRateLimitMiddleware(
+		cfg.PublicRateLimit,
+		time.Minute,
+		httpapi.ByClientAndParam("token"),
+	)
+	mwResetLimit := httpapi.NewRateLimitMiddleware(cfg.PasswordResetRateLimit, time.Hour, httpapi.ByClient)
 	mwLog := middleware.RequestLogger(&middleware.DefaultLogFormatter{Logger: log})
 
 	// Main router
 	app.router = chi.NewRouter()
 	app.router.Use(mwLog)
 	app.router.MethodFunc(http.MethodGet, "/healthz", healthz)
+	app.router.MethodFunc(http.MethodGet, "/.well-known/jwks.json", keysController.GetJWKS)
 	app.router.MethodFunc(http.MethodPost, "/api/v1/register", authController.Register)
 	app.router.MethodFunc(http.MethodPost, "/api/v1/login", authController.Login)
+	app.router.MethodFunc(http.MethodPost, "/api/v1/token/refresh", authController.Refresh)
+	app.router.With(mwResetLimit).MethodFunc(http.MethodPost, "/api/v1/password/reset", passwordsController.Reset)
+	app.router.MethodFunc(http.MethodPost, "/api/v1/password/reset/confirm", passwordsController.ConfirmReset)
 	app.router.MethodFunc(http.MethodGet, "/api/v1/oauth/providers", oauthController.Providers)
 	app.router.MethodFunc(http.MethodPost, "/api/v1/oauth/github", oauthController.Github)
+	// Signed links are used instead of tokens for downloading attachments
+	app.router.MethodFunc(http.MethodGet, "/api/v1/attachments/{id}/content", attachmentsController.Download)
+	// Shared notes and notepads are available to everyone
+	// Passwords of protected shares are checked on every request,
+	// so the requests are limited to prevent guessing them
+	app.router.With(mwPublicLimit).MethodFunc(http.MethodGet, "/api/v1/public/{token}", sharesController.GetPublic)
+	app.router.MethodFunc(
+		http.MethodGet,
+		"/api/v1/public/{token}/attachments/{id}/content",
+		sharesController.GetPublicAttachment,
+	)
+	// Stylesheets are public to be linked from shared notes
+	app.router.MethodFunc(http.MethodGet, "/api/v1/themes", themesController.GetList)
+	app.router.MethodFunc(http.MethodGet, "/api/v1/themes/{file}", themesController.GetCSS)
 
 	// Application router
 	r := chi.NewRouter()
 	r.Use(mwAuth)
 	// Users
-	r.MethodFunc(http.MethodGet, "/profile", authController.GetProfile)
-	r.MethodFunc(http.MethodPut, "/profile", authController.UpdateProfile)
+	r.With(mwSession).MethodFunc(http.MethodPost, "/logout", authController.Logout)
+	r.With(mwSession).MethodFunc(http.MethodGet, "/sessions", sessionsController.GetList)
+	r.With(mwSession).MethodFunc(http.MethodDelete, "/sessions", sessionsController.DeleteOthers)
+	r.With(mwSession).MethodFunc(http.MethodDelete, "/sessions/{id}", sessionsController.Delete)
+	r.With(mwSession).MethodFunc(http.MethodGet, "/profile", authController.GetProfile)
+	r.With(mwSession).MethodFunc(http.MethodPut, "/profile", authController.UpdateProfile)
+	r.With(mwSession).MethodFunc(http.MethodPost, "/profile/password", passwordsController.Change)
+	r.With(mwSession).MethodFunc(http.MethodGet, "/tokens", tokensController.GetList)
+	r.With(mwSession).MethodFunc(http.MethodPost, "/tokens", tokensController.Create)
+	r.With(mwSession).MethodFunc(http.MethodDelete, "/tokens/{id}", tokensController.Delete)
 	// Folders
-	r.MethodFunc(http.MethodGet, "/folders", foldersController.GetList)
-	r.MethodFunc(http.MethodPost, "/folders", foldersController.Create)
-	r.MethodFunc(http.MethodGet, "/folders/{id}", foldersController.GetOne)
-	r.MethodFunc(http.MethodPut, "/folders/{id}", foldersController.Update)
-	r.MethodFunc(http.MethodDelete, "/folders/{id}", foldersController.Delete)
+	r.With(mwFoldersRead).MethodFunc(http.MethodGet, "/folders", foldersController.GetList)
+	r.With(mwFoldersWrite).MethodFunc(http.MethodPost, "/folders", foldersController.Create)
+	r.With(mwFoldersRead).MethodFunc(http.MethodGet, "/folders/{id}", foldersController.GetOne)
+	r.With(mwFoldersWrite).MethodFunc(http.MethodPut, "/folders/{id}", foldersController.Update)
+	r.With(mwFoldersWrite).MethodFunc(http.MethodDelete, "/folders/{id}", foldersController.Delete)
+	r.With(mwFoldersWrite).MethodFunc(http.MethodPost, "/folders/{id}/move", foldersController.Move)
+	r.With(mwFoldersRead).MethodFunc(http.MethodGet, "/tree", foldersController.GetTree)
 	// Notepads
-	r.MethodFunc(http.MethodGet, "/notepads", notepadsController.GetList)
-	r.MethodFunc(http.MethodPost, "/notepads", notepadsController.Create)
-	r.MethodFunc(http.MethodGet, "/notepads/{id}", notepadsController.GetOne)
-	r.MethodFunc(http.MethodPut, "/notepads/{id}", notepadsController.Update)
-	r.MethodFunc(http.MethodDelete, "/notepads/{id}", notepadsController.Delete)
+	r.With(mwFoldersRead).MethodFunc(http.MethodGet, "/notepads", notepadsController.GetList)
+	r.With(mwFoldersWrite).MethodFunc(http.MethodPost, "/notepads", notepadsController.Create)
+	r.With(mwFoldersRead).MethodFunc(http.MethodGet, "/notepads/{id}", notepadsController.GetOne)
+	r.With(mwFoldersWrite).MethodFunc(http.MethodPut, "/notepads/{id}", notepadsController.Update)
+	r.With(mwFoldersWrite).MethodFunc(http.MethodDelete, "/notepads/{id}", notepadsController.Delete)
+	r.With(mwFoldersWrite).MethodFunc(http.MethodPost, "/notepads/{id}/move", notepadsController.Move)
 	// Notes
-	r.MethodFunc(http.MethodGet, "/notes", notesController.GetList)
-	r.MethodFunc(http.MethodPost, "/notes", notesController.Create)
-	r.MethodFunc(http.MethodGet, "/notes/{id}", notesController.GetOne)
-	r.MethodFunc(http.MethodPut, "/notes/{id}", notesController.Update)
-	r.MethodFunc(http.MethodDelete, "/notes/{id}", notesController.Delete)
+	r.With(mwNotesRead).MethodFunc(http.MethodGet, "/notes", notesController.GetList)
+	r.With(mwNotesWrite).MethodFunc(http.MethodPost, "/notes", notesController.Create)
+	r.With(mwNotesRead).MethodFunc(http.MethodGet, "/notes/{id}", notesController.GetOne)
+	r.With(mwNotesWrite).MethodFunc(http.MethodPut, "/notes/{id}", notesController.Update)
+	r.With(mwNotesWrite).MethodFunc(http.MethodDelete, "/notes/{id}", notesController.Delete)
+	r.With(mwNotesWrite).MethodFunc(http.MethodPost, "/notes/{id}/move", notesController.Move)
+	r.With(mwNotesRead).MethodFunc(http.MethodGet, "/notes/{id}/revisions", notesController.GetRevisions)
+	r.With(mwNotesRead).MethodFunc(http.MethodGet, "/notes/{id}/revisions/{rev}", notesController.GetRevision)
+	r.With(mwNotesWrite).MethodFunc(http.MethodPost, "/notes/{id}/revisions/{rev}/restore", notesController.Restore)
+	r.With(mwNotesRead).MethodFunc(http.MethodGet, "/notes/{id}/diff", notesController.GetDiff)
+	r.With(mwNotesRead).MethodFunc(http.MethodGet, "/notes/{id}/backlinks", notesController.GetBacklinks)
+	r.With(mwNotesRead).MethodFunc(http.MethodGet, "/graph", notesController.GetGraph)
+	// Preview is rendered on every change in editor, and it's CPU-bound
+	r.With(mwNotesRead, mwRenderLimit).MethodFunc(http.MethodPost, "/render", renderController.Render)
+	// Attachments
+	r.With(mwNotesRead).MethodFunc(http.MethodGet, "/notes/{id}/attachments", attachmentsController.GetList)
+	r.With(mwNotesWrite).MethodFunc(http.MethodPost, "/notes/{id}/attachments", attachmentsController.Upload)
+	r.With(mwNotesRead).MethodFunc(http.MethodGet, "/attachments/{id}", attachmentsController.GetOne)
+	r.With(mwNotesWrite).MethodFunc(http.MethodDelete, "/attachments/{id}", attachmentsController.Delete)
+	// Tags
+	r.With(mwTagsRead).MethodFunc(http.MethodGet, "/tags", tagsController.GetList)
+	r.With(mwTagsWrite).MethodFunc(http.MethodPost, "/tags", tagsController.Create)
+	r.With(mwTagsRead).MethodFunc(http.MethodGet, "/tags/{id}", tagsController.GetOne)
+	r.With(mwTagsWrite).MethodFunc(http.MethodPut, "/tags/{id}", tagsController.Update)
+	r.With(mwTagsWrite).MethodFunc(http.MethodDelete, "/tags/{id}", tagsController.Delete)
+	r.With(mwTagsWrite).MethodFunc(http.MethodPost, "/tags/{id}/merge", tagsController.Merge)
+	// Collaboration
+	r.With(mwSession).MethodFunc(http.MethodGet, "/grants", grantsController.GetList)
+	r.With(mwSession).MethodFunc(http.MethodPost, "/grants", grantsController.Create)
+	r.With(mwSession).MethodFunc(http.MethodDelete, "/grants/{id}", grantsController.Delete)
+	r.With(mwSession).MethodFunc(http.MethodGet, "/invites", grantsController.GetInvites)
+	r.With(mwSession).MethodFunc(http.MethodPost, "/invites/{id}/accept", grantsController.Accept)
+	r.With(mwSession).MethodFunc(http.MethodGet, "/shared", grantsController.GetShared)
+	// Shares
+	r.With(mwSession).MethodFunc(http.MethodGet, "/shares", sharesController.GetList)
+	r.With(mwSession).MethodFunc(http.MethodPost, "/shares", sharesController.Create)
+	r.With(mwSession).MethodFunc(http.MethodDelete, "/shares/{id}", sharesController.Delete)
+	// Trash
+	r.With(mwSession).MethodFunc(http.MethodGet, "/trash", trashController.GetList)
+	r.With(mwSession).MethodFunc(http.MethodDelete, "/trash", trashController.Empty)
+	r.With(mwSession).MethodFunc(http.MethodPost, "/trash/{type}/{id}/restore", trashController.Restore)
+	r.With(mwSession).MethodFunc(http.MethodDelete, "/trash/{type}/{id}", trashController.Purge)
+	// Search
+	r.With(mwNotesRead).MethodFunc(http.MethodGet, "/search", notesController.Search)
+	// Export
+	r.With(mwExport).MethodFunc(http.MethodGet, "/export", exportController.Export)
+	// Import
+	r.With(mwImport).MethodFunc(http.MethodPost, "/import", importsController.Create)
+	r.With(mwImport).MethodFunc(http.MethodGet, "/import/{id}", importsController.GetOne)
 
 	app.router.Mount("/api/v1", r)
 
@@ -90,6 +359,21 @@ func New(
 
 // Run starts application.
 func (app *Application) Run() error {
+	if app.trashRetention > 0 {
+		go app.cleanTrash()
+	}
+	go app.cleanAttachments()
+	go app.processImports()
+	go app.cleanTokens()
+	go app.reloadKeys()
+	if app.renderCache != nil {
+		go app.trimRenderCache()
+	}
+
+	if app.debugAddr != "" {
+		go app.serveDebug()
+	}
+
 	app.log.Infof("Start listening at %s", app.addr)
 	if err := http.ListenAndServe(app.addr, app.router); err != nil {
 		return errors.Wrap(err, "start server")
@@ -0,0 +1,48 @@
+package application
+
+import (
+	"time"
+)
+
+const (
+	// attachmentsCleanupInterval is a period between removals of
+	// deleted attachments from blob store.
+	attachmentsCleanupInterval = 10 * time.Minute
+	// attachmentsCleanupBatch is a number of blobs removed at once.
+	attachmentsCleanupBatch = 100
+)
+
+// cleanAttachments periodically removes blobs of deleted attachments.
+func (app *Application) cleanAttachments() {
+	ticker := time.NewTicker(attachmentsCleanupInterval)
+	defer ticker.Stop()
+
+	for ; true; <-ticker.C {
+		for {
+			keys, err := app.attachments.DeletedKeys(attachmentsCleanupBatch)
+			if err != nil {
+				app.log.Errorf("Failed to get deleted attachments: %v", err)
+				break
+			}
+
+			deleted := make([]string, 0, len(keys))
+			for _, key := range keys {
+				if err := app.blobs.Delete(key); err != nil {
+					app.log.Errorf("Failed to delete attachment blob: %v", err)
+					continue
+				}
+				deleted = append(deleted, key)
+			}
+			if err := app.attachments.ForgetKeys(deleted); err != nil {
+				app.log.Errorf("Failed to forget deleted attachments: %v", err)
+				break
+			}
+
+			// Stop on errors too, so failed blobs are retried
+			// on the next tick
+			if len(deleted) < attachmentsCleanupBatch {
+				break
+			}
+		}
+	}
+}
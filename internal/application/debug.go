@@ -0,0 +1,38 @@
+package application
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http"
+
+	"github.com/go-chi/chi"
+)
+
+// serveDebug serves debug handlers on the internal address. Handlers
+// expose internals of the process, like command line and memory
+// statistics, so they are not available on the public address.
+func (app *Application) serveDebug() {
+	r := chi.NewRouter()
+	r.MethodFunc(http.MethodGet, "/debug/vars", app.debugVars)
+
+	app.log.Infof("Start serving debug handlers at %s", app.debugAddr)
+	if err := http.ListenAndServe(app.debugAddr, r); err != nil {
+		app.log.Errorf("Failed to serve debug handlers: %v", err)
+	}
+}
+
+// debugVars writes exported variables like expvar.Handler, with counters
+// of the application added. The counters are not published to expvar,
+// because they belong to the application, and there can be several
+// applications in one process.
+func (app *Application) debugVars(w http.ResponseWriter, req *http.Request) {
+	vars := map[string]interface{}{}
+	expvar.Do(func(kv expvar.KeyValue) {
+		vars[kv.Key] = json.RawMessage(kv.Value.String())
+	})
+	vars["render_cache"] = app.renderer.Stats()
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	// nolint: errcheck,gosec
+	json.NewEncoder(w).Encode(vars)
+}
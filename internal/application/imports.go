@@ -0,0 +1,210 @@
+package application
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/tetafro/nott-backend-go/internal/domain"
+	"github.com/tetafro/nott-backend-go/internal/importer"
+	"github.com/tetafro/nott-backend-go/internal/storage"
+)
+
+// importsPollInterval is a period between checks for new imports.
+const importsPollInterval = 5 * time.Second
+
+// processImports periodically runs pending imports one by one.
+func (app *Application) processImports() {
+	ticker := time.NewTicker(importsPollInterval)
+	defer ticker.Stop()
+
+	for ; true; <-ticker.C {
+		for {
+			i, err := app.imports.Claim()
+			if err == domain.ErrNotFound {
+				break
+			}
+			if err != nil {
+				app.log.Errorf("Failed to get pending import: %v", err)
+				break
+			}
+			app.runImport(i)
+		}
+	}
+}
+
+// runImport imports the file and saves results. The file is deleted
+// from the blob store in any case.
+func (app *Application) runImport(i domain.Import) {
+	err := app.importFile(&i)
+
+	now := time.Now().UTC()
+	i.FinishedAt = &now
+	i.Status = domain.ImportDone
+	if err != nil {
+		app.log.Errorf("Failed to import file %d: %v", i.ID, err)
+		i.Status = domain.ImportFailed
+		app.addImportError(i, i.Name, err)
+	}
+	if _, err = app.imports.Update(i); err != nil {
+		app.log.Errorf("Failed to update import: %v", err)
+	}
+	if err = app.blobs.Delete(i.Key); err != nil {
+		app.log.Errorf("Failed to delete imported file: %v", err)
+	}
+}
+
+// importFile imports notes into a new folder. Each notepad is saved
+// at once with all its notes, and the counters of the import are
+// updated after that.
+func (app *Application) importFile(i *domain.Import) error {
+	blob, err := app.blobs.Get(i.Key)
+	if err != nil {
+		return errors.Wrap(err, "get file")
+	}
+	defer blob.Close() // nolint: errcheck
+
+	// Zip archives are read in random order, and the blob store
+	// can be remote, so the file is copied first
+	f, err := ioutil.TempFile("", "nott-import-")
+	if err != nil {
+		return errors.Wrap(err, "create temporary file")
+	}
+	defer os.Remove(f.Name()) // nolint: errcheck
+	defer f.Close()           // nolint: errcheck
+	size, err := io.Copy(f, blob)
+	if err != nil {
+		return errors.Wrap(err, "copy file")
+	}
+
+	title := strings.TrimSpace(strings.TrimSuffix(i.Name, path.Ext(i.Name)))
+	if title == "" {
+		title = "Import"
+	}
+	folder, err := app.folders.Create(domain.Folder{UserID: i.UserID, Title: title})
+	if err != nil {
+		return errors.Wrap(err, "create folder")
+	}
+	i.FolderID = &folder.ID
+	if _, err = app.imports.Update(*i); err != nil {
+		return errors.Wrap(err, "update import")
+	}
+
+	return importer.Parse(i.Format, f, size, i.Name, importer.Handler{
+		IDs: app.reserveImportIDs,
+		Folder: func(path []string) error {
+			if _, err := app.imports.ImportFolder(folder.ID, path); err != nil {
+				app.addImportError(*i, strings.Join(path, "/"), err)
+			}
+			return nil
+		},
+		Notepad: func(n importer.Notepad) error {
+			notepad := domain.Notepad{UserID: i.UserID, Title: n.Title}
+			_, err := app.imports.ImportNotepad(folder.ID, n.Folders, notepad, n.Notes)
+			if err != nil {
+				app.addImportError(*i, path.Join(append(n.Folders, n.Title)...), err)
+				return nil
+			}
+			i.Notepads++
+			i.Notes += len(n.Notes)
+			if _, err = app.imports.Update(*i); err != nil {
+				return errors.Wrap(err, "update import")
+			}
+			return nil
+		},
+		Attachment: func(a importer.Attachment) error {
+			a.UserID = i.UserID
+			if err := app.importAttachment(a); err != nil {
+				app.addImportError(*i, a.Name, err)
+			}
+			return nil
+		},
+		Error: func(item string, err error) {
+			app.addImportError(*i, item, err)
+		},
+	})
+}
+
+// reserveImportIDs gets new IDs for notes and attachments of the file.
+func (app *Application) reserveImportIDs(notes, attachments []int) (map[int]int, map[int]int, error) {
+	n, a, err := app.imports.ReserveIDs(len(notes), len(attachments))
+	if err != nil {
+		return nil, nil, err
+	}
+	noteIDs := make(map[int]int, len(notes))
+	for k, id := range notes {
+		noteIDs[id] = n[k]
+	}
+	attachmentIDs := make(map[int]int, len(attachments))
+	for k, id := range attachments {
+		attachmentIDs[id] = a[k]
+	}
+	return noteIDs, attachmentIDs, nil
+}
+
+// importAttachment saves attached file to the blob store and creates
+// the attachment. Limits are the same as for uploaded files.
+func (app *Application) importAttachment(a importer.Attachment) error {
+	if app.attachmentMaxSize > 0 && a.Size > app.attachmentMaxSize {
+		return errors.New("file is too large")
+	}
+	rc, err := a.Open()
+	if err != nil {
+		return errors.Wrap(err, "open file")
+	}
+	defer rc.Close() // nolint: errcheck
+
+	// Size in the header can be wrong, and only the size from
+	// the header is taken from the limits of the import
+	r := &io.LimitedReader{R: rc, N: a.Size + 1}
+
+	// Content type is detected by the content, like for uploaded files
+	head := make([]byte, 512)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return errors.Wrap(err, "read file")
+	}
+	head = head[:n]
+
+	key, err := storage.NewBlobKey(a.UserID)
+	if err != nil {
+		return errors.Wrap(err, "make blob key")
+	}
+	if err = app.blobs.Put(key, io.MultiReader(bytes.NewReader(head), r)); err != nil {
+		app.deleteBlob(key)
+		return errors.Wrap(err, "save file")
+	}
+	if r.N == 0 {
+		app.deleteBlob(key)
+		return errors.New("file is too large")
+	}
+
+	a.ContentType = http.DetectContentType(head)
+	a.Key = key
+	if _, err = app.attachments.Create(a.Attachment, app.attachmentsQuota); err != nil {
+		app.deleteBlob(key)
+		return err
+	}
+	return nil
+}
+
+// deleteBlob deletes blob of the attachment that was not imported.
+func (app *Application) deleteBlob(key string) {
+	if err := app.blobs.Delete(key); err != nil {
+		app.log.Errorf("Failed to delete blob: %v", err)
+	}
+}
+
+func (app *Application) addImportError(i domain.Import, item string, err error) {
+	e := domain.ImportError{ImportID: i.ID, Item: item, Error: err.Error()}
+	if err = app.imports.AddError(e); err != nil {
+		app.log.Errorf("Failed to save import error: %v", err)
+	}
+}
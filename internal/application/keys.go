@@ -0,0 +1,38 @@
+package application
+
+import (
+	"time"
+
+	"github.com/tetafro/nott-backend-go/internal/auth"
+)
+
+// keysReloadInterval is a period between reloads of keys for signing
+// tokens, so keys can be rotated without restarting.
+const keysReloadInterval = time.Minute
+
+// newKeyring creates keyring for signing tokens. The secret key is used
+// if there are no key files.
+func newKeyring(cfg Config) (*auth.Keyring, error) {
+	if len(cfg.JWTKeys) == 0 {
+		return auth.NewHMACKeyring(cfg.SignKey), nil
+	}
+	// Tokens signed with retired keys must be accepted
+	// at least until they expire
+	grace := cfg.JWTKeysGrace
+	if grace == 0 {
+		grace = cfg.AccessTokenTTL
+	}
+	return auth.LoadKeyring(cfg.JWTKeys, cfg.JWTSigningKey, grace)
+}
+
+// reloadKeys periodically reads keys for signing tokens from files.
+func (app *Application) reloadKeys() {
+	ticker := time.NewTicker(keysReloadInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := app.keys.Reload(); err != nil {
+			app.log.Errorf("Failed to reload keys: %v", err)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package application
+
+import (
+	"time"
+)
+
+// renderCacheTrimInterval is a period between removals of old entries
+// from the render cache.
+const renderCacheTrimInterval = 10 * time.Minute
+
+// trimRenderCache periodically removes old entries from the render
+// cache. Between trims the cache can grow beyond its size.
+func (app *Application) trimRenderCache() {
+	ticker := time.NewTicker(renderCacheTrimInterval)
+	defer ticker.Stop()
+
+	for ; true; <-ticker.C {
+		if err := app.renderCache.Trim(); err != nil {
+			app.log.Errorf("Failed to trim render cache: %v", err)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package application
+
+import (
+	"time"
+)
+
+// tokensCleanInterval is a period between removals of expired refresh
+// tokens, sessions, revoked access tokens and password reset tokens.
+const tokensCleanInterval = time.Hour
+
+// cleanTokens periodically removes expired tokens, they cannot be used
+// anyway.
+func (app *Application) cleanTokens() {
+	ticker := time.NewTicker(tokensCleanInterval)
+	defer ticker.Stop()
+
+	for ; true; <-ticker.C {
+		if err := app.refreshTokens.DeleteExpired(); err != nil {
+			app.log.Errorf("Failed to delete expired refresh tokens: %v", err)
+		}
+		if err := app.sessions.DeleteExpired(); err != nil {
+			app.log.Errorf("Failed to delete expired sessions: %v", err)
+		}
+		if err := app.denylist.DeleteExpired(); err != nil {
+			app.log.Errorf("Failed to delete expired revoked tokens: %v", err)
+		}
+		if err := app.resets.DeleteExpired(); err != nil {
+			app.log.Errorf("Failed to delete expired password reset tokens: %v", err)
+		}
+	}
+}
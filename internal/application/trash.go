@@ -0,0 +1,24 @@
+package application
+
+import (
+	"time"
+
+	"github.com/tetafro/nott-backend-go/internal/storage"
+)
+
+// trashCleanupInterval is a period between trash cleanups.
+const trashCleanupInterval = time.Hour
+
+// cleanTrash periodically deletes objects that have been in trash
+// for longer than retention period.
+func (app *Application) cleanTrash() {
+	ticker := time.NewTicker(trashCleanupInterval)
+	defer ticker.Stop()
+
+	for ; true; <-ticker.C {
+		before := time.Now().UTC().Add(-app.trashRetention)
+		if err := app.trash.Empty(storage.TrashFilter{DeletedBefore: &before}); err != nil {
+			app.log.Errorf("Failed to clean trash: %v", err)
+		}
+	}
+}
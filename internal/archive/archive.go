@@ -0,0 +1,142 @@
+// Package archive deals with zip archives of user's data. Folders
+// become directories, notepads become subdirectories, and notes become
+// markdown files with YAML front matter. The manifest keeps everything
+// that doesn't fit into the file tree, so the archive can be imported
+// without losses.
+package archive
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/tetafro/nott-backend-go/internal/domain"
+)
+
+// Version is a version of the archive format.
+const Version = 1
+
+// ManifestName is a name of the manifest file in the root of the archive.
+const ManifestName = "manifest.json"
+
+// AttachmentsDir is a directory for attached files in the root
+// of the archive.
+const AttachmentsDir = "attachments"
+
+// Manifest describes contents of the archive. Notes are not listed,
+// their metadata is kept in the front matter.
+type Manifest struct {
+	Version     int          `json:"version"`
+	ExportedAt  time.Time    `json:"exported_at"`
+	Folders     []Folder     `json:"folders"`
+	Notepads    []Notepad    `json:"notepads"`
+	Attachments []Attachment `json:"attachments"`
+}
+
+// Folder is a folder in the manifest.
+type Folder struct {
+	ID        int        `json:"id"`
+	ParentID  *int       `json:"parent_id"`
+	Title     string     `json:"title"`
+	Path      string     `json:"path"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at"`
+}
+
+// Notepad is a notepad in the manifest.
+type Notepad struct {
+	ID        int        `json:"id"`
+	FolderID  int        `json:"folder_id"`
+	Title     string     `json:"title"`
+	Path      string     `json:"path"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at"`
+}
+
+// Attachment is an attached file in the manifest.
+type Attachment struct {
+	ID          int       `json:"id"`
+	NoteID      int       `json:"note_id"`
+	Name        string    `json:"name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	Path        string    `json:"path"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// NewManifest makes manifest of the objects. Paths of folders and
+// notepads follow the hierarchy. Folders, whose parents are not in the
+// list, are placed in the root, as well as notepads without folders.
+// If parents of folders make a cycle, the folder where the cycle is
+// found is placed in the root.
+func NewManifest(
+	paths *Paths,
+	folders []domain.Folder,
+	notepads []domain.Notepad,
+	attachments []domain.Attachment,
+) Manifest {
+	m := Manifest{
+		Version:     Version,
+		ExportedAt:  time.Now().UTC(),
+		Folders:     make([]Folder, 0, len(folders)),
+		Notepads:    make([]Notepad, 0, len(notepads)),
+		Attachments: make([]Attachment, 0, len(attachments)),
+	}
+
+	byID := map[int]domain.Folder{}
+	for _, f := range folders {
+		byID[f.ID] = f
+	}
+	folderPaths := map[int]string{}
+	// Folders whose paths are being made, a folder whose parent is one
+	// of them is in a cycle, and it's placed in the root
+	making := map[int]bool{}
+	var folderPath func(f domain.Folder) string
+	folderPath = func(f domain.Folder) string {
+		if p, ok := folderPaths[f.ID]; ok {
+			return p
+		}
+		making[f.ID] = true
+		dir := ""
+		if f.ParentID != nil {
+			if parent, ok := byID[*f.ParentID]; ok && !making[parent.ID] {
+				dir = folderPath(parent)
+			}
+		}
+		p := paths.Make(dir, f.Title, "")
+		folderPaths[f.ID] = p
+		return p
+	}
+
+	for _, f := range folders {
+		m.Folders = append(m.Folders, Folder{
+			ID:        f.ID,
+			ParentID:  f.ParentID,
+			Title:     f.Title,
+			Path:      folderPath(f),
+			CreatedAt: f.CreatedAt,
+			UpdatedAt: f.UpdatedAt,
+		})
+	}
+	for _, n := range notepads {
+		m.Notepads = append(m.Notepads, Notepad{
+			ID:        n.ID,
+			FolderID:  n.FolderID,
+			Title:     n.Title,
+			Path:      paths.Make(folderPaths[n.FolderID], n.Title, ""),
+			CreatedAt: n.CreatedAt,
+			UpdatedAt: n.UpdatedAt,
+		})
+	}
+	for _, a := range attachments {
+		m.Attachments = append(m.Attachments, Attachment{
+			ID:          a.ID,
+			NoteID:      a.NoteID,
+			Name:        a.Name,
+			ContentType: a.ContentType,
+			Size:        a.Size,
+			Path:        paths.Make(AttachmentsDir, strconv.Itoa(a.ID)+"-"+a.Name, ""),
+			CreatedAt:   a.CreatedAt,
+		})
+	}
+	return m
+}
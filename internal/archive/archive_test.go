@@ -0,0 +1,80 @@
+package archive
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tetafro/nott-backend-go/internal/domain"
+)
+
+func TestNewManifest(t *testing.T) {
+	Int := func(n int) *int {
+		return &n
+	}
+	created := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	folders := []domain.Folder{
+		{ID: 2, ParentID: Int(1), Title: "Plans", CreatedAt: created},
+		{ID: 1, Title: "Work", CreatedAt: created},
+		{ID: 3, ParentID: Int(100), Title: "Shared", CreatedAt: created},
+	}
+	notepads := []domain.Notepad{
+		{ID: 10, FolderID: 2, Title: "2020", CreatedAt: created},
+		{ID: 11, FolderID: 2, Title: "2020", CreatedAt: created},
+		{ID: 12, FolderID: 200, Title: "Recipes", CreatedAt: created},
+	}
+	attachments := []domain.Attachment{
+		{ID: 30, NoteID: 20, Name: "photo.jpg", ContentType: "image/jpeg", Size: 10, CreatedAt: created},
+	}
+
+	m := NewManifest(NewPaths(), folders, notepads, attachments)
+	assert.Equal(t, Version, m.Version)
+	assert.Equal(t, []Folder{
+		{ID: 2, ParentID: Int(1), Title: "Plans", Path: "Work/Plans", CreatedAt: created},
+		{ID: 1, Title: "Work", Path: "Work", CreatedAt: created},
+		{ID: 3, ParentID: Int(100), Title: "Shared", Path: "Shared", CreatedAt: created},
+	}, m.Folders)
+	assert.Equal(t, []Notepad{
+		{ID: 10, FolderID: 2, Title: "2020", Path: "Work/Plans/2020", CreatedAt: created},
+		{ID: 11, FolderID: 2, Title: "2020", Path: "Work/Plans/2020 (2)", CreatedAt: created},
+		{ID: 12, FolderID: 200, Title: "Recipes", Path: "Recipes", CreatedAt: created},
+	}, m.Notepads)
+	assert.Equal(t, []Attachment{
+		{
+			ID:          30,
+			NoteID:      20,
+			Name:        "photo.jpg",
+			ContentType: "image/jpeg",
+			Size:        10,
+			Path:        "attachments/30-photo.jpg",
+			CreatedAt:   created,
+		},
+	}, m.Attachments)
+}
+
+func TestNewManifestWithCycle(t *testing.T) {
+	Int := func(n int) *int {
+		return &n
+	}
+
+	folders := []domain.Folder{
+		{ID: 1, ParentID: Int(3), Title: "A"},
+		{ID: 2, ParentID: Int(1), Title: "B"},
+		{ID: 3, ParentID: Int(2), Title: "C"},
+		{ID: 4, ParentID: Int(4), Title: "Self"},
+	}
+
+	m := NewManifest(NewPaths(), folders, nil, nil)
+	paths := map[int]string{}
+	for _, f := range m.Folders {
+		paths[f.ID] = f.Path
+	}
+	assert.Equal(t, map[int]string{
+		1: "B/C/A",
+		2: "B",
+		3: "B/C",
+		4: "Self",
+	}, paths)
+}
@@ -0,0 +1,66 @@
+package archive
+
+import (
+	"path"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxNameLength is a maximum length of file name in characters,
+// without extension and number suffix.
+const maxNameLength = 100
+
+// Paths makes unique paths for objects in the archive.
+type Paths struct {
+	// Paths are compared case-insensitively, since some file systems
+	// don't distinguish them
+	used map[string]bool
+}
+
+// NewPaths creates new set of paths. Names of the manifest and of the
+// attachments directory are reserved.
+func NewPaths() *Paths {
+	return &Paths{used: map[string]bool{
+		strings.ToLower(ManifestName):   true,
+		strings.ToLower(AttachmentsDir): true,
+	}}
+}
+
+// Make makes a path for the object with the title inside the directory.
+// Characters that are not allowed in file names are replaced, and
+// a number is added to the name if the path is already taken.
+func (p *Paths) Make(dir, title, ext string) string {
+	name := SafeName(title)
+	for i := 1; ; i++ {
+		n := name
+		if i > 1 {
+			n += " (" + strconv.Itoa(i) + ")"
+		}
+		full := path.Join(dir, n+ext)
+		if key := strings.ToLower(full); !p.used[key] {
+			p.used[key] = true
+			return full
+		}
+	}
+}
+
+// SafeName makes file name from the title.
+func SafeName(title string) string {
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '_'
+		}
+		return r
+	}, title)
+	if utf8.RuneCountInString(name) > maxNameLength {
+		name = string([]rune(name)[:maxNameLength])
+	}
+	// Leading dots make hidden files, trailing ones are dropped on Windows
+	name = strings.Trim(name, ". ")
+	if name == "" {
+		name = "Untitled"
+	}
+	return name
+}
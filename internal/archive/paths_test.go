@@ -0,0 +1,38 @@
+package archive
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaths_Make(t *testing.T) {
+	p := NewPaths()
+
+	assert.Equal(t, "Work", p.Make("", "Work", ""))
+	assert.Equal(t, "work (2)", p.Make("", "work", ""))
+	assert.Equal(t, "Work/Plans.md", p.Make("Work", "Plans", ".md"))
+	assert.Equal(t, "Work/Plans (2).md", p.Make("Work", "Plans", ".md"))
+	assert.Equal(t, "Work/Plans (2)", p.Make("Work", "Plans (2)", ""))
+	assert.Equal(t, "manifest.json (2)", p.Make("", "manifest.json", ""))
+	assert.Equal(t, "Attachments (2)", p.Make("", "Attachments", ""))
+}
+
+func TestSafeName(t *testing.T) {
+	testCases := map[string]string{
+		"Plans":            "Plans",
+		"2020/01: \"Q1\"?": "2020_01_ _Q1__",
+		"../secret":        "_secret",
+		"  . ":             "Untitled",
+		"":                 "Untitled",
+		"line\nbreak":      "line_break",
+		"Привет":           "Привет",
+	}
+	for title, name := range testCases {
+		assert.Equal(t, name, SafeName(title), title)
+	}
+
+	long := strings.Repeat("я", 200)
+	assert.Equal(t, strings.Repeat("я", maxNameLength), SafeName(long))
+}
@@ -0,0 +1,139 @@
+package archive
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/tetafro/nott-backend-go/internal/domain"
+)
+
+// frontMatter is a delimiter of front matter.
+const frontMatter = "---"
+
+// ParseNote parses markdown file with optional front matter, made
+// by FormatNote or by other tools. Only known fields are used, others
+// are ignored. The title is empty if the front matter doesn't have it.
+// ID is the one the note had when it was exported, it's used only for
+// restoring links to the note.
+func ParseNote(data []byte) (domain.Note, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // UTF-8 BOM
+	text := strings.Replace(string(data), "\r\n", "\n", -1)
+
+	if !strings.HasPrefix(text, frontMatter+"\n") {
+		return domain.Note{Text: text}, nil
+	}
+	rest := text[len(frontMatter)+1:]
+	var header, body string
+	switch i := strings.Index(rest, "\n"+frontMatter+"\n"); {
+	case strings.HasPrefix(rest, frontMatter+"\n"):
+		body = rest[len(frontMatter)+1:]
+	case i >= 0:
+		header, body = rest[:i], rest[i+len(frontMatter)+2:]
+	case strings.HasSuffix(rest, "\n"+frontMatter):
+		header = rest[:len(rest)-len(frontMatter)-1]
+	default:
+		return domain.Note{Text: text}, nil
+	}
+	body = strings.TrimPrefix(body, "\n")
+
+	n := domain.Note{Text: body}
+	for _, line := range strings.Split(header, "\n") {
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key, value := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		var err error
+		switch key {
+		case "id":
+			// Other tools can have IDs that are not numbers
+			n.ID, _ = strconv.Atoi(value)
+		case "title":
+			n.Title, err = unquote(value)
+		case "created_at":
+			n.CreatedAt, err = time.Parse(time.RFC3339, value)
+		case "updated_at":
+			var t time.Time
+			t, err = time.Parse(time.RFC3339, value)
+			n.UpdatedAt = &t
+		case "tags":
+			n.Tags, err = parseList(value)
+		}
+		if err != nil {
+			return domain.Note{}, errors.Wrapf(err, "invalid %s", key)
+		}
+	}
+	return n, nil
+}
+
+// parseList parses YAML flow sequence of strings, like [a, "b"].
+func parseList(s string) ([]string, error) {
+	if !strings.HasPrefix(s, "[") || !strings.HasSuffix(s, "]") {
+		return nil, errors.New("not a list")
+	}
+	s = strings.TrimSpace(s[1 : len(s)-1])
+	if s == "" {
+		return nil, nil
+	}
+	var items []string
+	for s != "" {
+		var item string
+		if strings.HasPrefix(s, `"`) || strings.HasPrefix(s, "'") {
+			end, err := closingQuote(s)
+			if err != nil {
+				return nil, err
+			}
+			if item, err = unquote(s[:end+1]); err != nil {
+				return nil, err
+			}
+			s = strings.TrimSpace(s[end+1:])
+			s = strings.TrimSpace(strings.TrimPrefix(s, ","))
+		} else {
+			parts := strings.SplitN(s, ",", 2)
+			item = strings.TrimSpace(parts[0])
+			s = ""
+			if len(parts) == 2 {
+				s = strings.TrimSpace(parts[1])
+			}
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// unquote unquotes YAML scalar. Plain scalars are returned as is.
+func unquote(s string) (string, error) {
+	switch {
+	case strings.HasPrefix(s, `"`):
+		return strconv.Unquote(s)
+	case strings.HasPrefix(s, "'"):
+		if len(s) < 2 || !strings.HasSuffix(s, "'") {
+			return "", errors.New("unterminated string")
+		}
+		return strings.Replace(s[1:len(s)-1], "''", "'", -1), nil
+	default:
+		return s, nil
+	}
+}
+
+// closingQuote finds the closing quote of the string in the beginning
+// of s. Double-quoted strings use backslash escapes, single-quoted
+// ones use doubled quotes.
+func closingQuote(s string) (int, error) {
+	q := s[0]
+	for i := 1; i < len(s); i++ {
+		switch {
+		case q == '"' && s[i] == '\\':
+			i++
+		case s[i] == q && q == '\'' && i+1 < len(s) && s[i+1] == '\'':
+			i++
+		case s[i] == q:
+			return i, nil
+		}
+	}
+	return 0, errors.New("unterminated string")
+}
@@ -0,0 +1,71 @@
+package archive
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tetafro/nott-backend-go/internal/domain"
+)
+
+func TestParseNote(t *testing.T) {
+	created := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	updated := time.Date(2020, 2, 3, 4, 5, 6, 0, time.UTC)
+
+	t.Run("formatted note", func(t *testing.T) {
+		n := domain.Note{
+			ID:        20,
+			Title:     `Say "hi": \o/`,
+			Text:      "# Hello\n\n---\n\nWorld\n",
+			Tags:      []string{"todo", "work/plans"},
+			CreatedAt: created,
+			UpdatedAt: &updated,
+		}
+		parsed, err := ParseNote(FormatNote(n))
+		assert.NoError(t, err)
+		assert.Equal(t, n, parsed)
+	})
+
+	t.Run("front matter of other tools", func(t *testing.T) {
+		md := "\xef\xbb\xbf---\r\n" +
+			"id: 8f2e-41\r\n" +
+			"title: It's plain\r\n" +
+			"author: Bob\r\n" +
+			"tags: ['a, b', \"c\", d]\r\n" +
+			"---\r\n" +
+			"Hello"
+		parsed, err := ParseNote([]byte(md))
+		assert.NoError(t, err)
+		assert.Equal(t, domain.Note{
+			Title: "It's plain",
+			Text:  "Hello",
+			Tags:  []string{"a, b", "c", "d"},
+		}, parsed)
+	})
+
+	t.Run("note without front matter", func(t *testing.T) {
+		md := "Hello\n---\nWorld"
+		parsed, err := ParseNote([]byte(md))
+		assert.NoError(t, err)
+		assert.Equal(t, domain.Note{Text: md}, parsed)
+	})
+
+	t.Run("unterminated front matter", func(t *testing.T) {
+		md := "---\ntitle: Hello\nWorld"
+		parsed, err := ParseNote([]byte(md))
+		assert.NoError(t, err)
+		assert.Equal(t, domain.Note{Text: md}, parsed)
+	})
+
+	t.Run("invalid front matter", func(t *testing.T) {
+		for _, md := range []string{
+			"---\ncreated_at: yesterday\n---\nHello",
+			"---\ntitle: \"Hello\n---\nHello",
+			"---\ntags: [\"a]\n---\nHello",
+		} {
+			_, err := ParseNote([]byte(md))
+			assert.Error(t, err, md)
+		}
+	})
+}
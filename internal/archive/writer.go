@@ -0,0 +1,120 @@
+package archive
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/tetafro/nott-backend-go/internal/domain"
+)
+
+// Writer writes archive to the underlying writer as it goes, so
+// the archive can be streamed.
+type Writer struct {
+	zw *zip.Writer
+}
+
+// NewWriter creates new archive writer.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{zw: zip.NewWriter(w)}
+}
+
+// WriteManifest writes manifest, and directories of all folders and
+// notepads, so empty ones are kept too.
+func (w *Writer) WriteManifest(m Manifest) error {
+	f, err := w.create(ManifestName)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err = enc.Encode(m); err != nil {
+		return errors.Wrap(err, "encode manifest")
+	}
+
+	for _, f := range m.Folders {
+		if _, err = w.create(f.Path + "/"); err != nil {
+			return err
+		}
+	}
+	for _, n := range m.Notepads {
+		if _, err = w.create(n.Path + "/"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteNote writes note as a markdown file with front matter.
+func (w *Writer) WriteNote(path string, n domain.Note) error {
+	f, err := w.create(path)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(FormatNote(n)); err != nil {
+		return errors.Wrapf(err, "write %s", path)
+	}
+	return nil
+}
+
+// WriteFile writes file with contents from the reader.
+func (w *Writer) WriteFile(path string, r io.Reader) error {
+	f, err := w.create(path)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(f, r); err != nil {
+		return errors.Wrapf(err, "write %s", path)
+	}
+	return nil
+}
+
+// Close finishes the archive. It doesn't close the underlying writer.
+func (w *Writer) Close() error {
+	return w.zw.Close()
+}
+
+func (w *Writer) create(path string) (io.Writer, error) {
+	f, err := w.zw.CreateHeader(&zip.FileHeader{
+		Name:     path,
+		Method:   zip.Deflate,
+		Modified: time.Now(),
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "create %s", path)
+	}
+	return f, nil
+}
+
+// FormatNote formats note as markdown with YAML front matter, that
+// keeps ID, title, timestamps and tags of the note.
+func FormatNote(n domain.Note) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("---\n")
+	buf.WriteString("id: " + strconv.Itoa(n.ID) + "\n")
+	buf.WriteString("title: " + quote(n.Title) + "\n")
+	buf.WriteString("created_at: " + n.CreatedAt.UTC().Format(time.RFC3339) + "\n")
+	if n.UpdatedAt != nil {
+		buf.WriteString("updated_at: " + n.UpdatedAt.UTC().Format(time.RFC3339) + "\n")
+	}
+	tags := make([]string, len(n.Tags))
+	for i, t := range n.Tags {
+		tags[i] = quote(t)
+	}
+	buf.WriteString("tags: [" + strings.Join(tags, ", ") + "]\n")
+	buf.WriteString("---\n\n")
+	buf.WriteString(n.Text)
+	return buf.Bytes()
+}
+
+// quote quotes string for YAML. Escape sequences of Go are valid
+// in double-quoted YAML scalars.
+func quote(s string) string {
+	return strconv.Quote(s)
+}
@@ -0,0 +1,91 @@
+package archive
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tetafro/nott-backend-go/internal/domain"
+)
+
+func TestWriter(t *testing.T) {
+	created := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	m := Manifest{
+		Version:    Version,
+		ExportedAt: created,
+		Folders:    []Folder{{ID: 1, Title: "Work", Path: "Work", CreatedAt: created}},
+		Notepads:   []Notepad{{ID: 10, FolderID: 1, Title: "Plans", Path: "Work/Plans", CreatedAt: created}},
+	}
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	assert.NoError(t, w.WriteManifest(m))
+	assert.NoError(t, w.WriteNote("Work/Plans/Q1.md", domain.Note{ID: 20, Title: "Q1", Text: "Hello", CreatedAt: created}))
+	assert.NoError(t, w.WriteFile("attachments/30-a.txt", strings.NewReader("file")))
+	assert.NoError(t, w.Close())
+
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoError(t, err)
+
+	files := map[string]string{}
+	for _, f := range r.File {
+		rc, err := f.Open()
+		assert.NoError(t, err)
+		b, err := ioutil.ReadAll(rc)
+		assert.NoError(t, err)
+		assert.NoError(t, rc.Close())
+		files[f.Name] = string(b)
+	}
+
+	var manifest Manifest
+	assert.NoError(t, json.Unmarshal([]byte(files[ManifestName]), &manifest))
+	assert.Equal(t, m, manifest)
+
+	assert.Equal(t, "", files["Work/"])
+	assert.Equal(t, "", files["Work/Plans/"])
+	assert.Contains(t, files["Work/Plans/Q1.md"], "title: \"Q1\"\n")
+	assert.Equal(t, "file", files["attachments/30-a.txt"])
+}
+
+func TestFormatNote(t *testing.T) {
+	created := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	updated := time.Date(2020, 2, 3, 4, 5, 6, 0, time.FixedZone("MSK", 3*3600))
+
+	t.Run("full note", func(t *testing.T) {
+		n := domain.Note{
+			ID:        20,
+			Title:     `Say "hi"`,
+			Text:      "# Hello\n\nWorld",
+			Tags:      []string{"todo", "work/plans"},
+			CreatedAt: created,
+			UpdatedAt: &updated,
+		}
+		md := "---\n" +
+			"id: 20\n" +
+			"title: \"Say \\\"hi\\\"\"\n" +
+			"created_at: 2020-01-02T03:04:05Z\n" +
+			"updated_at: 2020-02-03T01:05:06Z\n" +
+			"tags: [\"todo\", \"work/plans\"]\n" +
+			"---\n\n" +
+			"# Hello\n\nWorld"
+		assert.Equal(t, md, string(FormatNote(n)))
+	})
+
+	t.Run("note without updates and tags", func(t *testing.T) {
+		n := domain.Note{ID: 20, Title: "Note", Text: "Hello", CreatedAt: created}
+		md := "---\n" +
+			"id: 20\n" +
+			"title: \"Note\"\n" +
+			"created_at: 2020-01-02T03:04:05Z\n" +
+			"tags: []\n" +
+			"---\n\n" +
+			"Hello"
+		assert.Equal(t, md, string(FormatNote(n)))
+	})
+}
@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultDenylistTTL is a default time, during which a token is not
+// checked again after it's found valid.
+const DefaultDenylistTTL = 30 * time.Second
+
+// CachedDenylist is a denylist with in-memory cache, so the storage
+// is not queried on every request. Revoked tokens are cached until
+// they expire. Valid tokens are cached for ttl, so tokens revoked
+// by other instances of the application are denied after that time.
+type CachedDenylist struct {
+	list Denylist
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu sync.Mutex
+	// Expiration times of revoked tokens
+	revoked map[string]time.Time
+	// Times when valid tokens were checked
+	valid     map[string]time.Time
+	lastSweep time.Time
+}
+
+// NewCachedDenylist creates new cache of the denylist.
+func NewCachedDenylist(list Denylist, ttl time.Duration) *CachedDenylist {
+	return &CachedDenylist{
+		list:      list,
+		ttl:       ttl,
+		now:       time.Now,
+		revoked:   map[string]time.Time{},
+		valid:     map[string]time.Time{},
+		lastSweep: time.Now(),
+	}
+}
+
+// Revoke adds token to the list.
+func (d *CachedDenylist) Revoke(id string, expiresAt time.Time) error {
+	if err := d.list.Revoke(id, expiresAt); err != nil {
+		return errors.Wrap(err, "revoke token")
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.revoked[id] = expiresAt
+	delete(d.valid, id)
+	return nil
+}
+
+// Revoked checks if the token is in the list.
+func (d *CachedDenylist) Revoked(id string) (bool, error) {
+	now := d.now()
+
+	d.mu.Lock()
+	if now.Sub(d.lastSweep) > d.ttl {
+		d.sweep(now)
+	}
+	if _, ok := d.revoked[id]; ok {
+		d.mu.Unlock()
+		return true, nil
+	}
+	if t, ok := d.valid[id]; ok && now.Sub(t) < d.ttl {
+		d.mu.Unlock()
+		return false, nil
+	}
+	d.mu.Unlock()
+
+	revoked, err := d.list.Revoked(id)
+	if err != nil {
+		return false, errors.Wrap(err, "check token")
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if revoked {
+		// Expiration is unknown, the token is kept until the next
+		// sweep after its last check
+		d.revoked[id] = now.Add(d.ttl)
+	} else {
+		d.valid[id] = now
+	}
+	return revoked, nil
+}
+
+// sweep removes expired entries.
+func (d *CachedDenylist) sweep(now time.Time) {
+	for id, exp := range d.revoked {
+		if now.After(exp) {
+			delete(d.revoked, id)
+		}
+	}
+	for id, t := range d.valid {
+		if now.Sub(t) >= d.ttl {
+			delete(d.valid, id)
+		}
+	}
+	d.lastSweep = now
+}
@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCachedDenylist(t *testing.T) {
+	exp := time.Now().Add(time.Hour)
+
+	t.Run("Revoke token", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		listMock := NewMockDenylist(ctrl)
+		listMock.EXPECT().Revoke("abc", exp).Return(nil)
+
+		d := NewCachedDenylist(listMock, time.Minute)
+		assert.NoError(t, d.Revoke("abc", exp))
+
+		// Revoked token is not checked in the list
+		revoked, err := d.Revoked("abc")
+		assert.NoError(t, err)
+		assert.True(t, revoked)
+	})
+
+	t.Run("Check valid token", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		listMock := NewMockDenylist(ctrl)
+		listMock.EXPECT().Revoked("abc").Return(false, nil).Times(2)
+
+		now := time.Now()
+		d := NewCachedDenylist(listMock, time.Minute)
+		d.now = func() time.Time { return now }
+
+		for i := 0; i < 3; i++ {
+			revoked, err := d.Revoked("abc")
+			assert.NoError(t, err)
+			assert.False(t, revoked)
+		}
+
+		// Token is checked again after ttl
+		now = now.Add(time.Minute)
+		revoked, err := d.Revoked("abc")
+		assert.NoError(t, err)
+		assert.False(t, revoked)
+	})
+
+	t.Run("Check token revoked by another instance", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		listMock := NewMockDenylist(ctrl)
+		listMock.EXPECT().Revoked("abc").Return(true, nil)
+
+		d := NewCachedDenylist(listMock, time.Minute)
+		for i := 0; i < 2; i++ {
+			revoked, err := d.Revoked("abc")
+			assert.NoError(t, err)
+			assert.True(t, revoked)
+		}
+	})
+
+	t.Run("Fail to check token", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		listMock := NewMockDenylist(ctrl)
+		listMock.EXPECT().Revoked("abc").Return(false, errors.New("error"))
+
+		d := NewCachedDenylist(listMock, time.Minute)
+		_, err := d.Revoked("abc")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,312 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/pkg/errors"
+)
+
+// minRSABits is a minimal size of RSA keys.
+const minRSABits = 2048
+
+// keyExt is an extension of key files in directories.
+const keyExt = ".pem"
+
+// Keyring is a set of keys for signing tokens. One key signs new tokens,
+// and the others only verify tokens during the grace period after they
+// stop signing, so keys can be rotated without logging users out.
+type Keyring struct {
+	paths     []string
+	signingID string
+	grace     time.Duration
+	now       func() time.Time
+
+	mu      sync.RWMutex
+	keys    map[string]*signingKey
+	signing *signingKey
+}
+
+// signingKey is a key of the keyring. Key ID is the name of its file.
+type signingKey struct {
+	id      string
+	method  jwt.SigningMethod
+	private interface{}
+	public  interface{}
+	// modified is a modification time of the key file
+	modified time.Time
+	// retiredAt is a time when the key stopped signing tokens
+	retiredAt *time.Time
+}
+
+// NewHMACKeyring creates keyring with one secret key for HS256. Tokens
+// signed with it can only be verified by the ones who hold the secret.
+func NewHMACKeyring(secret string) *Keyring {
+	k := &signingKey{
+		method:  jwt.SigningMethodHS256,
+		private: []byte(secret),
+		public:  []byte(secret),
+	}
+	return &Keyring{
+		now:     time.Now,
+		keys:    map[string]*signingKey{k.id: k},
+		signing: k,
+	}
+}
+
+// LoadKeyring loads RSA and Ed25519 private keys in PEM format from
+// files and directories. Keys in directories must have .pem extension.
+// Tokens are signed with the key with the given ID, or with the last
+// one in alphabetical order if it's empty. Other keys are retired.
+func LoadKeyring(paths []string, signingID string, grace time.Duration) (*Keyring, error) {
+	k := &Keyring{
+		paths:     paths,
+		signingID: signingID,
+		grace:     grace,
+		now:       time.Now,
+		keys:      map[string]*signingKey{},
+	}
+	if err := k.Reload(); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// Reload reads keys from files again. Keys that stop signing tokens,
+// including removed ones, are retired, and tokens signed with them
+// are rejected after the grace period.
+//
+// There is no record of when keys were retired before the start, so
+// on the first load other keys are considered retired when the signing
+// key file was modified, i.e. when the signing key was put in place.
+// Otherwise keys that retired long ago would be accepted again after
+// every restart.
+func (k *Keyring) Reload() error {
+	if len(k.paths) == 0 {
+		return nil
+	}
+	keys, err := readKeys(k.paths)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return errors.New("no keys found")
+	}
+	signingID := k.signingID
+	if signingID == "" {
+		for id := range keys {
+			if id > signingID {
+				signingID = id
+			}
+		}
+	}
+	if _, ok := keys[signingID]; !ok {
+		return errors.Errorf("signing key %q not found", signingID)
+	}
+
+	now := k.now()
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	retiredAt := now
+	if len(k.keys) == 0 && keys[signingID].modified.Before(now) {
+		retiredAt = keys[signingID].modified
+	}
+
+	for id, key := range keys {
+		if old, ok := k.keys[id]; ok {
+			key.retiredAt = old.retiredAt
+		}
+	}
+	// Keep removed keys until the end of the grace period, expired keys
+	// that are still in files are kept too, so they are not accepted
+	// again as newly retired ones
+	for id, key := range k.keys {
+		if _, ok := keys[id]; !ok && k.active(key) {
+			keys[id] = key
+		}
+	}
+	for id, key := range keys {
+		if id == signingID {
+			key.retiredAt = nil
+		} else if key.retiredAt == nil {
+			key.retiredAt = &retiredAt
+		}
+	}
+	k.keys = keys
+	k.signing = keys[signingID]
+	return nil
+}
+
+// signingKey gets the key for signing new tokens.
+func (k *Keyring) signingKey() *signingKey {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.signing
+}
+
+// verificationKey gets the key for verifying tokens signed with the key
+// with the given ID.
+func (k *Keyring) verificationKey(token *jwt.Token) (interface{}, error) {
+	id, _ := token.Header["kid"].(string)
+
+	k.mu.RLock()
+	key, ok := k.keys[id]
+	k.mu.RUnlock()
+	if !ok || !k.active(key) {
+		return nil, errors.Errorf("unknown key %q", id)
+	}
+	// Algorithm is checked to prevent signing tokens with public keys
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, errors.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return key.public, nil
+}
+
+// active checks if tokens signed with the key are accepted.
+func (k *Keyring) active(key *signingKey) bool {
+	return key.retiredAt == nil || k.now().Sub(*key.retiredAt) <= k.grace
+}
+
+// JWKS is a set of public keys in JWK format (RFC 7517).
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWK is a public key in JWK format.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	ID        string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	// RSA keys
+	Modulus  string `json:"n,omitempty"`
+	Exponent string `json:"e,omitempty"`
+	// Ed25519 keys
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+}
+
+// JWKS gets public keys for verifying tokens. Secret keys are
+// not published.
+func (k *Keyring) JWKS() JWKS {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	jwks := JWKS{Keys: []JWK{}}
+	for _, key := range k.keys {
+		if !k.active(key) {
+			continue
+		}
+		jwk := JWK{ID: key.id, Use: "sig", Algorithm: key.method.Alg()}
+		switch pub := key.public.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.Modulus = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.Exponent = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		default:
+			continue
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	sort.Slice(jwks.Keys, func(i, j int) bool {
+		return jwks.Keys[i].ID < jwks.Keys[j].ID
+	})
+	return jwks
+}
+
+// readKeys reads keys from files and directories.
+func readKeys(paths []string) (map[string]*signingKey, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, errors.Wrap(err, "stat path")
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(path, "*"+keyExt))
+		if err != nil {
+			return nil, errors.Wrap(err, "list keys")
+		}
+		files = append(files, matches...)
+	}
+
+	keys := map[string]*signingKey{}
+	for _, file := range files {
+		id := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		if _, ok := keys[id]; ok {
+			return nil, errors.Errorf("duplicate key %q", id)
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, errors.Wrap(err, "stat key")
+		}
+		data, err := ioutil.ReadFile(file) // nolint: gosec
+		if err != nil {
+			return nil, errors.Wrap(err, "read key")
+		}
+		key, err := parseKey(data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parse key %s", file)
+		}
+		key.id = id
+		key.modified = info.ModTime()
+		keys[id] = key
+	}
+	return keys, nil
+}
+
+// parseKey parses RSA or Ed25519 private key in PEM format, either
+// PKCS #8 or PKCS #1 for RSA.
+func parseKey(data []byte) (*signingKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("invalid pem")
+	}
+
+	var private interface{}
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		private, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		private, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return nil, errors.Errorf("unsupported pem type %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch k := private.(type) {
+	case *rsa.PrivateKey:
+		if k.N.BitLen() < minRSABits {
+			return nil, errors.Errorf("rsa key must have at least %d bits", minRSABits)
+		}
+		return &signingKey{method: jwt.SigningMethodRS256, private: k, public: &k.PublicKey}, nil
+	case ed25519.PrivateKey:
+		return &signingKey{method: SigningMethodEdDSA, private: k, public: k.Public()}, nil
+	default:
+		return nil, errors.Errorf("unsupported key type %T", private)
+	}
+}
@@ -0,0 +1,303 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyring(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	t.Run("Sign and verify tokens", func(t *testing.T) {
+		dir := tempDir(t)
+		writeKey(t, filepath.Join(dir, "a.pem"), rsaKey)
+		writeKey(t, filepath.Join(dir, "b.pem"), edKey)
+		writeFile(t, filepath.Join(dir, "readme.txt"), "not a key")
+
+		for _, kid := range []string{"a", "b"} {
+			keys, err := LoadKeyring([]string{dir}, kid, time.Minute)
+			assert.NoError(t, err)
+			tokener := NewJWTokener(keys, time.Minute, time.Hour)
+
+			token, err := tokener.Issue(User{ID: 10}, 5)
+			assert.NoError(t, err)
+			assert.Equal(t, kid, tokenHeader(t, token.AccessToken)["kid"])
+
+			claims, err := tokener.Parse(token.AccessToken)
+			assert.NoError(t, err)
+			assert.Equal(t, 10, claims.UserID)
+		}
+	})
+
+	t.Run("Sign with the last key by default", func(t *testing.T) {
+		dir := tempDir(t)
+		writeKey(t, filepath.Join(dir, "2024-01.pem"), rsaKey)
+		writeKey(t, filepath.Join(dir, "2024-02.pem"), edKey)
+
+		keys, err := LoadKeyring([]string{dir}, "", time.Minute)
+		assert.NoError(t, err)
+		tokener := NewJWTokener(keys, time.Minute, time.Hour)
+
+		token, err := tokener.Issue(User{ID: 10}, 5)
+		assert.NoError(t, err)
+		assert.Equal(t, "2024-02", tokenHeader(t, token.AccessToken)["kid"])
+		assert.Equal(t, "EdDSA", tokenHeader(t, token.AccessToken)["alg"])
+	})
+
+	t.Run("Rotate keys", func(t *testing.T) {
+		dir := tempDir(t)
+		writeKey(t, filepath.Join(dir, "2024-01.pem"), edKey)
+
+		now := time.Now()
+		keys, err := LoadKeyring([]string{dir}, "", time.Minute)
+		assert.NoError(t, err)
+		keys.now = func() time.Time { return now }
+		tokener := NewJWTokener(keys, time.Minute, time.Hour)
+
+		old, err := tokener.Issue(User{ID: 10}, 5)
+		assert.NoError(t, err)
+
+		writeKey(t, filepath.Join(dir, "2024-02.pem"), rsaKey)
+		assert.NoError(t, keys.Reload())
+
+		token, err := tokener.Issue(User{ID: 10}, 5)
+		assert.NoError(t, err)
+		assert.Equal(t, "2024-02", tokenHeader(t, token.AccessToken)["kid"])
+
+		// Retired key is accepted during grace period
+		_, err = tokener.Parse(old.AccessToken)
+		assert.NoError(t, err)
+		assert.Len(t, keys.JWKS().Keys, 2)
+
+		now = now.Add(2 * time.Minute)
+		assert.NoError(t, keys.Reload())
+
+		_, err = tokener.Parse(old.AccessToken)
+		assert.Error(t, err)
+		_, err = tokener.Parse(token.AccessToken)
+		assert.NoError(t, err)
+		jwks := keys.JWKS()
+		assert.Len(t, jwks.Keys, 1)
+		assert.Equal(t, "2024-02", jwks.Keys[0].ID)
+	})
+
+	t.Run("Do not accept retired key after restart", func(t *testing.T) {
+		dir := tempDir(t)
+		writeKey(t, filepath.Join(dir, "2024-01.pem"), edKey)
+
+		keys, err := LoadKeyring([]string{dir}, "", time.Minute)
+		assert.NoError(t, err)
+		old, err := NewJWTokener(keys, time.Minute, time.Hour).Issue(User{ID: 10}, 5)
+		assert.NoError(t, err)
+
+		// The new key was put in place long ago, so the old one
+		// is retired for longer than the grace period
+		path := filepath.Join(dir, "2024-02.pem")
+		writeKey(t, path, rsaKey)
+		modified := time.Now().Add(-time.Hour)
+		assert.NoError(t, os.Chtimes(path, modified, modified))
+
+		keys, err = LoadKeyring([]string{dir}, "", time.Minute)
+		assert.NoError(t, err)
+		_, err = NewJWTokener(keys, time.Minute, time.Hour).Parse(old.AccessToken)
+		assert.Error(t, err)
+		jwks := keys.JWKS()
+		assert.Len(t, jwks.Keys, 1)
+		assert.Equal(t, "2024-02", jwks.Keys[0].ID)
+
+		// Just rotated key is accepted during grace period
+		modified = time.Now()
+		assert.NoError(t, os.Chtimes(path, modified, modified))
+
+		keys, err = LoadKeyring([]string{dir}, "", time.Minute)
+		assert.NoError(t, err)
+		_, err = NewJWTokener(keys, time.Minute, time.Hour).Parse(old.AccessToken)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Keep removed key during grace period", func(t *testing.T) {
+		dir := tempDir(t)
+		writeKey(t, filepath.Join(dir, "a.pem"), edKey)
+		writeKey(t, filepath.Join(dir, "b.pem"), rsaKey)
+
+		keys, err := LoadKeyring([]string{dir}, "a", time.Minute)
+		assert.NoError(t, err)
+		tokener := NewJWTokener(keys, time.Minute, time.Hour)
+
+		token, err := tokener.Issue(User{ID: 10}, 5)
+		assert.NoError(t, err)
+
+		keys.signingID = "b"
+		assert.NoError(t, os.Remove(filepath.Join(dir, "a.pem")))
+		assert.NoError(t, keys.Reload())
+
+		_, err = tokener.Parse(token.AccessToken)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Get public keys", func(t *testing.T) {
+		dir := tempDir(t)
+		writeKey(t, filepath.Join(dir, "a.pem"), rsaKey)
+		writeKey(t, filepath.Join(dir, "b.pem"), edKey)
+
+		keys, err := LoadKeyring([]string{dir}, "", time.Minute)
+		assert.NoError(t, err)
+
+		jwks := keys.JWKS()
+		assert.Len(t, jwks.Keys, 2)
+		assert.Equal(t, "a", jwks.Keys[0].ID)
+		assert.Equal(t, "RSA", jwks.Keys[0].KeyType)
+		assert.Equal(t, "RS256", jwks.Keys[0].Algorithm)
+		assert.Equal(t, "AQAB", jwks.Keys[0].Exponent)
+		assert.NotEmpty(t, jwks.Keys[0].Modulus)
+		assert.Equal(t, "b", jwks.Keys[1].ID)
+		assert.Equal(t, "OKP", jwks.Keys[1].KeyType)
+		assert.Equal(t, "Ed25519", jwks.Keys[1].Curve)
+		assert.Equal(t, "EdDSA", jwks.Keys[1].Algorithm)
+		assert.NotEmpty(t, jwks.Keys[1].X)
+	})
+
+	t.Run("Do not publish secret key", func(t *testing.T) {
+		keys := NewHMACKeyring("qwerty")
+		assert.Empty(t, keys.JWKS().Keys)
+	})
+
+	t.Run("Fail to verify token signed with public key", func(t *testing.T) {
+		dir := tempDir(t)
+		writeKey(t, filepath.Join(dir, "a.pem"), rsaKey)
+
+		keys, err := LoadKeyring([]string{dir}, "", time.Minute)
+		assert.NoError(t, err)
+		tokener := NewJWTokener(keys, time.Minute, time.Hour)
+
+		pub, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+		assert.NoError(t, err)
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwtClaims{
+			StandardClaims: jwt.StandardClaims{Subject: "10", Id: "abc", ExpiresAt: 4102444800},
+			SessionID:      5,
+		})
+		token.Header["kid"] = "a"
+		s, err := token.SignedString(pub)
+		assert.NoError(t, err)
+
+		_, err = tokener.Parse(s)
+		assert.Error(t, err)
+	})
+
+	t.Run("Fail to verify token with unknown key", func(t *testing.T) {
+		dir := tempDir(t)
+		writeKey(t, filepath.Join(dir, "a.pem"), edKey)
+		keys, err := LoadKeyring([]string{dir}, "", time.Minute)
+		assert.NoError(t, err)
+
+		other := tempDir(t)
+		writeKey(t, filepath.Join(other, "b.pem"), rsaKey)
+		otherKeys, err := LoadKeyring([]string{other}, "", time.Minute)
+		assert.NoError(t, err)
+
+		token, err := NewJWTokener(otherKeys, time.Minute, time.Hour).Issue(User{ID: 10}, 5)
+		assert.NoError(t, err)
+
+		_, err = NewJWTokener(keys, time.Minute, time.Hour).Parse(token.AccessToken)
+		assert.Error(t, err)
+	})
+
+	t.Run("Fail to load keys", func(t *testing.T) {
+		smallKey, err := rsa.GenerateKey(rand.Reader, 1024)
+		assert.NoError(t, err)
+
+		dir := tempDir(t)
+		writeKey(t, filepath.Join(dir, "small.pem"), smallKey)
+		writeFile(t, filepath.Join(dir, "invalid.pem"), "not a key")
+
+		testCases := []struct {
+			name      string
+			paths     []string
+			signingID string
+		}{
+			{name: "no keys", paths: []string{tempDir(t)}},
+			{name: "missing file", paths: []string{filepath.Join(dir, "missing.pem")}},
+			{name: "small key", paths: []string{filepath.Join(dir, "small.pem")}},
+			{name: "invalid key", paths: []string{filepath.Join(dir, "invalid.pem")}},
+			{name: "duplicate key", paths: []string{
+				filepath.Join(dir, "small.pem"),
+				filepath.Join(dir, "small.pem"),
+			}},
+		}
+		for _, tc := range testCases {
+			_, err := LoadKeyring(tc.paths, tc.signingID, time.Minute)
+			assert.Error(t, err, tc.name)
+		}
+
+		good := tempDir(t)
+		writeKey(t, filepath.Join(good, "a.pem"), edKey)
+		_, err = LoadKeyring([]string{good}, "b", time.Minute)
+		assert.Error(t, err)
+	})
+}
+
+func TestSigningMethodEdDSA(t *testing.T) {
+	pub, private, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	sig, err := SigningMethodEdDSA.Sign("header.payload", private)
+	assert.NoError(t, err)
+	assert.NoError(t, SigningMethodEdDSA.Verify("header.payload", sig, pub))
+	assert.Equal(t, jwt.ErrSignatureInvalid, SigningMethodEdDSA.Verify("header.other", sig, pub))
+
+	_, err = SigningMethodEdDSA.Sign("header.payload", []byte("qwerty"))
+	assert.Equal(t, jwt.ErrInvalidKeyType, err)
+	assert.Equal(t, jwt.ErrInvalidKeyType, SigningMethodEdDSA.Verify("header.payload", sig, private))
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "keys")
+	if err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) }) // nolint: errcheck,gosec
+	return dir
+}
+
+func writeKey(t *testing.T, path string, key interface{}) {
+	var block *pem.Block
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		block = &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(k)}
+	default:
+		b, err := x509.MarshalPKCS8PrivateKey(k)
+		if err != nil {
+			t.Fatalf("Failed to marshal key: %v", err)
+		}
+		block = &pem.Block{Type: "PRIVATE KEY", Bytes: b}
+	}
+	writeFile(t, path, string(pem.EncodeToMemory(block)))
+}
+
+func writeFile(t *testing.T, path, data string) {
+	if err := ioutil.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+}
+
+func tokenHeader(t *testing.T, s string) map[string]interface{} {
+	token, _, err := new(jwt.Parser).ParseUnverified(s, &jwtClaims{})
+	if err != nil {
+		t.Fatalf("Failed to parse token: %v", err)
+	}
+	return token.Header
+}
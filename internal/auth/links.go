@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// defaultLinkTTL is a default time to live of signed links.
+const defaultLinkTTL = 24 * time.Hour
+
+// LinkSigner signs links to user's objects, so they can be opened
+// without the Authorization header, e.g. from <img> tags.
+type LinkSigner struct {
+	secret []byte
+	ttl    time.Duration
+}
+
+// NewLinkSigner creates new link signer.
+func NewLinkSigner(secret string) *LinkSigner {
+	return &LinkSigner{secret: []byte(secret), ttl: defaultLinkTTL}
+}
+
+// Sign makes signature that gives the user access to the object
+// until the returned expiration time.
+func (s *LinkSigner) Sign(object string, userID int) (expires int64, signature string) {
+	return s.SignUntil(object, userID, time.Time{})
+}
+
+// SignUntil makes signature like Sign, but the link expires no later
+// than the given time, unless it's zero.
+func (s *LinkSigner) SignUntil(object string, userID int, until time.Time) (expires int64, signature string) {
+	exp := time.Now().Add(s.ttl)
+	if !until.IsZero() && until.Before(exp) {
+		exp = until
+	}
+	expires = exp.Unix()
+	return expires, s.sign(object, userID, expires)
+}
+
+// Verify checks signature of the link.
+func (s *LinkSigner) Verify(object string, userID int, expires int64, signature string) error {
+	if time.Now().Unix() > expires {
+		return errors.New("link expired")
+	}
+	expected := s.sign(object, userID, expires)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+func (s *LinkSigner) sign(object string, userID int, expires int64) string {
+	mac := hmac.New(sha256.New, s.secret)
+	// nolint: errcheck,gosec
+	mac.Write([]byte(object + "\n" + strconv.Itoa(userID) + "\n" + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
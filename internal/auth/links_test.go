@@ -0,0 +1,40 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinkSigner(t *testing.T) {
+	s := NewLinkSigner("qwerty")
+
+	t.Run("Verify signed link", func(t *testing.T) {
+		exp, sig := s.Sign("attachment/10", 1)
+		assert.NoError(t, s.Verify("attachment/10", 1, exp, sig))
+	})
+
+	t.Run("Sign link until given time", func(t *testing.T) {
+		until := time.Now().Add(time.Hour)
+		exp, sig := s.SignUntil("attachment/10", 1, until)
+		assert.Equal(t, until.Unix(), exp)
+		assert.NoError(t, s.Verify("attachment/10", 1, exp, sig))
+
+		exp, _ = s.SignUntil("attachment/10", 1, time.Now().Add(48*time.Hour))
+		assert.True(t, exp <= time.Now().Add(defaultLinkTTL).Unix())
+	})
+
+	t.Run("Fail to verify link for another object", func(t *testing.T) {
+		exp, sig := s.Sign("attachment/10", 1)
+		assert.Error(t, s.Verify("attachment/11", 1, exp, sig))
+		assert.Error(t, s.Verify("attachment/10", 2, exp, sig))
+		assert.Error(t, s.Verify("attachment/10", 1, exp+1, sig))
+	})
+
+	t.Run("Fail to verify expired link", func(t *testing.T) {
+		exp := time.Now().Add(-time.Minute).Unix()
+		sig := s.sign("attachment/10", 1, exp)
+		assert.Error(t, s.Verify("attachment/10", 1, exp, sig))
+	})
+}
@@ -1,10 +1,37 @@
 package auth
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// minPasswordLength is a minimal length of new passwords.
+const minPasswordLength = 8
+
+// ValidatePassword checks if the password is strong enough.
+func ValidatePassword(password string) error {
+	if len([]rune(password)) < minPasswordLength {
+		return errors.Errorf("password must have at least %d characters", minPasswordLength)
+	}
+	return nil
+}
+
+// Costs of password hashing. Passwords of shares are checked on every
+// view of the share, so they are cheaper to check than users' ones.
+const (
+	passwordCost      = 14
+	sharePasswordCost = bcrypt.DefaultCost
+)
 
 // HashPassword returnes hash of given password string.
 func HashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), 14)
+	bytes, err := bcrypt.GenerateFromPassword([]byte(password), passwordCost)
+	return string(bytes), err
+}
+
+// HashSharePassword returns hash of the password of a share.
+func HashSharePassword(password string) (string, error) {
+	bytes, err := bcrypt.GenerateFromPassword([]byte(password), sharePasswordCost)
 	return string(bytes), err
 }
 
@@ -2,6 +2,7 @@ package auth
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -15,4 +16,32 @@ func TestHash(t *testing.T) {
 		match := CheckPassword(password, hash)
 		assert.True(t, match)
 	})
+
+	t.Run("Hash password of share and check result", func(t *testing.T) {
+		password := "qwerty"
+		hash, err := HashSharePassword(password)
+		assert.NoError(t, err)
+
+		assert.True(t, CheckPassword(password, hash))
+		assert.False(t, CheckPassword("other", hash))
+	})
+}
+
+func TestValidatePassword(t *testing.T) {
+	assert.NoError(t, ValidatePassword("qwertyui"))
+	assert.NoError(t, ValidatePassword("пароль12"))
+	assert.Error(t, ValidatePassword("qwerty"))
+	assert.Error(t, ValidatePassword(""))
+}
+
+func TestNewPasswordReset(t *testing.T) {
+	token, pr, err := NewPasswordReset(10)
+	assert.NoError(t, err)
+	assert.Equal(t, 10, pr.UserID)
+	assert.Equal(t, HashToken(token), pr.Hash)
+	assert.WithinDuration(t, time.Now().Add(PasswordResetTTL), pr.ExpiresAt, time.Second)
+
+	other, _, err := NewPasswordReset(10)
+	assert.NoError(t, err)
+	assert.NotEqual(t, token, other)
 }
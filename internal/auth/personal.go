@@ -0,0 +1,142 @@
+package auth
+
+import (
+	"database/sql/driver"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// PersonalTokenPrefix starts every personal access token, so they
+// can be told apart from JWT.
+const PersonalTokenPrefix = "nott_"
+
+// maxPersonalTokenNameLength is a maximum length of token's name in characters.
+const maxPersonalTokenNameLength = 100
+
+// Scopes of personal access tokens.
+const (
+	ScopeFoldersRead  = "folders:read"
+	ScopeFoldersWrite = "folders:write"
+	ScopeNotesRead    = "notes:read"
+	ScopeNotesWrite   = "notes:write"
+	ScopeTagsRead     = "tags:read"
+	ScopeTagsWrite    = "tags:write"
+)
+
+// validScopes is a set of all scopes.
+var validScopes = map[string]bool{
+	ScopeFoldersRead:  true,
+	ScopeFoldersWrite: true,
+	ScopeNotesRead:    true,
+	ScopeNotesWrite:   true,
+	ScopeTagsRead:     true,
+	ScopeTagsWrite:    true,
+}
+
+// PersonalToken is a long-lived token for scripts and integrations,
+// that gives access only to the resources of its scopes. Only hash
+// of the token is stored.
+type PersonalToken struct {
+	ID        int        `json:"id" gorm:"column:id"`
+	UserID    int        `json:"-" gorm:"column:user_id"`
+	Name      string     `json:"name" gorm:"column:name"`
+	Scopes    Scopes     `json:"scopes" gorm:"column:scopes"`
+	Hash      string     `json:"-" gorm:"column:hash"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" gorm:"column:expires_at"`
+	// Token is shown only once, when it's created
+	Token string `json:"token,omitempty" gorm:"-"`
+
+	// Managed by gorm callbacks
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+}
+
+// NewPersonalToken makes new random personal access token for the user.
+func NewPersonalToken(userID int, name string, scopes []string, expiresAt *time.Time) (PersonalToken, error) {
+	token, err := randomToken()
+	if err != nil {
+		return PersonalToken{}, errors.Wrap(err, "generate token")
+	}
+	token = PersonalTokenPrefix + token
+	return PersonalToken{
+		UserID:    userID,
+		Name:      name,
+		Scopes:    scopes,
+		Hash:      HashToken(token),
+		ExpiresAt: expiresAt,
+		Token:     token,
+	}, nil
+}
+
+// Validate validates personal access token.
+func (t PersonalToken) Validate() error {
+	if t.UserID == 0 {
+		return errors.New("unknown user")
+	}
+	if strings.TrimSpace(t.Name) == "" {
+		return errors.New("name cannot be empty")
+	}
+	if utf8.RuneCountInString(t.Name) > maxPersonalTokenNameLength {
+		return errors.Errorf("name cannot be longer than %d characters", maxPersonalTokenNameLength)
+	}
+	if len(t.Scopes) == 0 {
+		return errors.New("scopes cannot be empty")
+	}
+	for _, s := range t.Scopes {
+		if !validScopes[s] {
+			return errors.Errorf("unknown scope %q", s)
+		}
+	}
+	if t.Hash == "" {
+		return errors.New("hash cannot be empty")
+	}
+	return nil
+}
+
+// Expired checks if the token can no longer be used at the moment.
+func (t PersonalToken) Expired(now time.Time) bool {
+	return t.ExpiresAt != nil && !now.Before(*t.ExpiresAt)
+}
+
+// Scopes is a list of scopes. It's stored as a space-separated
+// string, like scope parameter of OAuth.
+type Scopes []string
+
+// Has checks if the list has all the scopes.
+func (s Scopes) Has(scopes ...string) bool {
+	for _, scope := range scopes {
+		found := false
+		for _, v := range s {
+			if v == scope {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Value implements driver.Valuer interface.
+func (s Scopes) Value() (driver.Value, error) {
+	return strings.Join(s, " "), nil
+}
+
+// Scan implements sql.Scanner interface.
+func (s *Scopes) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case string:
+		*s = strings.Fields(v)
+	case []byte:
+		*s = strings.Fields(string(v))
+	case nil:
+		*s = nil
+	default:
+		return errors.Errorf("unsupported type %T", src)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPersonalToken(t *testing.T) {
+	t.Run("Make token", func(t *testing.T) {
+		pt, err := NewPersonalToken(10, "backup", []string{ScopeNotesRead}, nil)
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(pt.Token, PersonalTokenPrefix))
+		assert.Equal(t, HashToken(pt.Token), pt.Hash)
+		assert.NoError(t, pt.Validate())
+
+		other, err := NewPersonalToken(10, "backup", []string{ScopeNotesRead}, nil)
+		assert.NoError(t, err)
+		assert.NotEqual(t, pt.Token, other.Token)
+	})
+
+	t.Run("Validate token", func(t *testing.T) {
+		cases := []struct {
+			title string
+			token PersonalToken
+			err   bool
+		}{
+			{
+				title: "correct token",
+				token: PersonalToken{UserID: 10, Name: "backup", Scopes: Scopes{ScopeNotesRead}, Hash: "abc"},
+				err:   false,
+			},
+			{
+				title: "token without user",
+				token: PersonalToken{Name: "backup", Scopes: Scopes{ScopeNotesRead}, Hash: "abc"},
+				err:   true,
+			},
+			{
+				title: "token without name",
+				token: PersonalToken{UserID: 10, Name: " ", Scopes: Scopes{ScopeNotesRead}, Hash: "abc"},
+				err:   true,
+			},
+			{
+				title: "token with long name",
+				token: PersonalToken{UserID: 10, Name: strings.Repeat("a", 101), Scopes: Scopes{ScopeNotesRead}, Hash: "abc"},
+				err:   true,
+			},
+			{
+				title: "token with long name in non-latin letters",
+				token: PersonalToken{UserID: 10, Name: strings.Repeat("я", 100), Scopes: Scopes{ScopeNotesRead}, Hash: "abc"},
+				err:   false,
+			},
+			{
+				title: "token without scopes",
+				token: PersonalToken{UserID: 10, Name: "backup", Hash: "abc"},
+				err:   true,
+			},
+			{
+				title: "token with unknown scope",
+				token: PersonalToken{UserID: 10, Name: "backup", Scopes: Scopes{"admin"}, Hash: "abc"},
+				err:   true,
+			},
+		}
+		for _, c := range cases {
+			err := c.token.Validate()
+			if c.err {
+				assert.Error(t, err, c.title)
+			} else {
+				assert.NoError(t, err, c.title)
+			}
+		}
+	})
+
+	t.Run("Check expiration", func(t *testing.T) {
+		now := time.Now()
+		exp := now.Add(time.Minute)
+		pt := PersonalToken{ExpiresAt: &exp}
+
+		assert.False(t, pt.Expired(now))
+		assert.True(t, pt.Expired(exp))
+		assert.False(t, PersonalToken{}.Expired(now))
+	})
+}
+
+func TestScopes(t *testing.T) {
+	s := Scopes{ScopeNotesRead, ScopeFoldersRead}
+	assert.True(t, s.Has(ScopeNotesRead))
+	assert.True(t, s.Has(ScopeFoldersRead, ScopeNotesRead))
+	assert.False(t, s.Has(ScopeNotesRead, ScopeNotesWrite))
+
+	v, err := s.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, "notes:read folders:read", v)
+
+	var scanned Scopes
+	assert.NoError(t, scanned.Scan([]byte("notes:read folders:read")))
+	assert.Equal(t, s, scanned)
+	assert.NoError(t, scanned.Scan("tags:read"))
+	assert.Equal(t, Scopes{ScopeTagsRead}, scanned)
+	assert.Error(t, scanned.Scan(10))
+}
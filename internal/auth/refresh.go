@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrTokenReused is returned when a refresh token is used the second
+// time, which means that it has been stolen.
+var ErrTokenReused = errors.New("refresh token reused")
+
+// RefreshToken is a long-lived token for getting new access tokens.
+// Every refresh token can be used only once, it's rotated to the next
+// token of the same family. Only hash of the token is stored.
+type RefreshToken struct {
+	ID        int `gorm:"column:id"`
+	UserID    int `gorm:"column:user_id"`
+	SessionID int `gorm:"column:session_id"`
+	// Family is shared by all tokens rotated from the same login
+	Family    string     `gorm:"column:family"`
+	Hash      string     `gorm:"column:hash"`
+	ExpiresAt time.Time  `gorm:"column:expires_at"`
+	UsedAt    *time.Time `gorm:"column:used_at"`
+	RevokedAt *time.Time `gorm:"column:revoked_at"`
+
+	// Managed by gorm callbacks
+	CreatedAt time.Time `gorm:"column:created_at"`
+}
+
+// HashToken makes hash of a random token for storing it. Tokens have
+// enough entropy, so they don't need salt and slow hashing.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomToken makes random string for tokens and their IDs.
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
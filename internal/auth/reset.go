@@ -0,0 +1,37 @@
+package auth
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// PasswordResetTTL is time to live of password reset tokens.
+const PasswordResetTTL = time.Hour
+
+// PasswordReset is a single-use token for setting new password without
+// the current one, it's sent to user's email. Only hash of the token
+// is stored.
+type PasswordReset struct {
+	ID        int        `gorm:"column:id"`
+	UserID    int        `gorm:"column:user_id"`
+	Hash      string     `gorm:"column:hash"`
+	ExpiresAt time.Time  `gorm:"column:expires_at"`
+	UsedAt    *time.Time `gorm:"column:used_at"`
+
+	// Managed by gorm callbacks
+	CreatedAt time.Time `gorm:"column:created_at"`
+}
+
+// NewPasswordReset makes new random password reset token for the user.
+func NewPasswordReset(userID int) (token string, pr PasswordReset, err error) {
+	token, err = randomToken()
+	if err != nil {
+		return "", PasswordReset{}, errors.Wrap(err, "generate token")
+	}
+	return token, PasswordReset{
+		UserID:    userID,
+		Hash:      HashToken(token),
+		ExpiresAt: time.Now().Add(PasswordResetTTL).UTC(),
+	}, nil
+}
@@ -0,0 +1,28 @@
+package auth
+
+import (
+	"strconv"
+	"time"
+)
+
+// Session is a login of a user on a device. Refresh tokens belong
+// to the session, and access tokens have its ID.
+type Session struct {
+	ID        int    `json:"id" gorm:"column:id"`
+	UserID    int    `json:"-" gorm:"column:user_id"`
+	UserAgent string `json:"user_agent" gorm:"column:user_agent"`
+	IP        string `json:"ip" gorm:"column:ip"`
+	// LastSeenAt is updated when tokens are refreshed
+	LastSeenAt time.Time `json:"last_seen_at" gorm:"column:last_seen_at"`
+	// Current marks the session of the request
+	Current bool `json:"current" gorm:"-"`
+
+	// Managed by gorm callbacks
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+}
+
+// SessionKey is a key of the session in denylist. Revoking it revokes
+// all access tokens of the session.
+func SessionKey(id int) string {
+	return "session:" + strconv.Itoa(id)
+}
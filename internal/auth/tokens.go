@@ -9,8 +9,11 @@ import (
 )
 
 const (
-	// defaultTTL is a default token time to live.
-	defaultTTL = 10 * 24 * time.Hour
+	// DefaultAccessTTL is a default access token time to live.
+	DefaultAccessTTL = 15 * time.Minute
+
+	// DefaultRefreshTTL is a default refresh token time to live.
+	DefaultRefreshTTL = 30 * 24 * time.Hour
 
 	// defaultIssuer is a token issuer.
 	defaultIssuer = "nott"
@@ -18,46 +21,86 @@ const (
 
 // Tokener issues tokens for user authentication.
 type Tokener interface {
-	Issue(User) (Token, error)
-	Parse(token string) (id int, err error)
+	// Issue issues short-lived access token for the user's session
+	Issue(user User, sessionID int) (Token, error)
+	// IssueRefresh issues refresh token that starts new family,
+	// only its hash is kept in the returned structure
+	IssueRefresh() (token string, rt RefreshToken, err error)
+	// Parse validates access token and gets its claims
+	Parse(token string) (Claims, error)
+}
+
+// Denylist is a list of revoked access tokens. Tokens are kept
+// in the list until they expire.
+type Denylist interface {
+	Revoke(id string, expiresAt time.Time) error
+	Revoked(id string) (bool, error)
 }
 
 // Token is used for user authentication.
 type Token struct {
-	AccessToken string `json:"access_token"`
-	ExpiresAt   int64  `json:"expires_at,omitempty"`
+	AccessToken  string `json:"access_token"`
+	ExpiresAt    int64  `json:"expires_at,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+// Claims are data of a valid access token.
+type Claims struct {
+	UserID    int
+	SessionID int
+	// ID is a unique ID of the token (jti)
+	ID        string
+	ExpiresAt time.Time
+}
+
+// jwtClaims are claims of JWT with session ID.
+type jwtClaims struct {
+	jwt.StandardClaims
+	SessionID int `json:"sid,omitempty"`
 }
 
 // JWTokener issues JWT.
 type JWTokener struct {
-	secret []byte
-	issuer string
-	ttl    time.Duration
+	keys       *Keyring
+	issuer     string
+	ttl        time.Duration
+	refreshTTL time.Duration
 }
 
-// NewJWTokener creates new JWT tokener.
-func NewJWTokener(secret string) *JWTokener {
+// NewJWTokener creates new JWT tokener, that signs access tokens
+// with the keyring, and issues tokens with the given times to live.
+func NewJWTokener(keys *Keyring, ttl, refreshTTL time.Duration) *JWTokener {
 	return &JWTokener{
-		secret: []byte(secret),
-		issuer: defaultIssuer,
-		ttl:    defaultTTL,
+		keys:       keys,
+		issuer:     defaultIssuer,
+		ttl:        ttl,
+		refreshTTL: refreshTTL,
 	}
 }
 
 // Issue issues new token for the given user.
-func (t *JWTokener) Issue(user User) (Token, error) {
-	var exp int64
-	if t.ttl > 0 {
-		exp = time.Now().Add(t.ttl).Unix()
+func (t *JWTokener) Issue(user User, sessionID int) (Token, error) {
+	id, err := randomToken()
+	if err != nil {
+		return Token{}, errors.Wrap(err, "generate token id")
+	}
+	exp := time.Now().Add(t.ttl).Unix()
+	claims := jwtClaims{
+		StandardClaims: jwt.StandardClaims{
+			Id:        id,
+			Issuer:    t.issuer,
+			Subject:   strconv.Itoa(user.ID),
+			ExpiresAt: exp,
+		},
+		SessionID: sessionID,
 	}
-	claims := jwt.StandardClaims{
-		Issuer:    t.issuer,
-		Subject:   strconv.Itoa(user.ID),
-		ExpiresAt: exp,
+	key := t.keys.signingKey()
+	token := jwt.NewWithClaims(key.method, claims)
+	if key.id != "" {
+		token.Header["kid"] = key.id
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
-	s, err := token.SignedString(t.secret)
+	s, err := token.SignedString(key.private)
 	if err != nil {
 		return Token{}, errors.Wrap(err, "sign token string")
 	}
@@ -65,34 +108,61 @@ func (t *JWTokener) Issue(user User) (Token, error) {
 	return Token{AccessToken: s, ExpiresAt: exp}, nil
 }
 
+// IssueRefresh issues new refresh token.
+func (t *JWTokener) IssueRefresh() (string, RefreshToken, error) {
+	token, err := randomToken()
+	if err != nil {
+		return "", RefreshToken{}, errors.Wrap(err, "generate token")
+	}
+	family, err := randomToken()
+	if err != nil {
+		return "", RefreshToken{}, errors.Wrap(err, "generate family")
+	}
+	return token, RefreshToken{
+		Family:    family,
+		Hash:      HashToken(token),
+		ExpiresAt: time.Now().Add(t.refreshTTL).UTC(),
+	}, nil
+}
+
 // Parse parses, validates and gets user ID from JWT claims.
-func (t *JWTokener) Parse(accessToken string) (int, error) {
+func (t *JWTokener) Parse(accessToken string) (Claims, error) {
 	// Parse and validate
-	claims := jwt.StandardClaims{}
+	claims := jwtClaims{}
 	token, err := jwt.ParseWithClaims(
 		accessToken,
 		&claims,
-		func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, errors.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return t.secret, nil
-		},
+		t.keys.verificationKey,
 	)
 	if err != nil {
-		return 0, errors.Wrap(err, "parse token")
+		return Claims{}, errors.Wrap(err, "parse token")
 	}
 	if !token.Valid {
-		return 0, errors.New("invalid token")
+		return Claims{}, errors.New("invalid token")
 	}
 
 	// Parse claims and get user ID
 	if claims.Subject == "" {
-		return 0, errors.New("sub field is empty")
+		return Claims{}, errors.New("sub field is empty")
 	}
 	id, err := strconv.Atoi(claims.Subject)
 	if err != nil {
-		return 0, errors.New("id is not number")
+		return Claims{}, errors.New("id is not number")
+	}
+	// Tokens without ID and expiration can't be revoked
+	if claims.Id == "" {
+		return Claims{}, errors.New("jti field is empty")
+	}
+	if claims.ExpiresAt == 0 {
+		return Claims{}, errors.New("exp field is empty")
+	}
+	if claims.SessionID == 0 {
+		return Claims{}, errors.New("sid field is empty")
 	}
-	return id, nil
+	return Claims{
+		UserID:    id,
+		SessionID: claims.SessionID,
+		ID:        claims.Id,
+		ExpiresAt: time.Unix(claims.ExpiresAt, 0).UTC(),
+	}, nil
 }
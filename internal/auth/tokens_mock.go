@@ -7,6 +7,7 @@ package auth
 import (
 	gomock "github.com/golang/mock/gomock"
 	reflect "reflect"
+	time "time"
 )
 
 // MockTokener is a mock of Tokener interface
@@ -33,22 +34,36 @@ func (m *MockTokener) EXPECT() *MockTokenerMockRecorder {
 }
 
 // Issue mocks base method
-func (m *MockTokener) Issue(arg0 User) (Token, error) {
-	ret := m.ctrl.Call(m, "Issue", arg0)
+func (m *MockTokener) Issue(user User, sessionID int) (Token, error) {
+	ret := m.ctrl.Call(m, "Issue", user, sessionID)
 	ret0, _ := ret[0].(Token)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Issue indicates an expected call of Issue
-func (mr *MockTokenerMockRecorder) Issue(arg0 interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Issue", reflect.TypeOf((*MockTokener)(nil).Issue), arg0)
+func (mr *MockTokenerMockRecorder) Issue(user, sessionID interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Issue", reflect.TypeOf((*MockTokener)(nil).Issue), user, sessionID)
+}
+
+// IssueRefresh mocks base method
+func (m *MockTokener) IssueRefresh() (string, RefreshToken, error) {
+	ret := m.ctrl.Call(m, "IssueRefresh")
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(RefreshToken)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// IssueRefresh indicates an expected call of IssueRefresh
+func (mr *MockTokenerMockRecorder) IssueRefresh() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IssueRefresh", reflect.TypeOf((*MockTokener)(nil).IssueRefresh))
 }
 
 // Parse mocks base method
-func (m *MockTokener) Parse(token string) (int, error) {
+func (m *MockTokener) Parse(token string) (Claims, error) {
 	ret := m.ctrl.Call(m, "Parse", token)
-	ret0, _ := ret[0].(int)
+	ret0, _ := ret[0].(Claims)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -57,3 +72,51 @@ func (m *MockTokener) Parse(token string) (int, error) {
 func (mr *MockTokenerMockRecorder) Parse(token interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Parse", reflect.TypeOf((*MockTokener)(nil).Parse), token)
 }
+
+// MockDenylist is a mock of Denylist interface
+type MockDenylist struct {
+	ctrl     *gomock.Controller
+	recorder *MockDenylistMockRecorder
+}
+
+// MockDenylistMockRecorder is the mock recorder for MockDenylist
+type MockDenylistMockRecorder struct {
+	mock *MockDenylist
+}
+
+// NewMockDenylist creates a new mock instance
+func NewMockDenylist(ctrl *gomock.Controller) *MockDenylist {
+	mock := &MockDenylist{ctrl: ctrl}
+	mock.recorder = &MockDenylistMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockDenylist) EXPECT() *MockDenylistMockRecorder {
+	return m.recorder
+}
+
+// Revoke mocks base method
+func (m *MockDenylist) Revoke(id string, expiresAt time.Time) error {
+	ret := m.ctrl.Call(m, "Revoke", id, expiresAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Revoke indicates an expected call of Revoke
+func (mr *MockDenylistMockRecorder) Revoke(id, expiresAt interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockDenylist)(nil).Revoke), id, expiresAt)
+}
+
+// Revoked mocks base method
+func (m *MockDenylist) Revoked(id string) (bool, error) {
+	ret := m.ctrl.Call(m, "Revoked", id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Revoked indicates an expected call of Revoked
+func (mr *MockDenylistMockRecorder) Revoked(id interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoked", reflect.TypeOf((*MockDenylist)(nil).Revoked), id)
+}
@@ -14,6 +14,8 @@ type User struct {
 	ID       int    `json:"id" gorm:"column:id"`
 	Email    string `json:"email" gorm:"column:email"`
 	Password string `json:"-" gorm:"column:password"`
+	// Theme is a color theme for highlighting code in notes
+	Theme string `json:"theme" gorm:"column:theme"`
 
 	// Managed by gorm callbacks
 	CreatedAt time.Time  `json:"-" gorm:"column:created_at"`
@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"mime"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// inlineTypes are content types that are safe to show in browser.
+// Other attachments are always downloaded as files.
+var inlineTypes = map[string]bool{
+	"image/png":       true,
+	"image/jpeg":      true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"image/bmp":       true,
+	"application/pdf": true,
+	"text/plain":      true,
+}
+
+// Attachment represents a file attached to a note. Content of the
+// file is kept in a blob store under the Key.
+type Attachment struct {
+	ID          int    `json:"id" gorm:"column:id"`
+	UserID      int    `json:"user_id" gorm:"column:user_id"`
+	NoteID      int    `json:"note_id" gorm:"column:note_id"`
+	Name        string `json:"name" gorm:"column:name"`
+	ContentType string `json:"content_type" gorm:"column:content_type"`
+	Size        int64  `json:"size" gorm:"column:size"`
+	Key         string `json:"-" gorm:"column:key"`
+	// URL is a download link, it is made for each response
+	URL string `json:"url,omitempty" gorm:"-"`
+
+	// Managed by gorm callbacks
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+}
+
+// Validate validates attachment.
+func (a Attachment) Validate() error {
+	if a.UserID == 0 {
+		return errors.New("unknown user")
+	}
+	if a.NoteID == 0 {
+		return errors.New("note id cannot be empty")
+	}
+	if a.Name == "" {
+		return errors.New("name cannot be empty")
+	}
+	if a.Key == "" {
+		return errors.New("key cannot be empty")
+	}
+	return nil
+}
+
+// Inline checks if the attachment can be shown in browser rather
+// than downloaded.
+func (a Attachment) Inline() bool {
+	t, _, err := mime.ParseMediaType(a.ContentType)
+	if err != nil {
+		return false
+	}
+	return inlineTypes[strings.ToLower(t)]
+}
@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttachmentValidation(t *testing.T) {
+	cases := []struct {
+		title      string
+		attachment Attachment
+		err        bool
+	}{
+		{
+			title:      "correct attachment",
+			attachment: Attachment{UserID: 10, NoteID: 20, Name: "a.png", Key: "10/abc"},
+			err:        false,
+		},
+		{
+			title:      "attachment without user",
+			attachment: Attachment{NoteID: 20, Name: "a.png", Key: "10/abc"},
+			err:        true,
+		},
+		{
+			title:      "attachment without note",
+			attachment: Attachment{UserID: 10, Name: "a.png", Key: "10/abc"},
+			err:        true,
+		},
+		{
+			title:      "attachment without name",
+			attachment: Attachment{UserID: 10, NoteID: 20, Key: "10/abc"},
+			err:        true,
+		},
+		{
+			title:      "attachment without key",
+			attachment: Attachment{UserID: 10, NoteID: 20, Name: "a.png"},
+			err:        true,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.title, func(t *testing.T) {
+			err := tt.attachment.Validate()
+			if tt.err {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestAttachmentInline(t *testing.T) {
+	assert.True(t, Attachment{ContentType: "image/png"}.Inline())
+	assert.True(t, Attachment{ContentType: "text/plain; charset=utf-8"}.Inline())
+	assert.False(t, Attachment{ContentType: "text/html; charset=utf-8"}.Inline())
+	assert.False(t, Attachment{ContentType: "image/svg+xml"}.Inline())
+	assert.False(t, Attachment{ContentType: ""}.Inline())
+}
@@ -4,3 +4,34 @@ import "github.com/pkg/errors"
 
 // ErrNotFound is returned when object is not found.
 var ErrNotFound = errors.New("not found")
+
+// ErrParentNotFound is returned when parent of the object doesn't
+// exist or belongs to another user.
+var ErrParentNotFound = errors.New("parent not found")
+
+// ErrParentDeleted is returned when object cannot be restored
+// from trash because its parent is still in trash.
+var ErrParentDeleted = errors.New("parent is in trash")
+
+// ErrCycle is returned when folder is put into itself or into
+// one of its subfolders.
+var ErrCycle = errors.New("folder cannot be put into itself or its subfolder")
+
+// ErrTagExists is returned when user already has a tag with
+// the same name.
+var ErrTagExists = errors.New("tag already exists")
+
+// ErrQuotaExceeded is returned when user has no space left for
+// new attachments.
+var ErrQuotaExceeded = errors.New("attachments quota exceeded")
+
+// ErrForbidden is returned when the object is available to the user,
+// but their role doesn't allow the action.
+var ErrForbidden = errors.New("forbidden")
+
+// ErrUserNotFound is returned when there is no user to share
+// an object with.
+var ErrUserNotFound = errors.New("user not found")
+
+// ErrGrantToOwner is returned when object is shared with its owner.
+var ErrGrantToOwner = errors.New("object cannot be shared with its owner")
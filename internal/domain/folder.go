@@ -16,6 +16,8 @@ type Folder struct {
 	// Managed by gorm callbacks
 	CreatedAt time.Time  `json:"-" gorm:"column:created_at"`
 	UpdatedAt *time.Time `json:"-" gorm:"column:updated_at"`
+	// Set when the folder is moved to trash
+	DeletedAt *time.Time `json:"deleted_at,omitempty" gorm:"column:deleted_at"`
 }
 
 // Validate validates folder.
@@ -28,3 +30,14 @@ func (f Folder) Validate() error {
 	}
 	return nil
 }
+
+// CheckParent checks if the folder can be put into the parent folder.
+func (f Folder) CheckParent(parent Folder) error {
+	if f.ParentID == nil || *f.ParentID != parent.ID {
+		return errors.New("wrong parent")
+	}
+	if parent.UserID != f.UserID || parent.DeletedAt != nil {
+		return ErrParentNotFound
+	}
+	return nil
+}
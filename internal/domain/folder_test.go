@@ -60,3 +60,23 @@ func TestFolderValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestFolderCheckParent(t *testing.T) {
+	parentID := 20
+	folder := Folder{UserID: 10, ParentID: &parentID, Title: "x-folder"}
+
+	t.Run("folder of the same user", func(t *testing.T) {
+		err := folder.CheckParent(Folder{ID: 20, UserID: 10})
+		assert.NoError(t, err)
+	})
+
+	t.Run("folder of another user", func(t *testing.T) {
+		err := folder.CheckParent(Folder{ID: 20, UserID: 11})
+		assert.Equal(t, ErrParentNotFound, err)
+	})
+
+	t.Run("root folder", func(t *testing.T) {
+		err := Folder{UserID: 10, Title: "x-folder"}.CheckParent(Folder{ID: 20, UserID: 10})
+		assert.Error(t, err)
+	})
+}
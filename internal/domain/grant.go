@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Role defines what a user can do with a folder or a notepad
+// of another user. Roles are ordered, every role allows everything
+// that the lower ones do.
+type Role int
+
+const (
+	// RoleNone means no access.
+	RoleNone Role = iota
+	// RoleViewer allows reading.
+	RoleViewer
+	// RoleEditor allows creating, changing and deleting contents.
+	RoleEditor
+	// RoleOwner allows sharing with other users.
+	RoleOwner
+)
+
+var roleNames = map[Role]string{
+	RoleViewer: "viewer",
+	RoleEditor: "editor",
+	RoleOwner:  "owner",
+}
+
+// String returns name of the role.
+func (r Role) String() string {
+	return roleNames[r]
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (r Role) MarshalText() ([]byte, error) {
+	name, ok := roleNames[r]
+	if !ok {
+		return nil, errors.Errorf("unknown role %d", r)
+	}
+	return []byte(name), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (r *Role) UnmarshalText(text []byte) error {
+	for role, name := range roleNames {
+		if name == string(text) {
+			*r = role
+			return nil
+		}
+	}
+	return errors.Errorf("unknown role %q", text)
+}
+
+// Grant gives a user access to a folder with all its contents,
+// or to a single notepad of another user. The grant takes effect
+// when the user accepts it.
+type Grant struct {
+	ID        int  `json:"id" gorm:"column:id"`
+	UserID    int  `json:"user_id" gorm:"column:user_id"`
+	FolderID  *int `json:"folder_id,omitempty" gorm:"column:folder_id"`
+	NotepadID *int `json:"notepad_id,omitempty" gorm:"column:notepad_id"`
+	Role      Role `json:"role" gorm:"column:role"`
+	InvitedBy int  `json:"invited_by" gorm:"column:invited_by"`
+	// Title of the shared folder or notepad
+	Title      string     `json:"title" gorm:"-"`
+	AcceptedAt *time.Time `json:"accepted_at" gorm:"column:accepted_at"`
+
+	// Managed by gorm callbacks
+	CreatedAt time.Time  `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt *time.Time `json:"-" gorm:"column:updated_at"`
+}
+
+// Validate validates grant.
+func (g Grant) Validate() error {
+	if g.InvitedBy == 0 {
+		return errors.New("unknown user")
+	}
+	if (g.FolderID == nil) == (g.NotepadID == nil) {
+		return errors.New("either folder id or notepad id must be set")
+	}
+	if _, ok := roleNames[g.Role]; !ok {
+		return errors.New("unknown role")
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoleJSON(t *testing.T) {
+	for _, role := range []Role{RoleViewer, RoleEditor, RoleOwner} {
+		data, err := json.Marshal(role)
+		assert.NoError(t, err)
+
+		var r Role
+		assert.NoError(t, json.Unmarshal(data, &r))
+		assert.Equal(t, role, r)
+	}
+
+	data, err := json.Marshal(RoleEditor)
+	assert.NoError(t, err)
+	assert.Equal(t, `"editor"`, string(data))
+
+	_, err = json.Marshal(RoleNone)
+	assert.Error(t, err)
+
+	var r Role
+	assert.Error(t, json.Unmarshal([]byte(`"admin"`), &r))
+}
+
+func TestGrantValidation(t *testing.T) {
+	id := 20
+
+	cases := []struct {
+		title string
+		grant Grant
+		err   bool
+	}{
+		{
+			title: "correct folder grant",
+			grant: Grant{InvitedBy: 10, FolderID: &id, Role: RoleViewer},
+			err:   false,
+		},
+		{
+			title: "correct notepad grant",
+			grant: Grant{InvitedBy: 10, NotepadID: &id, Role: RoleOwner},
+			err:   false,
+		},
+		{
+			title: "grant without inviting user",
+			grant: Grant{FolderID: &id, Role: RoleViewer},
+			err:   true,
+		},
+		{
+			title: "grant without object",
+			grant: Grant{InvitedBy: 10, Role: RoleViewer},
+			err:   true,
+		},
+		{
+			title: "grant of folder and notepad",
+			grant: Grant{InvitedBy: 10, FolderID: &id, NotepadID: &id, Role: RoleViewer},
+			err:   true,
+		},
+		{
+			title: "grant without role",
+			grant: Grant{InvitedBy: 10, FolderID: &id},
+			err:   true,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.title, func(t *testing.T) {
+			err := tt.grant.Validate()
+			if tt.err {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
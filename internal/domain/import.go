@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Formats of imported files.
+const (
+	// ImportMarkdown is a zip archive of markdown files, directories
+	// become folders and notepads. Archives made by export keep titles,
+	// timestamps and tags.
+	ImportMarkdown = "markdown"
+	// ImportENEX is an Evernote export of a notebook.
+	ImportENEX = "enex"
+	// ImportJoplin is a zip archive of a Joplin export in JSON or RAW
+	// format.
+	ImportJoplin = "joplin"
+)
+
+// Statuses of import.
+const (
+	ImportPending = "pending"
+	ImportRunning = "running"
+	ImportDone    = "done"
+	ImportFailed  = "failed"
+)
+
+// Import is a background job that imports notes from a file. Imported
+// folders and notepads are put into a new top level folder.
+type Import struct {
+	ID     int    `json:"id" gorm:"column:id"`
+	UserID int    `json:"user_id" gorm:"column:user_id"`
+	Format string `json:"format" gorm:"column:format"`
+	// Name is the name of the uploaded file
+	Name string `json:"name" gorm:"column:name"`
+	// Key is a key of the uploaded file in the blob store
+	Key    string `json:"-" gorm:"column:key"`
+	Status string `json:"status" gorm:"column:status"`
+	// FolderID is a folder that contains imported objects
+	FolderID *int `json:"folder_id" gorm:"column:folder_id"`
+	// Numbers of imported objects
+	Notepads int `json:"notepads" gorm:"column:notepads"`
+	Notes    int `json:"notes" gorm:"column:notes"`
+	// Errors of items that were skipped, or of the whole import
+	// if it failed
+	Errors []ImportError `json:"errors" gorm:"-"`
+
+	// Managed by gorm callbacks
+	CreatedAt  time.Time  `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt  *time.Time `json:"-" gorm:"column:updated_at"`
+	FinishedAt *time.Time `json:"finished_at" gorm:"column:finished_at"`
+}
+
+// ImportError is an error of one item of import.
+type ImportError struct {
+	ID       int    `json:"-" gorm:"column:id"`
+	ImportID int    `json:"-" gorm:"column:import_id"`
+	Item     string `json:"item" gorm:"column:item"`
+	Error    string `json:"error" gorm:"column:error"`
+}
+
+// Validate validates import.
+func (i Import) Validate() error {
+	if i.UserID == 0 {
+		return errors.New("unknown user")
+	}
+	switch i.Format {
+	case ImportMarkdown, ImportENEX, ImportJoplin:
+	default:
+		return errors.Errorf("unknown format %q", i.Format)
+	}
+	if i.Key == "" {
+		return errors.New("key cannot be empty")
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportValidation(t *testing.T) {
+	cases := []struct {
+		title string
+		imp   Import
+		err   bool
+	}{
+		{
+			title: "correct markdown import",
+			imp:   Import{UserID: 10, Format: ImportMarkdown, Key: "abc"},
+			err:   false,
+		},
+		{
+			title: "correct evernote import",
+			imp:   Import{UserID: 10, Format: ImportENEX, Key: "abc"},
+			err:   false,
+		},
+		{
+			title: "import without user",
+			imp:   Import{Format: ImportJoplin, Key: "abc"},
+			err:   true,
+		},
+		{
+			title: "import of unknown format",
+			imp:   Import{UserID: 10, Format: "docx", Key: "abc"},
+			err:   true,
+		},
+		{
+			title: "import without file",
+			imp:   Import{UserID: 10, Format: ImportMarkdown},
+			err:   true,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.title, func(t *testing.T) {
+			err := tt.imp.Validate()
+			if tt.err {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
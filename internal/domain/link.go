@@ -0,0 +1,43 @@
+package domain
+
+// NoteLink is a wiki link from one note to another.
+type NoteLink struct {
+	NoteID int `json:"note_id" gorm:"column:note_id"`
+	// Target as it's written in the text: ID for [[note:123]] links,
+	// title for [[Note Title]] links
+	LinkID    *int   `json:"link_id,omitempty" gorm:"column:link_id"`
+	LinkTitle string `json:"link_title,omitempty" gorm:"column:link_title"`
+	// Resolved target note, not set if the link is broken
+	TargetID    *int   `json:"target_id" gorm:"column:target_id"`
+	TargetTitle string `json:"target_title,omitempty" gorm:"column:target_title"`
+	Broken      bool   `json:"broken" gorm:"column:broken"`
+}
+
+// NoteGraph is a graph of notes connected by wiki links.
+type NoteGraph struct {
+	Notes []NoteNode `json:"notes"`
+	Links []NoteLink `json:"links"`
+}
+
+// BuildGraph builds a graph from lists of notes and their links.
+// Links from notes that are not in the list are skipped, as well as
+// links to such notes, so the graph doesn't reveal notes that are not
+// available to the user. Broken links are kept.
+func BuildGraph(notes []NoteNode, links []NoteLink) NoteGraph {
+	ids := map[int]bool{}
+	for _, n := range notes {
+		ids[n.ID] = true
+	}
+
+	g := NoteGraph{Notes: notes, Links: []NoteLink{}}
+	for _, l := range links {
+		if !ids[l.NoteID] {
+			continue
+		}
+		if l.TargetID != nil && !ids[*l.TargetID] {
+			continue
+		}
+		g.Links = append(g.Links, l)
+	}
+	return g
+}
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildGraph(t *testing.T) {
+	Int := func(n int) *int {
+		return &n
+	}
+
+	t.Run("links between available notes", func(t *testing.T) {
+		notes := []NoteNode{
+			{ID: 1, NotepadID: 10, Title: "First"},
+			{ID: 2, NotepadID: 10, Title: "Second"},
+		}
+		links := []NoteLink{
+			{NoteID: 1, LinkTitle: "Second", TargetID: Int(2), TargetTitle: "Second"},
+			{NoteID: 1, LinkID: Int(3), TargetID: Int(3), TargetTitle: "Hidden"},
+			{NoteID: 2, LinkTitle: "Missing", Broken: true},
+			{NoteID: 4, LinkID: Int(1), TargetID: Int(1), TargetTitle: "First"},
+		}
+
+		g := BuildGraph(notes, links)
+		assert.Equal(t, NoteGraph{
+			Notes: notes,
+			Links: []NoteLink{
+				{NoteID: 1, LinkTitle: "Second", TargetID: Int(2), TargetTitle: "Second"},
+				{NoteID: 2, LinkTitle: "Missing", Broken: true},
+			},
+		}, g)
+	})
+
+	t.Run("no links", func(t *testing.T) {
+		notes := []NoteNode{{ID: 1, NotepadID: 10, Title: "First"}}
+		g := BuildGraph(notes, nil)
+		assert.Equal(t, NoteGraph{Notes: notes, Links: []NoteLink{}}, g)
+	})
+}
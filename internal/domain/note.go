@@ -1,6 +1,8 @@
 package domain
 
 import (
+	"html"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -13,12 +15,16 @@ type Note struct {
 	NotepadID int    `json:"notepad_id" gorm:"column:notepad_id"`
 	Title     string `json:"title" gorm:"column:title"`
 	Text      string `json:"text" gorm:"column:text"`
+	// Tags are set explicitly or parsed from #hashtags in Text
+	Tags []string `json:"tags,omitempty" gorm:"-"`
 	// HTML field is rendered from Text (which contains markdown) on the fly
 	HTML string `json:"html,omitempty" gorm:"-"`
 
 	// Managed by gorm callbacks
 	CreatedAt time.Time  `json:"-" gorm:"column:created_at"`
 	UpdatedAt *time.Time `json:"-" gorm:"column:updated_at"`
+	// Set when the note is moved to trash
+	DeletedAt *time.Time `json:"deleted_at,omitempty" gorm:"column:deleted_at"`
 }
 
 // Validate validates note.
@@ -32,5 +38,53 @@ func (n Note) Validate() error {
 	if n.Title == "" {
 		return errors.New("title cannot be empty")
 	}
+	for _, t := range n.Tags {
+		if err := ValidateTagName(NormalizeTag(t)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CollectTags gets all tags of the note: explicitly set ones and
+// #hashtags from the text.
+func (n Note) CollectTags() []string {
+	return NormalizeTags(append(ParseTags(n.Text), n.Tags...))
+}
+
+// CheckParent checks if the note can be put into the notepad.
+func (n Note) CheckParent(notepad Notepad) error {
+	if n.NotepadID != notepad.ID {
+		return errors.New("wrong parent")
+	}
+	if notepad.UserID != n.UserID || notepad.DeletedAt != nil {
+		return ErrParentNotFound
+	}
 	return nil
 }
+
+// NoteHit is a note found by full-text search.
+type NoteHit struct {
+	Note
+	// Rank shows how well the note matches the search query
+	Rank float64 `json:"rank" gorm:"column:rank"`
+	// Snippet is a fragment of the note text with matched words
+	// wrapped in <mark> tags
+	Snippet string `json:"snippet" gorm:"column:snippet"`
+}
+
+// Delimiters of matched words in raw snippets. Control characters are
+// used instead of tags, so they can't be confused with HTML in the text.
+const (
+	SnippetStartSel = "\x02"
+	SnippetStopSel  = "\x03"
+)
+
+// MarkSnippet makes HTML snippet from the raw one: the text is escaped,
+// and matched words are wrapped in <mark> tags.
+func MarkSnippet(raw string) string {
+	s := html.EscapeString(raw)
+	s = strings.Replace(s, SnippetStartSel, "<mark>", -1)
+	s = strings.Replace(s, SnippetStopSel, "</mark>", -1)
+	return s
+}
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -58,6 +59,16 @@ func TestNoteValidation(t *testing.T) {
 			},
 			err: false,
 		},
+		{
+			title: "note with invalid tag",
+			note: Note{
+				UserID:    10,
+				NotepadID: 20,
+				Title:     "x-note",
+				Tags:      []string{"hello world"},
+			},
+			err: true,
+		},
 	}
 
 	for _, tt := range cases {
@@ -71,3 +82,35 @@ func TestNoteValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestNoteCheckParent(t *testing.T) {
+	note := Note{UserID: 10, NotepadID: 20, Title: "x-note"}
+
+	t.Run("notepad of the same user", func(t *testing.T) {
+		err := note.CheckParent(Notepad{ID: 20, UserID: 10})
+		assert.NoError(t, err)
+	})
+
+	t.Run("notepad of another user", func(t *testing.T) {
+		err := note.CheckParent(Notepad{ID: 20, UserID: 11})
+		assert.Equal(t, ErrParentNotFound, err)
+	})
+
+	t.Run("notepad in trash", func(t *testing.T) {
+		now := time.Now()
+		err := note.CheckParent(Notepad{ID: 20, UserID: 10, DeletedAt: &now})
+		assert.Equal(t, ErrParentNotFound, err)
+	})
+
+	t.Run("wrong notepad", func(t *testing.T) {
+		err := note.CheckParent(Notepad{ID: 21, UserID: 10})
+		assert.Error(t, err)
+	})
+}
+
+func TestMarkSnippet(t *testing.T) {
+	raw := "<script>alert(1)</script> " + SnippetStartSel + "hello" + SnippetStopSel + " & bye"
+	assert.Equal(t,
+		"&lt;script&gt;alert(1)&lt;/script&gt; <mark>hello</mark> &amp; bye",
+		MarkSnippet(raw))
+}
@@ -16,6 +16,8 @@ type Notepad struct {
 	// Managed by gorm callbacks
 	CreatedAt time.Time  `json:"-" gorm:"column:created_at"`
 	UpdatedAt *time.Time `json:"-" gorm:"column:updated_at"`
+	// Set when the notepad is moved to trash
+	DeletedAt *time.Time `json:"deleted_at,omitempty" gorm:"column:deleted_at"`
 }
 
 // Validate validates notepad.
@@ -31,3 +33,14 @@ func (n Notepad) Validate() error {
 	}
 	return nil
 }
+
+// CheckParent checks if the notepad can be put into the folder.
+func (n Notepad) CheckParent(folder Folder) error {
+	if n.FolderID != folder.ID {
+		return errors.New("wrong parent")
+	}
+	if folder.UserID != n.UserID || folder.DeletedAt != nil {
+		return ErrParentNotFound
+	}
+	return nil
+}
@@ -58,3 +58,22 @@ func TestNotepadValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestNotepadCheckParent(t *testing.T) {
+	notepad := Notepad{UserID: 10, FolderID: 20, Title: "x-notepad"}
+
+	t.Run("folder of the same user", func(t *testing.T) {
+		err := notepad.CheckParent(Folder{ID: 20, UserID: 10})
+		assert.NoError(t, err)
+	})
+
+	t.Run("folder of another user", func(t *testing.T) {
+		err := notepad.CheckParent(Folder{ID: 20, UserID: 11})
+		assert.Equal(t, ErrParentNotFound, err)
+	})
+
+	t.Run("wrong folder", func(t *testing.T) {
+		err := notepad.CheckParent(Folder{ID: 21, UserID: 10})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// NoteRevision represents a saved state of a note. New revision
+// is created every time the note is saved.
+type NoteRevision struct {
+	ID     int    `json:"id" gorm:"column:id"`
+	NoteID int    `json:"note_id" gorm:"column:note_id"`
+	UserID int    `json:"user_id" gorm:"column:user_id"`
+	Number int    `json:"number" gorm:"column:number"`
+	Title  string `json:"title" gorm:"column:title"`
+	Text   string `json:"text" gorm:"column:text"`
+	// Managed by gorm callbacks
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+}
+
+// DiffRevisions makes unified diff of texts of two note revisions.
+func DiffRevisions(from, to NoteRevision) (string, error) {
+	d := difflib.UnifiedDiff{
+		A:        splitLines(from.Text),
+		B:        splitLines(to.Text),
+		FromFile: "revision " + strconv.Itoa(from.Number),
+		FromDate: from.CreatedAt.Format(time.RFC3339),
+		ToFile:   "revision " + strconv.Itoa(to.Number),
+		ToDate:   to.CreatedAt.Format(time.RFC3339),
+		Context:  3,
+	}
+	return difflib.GetUnifiedDiffString(d)
+}
+
+// splitLines splits text to lines keeping line endings. Last line
+// always gets a line ending, so it doesn't show up in diff when
+// only the trailing newline has changed.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		return lines[:len(lines)-1]
+	}
+	lines[len(lines)-1] += "\n"
+	return lines
+}
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffRevisions(t *testing.T) {
+	date := time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run("different texts", func(t *testing.T) {
+		from := NoteRevision{Number: 1, Text: "one\ntwo\nthree\n", CreatedAt: date}
+		to := NoteRevision{Number: 2, Text: "one\n2\nthree\n", CreatedAt: date}
+
+		diff, err := DiffRevisions(from, to)
+		assert.NoError(t, err)
+		assert.Equal(t, ""+
+			"--- revision 1\t2018-01-02T03:04:05Z\n"+
+			"+++ revision 2\t2018-01-02T03:04:05Z\n"+
+			"@@ -1,3 +1,3 @@\n"+
+			" one\n"+
+			"-two\n"+
+			"+2\n"+
+			" three\n", diff)
+	})
+
+	t.Run("same texts", func(t *testing.T) {
+		from := NoteRevision{Number: 1, Text: "one\n", CreatedAt: date}
+		to := NoteRevision{Number: 2, Text: "one\n", CreatedAt: date}
+
+		diff, err := DiffRevisions(from, to)
+		assert.NoError(t, err)
+		assert.Equal(t, "", diff)
+	})
+}
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Share is a public link that gives anonymous read-only access
+// to a note or to all notes of a notepad.
+type Share struct {
+	ID        int    `json:"id" gorm:"column:id"`
+	UserID    int    `json:"user_id" gorm:"column:user_id"`
+	Token     string `json:"token" gorm:"column:token"`
+	NoteID    *int   `json:"note_id,omitempty" gorm:"column:note_id"`
+	NotepadID *int   `json:"notepad_id,omitempty" gorm:"column:notepad_id"`
+	// Password is a hash of the password that protects the share,
+	// empty if there is no protection
+	Password  string     `json:"-" gorm:"column:password"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" gorm:"column:expires_at"`
+	// URL is a public link, it is made for each response
+	URL string `json:"url,omitempty" gorm:"-"`
+
+	// Managed by gorm callbacks
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+}
+
+// Validate validates share.
+func (s Share) Validate() error {
+	if s.UserID == 0 {
+		return errors.New("unknown user")
+	}
+	if s.Token == "" {
+		return errors.New("token cannot be empty")
+	}
+	if (s.NoteID == nil) == (s.NotepadID == nil) {
+		return errors.New("either note id or notepad id must be set")
+	}
+	return nil
+}
+
+// Protected checks if the share requires password.
+func (s Share) Protected() bool {
+	return s.Password != ""
+}
+
+// Expired checks if the share is no longer available at the moment.
+func (s Share) Expired(now time.Time) bool {
+	return s.ExpiresAt != nil && !now.Before(*s.ExpiresAt)
+}
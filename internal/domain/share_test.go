@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShareValidation(t *testing.T) {
+	id := 20
+
+	cases := []struct {
+		title string
+		share Share
+		err   bool
+	}{
+		{
+			title: "correct note share",
+			share: Share{UserID: 10, Token: "abc", NoteID: &id},
+			err:   false,
+		},
+		{
+			title: "correct notepad share",
+			share: Share{UserID: 10, Token: "abc", NotepadID: &id},
+			err:   false,
+		},
+		{
+			title: "share without user",
+			share: Share{Token: "abc", NoteID: &id},
+			err:   true,
+		},
+		{
+			title: "share without token",
+			share: Share{UserID: 10, NoteID: &id},
+			err:   true,
+		},
+		{
+			title: "share without object",
+			share: Share{UserID: 10, Token: "abc"},
+			err:   true,
+		},
+		{
+			title: "share of note and notepad",
+			share: Share{UserID: 10, Token: "abc", NoteID: &id, NotepadID: &id},
+			err:   true,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.title, func(t *testing.T) {
+			err := tt.share.Validate()
+			if tt.err {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestShareExpired(t *testing.T) {
+	now := time.Now()
+	past, future := now.Add(-time.Minute), now.Add(time.Minute)
+
+	assert.False(t, Share{}.Expired(now))
+	assert.False(t, Share{ExpiresAt: &future}.Expired(now))
+	assert.True(t, Share{ExpiresAt: &past}.Expired(now))
+	assert.True(t, Share{ExpiresAt: &now}.Expired(now))
+}
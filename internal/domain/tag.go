@@ -0,0 +1,169 @@
+package domain
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// maxTagLength is a maximum length of tag name in characters.
+const maxTagLength = 64
+
+var (
+	// tagName matches valid tag names: letters, digits, underscores,
+	// dashes and slashes (for nested tags like "work/project").
+	tagName = regexp.MustCompile(`^[\p{L}\p{N}_/-]+$`)
+	// hashtag matches inline tags in markdown. The hash must not be
+	// preceded by a word character, so URL fragments and HTML entities
+	// are not treated as tags. Headings are skipped, since they have
+	// a space after the hash.
+	hashtag = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_&/#])#([\p{L}\p{N}_/-]+)`)
+	// inlineCode matches inline code spans in markdown.
+	inlineCode = regexp.MustCompile("`[^`]*`")
+	// hasLetter checks that tag is not just a number, like issue
+	// references "#123".
+	hasLetter = regexp.MustCompile(`\pL`)
+)
+
+// Tag represents a label that can be attached to notes.
+type Tag struct {
+	ID     int    `json:"id" gorm:"column:id"`
+	UserID int    `json:"user_id" gorm:"column:user_id"`
+	Name   string `json:"name" gorm:"column:name"`
+
+	// Managed by gorm callbacks
+	CreatedAt time.Time  `json:"-" gorm:"column:created_at"`
+	UpdatedAt *time.Time `json:"-" gorm:"column:updated_at"`
+}
+
+// Validate validates tag.
+func (t Tag) Validate() error {
+	if t.UserID == 0 {
+		return errors.New("unknown user")
+	}
+	return ValidateTagName(t.Name)
+}
+
+// ValidateTagName checks if the string can be used as a tag name.
+func ValidateTagName(name string) error {
+	if name == "" {
+		return errors.New("tag cannot be empty")
+	}
+	if utf8.RuneCountInString(name) > maxTagLength {
+		return errors.Errorf("tag cannot be longer than %d characters", maxTagLength)
+	}
+	if !tagName.MatchString(name) {
+		return errors.Errorf("invalid tag %q", name)
+	}
+	return nil
+}
+
+// NormalizeTag converts tag name to its canonical form. Tags are
+// case-insensitive, and the leading hash is optional.
+func NormalizeTag(name string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(name), "#"))
+}
+
+// ParseTags finds inline #hashtags in markdown text. Code blocks and
+// code spans are skipped. Tags are returned normalized, sorted and
+// without duplicates.
+func ParseTags(text string) []string {
+	var (
+		tags  []string
+		fence string
+	)
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			continue
+		}
+		line = inlineCode.ReplaceAllString(line, "")
+		for _, m := range hashtag.FindAllStringSubmatch(line, -1) {
+			name := strings.TrimRight(m[1], "/-")
+			if hasLetter.MatchString(name) && ValidateTagName(name) == nil {
+				tags = append(tags, name)
+			}
+		}
+	}
+	return NormalizeTags(tags)
+}
+
+// ReplaceTag renames inline #hashtags of the tag in markdown text.
+// If the new name is empty, the hash is removed and the word is left
+// in the text. Code blocks and code spans are not changed.
+func ReplaceTag(text, from, to string) string {
+	lines := strings.Split(text, "\n")
+	var fence string
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			continue
+		}
+
+		var b strings.Builder
+		last := 0
+		for _, code := range inlineCode.FindAllStringIndex(line, -1) {
+			b.WriteString(replaceHashtags(line[last:code[0]], from, to))
+			b.WriteString(line[code[0]:code[1]])
+			last = code[1]
+		}
+		b.WriteString(replaceHashtags(line[last:], from, to))
+		lines[i] = b.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// replaceHashtags renames hashtags of the tag in the text without code.
+func replaceHashtags(text, from, to string) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range hashtag.FindAllStringSubmatchIndex(text, -1) {
+		name := strings.TrimRight(text[m[2]:m[3]], "/-")
+		if NormalizeTag(name) != from {
+			continue
+		}
+		b.WriteString(text[last : m[2]-1]) // without the hash
+		if to != "" {
+			b.WriteString("#" + to)
+		} else {
+			b.WriteString(name)
+		}
+		last = m[2] + len(name)
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// NormalizeTags normalizes tags, sorts them and removes duplicates.
+func NormalizeTags(tags []string) []string {
+	set := make(map[string]struct{}, len(tags))
+	for _, t := range tags {
+		if t = NormalizeTag(t); t != "" {
+			set[t] = struct{}{}
+		}
+	}
+	uniq := make([]string, 0, len(set))
+	for t := range set {
+		uniq = append(uniq, t)
+	}
+	sort.Strings(uniq)
+	return uniq
+}
@@ -0,0 +1,130 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTagValidation(t *testing.T) {
+	cases := []struct {
+		title string
+		tag   Tag
+		err   bool
+	}{
+		{
+			title: "correct tag",
+			tag:   Tag{UserID: 10, Name: "work/project-1"},
+			err:   false,
+		},
+		{
+			title: "tag without user",
+			tag:   Tag{Name: "work"},
+			err:   true,
+		},
+		{
+			title: "tag without name",
+			tag:   Tag{UserID: 10},
+			err:   true,
+		},
+		{
+			title: "tag with spaces",
+			tag:   Tag{UserID: 10, Name: "my work"},
+			err:   true,
+		},
+		{
+			title: "too long tag",
+			tag:   Tag{UserID: 10, Name: strings.Repeat("x", 65)},
+			err:   true,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.title, func(t *testing.T) {
+			err := tt.tag.Validate()
+			if tt.err {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestParseTags(t *testing.T) {
+	cases := []struct {
+		title string
+		text  string
+		tags  []string
+	}{
+		{
+			title: "no tags",
+			text:  "hello, world",
+			tags:  []string{},
+		},
+		{
+			title: "tags in text",
+			text:  "#Todo: call Bob about #work/project, then #todo again.",
+			tags:  []string{"todo", "work/project"},
+		},
+		{
+			title: "headings, links and numbers",
+			text:  "# Title\n\nSee http://example.com/#anchor, issue #123 and &#35;",
+			tags:  []string{},
+		},
+		{
+			title: "code",
+			text:  "#one `#two`\n```\n#three\n```\n~~~go\n#four\n~~~\n#five",
+			tags:  []string{"five", "one"},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.title, func(t *testing.T) {
+			assert.Equal(t, tt.tags, ParseTags(tt.text))
+		})
+	}
+}
+
+func TestReplaceTag(t *testing.T) {
+	cases := []struct {
+		title string
+		text  string
+		from  string
+		to    string
+		want  string
+	}{
+		{
+			title: "rename",
+			text:  "#Todo: call Bob, #todo-, #todos and #work/todo",
+			from:  "todo",
+			to:    "tasks",
+			want:  "#tasks: call Bob, #tasks-, #todos and #work/todo",
+		},
+		{
+			title: "remove hash",
+			text:  "#todo\nSee #todo, not page#todo",
+			from:  "todo",
+			want:  "todo\nSee todo, not page#todo",
+		},
+		{
+			title: "code",
+			text:  "#one `#one` #one\n```\n#one\n```\n#one",
+			from:  "one",
+			to:    "two",
+			want:  "#two `#one` #two\n```\n#one\n```\n#two",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.title, func(t *testing.T) {
+			assert.Equal(t, tt.want, ReplaceTag(tt.text, tt.from, tt.to))
+		})
+	}
+}
+
+func TestNoteCollectTags(t *testing.T) {
+	n := Note{Text: "#one and #Two", Tags: []string{"#three", "two", " "}}
+	assert.Equal(t, []string{"one", "three", "two"}, n.CollectTags())
+}
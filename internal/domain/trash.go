@@ -0,0 +1,39 @@
+package domain
+
+import "github.com/pkg/errors"
+
+// Types of objects that can be moved to trash.
+const (
+	TrashFolder  = "folder"
+	TrashNotepad = "notepad"
+	TrashNote    = "note"
+)
+
+// Trash contains deleted objects. Only objects that were deleted
+// explicitly are listed, their children are restored or purged
+// together with them.
+type Trash struct {
+	Folders  []Folder  `json:"folders"`
+	Notepads []Notepad `json:"notepads"`
+	Notes    []Note    `json:"notes"`
+}
+
+// TrashItem is a reference to an object in trash.
+type TrashItem struct {
+	Type   string
+	ID     int
+	UserID int
+}
+
+// Validate validates trash item.
+func (i TrashItem) Validate() error {
+	if i.UserID == 0 {
+		return errors.New("unknown user")
+	}
+	switch i.Type {
+	case TrashFolder, TrashNotepad, TrashNote:
+	default:
+		return errors.New("unknown object type")
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrashItemValidation(t *testing.T) {
+	cases := []struct {
+		title string
+		item  TrashItem
+		err   bool
+	}{
+		{
+			title: "correct folder",
+			item:  TrashItem{Type: TrashFolder, ID: 10, UserID: 20},
+			err:   false,
+		},
+		{
+			title: "correct note",
+			item:  TrashItem{Type: TrashNote, ID: 10, UserID: 20},
+			err:   false,
+		},
+		{
+			title: "item without user",
+			item:  TrashItem{Type: TrashNotepad, ID: 10},
+			err:   true,
+		},
+		{
+			title: "item of unknown type",
+			item:  TrashItem{Type: "user", ID: 10, UserID: 20},
+			err:   true,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.title, func(t *testing.T) {
+			err := tt.item.Validate()
+			if tt.err {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
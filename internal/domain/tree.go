@@ -0,0 +1,98 @@
+package domain
+
+// MaxTreeDepth is a maximum number of folder levels in the tree.
+const MaxTreeDepth = 100
+
+// FolderNode is a folder in the tree of user's folders, notepads
+// and notes.
+type FolderNode struct {
+	ID       int    `json:"id" gorm:"column:id"`
+	ParentID *int   `json:"parent_id" gorm:"column:parent_id"`
+	Title    string `json:"title" gorm:"column:title"`
+	// Number of direct children, they are counted even if they
+	// are not included in the tree
+	FoldersCount  int `json:"folders_count" gorm:"column:folders_count"`
+	NotepadsCount int `json:"notepads_count" gorm:"column:notepads_count"`
+
+	Folders  []FolderNode  `json:"folders" gorm:"-"`
+	Notepads []NotepadNode `json:"notepads" gorm:"-"`
+}
+
+// NotepadNode is a notepad in the tree of user's folders, notepads
+// and notes.
+type NotepadNode struct {
+	ID         int    `json:"id" gorm:"column:id"`
+	FolderID   int    `json:"folder_id" gorm:"column:folder_id"`
+	Title      string `json:"title" gorm:"column:title"`
+	NotesCount int    `json:"notes_count" gorm:"column:notes_count"`
+
+	Notes []NoteNode `json:"notes,omitempty" gorm:"-"`
+}
+
+// NoteNode is a note in the tree of user's folders, notepads
+// and notes.
+type NoteNode struct {
+	ID        int    `json:"id" gorm:"column:id"`
+	NotepadID int    `json:"notepad_id" gorm:"column:notepad_id"`
+	Title     string `json:"title" gorm:"column:title"`
+}
+
+// BuildTree builds a tree from flat lists of nodes. Folders, whose
+// parents are not in the list, become roots of the tree. If parents
+// of folders make a cycle, the first folder of the cycle in the list
+// becomes a root, and the cycle is cut before it. Order of nodes
+// on each level is the same as in the lists.
+func BuildTree(folders []FolderNode, notepads []NotepadNode, notes []NoteNode) []FolderNode {
+	notesByNotepad := map[int][]NoteNode{}
+	for _, n := range notes {
+		notesByNotepad[n.NotepadID] = append(notesByNotepad[n.NotepadID], n)
+	}
+
+	notepadsByFolder := map[int][]NotepadNode{}
+	for _, n := range notepads {
+		n.Notes = notesByNotepad[n.ID]
+		notepadsByFolder[n.FolderID] = append(notepadsByFolder[n.FolderID], n)
+	}
+
+	ids := map[int]bool{}
+	for _, f := range folders {
+		ids[f.ID] = true
+	}
+	var roots []FolderNode
+	children := map[int][]FolderNode{}
+	for _, f := range folders {
+		if f.ParentID == nil || !ids[*f.ParentID] {
+			roots = append(roots, f)
+		} else {
+			children[*f.ParentID] = append(children[*f.ParentID], f)
+		}
+	}
+
+	visited := map[int]bool{}
+	var build func(f FolderNode) FolderNode
+	build = func(f FolderNode) FolderNode {
+		visited[f.ID] = true
+		f.Folders = []FolderNode{}
+		for _, c := range children[f.ID] {
+			if !visited[c.ID] {
+				f.Folders = append(f.Folders, build(c))
+			}
+		}
+		f.Notepads = notepadsByFolder[f.ID]
+		if f.Notepads == nil {
+			f.Notepads = []NotepadNode{}
+		}
+		return f
+	}
+
+	tree := make([]FolderNode, 0, len(roots))
+	for _, r := range roots {
+		tree = append(tree, build(r))
+	}
+	for _, f := range folders {
+		if !visited[f.ID] {
+			tree = append(tree, build(f))
+		}
+	}
+	return tree
+}
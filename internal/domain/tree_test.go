@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildTree(t *testing.T) {
+	Int := func(n int) *int {
+		return &n
+	}
+
+	t.Run("full tree", func(t *testing.T) {
+		folders := []FolderNode{
+			{ID: 1, Title: "Root", FoldersCount: 1, NotepadsCount: 1},
+			{ID: 2, ParentID: Int(1), Title: "Child", NotepadsCount: 0},
+		}
+		notepads := []NotepadNode{
+			{ID: 10, FolderID: 1, Title: "Notepad", NotesCount: 1},
+		}
+		notes := []NoteNode{
+			{ID: 100, NotepadID: 10, Title: "Note"},
+		}
+
+		tree := BuildTree(folders, notepads, notes)
+		assert.Equal(t, []FolderNode{
+			{
+				ID:            1,
+				Title:         "Root",
+				FoldersCount:  1,
+				NotepadsCount: 1,
+				Folders: []FolderNode{
+					{
+						ID:       2,
+						ParentID: Int(1),
+						Title:    "Child",
+						Folders:  []FolderNode{},
+						Notepads: []NotepadNode{},
+					},
+				},
+				Notepads: []NotepadNode{
+					{
+						ID:         10,
+						FolderID:   1,
+						Title:      "Notepad",
+						NotesCount: 1,
+						Notes:      []NoteNode{{ID: 100, NotepadID: 10, Title: "Note"}},
+					},
+				},
+			},
+		}, tree)
+	})
+
+	t.Run("subtree", func(t *testing.T) {
+		folders := []FolderNode{
+			{ID: 2, ParentID: Int(1), Title: "Child"},
+			{ID: 3, ParentID: Int(2), Title: "Grandchild"},
+		}
+
+		tree := BuildTree(folders, nil, nil)
+		assert.Len(t, tree, 1)
+		assert.Equal(t, 2, tree[0].ID)
+		assert.Len(t, tree[0].Folders, 1)
+		assert.Equal(t, 3, tree[0].Folders[0].ID)
+	})
+
+	t.Run("subtree in a cycle", func(t *testing.T) {
+		folders := []FolderNode{
+			{ID: 1, ParentID: Int(3), Title: "Root"},
+			{ID: 2, ParentID: Int(1), Title: "Child"},
+			{ID: 3, ParentID: Int(2), Title: "Grandchild"},
+		}
+
+		tree := BuildTree(folders, nil, nil)
+		assert.Len(t, tree, 1)
+		assert.Equal(t, 1, tree[0].ID)
+		assert.Len(t, tree[0].Folders, 1)
+		assert.Equal(t, 2, tree[0].Folders[0].ID)
+		assert.Len(t, tree[0].Folders[0].Folders, 1)
+		assert.Equal(t, 3, tree[0].Folders[0].Folders[0].ID)
+		assert.Empty(t, tree[0].Folders[0].Folders[0].Folders)
+	})
+
+	t.Run("empty tree", func(t *testing.T) {
+		tree := BuildTree(nil, nil, nil)
+		assert.Len(t, tree, 0)
+	})
+}
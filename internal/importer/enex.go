@@ -0,0 +1,104 @@
+package importer
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/tetafro/nott-backend-go/internal/domain"
+	"github.com/tetafro/nott-backend-go/internal/markdown"
+)
+
+// enexTime is a format of timestamps in Evernote exports.
+const enexTime = "20060102T150405Z"
+
+// enexNote is a note in Evernote export. Content is an XHTML document.
+type enexNote struct {
+	Title   string   `xml:"title"`
+	Content string   `xml:"content"`
+	Created string   `xml:"created"`
+	Updated string   `xml:"updated"`
+	Tags    []string `xml:"tag"`
+	// Only the number of attached files is needed
+	Resources []struct{} `xml:"resource"`
+}
+
+// parseENEX reads Evernote export of a notebook. All notes go to one
+// notepad named after the file. The file is read note by note, since
+// it can be large due to attached files.
+func parseENEX(r io.Reader, name string, h Handler) error {
+	n := Notepad{Title: title(baseName(name))}
+
+	dec := xml.NewDecoder(r)
+	num := 0
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.Wrap(err, "read xml")
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "note" {
+			continue
+		}
+
+		num++
+		var en enexNote
+		if err = dec.DecodeElement(&en, &start); err != nil {
+			return errors.Wrap(err, "read note")
+		}
+		item := en.Title
+		if item == "" {
+			item = "note " + strconv.Itoa(num)
+		}
+
+		note, err := convertENEXNote(en)
+		if err != nil {
+			h.Error(item, err)
+			continue
+		}
+		note.Tags = cleanTags(item, note.Tags, h)
+		if len(en.Resources) > 0 {
+			h.Error(item, errors.Errorf("%d attached files are not imported", len(en.Resources)))
+		}
+		n.Notes = append(n.Notes, note)
+	}
+
+	if len(n.Notes) == 0 {
+		return nil
+	}
+	return h.Notepad(n)
+}
+
+func convertENEXNote(en enexNote) (domain.Note, error) {
+	if len(en.Content) > maxNoteSize {
+		return domain.Note{}, errors.New("note is too large")
+	}
+	text, err := markdown.FromHTML(en.Content)
+	if err != nil {
+		return domain.Note{}, errors.Wrap(err, "convert content")
+	}
+	n := domain.Note{
+		Title: title(en.Title),
+		Text:  text,
+		Tags:  en.Tags,
+	}
+	if en.Created != "" {
+		if n.CreatedAt, err = time.Parse(enexTime, en.Created); err != nil {
+			return domain.Note{}, errors.Wrap(err, "invalid created time")
+		}
+	}
+	if en.Updated != "" {
+		updated, err := time.Parse(enexTime, en.Updated)
+		if err != nil {
+			return domain.Note{}, errors.Wrap(err, "invalid updated time")
+		}
+		n.UpdatedAt = &updated
+	}
+	return n, nil
+}
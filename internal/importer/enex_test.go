@@ -0,0 +1,71 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tetafro/nott-backend-go/internal/domain"
+)
+
+func TestParseENEX(t *testing.T) {
+	t.Run("valid export", func(t *testing.T) {
+		enex := `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE en-export SYSTEM "http://xml.evernote.com/pub/evernote-export3.dtd">
+<en-export export-date="20200101T000000Z" application="Evernote" version="10">
+  <note>
+    <title>Shopping</title>
+    <content><![CDATA[<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE en-note SYSTEM "http://xml.evernote.com/pub/enml2.dtd">
+<en-note><div><en-todo checked="true"/>Milk</div><div><en-todo/>Bread</div><en-media hash="abc" type="image/png"/></en-note>]]></content>
+    <created>20200102T030405Z</created>
+    <updated>20200203T040506Z</updated>
+    <tag>home</tag>
+    <tag>to buy</tag>
+    <resource><data encoding="base64">aGVsbG8=</data></resource>
+  </note>
+  <note>
+    <title></title>
+    <content><![CDATA[<en-note><b>Bold</b></en-note>]]></content>
+  </note>
+  <note>
+    <title>Broken</title>
+    <content></content>
+    <created>yesterday</created>
+  </note>
+</en-export>`
+		var res result
+		r := strings.NewReader(enex)
+		err := Parse("enex", r, r.Size(), "My Notebook.enex", res.handler())
+		assert.NoError(t, err)
+
+		updated := time.Date(2020, 2, 3, 4, 5, 6, 0, time.UTC)
+		assert.Equal(t, []Notepad{{
+			Title: "My Notebook",
+			Notes: []domain.Note{
+				{
+					Title:     "Shopping",
+					Text:      "[x] Milk\n\n[ ] Bread",
+					Tags:      []string{"home", "to-buy"},
+					CreatedAt: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+					UpdatedAt: &updated,
+				},
+				{Title: "Untitled", Text: "**Bold**"},
+			},
+		}}, res.notepads)
+		assert.Equal(t, map[string]string{
+			"Shopping": "1 attached files are not imported",
+			"Broken": `invalid created time: parsing time "yesterday" ` +
+				`as "20060102T150405Z": cannot parse "yesterday" as "2006"`,
+		}, res.errors)
+	})
+
+	t.Run("invalid xml", func(t *testing.T) {
+		var res result
+		r := strings.NewReader("<en-export><note><title>A</note>")
+		err := Parse("enex", r, r.Size(), "notes.enex", res.handler())
+		assert.Error(t, err)
+	})
+}
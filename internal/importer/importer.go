@@ -0,0 +1,209 @@
+// Package importer reads notes exported from other applications.
+// Parsed notes are passed to the caller notepad by notepad, so the
+// caller can save them while the rest of the file is being read.
+package importer
+
+import (
+	"archive/zip"
+	"io"
+	"io/ioutil"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/tetafro/nott-backend-go/internal/domain"
+)
+
+// maxNoteSize is a maximum size of one note in bytes.
+const maxNoteSize = 10 << 20
+
+// frontMatterSize is a size of the beginning of markdown file that
+// is read to get the front matter without reading the whole file.
+// The beginning is read again with the rest of the file, and both
+// reads are taken from the limit of the import.
+const frontMatterSize = 4 << 10
+
+// Limits of total size of files read from one archive. Archives can
+// be compressed very well, so the size of the uploaded file doesn't
+// limit the work. Notes of a notepad are kept in memory until the
+// whole notepad is read, so they have their own limit. Variables
+// are changed in tests.
+var (
+	maxImportSize  int64 = 1 << 30
+	maxNotepadSize int64 = 100 << 20
+)
+
+// Errors of items that don't fit into the limits.
+var (
+	errImportTooLarge  = errors.New("import is too large")
+	errNotepadTooLarge = errors.New("notepad is too large")
+)
+
+// untitled is a title of notes and notepads that don't have any.
+const untitled = "Untitled"
+
+// Notepad is a notepad with notes read from the file.
+type Notepad struct {
+	// Folders is a path to the notepad, a list of folder titles
+	// starting from the top
+	Folders []string
+	Title   string
+	Notes   []domain.Note
+}
+
+// Attachment is an attached file read from the file.
+type Attachment struct {
+	domain.Attachment
+	// Open opens contents of the file
+	Open func() (io.ReadCloser, error)
+}
+
+// Handler gets results of parsing.
+type Handler struct {
+	// IDs is called before notepads with IDs of notes and attached files
+	// if the file keeps them. It returns new IDs for them, notes and
+	// attachments are passed with the new IDs, and links between them
+	// are changed to the new IDs. Notes and attachments that don't get
+	// new IDs are passed with zero IDs.
+	IDs func(notes, attachments []int) (map[int]int, map[int]int, error)
+	// Folder is called for each folder without notepads, path is a list
+	// of folder titles starting from the top
+	Folder func(path []string) error
+	// Notepad is called for each notepad, parsing stops if it
+	// returns an error
+	Notepad func(Notepad) error
+	// Attachment is called for each attached file after all notepads,
+	// parsing stops if it returns an error
+	Attachment func(Attachment) error
+	// Error is called for items that are skipped
+	Error func(item string, err error)
+}
+
+// Parse reads notes from the file in the format. Name is the name
+// of the file, it's used as a title of the notepad when the file
+// doesn't have one. Errors of single items are passed to the handler,
+// and the error is returned only if the whole file is unreadable.
+func Parse(format string, r io.ReaderAt, size int64, name string, h Handler) error {
+	switch format {
+	case domain.ImportMarkdown:
+		return parseMarkdown(r, size, name, h)
+	case domain.ImportENEX:
+		return parseENEX(io.NewSectionReader(r, 0, size), name, h)
+	case domain.ImportJoplin:
+		return parseJoplin(r, size, name, h)
+	}
+	return errors.Errorf("unknown format %q", format)
+}
+
+// openZip opens zip archive. Service files of operating systems
+// are skipped.
+func openZip(r io.ReaderAt, size int64) ([]*zip.File, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, errors.Wrap(err, "open zip archive")
+	}
+	files := make([]*zip.File, 0, len(zr.File))
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() ||
+			strings.HasPrefix(f.Name, "__MACOSX/") ||
+			path.Base(f.Name) == ".DS_Store" {
+			continue
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+// budget is a number of bytes that can still be read.
+type budget int64
+
+// take takes n bytes from the budget, nothing is taken if there
+// is not enough.
+func (b *budget) take(n int64) bool {
+	if n > int64(*b) {
+		return false
+	}
+	*b -= budget(n)
+	return true
+}
+
+// readZipFile reads file from zip archive, files larger than a note
+// can be are not read. Read bytes are taken from the budget.
+func readZipFile(f *zip.File, b *budget) ([]byte, error) {
+	if f.UncompressedSize64 > maxNoteSize {
+		return nil, errors.New("file is too large")
+	}
+	if f.UncompressedSize64 > uint64(*b) {
+		return nil, errImportTooLarge
+	}
+	data, err := readZipHead(f, maxNoteSize, b)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxNoteSize {
+		return nil, errors.New("file is too large")
+	}
+	return data, nil
+}
+
+// readZipHead reads up to n bytes from the beginning of the file
+// in zip archive, it reads one more byte if the file is larger.
+// Read bytes are taken from the budget.
+func readZipHead(f *zip.File, n int64, b *budget) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, errors.Wrap(err, "open file")
+	}
+	defer rc.Close() // nolint: errcheck
+
+	// Size in the header can be wrong
+	limit := n + 1
+	if int64(*b) < limit {
+		limit = int64(*b) + 1
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(rc, limit))
+	if err != nil {
+		return nil, errors.Wrap(err, "read file")
+	}
+	if !b.take(int64(len(data))) {
+		*b = 0
+		return nil, errImportTooLarge
+	}
+	return data, nil
+}
+
+// baseName gets name of the file without directories and extension.
+func baseName(name string) string {
+	name = path.Base(strings.Replace(name, `\`, "/", -1))
+	return strings.TrimSuffix(name, path.Ext(name))
+}
+
+// title gets the first non-empty title.
+func title(titles ...string) string {
+	for _, t := range titles {
+		if t = strings.TrimSpace(t); t != "" && t != "." && t != "/" {
+			return t
+		}
+	}
+	return untitled
+}
+
+// cleanTags makes tags valid. Spaces, which are allowed in other
+// applications, are replaced with dashes, and tags that are still
+// invalid are reported and dropped.
+func cleanTags(item string, tags []string, h Handler) []string {
+	var clean []string
+	for _, t := range tags {
+		t = strings.Join(strings.Fields(domain.NormalizeTag(t)), "-")
+		if t == "" {
+			continue
+		}
+		if err := domain.ValidateTagName(t); err != nil {
+			h.Error(item, errors.Wrapf(err, "skip tag %q", t))
+			continue
+		}
+		clean = append(clean, t)
+	}
+	return clean
+}
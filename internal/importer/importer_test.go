@@ -0,0 +1,114 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// result collects results of parsing. New IDs are old ones plus 100.
+type result struct {
+	notepads    []Notepad
+	folders     [][]string
+	attachments []Attachment
+	// contents of attached files by their IDs
+	contents map[int]string
+	errors   map[string]string
+}
+
+func (r *result) handler() Handler {
+	r.contents = map[int]string{}
+	r.errors = map[string]string{}
+	return Handler{
+		IDs: func(notes, attachments []int) (map[int]int, map[int]int, error) {
+			n, a := map[int]int{}, map[int]int{}
+			for _, id := range notes {
+				n[id] = id + 100
+			}
+			for _, id := range attachments {
+				a[id] = id + 100
+			}
+			return n, a, nil
+		},
+		Folder: func(path []string) error {
+			r.folders = append(r.folders, path)
+			return nil
+		},
+		Notepad: func(n Notepad) error {
+			r.notepads = append(r.notepads, n)
+			return nil
+		},
+		Attachment: func(a Attachment) error {
+			f, err := a.Open()
+			if err != nil {
+				return err
+			}
+			defer f.Close() // nolint: errcheck
+			data, err := ioutil.ReadAll(f)
+			if err != nil {
+				return err
+			}
+			r.contents[a.ID] = string(data)
+			a.Open = nil
+			r.attachments = append(r.attachments, a)
+			return nil
+		},
+		Error: func(item string, err error) {
+			r.errors[item] = err.Error()
+		},
+	}
+}
+
+// makeZip makes zip archive with the files, they are added in order
+// of the names in the list.
+func makeZip(t *testing.T, files ...string) *bytes.Reader {
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	for i := 0; i < len(files); i += 2 {
+		w, err := zw.Create(files[i])
+		assert.NoError(t, err)
+		_, err = w.Write([]byte(files[i+1]))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, zw.Close())
+	return bytes.NewReader(buf.Bytes())
+}
+
+func TestParse(t *testing.T) {
+	t.Run("unknown format", func(t *testing.T) {
+		var res result
+		err := Parse("docx", bytes.NewReader(nil), 0, "file.docx", res.handler())
+		assert.Error(t, err)
+	})
+
+	t.Run("not a zip archive", func(t *testing.T) {
+		var res result
+		r := bytes.NewReader([]byte("hello"))
+		err := Parse("markdown", r, r.Size(), "file.zip", res.handler())
+		assert.Error(t, err)
+	})
+
+	t.Run("handler error", func(t *testing.T) {
+		r := makeZip(t, "a/1.md", "one", "b/2.md", "two")
+		calls := 0
+		err := Parse("markdown", r, r.Size(), "file.zip", Handler{
+			Notepad: func(n Notepad) error {
+				calls++
+				return errors.New("error")
+			},
+		})
+		assert.Error(t, err)
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestCleanTags(t *testing.T) {
+	var res result
+	tags := cleanTags("note", []string{"#Work", "to do", " ", "a+b"}, res.handler())
+	assert.Equal(t, []string{"work", "to-do"}, tags)
+	assert.Equal(t, map[string]string{"note": `skip tag "a+b": invalid tag "a+b"`}, res.errors)
+}
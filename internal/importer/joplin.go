@@ -0,0 +1,260 @@
+package importer
+
+import (
+	"encoding/json"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/tetafro/nott-backend-go/internal/domain"
+)
+
+// Types of items in Joplin exports.
+const (
+	joplinNote     = 1
+	joplinFolder   = 2
+	joplinResource = 4
+	joplinTag      = 5
+	joplinNoteTag  = 6
+)
+
+// joplinItem is an item of Joplin export: a note, a folder (notebook),
+// a tag, or a link between a note and a tag.
+type joplinItem struct {
+	ID              string     `json:"id"`
+	Type            int        `json:"type_"`
+	ParentID        string     `json:"parent_id"`
+	Title           string     `json:"title"`
+	Body            string     `json:"body"`
+	NoteID          string     `json:"note_id"`
+	TagID           string     `json:"tag_id"`
+	CreatedTime     joplinTime `json:"created_time"`
+	UpdatedTime     joplinTime `json:"updated_time"`
+	UserCreatedTime joplinTime `json:"user_created_time"`
+	UserUpdatedTime joplinTime `json:"user_updated_time"`
+}
+
+// joplinTime is a timestamp in Joplin export: milliseconds since epoch
+// in JSON exports, and RFC 3339 in RAW exports.
+type joplinTime struct {
+	time.Time
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (t *joplinTime) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	if strings.HasPrefix(string(data), `"`) {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		return t.parse(s)
+	}
+	ms, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil {
+		return errors.Errorf("invalid time %s", data)
+	}
+	if ms > 0 {
+		t.Time = time.Unix(ms/1000, ms%1000*int64(time.Millisecond)).UTC()
+	}
+	return nil
+}
+
+func (t *joplinTime) parse(s string) error {
+	if s == "" {
+		return nil
+	}
+	parsed, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return errors.Errorf("invalid time %q", s)
+	}
+	t.Time = parsed.UTC()
+	return nil
+}
+
+// parseJoplin reads zip archive of Joplin export in JSON or RAW format.
+// Both formats have one file per item. Notebooks with notes become
+// notepads, and their parent notebooks become folders. Notes without
+// notebooks go to a notepad named after the archive. Files that don't
+// fit into the limits of the import or of the notepad are skipped.
+func parseJoplin(r io.ReaderAt, size int64, name string, h Handler) error {
+	files, err := openZip(r, size)
+	if err != nil {
+		return err
+	}
+	total := budget(maxImportSize)
+
+	var (
+		folders  = map[string]joplinItem{}
+		notes    = map[string][]joplinItem{}
+		tags     = map[string]string{}
+		noteTags = map[string][]string{}
+		// sizes of notes of notepads by their IDs
+		sizes = map[string]int64{}
+	)
+	for _, f := range files {
+		ext := strings.ToLower(path.Ext(f.Name))
+		if ext != ".json" && ext != ".md" {
+			// Contents of attached files, they are reported
+			// as resource items
+			continue
+		}
+		data, err := readZipFile(f, &total)
+		if err != nil {
+			h.Error(f.Name, err)
+			continue
+		}
+		var item joplinItem
+		if ext == ".json" {
+			err = json.Unmarshal(data, &item)
+		} else {
+			item, err = parseJoplinRaw(string(data))
+		}
+		if err != nil {
+			h.Error(f.Name, errors.Wrap(err, "invalid item"))
+			continue
+		}
+
+		switch item.Type {
+		case joplinNote:
+			if sizes[item.ParentID]+int64(len(item.Body)) > maxNotepadSize {
+				h.Error(f.Name, errNotepadTooLarge)
+				continue
+			}
+			sizes[item.ParentID] += int64(len(item.Body))
+			notes[item.ParentID] = append(notes[item.ParentID], item)
+		case joplinFolder:
+			folders[item.ID] = item
+		case joplinTag:
+			tags[item.ID] = item.Title
+		case joplinNoteTag:
+			noteTags[item.NoteID] = append(noteTags[item.NoteID], item.TagID)
+		case joplinResource:
+			h.Error(title(item.Title, f.Name), errors.New("attached files are not imported"))
+		}
+	}
+
+	notepads := make([]Notepad, 0, len(notes))
+	for parentID, items := range notes {
+		n := Notepad{Title: title(baseName(name))}
+		if folder, ok := folders[parentID]; ok {
+			n.Title = title(folder.Title)
+			n.Folders = joplinPath(folders, folder)
+		}
+		sort.Slice(items, func(i, j int) bool {
+			return items[i].CreatedTime.Before(items[j].CreatedTime.Time)
+		})
+		for _, item := range items {
+			note := domain.Note{
+				Title:     title(item.Title),
+				Text:      item.Body,
+				CreatedAt: firstTime(item.UserCreatedTime, item.CreatedTime),
+			}
+			if updated := firstTime(item.UserUpdatedTime, item.UpdatedTime); !updated.IsZero() {
+				note.UpdatedAt = &updated
+			}
+			for _, id := range noteTags[item.ID] {
+				if t, ok := tags[id]; ok {
+					note.Tags = append(note.Tags, t)
+				}
+			}
+			note.Tags = cleanTags(note.Title, note.Tags, h)
+			n.Notes = append(n.Notes, note)
+		}
+		notepads = append(notepads, n)
+	}
+	key := func(n Notepad) string {
+		return strings.Join(n.Folders, "/") + "/" + n.Title
+	}
+	sort.Slice(notepads, func(i, j int) bool {
+		return key(notepads[i]) < key(notepads[j])
+	})
+
+	for _, n := range notepads {
+		if err = h.Notepad(n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseJoplinRaw parses item in RAW format: the title, the body,
+// and properties in "key: value" lines at the end, separated from
+// the rest by empty lines. Folders and tags don't have a body.
+func parseJoplinRaw(s string) (joplinItem, error) {
+	lines := strings.Split(strings.TrimRight(strings.Replace(s, "\r\n", "\n", -1), "\n"), "\n")
+
+	props := map[string]string{}
+	i := len(lines) - 1
+	for ; i >= 0 && lines[i] != ""; i-- {
+		parts := strings.SplitN(lines[i], ":", 2)
+		if len(parts) != 2 {
+			return joplinItem{}, errors.Errorf("invalid property %q", lines[i])
+		}
+		props[parts[0]] = strings.TrimSpace(parts[1])
+	}
+	if len(props) == 0 {
+		return joplinItem{}, errors.New("no properties")
+	}
+
+	var item joplinItem
+	if i > 0 {
+		item.Title = lines[0]
+		if i > 2 {
+			item.Body = strings.Join(lines[2:i], "\n")
+		}
+	}
+
+	var err error
+	if item.Type, err = strconv.Atoi(props["type_"]); err != nil {
+		return joplinItem{}, errors.Errorf("invalid type %q", props["type_"])
+	}
+	item.ID = props["id"]
+	item.ParentID = props["parent_id"]
+	item.NoteID = props["note_id"]
+	item.TagID = props["tag_id"]
+	for key, t := range map[string]*joplinTime{
+		"created_time":      &item.CreatedTime,
+		"updated_time":      &item.UpdatedTime,
+		"user_created_time": &item.UserCreatedTime,
+		"user_updated_time": &item.UserUpdatedTime,
+	} {
+		if err = t.parse(props[key]); err != nil {
+			return joplinItem{}, err
+		}
+	}
+	return item, nil
+}
+
+// joplinPath gets titles of parent folders of the folder, starting
+// from the top.
+func joplinPath(folders map[string]joplinItem, f joplinItem) []string {
+	var titles []string
+	seen := map[string]bool{f.ID: true}
+	for {
+		parent, ok := folders[f.ParentID]
+		if !ok || seen[parent.ID] {
+			break
+		}
+		seen[parent.ID] = true
+		titles = append([]string{title(parent.Title)}, titles...)
+		f = parent
+	}
+	return titles
+}
+
+func firstTime(times ...joplinTime) time.Time {
+	for _, t := range times {
+		if !t.IsZero() {
+			return t.Time
+		}
+	}
+	return time.Time{}
+}
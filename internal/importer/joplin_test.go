@@ -0,0 +1,117 @@
+package importer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tetafro/nott-backend-go/internal/domain"
+)
+
+func TestParseJoplin(t *testing.T) {
+	created := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	updated := time.Date(2020, 2, 3, 4, 5, 6, 0, time.UTC)
+
+	t.Run("json export", func(t *testing.T) {
+		r := makeZip(t,
+			"f1.json", `{"id": "f1", "type_": 2, "title": "Work", "parent_id": ""}`,
+			"f2.json", `{"id": "f2", "type_": 2, "title": "Plans", "parent_id": "f1"}`,
+			"n1.json", `{"id": "n1", "type_": 1, "title": "Second", "body": "Two",
+				"parent_id": "f2", "created_time": 1577934245001}`,
+			"n2.json", `{"id": "n2", "type_": 1, "title": "First", "body": "One",
+				"parent_id": "f2", "created_time": 1577934245000,
+				"user_created_time": 1577934245000, "user_updated_time": 1580702706000}`,
+			"n3.json", `{"id": "n3", "type_": 1, "title": "", "body": "Lost", "parent_id": "f3"}`,
+			"t1.json", `{"id": "t1", "type_": 5, "title": "Big Plans"}`,
+			"nt1.json", `{"id": "nt1", "type_": 6, "note_id": "n2", "tag_id": "t1"}`,
+			"r1.json", `{"id": "r1", "type_": 4, "title": "image.png"}`,
+			"resources/r1.png", "png",
+			"bad.json", `{"id": 1}`,
+		)
+		var res result
+		err := Parse("joplin", r, r.Size(), "joplin.zip", res.handler())
+		assert.NoError(t, err)
+		assert.Equal(t, []Notepad{
+			{
+				Title: "joplin",
+				Notes: []domain.Note{{Title: "Untitled", Text: "Lost"}},
+			},
+			{
+				Folders: []string{"Work"},
+				Title:   "Plans",
+				Notes: []domain.Note{
+					{
+						Title:     "First",
+						Text:      "One",
+						Tags:      []string{"big-plans"},
+						CreatedAt: created,
+						UpdatedAt: &updated,
+					},
+					{
+						Title:     "Second",
+						Text:      "Two",
+						CreatedAt: created.Add(time.Millisecond),
+					},
+				},
+			},
+		}, res.notepads)
+		assert.Equal(t, map[string]string{
+			"image.png": "attached files are not imported",
+			"bad.json": "invalid item: json: cannot unmarshal number " +
+				"into Go struct field joplinItem.id of type string",
+		}, res.errors)
+	})
+
+	t.Run("raw export", func(t *testing.T) {
+		r := makeZip(t,
+			"f1.md", "Work\n\nid: f1\nparent_id: \ncreated_time: 2020-01-02T03:04:05.000Z\ntype_: 2",
+			"n1.md", "Note\n\n# Hello\n\nWorld\n\n"+
+				"id: n1\nparent_id: f1\n"+
+				"created_time: 2020-01-02T03:04:05.000Z\n"+
+				"updated_time: 2020-02-03T04:05:06.000Z\n"+
+				"type_: 1\n",
+			"n2.md", "Empty\n\nid: n2\nparent_id: f1\ntype_: 1",
+			"bad.md", "Hello\n\nWorld",
+		)
+		var res result
+		err := Parse("joplin", r, r.Size(), "joplin.zip", res.handler())
+		assert.NoError(t, err)
+		assert.Equal(t, []Notepad{{
+			Title: "Work",
+			Notes: []domain.Note{
+				{Title: "Empty"},
+				{
+					Title:     "Note",
+					Text:      "# Hello\n\nWorld",
+					CreatedAt: created,
+					UpdatedAt: &updated,
+				},
+			},
+		}}, res.notepads)
+		assert.Equal(t, map[string]string{
+			"bad.md": `invalid item: invalid property "World"`,
+		}, res.errors)
+	})
+
+	t.Run("too large notepad", func(t *testing.T) {
+		defer func(n int64) { maxNotepadSize = n }(maxNotepadSize)
+		maxNotepadSize = 5
+
+		r := makeZip(t,
+			"f1.json", `{"id": "f1", "type_": 2, "title": "Work", "parent_id": ""}`,
+			"n1.json", `{"id": "n1", "type_": 1, "title": "A", "body": "123", "parent_id": "f1"}`,
+			"n2.json", `{"id": "n2", "type_": 1, "title": "B", "body": "456", "parent_id": "f1"}`,
+		)
+		var res result
+		err := Parse("joplin", r, r.Size(), "joplin.zip", res.handler())
+		assert.NoError(t, err)
+		assert.Equal(t, []Notepad{{
+			Title: "Work",
+			Notes: []domain.Note{{Title: "A", Text: "123"}},
+		}}, res.notepads)
+		assert.Equal(t, map[string]string{
+			"n2.json": "notepad is too large",
+		}, res.errors)
+	})
+}
@@ -0,0 +1,328 @@
+package importer
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/tetafro/nott-backend-go/internal/archive"
+	"github.com/tetafro/nott-backend-go/internal/domain"
+	"github.com/tetafro/nott-backend-go/internal/markdown"
+)
+
+// parseMarkdown reads zip archive of markdown files. Each directory
+// with files becomes a notepad, and its parent directories become
+// folders. Files in the root go to a notepad named after the archive.
+// If the archive was made by export, original titles of folders
+// and notepads are taken from the manifest, empty folders and notepads
+// are restored, and attached files are imported. Files that don't fit
+// into the limits of the import or of the notepad are skipped.
+func parseMarkdown(r io.ReaderAt, size int64, name string, h Handler) error {
+	files, err := openZip(r, size)
+	if err != nil {
+		return err
+	}
+	total := budget(maxImportSize)
+
+	var m archive.Manifest
+	dirs := map[string][]*zip.File{}
+	attached := map[string]*zip.File{}
+	for _, f := range files {
+		switch {
+		case f.Name == archive.ManifestName:
+			if m, err = readManifest(f, &total); err != nil {
+				h.Error(f.Name, err)
+			}
+		case strings.HasPrefix(f.Name, archive.AttachmentsDir+"/"):
+			attached[f.Name] = f
+		case !isMarkdown(f.Name):
+			h.Error(f.Name, errors.New("not a markdown file"))
+		default:
+			dir := path.Dir(f.Name)
+			dirs[dir] = append(dirs[dir], f)
+		}
+	}
+
+	// Titles of directories by their paths
+	titles := map[string]string{}
+	for _, f := range m.Folders {
+		titles[f.Path] = f.Title
+	}
+	// Notepads of the manifest are imported even if they are empty
+	empty := map[string]bool{}
+	for _, n := range m.Notepads {
+		titles[n.Path] = n.Title
+		if _, ok := dirs[n.Path]; !ok {
+			dirs[n.Path] = nil
+		}
+		empty[n.Path] = true
+	}
+
+	paths := make([]string, 0, len(dirs))
+	for dir := range dirs {
+		paths = append(paths, dir)
+		sort.Slice(dirs[dir], func(i, j int) bool {
+			return dirs[dir][i].Name < dirs[dir][j].Name
+		})
+	}
+	sort.Strings(paths)
+
+	ids, err := remapIDs(paths, dirs, m.Attachments, &total, h)
+	if err != nil {
+		return err
+	}
+
+	if err = importFolders(m.Folders, paths, titles, h); err != nil {
+		return err
+	}
+
+	// Notes with the same ID in the archive can't keep it both
+	imported := map[int]bool{}
+	for _, dir := range paths {
+		n := Notepad{Title: title(baseName(name))}
+		if dir != "." {
+			n.Folders = folderPath(dir, titles)
+			n.Title = n.Folders[len(n.Folders)-1]
+			n.Folders = n.Folders[:len(n.Folders)-1]
+		}
+
+		left := budget(maxNotepadSize)
+		for _, f := range dirs[dir] {
+			note, err := readMarkdownNote(f, &total, h)
+			if err != nil {
+				h.Error(f.Name, err)
+				continue
+			}
+			if !left.take(int64(len(note.Text))) {
+				h.Error(f.Name, errNotepadTooLarge)
+				continue
+			}
+			if imported[note.ID] {
+				note.ID = 0
+			}
+			imported[note.ID] = true
+			note.ID = ids.notes[note.ID]
+			note.Text = ids.rewrite(note.Text)
+			n.Notes = append(n.Notes, note)
+		}
+		if len(n.Notes) == 0 && !empty[dir] {
+			continue
+		}
+		if err = h.Notepad(n); err != nil {
+			return err
+		}
+	}
+
+	return importAttachments(m.Attachments, attached, ids, &total, h)
+}
+
+// readManifest reads the manifest of exported archive.
+func readManifest(f *zip.File, b *budget) (archive.Manifest, error) {
+	data, err := readZipFile(f, b)
+	if err != nil {
+		return archive.Manifest{}, err
+	}
+	var m archive.Manifest
+	if err = json.Unmarshal(data, &m); err != nil {
+		return archive.Manifest{}, errors.Wrap(err, "invalid manifest")
+	}
+	return m, nil
+}
+
+// importFolders passes folders of the manifest that don't have
+// notepads inside, other folders are created with their notepads.
+func importFolders(folders []archive.Folder, paths []string, titles map[string]string, h Handler) error {
+	for _, f := range folders {
+		used := false
+		for _, p := range paths {
+			if strings.HasPrefix(p, f.Path+"/") {
+				used = true
+				break
+			}
+		}
+		if used {
+			continue
+		}
+		if err := h.Folder(folderPath(f.Path, titles)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// folderPath gets titles of directories in the path.
+func folderPath(dir string, titles map[string]string) []string {
+	parts := strings.Split(dir, "/")
+	folders := make([]string, len(parts))
+	for i := range parts {
+		p := strings.Join(parts[:i+1], "/")
+		folders[i] = title(titles[p], parts[i])
+	}
+	return folders
+}
+
+// importAttachments passes files of the manifest attachments. Files
+// that are not in the manifest are skipped, since it's not known what
+// notes they are attached to. Sizes of the files are taken from
+// the budget, the handler must not read more than the size.
+func importAttachments(
+	attachments []archive.Attachment,
+	files map[string]*zip.File,
+	ids idMap,
+	b *budget,
+	h Handler,
+) error {
+	listed := map[string]bool{}
+	for _, a := range attachments {
+		listed[a.Path] = true
+		f, ok := files[a.Path]
+		if !ok {
+			h.Error(a.Path, errors.New("file not found"))
+			continue
+		}
+		noteID, ok := ids.notes[a.NoteID]
+		if !ok {
+			h.Error(a.Path, errors.New("note of the file not found"))
+			continue
+		}
+		if f.UncompressedSize64 > uint64(*b) || !b.take(int64(f.UncompressedSize64)) {
+			h.Error(a.Path, errImportTooLarge)
+			continue
+		}
+		err := h.Attachment(Attachment{
+			Attachment: domain.Attachment{
+				ID:          ids.attachments[a.ID],
+				NoteID:      noteID,
+				Name:        title(a.Name, path.Base(a.Path)),
+				ContentType: a.ContentType,
+				Size:        int64(f.UncompressedSize64),
+				CreatedAt:   a.CreatedAt,
+			},
+			Open: f.Open,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		if !listed[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		h.Error(name, errors.New("file is not in the manifest"))
+	}
+	return nil
+}
+
+// idMap maps IDs of notes and attachments in the archive to new ones.
+type idMap struct {
+	notes       map[int]int
+	attachments map[int]int
+}
+
+// remapIDs gets new IDs for notes and attachments that have IDs
+// in the archive. Only the beginning of notes with the front matter
+// is read.
+func remapIDs(
+	paths []string,
+	dirs map[string][]*zip.File,
+	attachments []archive.Attachment,
+	b *budget,
+	h Handler,
+) (idMap, error) {
+	var notes []int
+	seen := map[int]bool{}
+	for _, dir := range paths {
+		for _, f := range dirs[dir] {
+			// Invalid notes are reported when they are imported
+			data, err := readZipHead(f, frontMatterSize, b)
+			if err != nil {
+				continue
+			}
+			if n, err := archive.ParseNote(data); err == nil && n.ID != 0 && !seen[n.ID] {
+				seen[n.ID] = true
+				notes = append(notes, n.ID)
+			}
+		}
+	}
+	var files []int
+	for _, a := range attachments {
+		files = append(files, a.ID)
+	}
+	if len(notes) == 0 && len(files) == 0 {
+		return idMap{}, nil
+	}
+
+	n, a, err := h.IDs(notes, files)
+	if err != nil {
+		return idMap{}, errors.Wrap(err, "get new ids")
+	}
+	return idMap{notes: n, attachments: a}, nil
+}
+
+// noteRef matches wiki links to notes by ID.
+var noteRef = regexp.MustCompile(`(\[\[\s*` + markdown.NoteScheme + `)(\d+)(\s*\]\])`)
+
+// attachmentRef matches links to attachments in link destinations
+// and link reference definitions.
+var attachmentRef = regexp.MustCompile(`((?:\]\(|\]:[ \t]*)<?` + markdown.AttachmentScheme + `)(\d+)`)
+
+// rewrite changes IDs in links to notes and attachments of the archive
+// to the new ones. Links to other objects are left as is.
+func (m idMap) rewrite(text string) string {
+	text = replaceIDs(text, noteRef, m.notes)
+	text = replaceIDs(text, attachmentRef, m.attachments)
+	return text
+}
+
+// replaceIDs replaces IDs in the second group of the regexp matches.
+func replaceIDs(text string, re *regexp.Regexp, ids map[int]int) string {
+	if len(ids) == 0 {
+		return text
+	}
+	return re.ReplaceAllStringFunc(text, func(s string) string {
+		m := re.FindStringSubmatch(s)
+		id, err := strconv.Atoi(m[2])
+		if err != nil {
+			return s
+		}
+		newID, ok := ids[id]
+		if !ok {
+			return s
+		}
+		return m[1] + strconv.Itoa(newID) + strings.Join(m[3:], "")
+	})
+}
+
+func readMarkdownNote(f *zip.File, b *budget, h Handler) (domain.Note, error) {
+	data, err := readZipFile(f, b)
+	if err != nil {
+		return domain.Note{}, err
+	}
+	n, err := archive.ParseNote(data)
+	if err != nil {
+		return domain.Note{}, err
+	}
+	n.Title = title(n.Title, baseName(f.Name))
+	n.Tags = cleanTags(f.Name, n.Tags, h)
+	return n, nil
+}
+
+func isMarkdown(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".md", ".markdown", ".txt":
+		return true
+	}
+	return false
+}
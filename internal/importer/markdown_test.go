@@ -0,0 +1,176 @@
+package importer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tetafro/nott-backend-go/internal/domain"
+)
+
+func TestParseMarkdown(t *testing.T) {
+	t.Run("plain files", func(t *testing.T) {
+		r := makeZip(t,
+			"Work/Projects/b.md", "Second",
+			"Work/Projects/a.md", "---\ntitle: First\ntags: [plans, to do]\n---\n\nFirst",
+			"Work/Projects/image.png", "png",
+			"readme.md", "Hello",
+			"__MACOSX/._readme.md", "junk",
+		)
+		var res result
+		err := Parse("markdown", r, r.Size(), "Notes.zip", res.handler())
+		assert.NoError(t, err)
+		assert.Equal(t, []Notepad{
+			{
+				Title: "Notes",
+				Notes: []domain.Note{{Title: "readme", Text: "Hello"}},
+			},
+			{
+				Folders: []string{"Work"},
+				Title:   "Projects",
+				Notes: []domain.Note{
+					{Title: "First", Text: "First", Tags: []string{"plans", "to-do"}},
+					{Title: "b", Text: "Second"},
+				},
+			},
+		}, res.notepads)
+		assert.Equal(t, map[string]string{
+			"Work/Projects/image.png": "not a markdown file",
+		}, res.errors)
+	})
+
+	t.Run("exported archive", func(t *testing.T) {
+		manifest := `{
+			"version": 1,
+			"folders": [
+				{"id": 1, "title": "Work: 2020", "path": "Work_ 2020"},
+				{"id": 5, "parent_id": 1, "title": "Old", "path": "Work_ 2020/Old"}
+			],
+			"notepads": [
+				{"id": 2, "folder_id": 1, "title": "Plans?", "path": "Work_ 2020/Plans_"},
+				{"id": 6, "folder_id": 1, "title": "Empty", "path": "Work_ 2020/Empty"}
+			],
+			"attachments": [{
+				"id": 3,
+				"note_id": 4,
+				"name": "image.png",
+				"content_type": "image/png",
+				"path": "attachments/3-image.png",
+				"created_at": "2020-01-02T03:04:05Z"
+			}]
+		}`
+		note := "---\n" +
+			"id: 4\n" +
+			"title: \"Note: 1\"\n" +
+			"created_at: 2020-01-02T03:04:05Z\n" +
+			"tags: []\n" +
+			"---\n\n" +
+			"Hello"
+		r := makeZip(t,
+			"manifest.json", manifest,
+			"Work_ 2020/Plans_/Note_ 1.md", note,
+			"attachments/3-image.png", "png",
+			"attachments/7-unknown.png", "png",
+		)
+		var res result
+		err := Parse("markdown", r, r.Size(), "export.zip", res.handler())
+		assert.NoError(t, err)
+		assert.Equal(t, [][]string{{"Work: 2020", "Old"}}, res.folders)
+		assert.Equal(t, []Notepad{
+			{
+				Folders: []string{"Work: 2020"},
+				Title:   "Empty",
+			},
+			{
+				Folders: []string{"Work: 2020"},
+				Title:   "Plans?",
+				Notes: []domain.Note{{
+					ID:        104,
+					Title:     "Note: 1",
+					Text:      "Hello",
+					CreatedAt: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+				}},
+			},
+		}, res.notepads)
+		assert.Equal(t, []Attachment{{Attachment: domain.Attachment{
+			ID:          103,
+			NoteID:      104,
+			Name:        "image.png",
+			ContentType: "image/png",
+			Size:        3,
+			CreatedAt:   time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		}}}, res.attachments)
+		assert.Equal(t, map[int]string{103: "png"}, res.contents)
+		assert.Equal(t, map[string]string{
+			"attachments/7-unknown.png": "file is not in the manifest",
+		}, res.errors)
+	})
+
+	t.Run("links to notes and attachments", func(t *testing.T) {
+		manifest := `{
+			"version": 1,
+			"attachments": [{"id": 3, "note_id": 4, "path": "attachments/3-a.png"}]
+		}`
+		r := makeZip(t,
+			"manifest.json", manifest,
+			"a.md", "---\nid: 4\n---\n\nSee [[note:5]] and ![a](attachment:3).",
+			"b.md", "---\nid: 5\n---\n\nSee [[note:4]], [[note:9]] and [a][1].\n\n[1]: attachment:3",
+			"c.md", "---\nid: 5\n---\n\nText about note:4 and attachment:3",
+			"attachments/3-a.png", "png",
+		)
+		var res result
+		err := Parse("markdown", r, r.Size(), "notes.zip", res.handler())
+		assert.NoError(t, err)
+		assert.Equal(t, []Notepad{{
+			Title: "notes",
+			Notes: []domain.Note{
+				{ID: 104, Title: "a", Text: "See [[note:105]] and ![a](attachment:103)."},
+				{ID: 105, Title: "b", Text: "See [[note:104]], [[note:9]] and [a][1].\n\n[1]: attachment:103"},
+				{Title: "c", Text: "Text about note:4 and attachment:3"},
+			},
+		}}, res.notepads)
+		assert.Len(t, res.attachments, 1)
+		assert.Empty(t, res.errors)
+	})
+
+	t.Run("invalid note", func(t *testing.T) {
+		r := makeZip(t,
+			"a.md", "---\ncreated_at: yesterday\n---\nHello",
+			"b.md", "Hello",
+		)
+		var res result
+		err := Parse("markdown", r, r.Size(), "notes.zip", res.handler())
+		assert.NoError(t, err)
+		assert.Len(t, res.notepads, 1)
+		assert.Len(t, res.notepads[0].Notes, 1)
+		assert.Contains(t, res.errors, "a.md")
+	})
+
+	t.Run("too large files", func(t *testing.T) {
+		defer func(i, n int64) {
+			maxImportSize, maxNotepadSize = i, n
+		}(maxImportSize, maxNotepadSize)
+		// Beginnings of the notes are read twice: 21 bytes for IDs,
+		// then 5 and 6 bytes for notepad a and 10 bytes for notepad b
+		maxImportSize, maxNotepadSize = 40, 10
+
+		r := makeZip(t,
+			"a/1.md", "12345",
+			"a/2.md", "123456",
+			"b/3.md", "1234567890",
+		)
+		var res result
+		err := Parse("markdown", r, r.Size(), "notes.zip", res.handler())
+		assert.NoError(t, err)
+		assert.Equal(t, []Notepad{{
+			Folders: []string{},
+			Title:   "a",
+			Notes:   []domain.Note{{Title: "1", Text: "12345"}},
+		}}, res.notepads)
+		assert.Equal(t, map[string]string{
+			"a/2.md": "notepad is too large",
+			"b/3.md": "import is too large",
+		}, res.errors)
+	})
+}
@@ -0,0 +1,47 @@
+package mail
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// FileMailer writes messages to files in a directory instead of sending
+// them. Files are in .eml format, that can be opened by mail clients.
+type FileMailer struct {
+	// seq goes first to be aligned for atomic operations
+	seq  uint64
+	dir  string
+	from string
+	now  func() time.Time
+}
+
+// NewFileMailer creates new file mailer. The directory is created
+// if it doesn't exist.
+func NewFileMailer(dir, from string) (*FileMailer, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, errors.Wrap(err, "create directory")
+	}
+	return &FileMailer{dir: dir, from: from, now: time.Now}, nil
+}
+
+// Send writes message to a new file.
+func (m *FileMailer) Send(msg Message) error {
+	now := m.now()
+	data, err := format(m.from, msg, now)
+	if err != nil {
+		return errors.Wrap(err, "format message")
+	}
+	// Sequence number keeps names unique within the same nanosecond
+	seq := atomic.AddUint64(&m.seq, 1)
+	name := strconv.FormatInt(now.UnixNano(), 10) + "-" + strconv.FormatUint(seq, 10) + ".eml"
+	if err = ioutil.WriteFile(filepath.Join(m.dir, name), data, 0600); err != nil {
+		return errors.Wrap(err, "write file")
+	}
+	return nil
+}
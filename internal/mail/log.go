@@ -0,0 +1,28 @@
+package mail
+
+import (
+	"github.com/sirupsen/logrus"
+)
+
+// LogMailer writes messages to log instead of sending them. It's used
+// for development.
+type LogMailer struct {
+	log logrus.FieldLogger
+}
+
+// NewLogMailer creates new log mailer.
+func NewLogMailer(log logrus.FieldLogger) *LogMailer {
+	return &LogMailer{log: log}
+}
+
+// Send writes message to log.
+func (m *LogMailer) Send(msg Message) error {
+	if err := msg.validate(); err != nil {
+		return err
+	}
+	m.log.WithFields(logrus.Fields{
+		"to":      msg.To,
+		"subject": msg.Subject,
+	}).Infof("Mail message:\n%s", msg.Body)
+	return nil
+}
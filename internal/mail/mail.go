@@ -0,0 +1,62 @@
+// Package mail sends email messages to users.
+package mail
+
+import (
+	"bytes"
+	"mime"
+	"mime/quotedprintable"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Mailer sends email messages.
+type Mailer interface {
+	Send(Message) error
+}
+
+// Message is a plain text email message.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// validate checks that the message can't inject headers.
+func (m Message) validate() error {
+	if m.To == "" {
+		return errors.New("recipient cannot be empty")
+	}
+	if strings.ContainsAny(m.To+m.Subject, "\r\n") {
+		return errors.New("header cannot contain line breaks")
+	}
+	return nil
+}
+
+// format makes message in RFC 5322 format.
+func format(from string, m Message, now time.Time) ([]byte, error) {
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteString("From: " + from + "\r\n")
+	buf.WriteString("To: " + m.To + "\r\n")
+	buf.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", m.Subject) + "\r\n")
+	buf.WriteString("Date: " + now.Format(time.RFC1123Z) + "\r\n")
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n")
+	buf.WriteString("\r\n")
+
+	w := quotedprintable.NewWriter(buf)
+	body := strings.Replace(m.Body, "\r\n", "\n", -1)
+	if _, err := w.Write([]byte(strings.Replace(body, "\n", "\r\n", -1))); err != nil {
+		return nil, errors.Wrap(err, "encode body")
+	}
+	if err := w.Close(); err != nil {
+		return nil, errors.Wrap(err, "encode body")
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,163 @@
+package mail
+
+import (
+	"bufio"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat(t *testing.T) {
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run("Format message", func(t *testing.T) {
+		data, err := format("nott@example.com", Message{
+			To:      "bob@example.com",
+			Subject: "Password reset",
+			Body:    "Hello,\nuse the link.",
+		}, now)
+		assert.NoError(t, err)
+		assert.Equal(t, "From: nott@example.com\r\n"+
+			"To: bob@example.com\r\n"+
+			"Subject: Password reset\r\n"+
+			"Date: Thu, 02 Jan 2020 03:04:05 +0000\r\n"+
+			"MIME-Version: 1.0\r\n"+
+			"Content-Type: text/plain; charset=utf-8\r\n"+
+			"Content-Transfer-Encoding: quoted-printable\r\n"+
+			"\r\n"+
+			"Hello,\r\nuse the link.", string(data))
+	})
+
+	t.Run("Fail to format message with injected headers", func(t *testing.T) {
+		for _, m := range []Message{
+			{Subject: "Hello"},
+			{To: "bob@example.com\r\nBcc: eve@example.com", Subject: "Hello"},
+			{To: "bob@example.com", Subject: "Hello\nBcc: eve@example.com"},
+		} {
+			_, err := format("nott@example.com", m, now)
+			assert.Error(t, err)
+		}
+	})
+}
+
+func TestLogMailer(t *testing.T) {
+	log, hook := test.NewNullLogger()
+
+	m := NewLogMailer(log)
+	err := m.Send(Message{To: "bob@example.com", Subject: "Hello", Body: "Hello, Bob"})
+	assert.NoError(t, err)
+
+	entry := hook.LastEntry()
+	assert.Equal(t, logrus.InfoLevel, entry.Level)
+	assert.Equal(t, "bob@example.com", entry.Data["to"])
+	assert.Contains(t, entry.Message, "Hello, Bob")
+}
+
+func TestFileMailer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mail")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir) // nolint: errcheck
+
+	m, err := NewFileMailer(filepath.Join(dir, "mail"), "nott@example.com")
+	assert.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		err = m.Send(Message{To: "bob@example.com", Subject: "Hello", Body: "Hello, Bob"})
+		assert.NoError(t, err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "mail", "*.eml"))
+	assert.NoError(t, err)
+	assert.Len(t, files, 2)
+
+	data, err := ioutil.ReadFile(files[0])
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "To: bob@example.com\r\n")
+	assert.True(t, strings.HasSuffix(string(data), "\r\n\r\nHello, Bob"))
+}
+
+func TestSMTPMailer(t *testing.T) {
+	t.Run("Send message", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		assert.NoError(t, err)
+		defer ln.Close() // nolint: errcheck
+
+		received := make(chan []string, 1)
+		go serveSMTP(ln, received)
+
+		host, port, err := net.SplitHostPort(ln.Addr().String())
+		assert.NoError(t, err)
+		p, err := net.LookupPort("tcp", port)
+		assert.NoError(t, err)
+
+		m, err := NewSMTPMailer(SMTPConfig{Host: host, Port: p, From: "nott@example.com"})
+		assert.NoError(t, err)
+
+		err = m.Send(Message{To: "bob@example.com", Subject: "Hello", Body: "Hello, Bob"})
+		assert.NoError(t, err)
+
+		lines := <-received
+		assert.Contains(t, lines, "MAIL FROM:<nott@example.com> BODY=8BITMIME")
+		assert.Contains(t, lines, "RCPT TO:<bob@example.com>")
+		assert.Contains(t, lines, "Subject: Hello")
+		assert.Contains(t, lines, "Hello, Bob")
+	})
+
+	t.Run("Fail to create mailer", func(t *testing.T) {
+		_, err := NewSMTPMailer(SMTPConfig{Port: 25, From: "nott@example.com"})
+		assert.Error(t, err)
+		_, err = NewSMTPMailer(SMTPConfig{Host: "localhost", Port: 25})
+		assert.Error(t, err)
+	})
+}
+
+// serveSMTP accepts one connection and plays SMTP server without
+// extensions, received lines are sent to the channel.
+func serveSMTP(ln net.Listener, received chan<- []string) {
+	conn, err := ln.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close() // nolint: errcheck
+
+	var lines []string
+	r := bufio.NewReader(conn)
+	write := func(s string) { conn.Write([]byte(s + "\r\n")) } // nolint: errcheck,gosec
+	write("220 localhost ESMTP")
+	data := false
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			break
+		}
+		line = strings.TrimRight(line, "\r\n")
+		lines = append(lines, line)
+		switch {
+		case data && line == ".":
+			data = false
+			write("250 OK")
+		case data:
+		case strings.HasPrefix(line, "EHLO"):
+			write("250-localhost")
+			write("250 8BITMIME")
+		case line == "DATA":
+			data = true
+			write("354 Go ahead")
+		case line == "QUIT":
+			write("221 Bye")
+			received <- lines
+			return
+		default:
+			write("250 OK")
+		}
+	}
+	received <- lines
+}
@@ -0,0 +1,61 @@
+package mail
+
+import (
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// SMTPConfig is a configuration of SMTP server.
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	// From is an address of the sender
+	From string
+}
+
+// SMTPMailer sends messages through SMTP server. Connection is
+// upgraded with STARTTLS if the server supports it.
+type SMTPMailer struct {
+	addr string
+	from string
+	auth smtp.Auth
+	now  func() time.Time
+}
+
+// NewSMTPMailer creates new SMTP mailer. Authentication is used
+// if username is set.
+func NewSMTPMailer(cfg SMTPConfig) (*SMTPMailer, error) {
+	if cfg.Host == "" {
+		return nil, errors.New("host cannot be empty")
+	}
+	if cfg.From == "" {
+		return nil, errors.New("sender cannot be empty")
+	}
+	m := &SMTPMailer{
+		addr: net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+		from: cfg.From,
+		now:  time.Now,
+	}
+	if cfg.Username != "" {
+		m.auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+	return m, nil
+}
+
+// Send sends message.
+func (m *SMTPMailer) Send(msg Message) error {
+	data, err := format(m.from, msg, m.now())
+	if err != nil {
+		return errors.Wrap(err, "format message")
+	}
+	if err = smtp.SendMail(m.addr, m.auth, m.from, []string{msg.To}, data); err != nil {
+		return errors.Wrap(err, "send message")
+	}
+	return nil
+}
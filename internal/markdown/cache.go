@@ -0,0 +1,179 @@
+package markdown
+
+import (
+	lru "container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// cacheVersion is a part of cache keys, it must be changed when
+// rendering changes, so old results are not used.
+const cacheVersion = 3
+
+// Cache keeps rendered HTML by keys made with CacheKey.
+type Cache interface {
+	// Get gets HTML by key, returns false if there is no such key
+	Get(key string) (html string, ok bool, err error)
+	// Set saves HTML, the cache may evict other keys to stay
+	// within its size
+	Set(key, html string) error
+}
+
+// CacheStats are counters of cache usage.
+type CacheStats struct {
+	Hits   int64 `json:"hits"`
+	Misses int64 `json:"misses"`
+	Errors int64 `json:"errors"`
+}
+
+// CachedRenderer renders markdown to HTML using cache. The cache
+// must not be shared between renderers with different configs.
+type CachedRenderer struct {
+	// Counters go first to be aligned for atomic operations
+	hits     int64
+	misses   int64
+	errors   int64
+	renderer Renderer
+	cache    Cache
+}
+
+// NewCachedRenderer creates new renderer. Cache can be nil, then
+// everything is rendered on every call.
+func NewCachedRenderer(r Renderer, cache Cache) *CachedRenderer {
+	return &CachedRenderer{renderer: r, cache: cache}
+}
+
+// Render renders markdown to HTML. Links to attachments are resolved
+// after getting HTML from the cache, since download URLs are different
+// for every user and expire. Errors of the cache are counted, and
+// the markdown is rendered in this case.
+func (r *CachedRenderer) Render(markdown string, opts Options) string {
+	if r.cache == nil {
+		return r.renderer.Render(markdown, opts)
+	}
+
+	attachmentURL := opts.AttachmentURL
+	opts.AttachmentURL = nil
+	key := CacheKey(markdown, opts)
+	html, ok, err := r.cache.Get(key)
+	if err != nil {
+		atomic.AddInt64(&r.errors, 1)
+	}
+	if ok {
+		atomic.AddInt64(&r.hits, 1)
+		return resolveAttachments(html, attachmentURL)
+	}
+
+	atomic.AddInt64(&r.misses, 1)
+	html = r.renderer.Render(markdown, opts)
+	if err = r.cache.Set(key, html); err != nil {
+		atomic.AddInt64(&r.errors, 1)
+	}
+	return resolveAttachments(html, attachmentURL)
+}
+
+// Stats gets counters of cache usage.
+func (r *CachedRenderer) Stats() CacheStats {
+	return CacheStats{
+		Hits:   atomic.LoadInt64(&r.hits),
+		Misses: atomic.LoadInt64(&r.misses),
+		Errors: atomic.LoadInt64(&r.errors),
+	}
+}
+
+// CacheKey makes cache key of rendered markdown. It's a hash of the
+// text and the options that affect the result: mode of handling HTML,
+// theme, table of contents, the policy, and the key of wiki link
+// targets. The text is not parsed, so getting the key is cheap.
+// Links to attachments are not resolved, see CachedRenderer.Render.
+func CacheKey(markdown string, opts Options) string {
+	h := sha256.New()
+	write := func(s string) {
+		// Lengths separate strings, so they can't be mixed up
+		io.WriteString(h, strconv.Itoa(len(s))+":"+s) // nolint: errcheck
+	}
+
+	write(strconv.Itoa(cacheVersion))
+	write(markdown)
+	write(string(opts.RawHTML))
+	write(opts.Theme)
+	write(strconv.FormatBool(opts.TableOfContents))
+	if opts.Policy != nil {
+		// Maps are printed sorted by keys
+		write(fmt.Sprintf("%v", *opts.Policy))
+	}
+	write(strconv.FormatBool(opts.NoteURL != nil))
+	write(opts.LinksKey)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// MemoryCache is an in-memory cache, that keeps limited number
+// of entries and evicts least recently used ones.
+type MemoryCache struct {
+	mu    sync.Mutex
+	size  int
+	items map[string]*lru.Element
+	// Most recently used items are in front
+	order *lru.List
+}
+
+type memoryCacheItem struct {
+	key  string
+	html string
+}
+
+// NewMemoryCache creates new in-memory cache that keeps up to size
+// entries.
+func NewMemoryCache(size int) *MemoryCache {
+	return &MemoryCache{
+		size:  size,
+		items: map[string]*lru.Element{},
+		order: lru.New(),
+	}
+}
+
+// Get gets HTML by key.
+func (c *MemoryCache) Get(key string) (string, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.items[key]
+	if !ok {
+		return "", false, nil
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*memoryCacheItem).html, true, nil
+}
+
+// Set saves HTML, and evicts least recently used entries if the cache
+// is full.
+func (c *MemoryCache) Set(key, html string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.items[key]; ok {
+		e.Value.(*memoryCacheItem).html = html
+		c.order.MoveToFront(e)
+		return nil
+	}
+	c.items[key] = c.order.PushFront(&memoryCacheItem{key: key, html: html})
+	for c.order.Len() > c.size {
+		e := c.order.Back()
+		c.order.Remove(e)
+		delete(c.items, e.Value.(*memoryCacheItem).key)
+	}
+	return nil
+}
+
+// Len gets number of entries in the cache.
+func (c *MemoryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
@@ -0,0 +1,170 @@
+package markdown
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheKey(t *testing.T) {
+	md := "Hello, [[note:1]]"
+	key := CacheKey(md, Options{})
+
+	t.Run("same text and options", func(t *testing.T) {
+		assert.Equal(t, key, CacheKey(md, Options{}))
+	})
+
+	t.Run("different text", func(t *testing.T) {
+		assert.NotEqual(t, key, CacheKey(md+"!", Options{}))
+	})
+
+	t.Run("different mode of raw html", func(t *testing.T) {
+		assert.NotEqual(t, key, CacheKey(md, Options{RawHTML: RawHTMLEscape}))
+	})
+
+	t.Run("different policy", func(t *testing.T) {
+		p := DefaultPolicy()
+		k1 := CacheKey(md, Options{Policy: p})
+		assert.NotEqual(t, key, k1)
+		p.Attributes = nil
+		assert.NotEqual(t, k1, CacheKey(md, Options{Policy: p}))
+	})
+
+	t.Run("different wiki link targets", func(t *testing.T) {
+		url := func(l WikiLink) (string, string, bool) {
+			return "/notes/" + strconv.Itoa(l.ID), "Note", true
+		}
+		k1 := CacheKey(md, Options{NoteURL: url, LinksKey: "1:Note"})
+		assert.NotEqual(t, key, k1)
+		assert.Equal(t, k1, CacheKey(md, Options{NoteURL: url, LinksKey: "1:Note"}))
+		assert.NotEqual(t, k1, CacheKey(md, Options{NoteURL: url, LinksKey: "1:Renamed"}))
+		assert.NotEqual(t, k1, CacheKey(md, Options{LinksKey: "1:Note"}))
+	})
+
+	t.Run("attachments are ignored", func(t *testing.T) {
+		url := func(id int) string { return "/attachments/" + strconv.Itoa(id) }
+		assert.Equal(t, key, CacheKey(md, Options{AttachmentURL: url}))
+	})
+}
+
+func TestMemoryCache(t *testing.T) {
+	c := NewMemoryCache(2)
+	assert.NoError(t, c.Set("a", "1"))
+	assert.NoError(t, c.Set("b", "2"))
+
+	// Make "a" the most recently used
+	html, ok, err := c.Get("a")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "1", html)
+
+	assert.NoError(t, c.Set("c", "3"))
+	assert.Equal(t, 2, c.Len())
+
+	_, ok, err = c.Get("b")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	for key, val := range map[string]string{"a": "1", "c": "3"} {
+		html, ok, err = c.Get(key)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, val, html)
+	}
+}
+
+type failingCache struct{}
+
+func (failingCache) Get(string) (string, bool, error) {
+	return "", false, errors.New("fail")
+}
+
+func (failingCache) Set(string, string) error {
+	return errors.New("fail")
+}
+
+func TestCachedRenderer(t *testing.T) {
+	md := "![image](attachment:10)"
+	url := func(user string) AttachmentURL {
+		return func(id int) string {
+			return "/attachments/" + strconv.Itoa(id) + "?user=" + user
+		}
+	}
+
+	t.Run("hits and misses", func(t *testing.T) {
+		r := NewCachedRenderer(defaultRenderer, NewMemoryCache(10))
+
+		html := r.Render(md, Options{AttachmentURL: url("1")})
+		assert.Equal(t, Render(md, Options{AttachmentURL: url("1")}), html)
+		assert.Equal(t, CacheStats{Misses: 1}, r.Stats())
+
+		// Attachments are resolved for every call
+		html = r.Render(md, Options{AttachmentURL: url("2")})
+		assert.Equal(t, Render(md, Options{AttachmentURL: url("2")}), html)
+		assert.Equal(t, CacheStats{Hits: 1, Misses: 1}, r.Stats())
+
+		r.Render(md+"!", Options{})
+		assert.Equal(t, CacheStats{Hits: 1, Misses: 2}, r.Stats())
+	})
+
+	t.Run("cache errors", func(t *testing.T) {
+		r := NewCachedRenderer(defaultRenderer, failingCache{})
+		assert.Equal(t, Render(md, Options{}), r.Render(md, Options{}))
+		assert.Equal(t, CacheStats{Misses: 1, Errors: 2}, r.Stats())
+	})
+
+	t.Run("no cache", func(t *testing.T) {
+		r := NewCachedRenderer(defaultRenderer, nil)
+		assert.Equal(t, Render(md, Options{}), r.Render(md, Options{}))
+	})
+}
+
+// benchmarkNote is a note with code, which is the most expensive
+// to render.
+var benchmarkNote = strings.Repeat("# Title\n\n"+
+	"Some *text* with [a link](https://example.com) and ![image](attachment:1).\n\n"+
+	"```go\n"+
+	"func main() {\n"+
+	"\tfor i := 0; i < 10; i++ {\n"+
+	"\t\tfmt.Println(\"Hello, world\", i)\n"+
+	"\t}\n"+
+	"}\n"+
+	"```\n\n", 10)
+
+func BenchmarkRender(b *testing.B) {
+	url := func(id int) string { return "/attachments/" + strconv.Itoa(id) }
+	for i := 0; i < b.N; i++ {
+		Render(benchmarkNote, Options{AttachmentURL: url})
+	}
+}
+
+func BenchmarkRendererCached(b *testing.B) {
+	url := func(id int) string { return "/attachments/" + strconv.Itoa(id) }
+	r := NewCachedRenderer(defaultRenderer, NewMemoryCache(10))
+	r.Render(benchmarkNote, Options{AttachmentURL: url})
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.Render(benchmarkNote, Options{AttachmentURL: url})
+	}
+}
+
+// BenchmarkRendererCachedWithLinks uses the options of getting a note:
+// wiki links and attachments are resolved.
+func BenchmarkRendererCachedWithLinks(b *testing.B) {
+	md := benchmarkNote + "See [[note:1]] and [[Other Note]].\n"
+	opts := Options{
+		AttachmentURL: func(id int) string { return "/attachments/" + strconv.Itoa(id) },
+		NoteURL: func(l WikiLink) (string, string, bool) {
+			return "/notes/1", "Note", true
+		},
+		LinksKey: "1:Note",
+	}
+	r := NewCachedRenderer(defaultRenderer, NewMemoryCache(10))
+	r.Render(md, opts)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.Render(md, opts)
+	}
+}
@@ -0,0 +1,146 @@
+package markdown
+
+import (
+	"bytes"
+	"html"
+	"strings"
+	"unicode"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// wordsPerMinute is an average reading speed.
+const wordsPerMinute = 200
+
+// Document is rendered markdown with its structure.
+type Document struct {
+	HTML    string    `json:"html"`
+	Outline []Heading `json:"outline"`
+	Stats   Stats     `json:"stats"`
+}
+
+// Heading is a heading in the text. ID is an anchor of the heading,
+// it's empty if heading anchors are disabled.
+type Heading struct {
+	Level int    `json:"level"`
+	Title string `json:"title"`
+	ID    string `json:"id,omitempty"`
+}
+
+// Stats are statistics of the text without markup.
+type Stats struct {
+	Words int `json:"words"`
+	// Characters are counted without spaces
+	Characters int `json:"characters"`
+	// ReadingTime is estimated time of reading in minutes
+	ReadingTime int `json:"reading_time"`
+}
+
+// outline gets headings from the tree.
+func outline(doc ast.Node, source []byte) []Heading {
+	headings := []Heading{}
+	for node := doc.FirstChild(); node != nil; node = node.NextSibling() {
+		h, ok := node.(*ast.Heading)
+		if !ok {
+			continue
+		}
+		heading := Heading{Level: h.Level, Title: plainText(h, source)}
+		if id, ok := h.AttributeString("id"); ok {
+			if b, ok := id.([]byte); ok {
+				heading.ID = string(b)
+			}
+		}
+		headings = append(headings, heading)
+	}
+	return headings
+}
+
+// stats counts words and characters in the tree.
+func stats(doc ast.Node, source []byte) Stats {
+	var s Stats
+	inWord := false
+	for _, r := range plainText(doc, source) {
+		if unicode.IsSpace(r) {
+			inWord = false
+			continue
+		}
+		s.Characters++
+		if !inWord && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			s.Words++
+			inWord = true
+		}
+	}
+	s.ReadingTime = (s.Words + wordsPerMinute - 1) / wordsPerMinute
+	return s
+}
+
+// plainText gets text of the node without markup. Blocks are separated
+// by line breaks.
+func plainText(node ast.Node, source []byte) string {
+	var buf bytes.Buffer
+	ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) { // nolint: errcheck
+		if !entering {
+			if n.Type() == ast.TypeBlock {
+				buf.WriteByte('\n')
+			}
+			return ast.WalkContinue, nil
+		}
+		switch n := n.(type) {
+		case *ast.Text:
+			buf.Write(n.Segment.Value(source))
+			if n.SoftLineBreak() || n.HardLineBreak() {
+				buf.WriteByte('\n')
+			}
+		case *ast.String:
+			buf.Write(n.Value)
+		case *wikiLinkNode:
+			buf.WriteString(n.label)
+		case *ast.CodeBlock, *ast.FencedCodeBlock:
+			for i := 0; i < n.Lines().Len(); i++ {
+				s := n.Lines().At(i)
+				buf.Write(s.Value(source))
+			}
+		case *ast.RawHTML, *ast.HTMLBlock:
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	return strings.TrimSpace(buf.String())
+}
+
+// tableOfContents renders headings to nested lists of links.
+func tableOfContents(headings []Heading) string {
+	if len(headings) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(`<nav class="toc">` + "\n")
+	// Levels of open lists, headings can skip levels
+	var levels []int
+	for _, h := range headings {
+		for len(levels) > 0 && levels[len(levels)-1] > h.Level {
+			b.WriteString("</li>\n</ul>\n")
+			levels = levels[:len(levels)-1]
+		}
+		switch {
+		case len(levels) == 0 || levels[len(levels)-1] < h.Level:
+			b.WriteString("<ul>\n")
+			levels = append(levels, h.Level)
+		default:
+			b.WriteString("</li>\n")
+		}
+		b.WriteString("<li>")
+		title := html.EscapeString(h.Title)
+		if h.ID != "" {
+			b.WriteString(`<a href="#` + html.EscapeString(h.ID) + `">` + title + `</a>`)
+		} else {
+			b.WriteString(title)
+		}
+	}
+	for range levels {
+		b.WriteString("</li>\n</ul>\n")
+	}
+	b.WriteString("</nav>\n")
+	return b.String()
+}
@@ -0,0 +1,59 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderDocument(t *testing.T) {
+	md := "# Title\n\n" +
+		"Some *text* with [[note:1]] and `code`.\n\n" +
+		"## Part one\n\n" +
+		"### Sub <b>part</b>\n\n" +
+		"## Part two\n"
+
+	t.Run("outline and stats", func(t *testing.T) {
+		doc := Render(md, Options{})
+		d := defaultRenderer.RenderDocument(md, Options{})
+		assert.Equal(t, doc, d.HTML)
+		assert.Equal(t, []Heading{
+			{Level: 1, Title: "Title", ID: "title"},
+			{Level: 2, Title: "Part one", ID: "part-one"},
+			{Level: 3, Title: "Sub part", ID: "sub-bpartb"},
+			{Level: 2, Title: "Part two", ID: "part-two"},
+		}, d.Outline)
+		assert.Equal(t, Stats{Words: 13, Characters: 52, ReadingTime: 1}, d.Stats)
+	})
+
+	t.Run("empty text", func(t *testing.T) {
+		d := defaultRenderer.RenderDocument("", Options{})
+		assert.Equal(t, "", d.HTML)
+		assert.Equal(t, []Heading{}, d.Outline)
+		assert.Equal(t, Stats{}, d.Stats)
+	})
+
+	t.Run("table of contents", func(t *testing.T) {
+		html := `<nav class="toc">` + "\n<ul>\n" +
+			`<li><a href="#title">Title</a><ul>` + "\n" +
+			`<li><a href="#part-one">Part one</a><ul>` + "\n" +
+			`<li><a href="#sub-bpartb">Sub part</a></li>` + "\n</ul>\n</li>\n" +
+			`<li><a href="#part-two">Part two</a></li>` + "\n</ul>\n</li>\n" +
+			"</ul>\n</nav>\n" +
+			`<h1 id="title">Title</h1>`
+		assert.Contains(t, Render(md, Options{TableOfContents: true}), html)
+	})
+
+	t.Run("table of contents without anchors", func(t *testing.T) {
+		r := NewCommonMark(Config{})
+		html := `<nav class="toc">` + "\n<ul>\n<li>A</li>\n<li>B</li>\n</ul>\n</nav>\n"
+		assert.Contains(t, r.Render("## A\n\n## B", Options{TableOfContents: true}), html)
+	})
+
+	t.Run("theme", func(t *testing.T) {
+		md := "```go\nx := 1\n```"
+		assert.Regexp(t, `^<pre style="color:#f8f8f2;background-color:#272822">`,
+			Render(md, Options{Theme: "monokai"}))
+		assert.NotEqual(t, CacheKey(md, Options{}), CacheKey(md, Options{Theme: "monokai"}))
+	})
+}
@@ -0,0 +1,123 @@
+package markdown
+
+import (
+	"bytes"
+
+	"github.com/alecthomas/chroma"
+	chromahtml "github.com/alecthomas/chroma/formatters/html"
+	"github.com/alecthomas/chroma/lexers"
+	"github.com/alecthomas/chroma/styles"
+	"github.com/pkg/errors"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+// themeAttr is an attribute of code blocks that sets theme
+// for inline styles.
+const themeAttr = "theme"
+
+var (
+	// classFormatter styles code with CSS classes
+	classFormatter = chromahtml.New(chromahtml.WithClasses())
+	// inlineFormatter styles code with style attributes
+	inlineFormatter = chromahtml.New()
+)
+
+// Themes gets names of themes for highlighting code.
+func Themes() []string {
+	return styles.Names()
+}
+
+// ValidTheme checks if there is a theme with the name.
+func ValidTheme(name string) bool {
+	_, ok := styles.Registry[name]
+	return ok
+}
+
+// ThemeCSS makes stylesheet of the theme for code that is styled
+// with classes.
+func ThemeCSS(name string) (string, error) {
+	if !ValidTheme(name) {
+		return "", errors.Errorf("unknown theme %q", name)
+	}
+	var buf bytes.Buffer
+	if err := classFormatter.WriteCSS(&buf, styles.Get(name)); err != nil {
+		return "", errors.Wrap(err, "write css")
+	}
+	return buf.String(), nil
+}
+
+// setTheme sets theme of all code blocks in the tree.
+func setTheme(doc ast.Node, theme string) {
+	ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) { // nolint: errcheck
+		if entering && (node.Kind() == ast.KindCodeBlock || node.Kind() == ast.KindFencedCodeBlock) {
+			node.SetAttributeString(themeAttr, theme)
+		}
+		return ast.WalkContinue, nil
+	})
+}
+
+// highlighting is an extension that highlights code blocks
+// with chroma.
+type highlighting struct{}
+
+func (highlighting) Extend(m goldmark.Markdown) {
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(codeRenderer{}, 200)))
+}
+
+// codeRenderer renders code blocks with highlighted syntax. Language
+// is taken from the info string of fenced blocks, and it's detected
+// from the code otherwise. Code is styled with classes, unless theme
+// is set for the block by attribute.
+type codeRenderer struct{}
+
+func (r codeRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(ast.KindCodeBlock, r.renderCode)
+	reg.Register(ast.KindFencedCodeBlock, r.renderCode)
+}
+
+func (r codeRenderer) renderCode(
+	w util.BufWriter,
+	source []byte,
+	node ast.Node,
+	entering bool,
+) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+
+	var code bytes.Buffer
+	for i := 0; i < node.Lines().Len(); i++ {
+		s := node.Lines().At(i)
+		code.Write(s.Value(source))
+	}
+
+	var lexer chroma.Lexer
+	if n, ok := node.(*ast.FencedCodeBlock); ok && n.Info != nil {
+		lexer = lexers.Get(string(n.Language(source)))
+	} else {
+		lexer = lexers.Analyse(code.String())
+	}
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+
+	formatter, style := classFormatter, styles.Get(DefaultTheme)
+	if theme, ok := node.AttributeString(themeAttr); ok {
+		formatter, style = inlineFormatter, styles.Get(theme.(string))
+	}
+
+	it, err := lexer.Tokenise(nil, code.String())
+	if err == nil {
+		err = formatter.Format(w, style, it)
+	}
+	if err != nil {
+		// Lexers fail only on broken regular expressions
+		_, _ = w.WriteString("<pre><code>")
+		_, _ = w.Write(util.EscapeHTML(code.Bytes()))
+		_, _ = w.WriteString("</code></pre>\n")
+	}
+	return ast.WalkSkipChildren, nil
+}
@@ -0,0 +1,27 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThemes(t *testing.T) {
+	assert.Contains(t, Themes(), DefaultTheme)
+	assert.True(t, ValidTheme("monokai"))
+	assert.False(t, ValidTheme("unknown"))
+}
+
+func TestThemeCSS(t *testing.T) {
+	t.Run("valid theme", func(t *testing.T) {
+		css, err := ThemeCSS("monokai")
+		assert.NoError(t, err)
+		assert.Contains(t, css, ".chroma { color: #f8f8f2; background-color: #272822 }")
+		assert.Regexp(t, `\.chroma \.nx \{ color: #[0-9a-f]+ \}`, css)
+	})
+
+	t.Run("unknown theme", func(t *testing.T) {
+		_, err := ThemeCSS("unknown")
+		assert.EqualError(t, err, `unknown theme "unknown"`)
+	})
+}
@@ -0,0 +1,289 @@
+package markdown
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	// spaces matches runs of whitespace in HTML text.
+	spaces = regexp.MustCompile(`\s+`)
+	// doubleSpaces matches runs of spaces in markdown lines.
+	doubleSpaces = regexp.MustCompile(` {2,}`)
+	// escaper escapes characters that have special meaning in markdown.
+	escaper = strings.NewReplacer(
+		`\`, `\\`,
+		"*", `\*`,
+		"_", `\_`,
+		"`", "\\`",
+		"[", `\[`,
+		"]", `\]`,
+	)
+)
+
+// FromHTML converts HTML to markdown. Formatting that markdown doesn't
+// support is dropped, the text is kept. Evernote checkboxes (<en-todo>)
+// become task list items, embedded files (<en-media>) are skipped.
+func FromHTML(s string) (string, error) {
+	doc, err := html.Parse(strings.NewReader(s))
+	if err != nil {
+		return "", errors.Wrap(err, "parse html")
+	}
+	return strings.Join(blocks(doc), "\n\n"), nil
+}
+
+// blocks converts contents of the node to markdown blocks. Inline
+// children are collected into paragraphs.
+func blocks(n *html.Node) []string {
+	var (
+		result []string
+		para   strings.Builder
+	)
+	flush := func() {
+		if p := paragraph(para.String()); p != "" {
+			result = append(result, p)
+		}
+		para.Reset()
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && isBlock(c) {
+			flush()
+			result = append(result, block(c)...)
+			continue
+		}
+		para.WriteString(inline(c))
+	}
+	flush()
+	return result
+}
+
+// block converts block element to markdown blocks.
+func block(n *html.Node) []string {
+	switch n.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		level := int(n.Data[1] - '0')
+		text := strings.Replace(paragraph(inlineChildren(n)), "  \n", " ", -1)
+		if text == "" {
+			return nil
+		}
+		return []string{strings.Repeat("#", level) + " " + text}
+	case atom.Ul, atom.Ol:
+		if l := list(n); l != "" {
+			return []string{l}
+		}
+		return nil
+	case atom.Blockquote:
+		inner := strings.Join(blocks(n), "\n\n")
+		if inner == "" {
+			return nil
+		}
+		return []string{prefixLines(inner, "> ", ">")}
+	case atom.Pre:
+		code := strings.TrimRight(textContent(n), "\n")
+		return []string{"```\n" + code + "\n```"}
+	case atom.Hr:
+		return []string{"---"}
+	case atom.Table:
+		if t := table(n); t != "" {
+			return []string{t}
+		}
+		return nil
+	case atom.Head, atom.Script, atom.Style, atom.Title:
+		return nil
+	default:
+		return blocks(n)
+	}
+}
+
+// list converts list to markdown. Contents of items are indented,
+// so nested blocks stay inside the item.
+func list(n *html.Node) string {
+	var items []string
+	num := 1
+	if start, err := strconv.Atoi(attr(n, "start")); err == nil {
+		num = start
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode || c.DataAtom != atom.Li {
+			continue
+		}
+		marker := "- "
+		if n.DataAtom == atom.Ol {
+			marker = strconv.Itoa(num) + ". "
+			num++
+		}
+		content := strings.Join(blocks(c), "\n\n")
+		indent := strings.Repeat(" ", len(marker))
+		items = append(items, marker+strings.TrimPrefix(prefixLines(content, indent, ""), indent))
+	}
+	return strings.Join(items, "\n")
+}
+
+// table converts table to markdown. The first row becomes the header.
+func table(n *html.Node) string {
+	var rows []string
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			if c.DataAtom != atom.Tr {
+				walk(c)
+				continue
+			}
+			var cells []string
+			for td := c.FirstChild; td != nil; td = td.NextSibling {
+				if td.DataAtom == atom.Td || td.DataAtom == atom.Th {
+					cell := strings.Replace(paragraph(inlineChildren(td)), "  \n", " ", -1)
+					cells = append(cells, strings.Replace(cell, "|", `\|`, -1))
+				}
+			}
+			rows = append(rows, "| "+strings.Join(cells, " | ")+" |")
+			if len(rows) == 1 {
+				rows = append(rows, "|"+strings.Repeat(" --- |", len(cells)))
+			}
+		}
+	}
+	walk(n)
+	return strings.Join(rows, "\n")
+}
+
+// inline converts inline node to markdown.
+func inline(n *html.Node) string {
+	switch n.Type {
+	case html.TextNode:
+		return escaper.Replace(spaces.ReplaceAllString(n.Data, " "))
+	case html.ElementNode:
+	default:
+		return ""
+	}
+
+	switch n.DataAtom {
+	case atom.Br:
+		return "  \n"
+	case atom.Strong, atom.B:
+		return wrap(inlineChildren(n), "**")
+	case atom.Em, atom.I:
+		return wrap(inlineChildren(n), "*")
+	case atom.S, atom.Strike, atom.Del:
+		return wrap(inlineChildren(n), "~~")
+	case atom.Code, atom.Tt:
+		return wrap(textContent(n), "`")
+	case atom.A:
+		text := inlineChildren(n)
+		href := attr(n, "href")
+		if href == "" || strings.TrimSpace(text) == "" {
+			return text
+		}
+		return "[" + text + "](" + href + ")"
+	case atom.Img:
+		src := attr(n, "src")
+		if src == "" || strings.HasPrefix(src, "data:") {
+			return ""
+		}
+		return "![" + escaper.Replace(attr(n, "alt")) + "](" + src + ")"
+	case atom.Script, atom.Style:
+		return ""
+	}
+
+	switch n.Data {
+	case "en-todo":
+		// Parser doesn't know that the element is empty, so the
+		// following text becomes its children
+		if attr(n, "checked") == "true" {
+			return "[x] " + inlineChildren(n)
+		}
+		return "[ ] " + inlineChildren(n)
+	case "en-media":
+		return ""
+	}
+	return inlineChildren(n)
+}
+
+func inlineChildren(n *html.Node) string {
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		b.WriteString(inline(c))
+	}
+	return b.String()
+}
+
+// wrap wraps text with the markup, spaces around the text are moved
+// outside, since markdown doesn't allow them inside.
+func wrap(s, markup string) string {
+	trimmed := strings.TrimSpace(s)
+	if trimmed == "" {
+		return s
+	}
+	start := strings.Index(s, trimmed)
+	return s[:start] + markup + trimmed + markup + s[start+len(trimmed):]
+}
+
+// paragraph cleans up inline markdown: lines are trimmed, but hard
+// line breaks are kept.
+func paragraph(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		hardBreak := strings.HasSuffix(l, "  ") && i < len(lines)-1
+		l = strings.TrimSpace(doubleSpaces.ReplaceAllString(l, " "))
+		if hardBreak {
+			l += "  "
+		}
+		lines[i] = l
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+func prefixLines(s, prefix, emptyPrefix string) string {
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		if l == "" {
+			lines[i] = emptyPrefix
+		} else {
+			lines[i] = prefix + l
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	if n.DataAtom == atom.Br {
+		return "\n"
+	}
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		b.WriteString(textContent(c))
+	}
+	return b.String()
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// isBlock checks if the element starts a new block.
+func isBlock(n *html.Node) bool {
+	switch n.DataAtom {
+	case atom.P, atom.Div, atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6,
+		atom.Ul, atom.Ol, atom.Li, atom.Blockquote, atom.Pre, atom.Hr, atom.Table,
+		atom.Section, atom.Article, atom.Header, atom.Footer, atom.Center,
+		atom.Html, atom.Head, atom.Body:
+		return true
+	}
+	return n.Data == "en-note"
+}
@@ -0,0 +1,62 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromHTML(t *testing.T) {
+	testCases := []struct {
+		name string
+		html string
+		md   string
+	}{
+		{
+			name: "paragraphs and inline formatting",
+			html: "<p>Hello, <b>bold</b> and <i> italic </i> world</p>\n<div>Line<br/>break</div>",
+			md:   "Hello, **bold** and *italic* world\n\nLine  \nbreak",
+		},
+		{
+			name: "headings, links and images",
+			html: `<h2>Title</h2><p><a href="https://example.com">site</a> ` +
+				`<img src="https://example.com/a.png" alt="pic"><img src="data:image/png;base64,AAA"></p>`,
+			md: "## Title\n\n[site](https://example.com) ![pic](https://example.com/a.png)",
+		},
+		{
+			name: "lists",
+			html: "<ul><li>one</li><li>two<ol><li>a</li><li>b</li></ol></li></ul>",
+			md:   "- one\n- two\n\n  1. a\n  2. b",
+		},
+		{
+			name: "quotes and code",
+			html: "<blockquote><p>first</p><p>second</p></blockquote><pre>func main() {\n\treturn\n}</pre>",
+			md:   "> first\n>\n> second\n\n```\nfunc main() {\n\treturn\n}\n```",
+		},
+		{
+			name: "markdown characters are escaped",
+			html: "<p>2*2 [not_link] <code>a*b</code></p>",
+			md:   "2\\*2 \\[not\\_link\\] `a*b`",
+		},
+		{
+			name: "table",
+			html: "<table><tr><th>Name</th><th>Value</th></tr><tr><td>a|b</td><td>1</td></tr></table>",
+			md:   "| Name | Value |\n| --- | --- |\n| a\\|b | 1 |",
+		},
+		{
+			name: "evernote note",
+			html: `<?xml version="1.0" encoding="UTF-8"?>` +
+				`<!DOCTYPE en-note SYSTEM "http://xml.evernote.com/pub/enml2.dtd">` +
+				`<en-note><div><en-todo checked="true"/>done</div>` +
+				`<div><en-todo/>todo</div><en-media type="image/png" hash="abc"/></en-note>`,
+			md: "[x] done\n\n[ ] todo",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			md, err := FromHTML(tc.html)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.md, md)
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// NoteScheme is a scheme of wiki links to notes by ID, e.g. [[note:123]].
+const NoteScheme = "note:"
+
+// wikiLink matches wiki link at the beginning of the text:
+// [[Note Title]] or [[note:123]].
+var wikiLink = regexp.MustCompile(`^\[\[([^\[\]\n]+)\]\]`)
+
+// WikiLink is a link to another note, either by ID or by title.
+type WikiLink struct {
+	ID    int
+	Title string
+}
+
+// NoteURL resolves wiki link to URL and title of the linked note.
+// It returns false if the link is broken.
+type NoteURL func(link WikiLink) (url, title string, ok bool)
+
+// ParseLinks finds wiki links in markdown. Code blocks, code spans
+// and text of regular links are skipped. Links are returned in order
+// of appearance without duplicates, titles are compared case-insensitively.
+func ParseLinks(markdown string) []WikiLink {
+	var links []WikiLink
+	seen := map[WikiLink]bool{}
+	doc := defaultRenderer.md.Parser().Parse(text.NewReader([]byte(markdown)))
+	for _, node := range wikiLinkNodes(doc) {
+		l := node.link
+		key := WikiLink{ID: l.ID, Title: strings.ToLower(l.Title)}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		links = append(links, l)
+	}
+	return links
+}
+
+// parseWikiLink parses contents of the wiki link.
+func parseWikiLink(s string) (WikiLink, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return WikiLink{}, false
+	}
+	if strings.HasPrefix(s, NoteScheme) {
+		// IDs are 32-bit in the database
+		id, err := strconv.ParseInt(strings.TrimPrefix(s, NoteScheme), 10, 32)
+		if err == nil && id > 0 {
+			return WikiLink{ID: int(id)}, true
+		}
+	}
+	return WikiLink{Title: s}, true
+}
+
+// kindWikiLink is a kind of wiki link nodes.
+var kindWikiLink = ast.NewNodeKind("WikiLink")
+
+// wikiLinkNode is a wiki link in the syntax tree. It's resolved
+// before rendering.
+type wikiLinkNode struct {
+	ast.BaseInline
+	link WikiLink
+	raw  string
+	// Resolved link, rendered as plain label if url is empty
+	label  string
+	url    string
+	broken bool
+}
+
+func (n *wikiLinkNode) Kind() ast.NodeKind {
+	return kindWikiLink
+}
+
+func (n *wikiLinkNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+// wikiLinks is an extension that parses and renders wiki links.
+type wikiLinks struct{}
+
+func (wikiLinks) Extend(m goldmark.Markdown) {
+	// Wiki links go before regular links, which start with
+	// the same character
+	m.Parser().AddOptions(parser.WithInlineParsers(util.Prioritized(wikiLinkParser{}, 199)))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(wikiLinkRenderer{}, 200)))
+}
+
+type wikiLinkParser struct{}
+
+func (wikiLinkParser) Trigger() []byte {
+	return []byte{'['}
+}
+
+func (wikiLinkParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, _ := block.PeekLine()
+	m := wikiLink.FindSubmatch(line)
+	if m == nil {
+		return nil
+	}
+	l, ok := parseWikiLink(string(m[1]))
+	if !ok {
+		return nil
+	}
+	block.Advance(len(m[0]))
+	label := l.Title
+	if l.ID != 0 {
+		label = NoteScheme + strconv.Itoa(l.ID)
+	}
+	return &wikiLinkNode{link: l, raw: string(m[0]), label: label}
+}
+
+type wikiLinkRenderer struct{}
+
+func (wikiLinkRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(kindWikiLink, renderWikiLink)
+}
+
+func renderWikiLink(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*wikiLinkNode)
+	label := html.EscapeString(n.label)
+	switch {
+	case n.broken:
+		_, _ = w.WriteString(`<span class="note-link broken">` + label + `</span>`)
+	case n.url != "":
+		_, _ = w.WriteString(`<a class="note-link" href="` + html.EscapeString(n.url) + `">` + label + `</a>`)
+	default:
+		_, _ = w.WriteString(label)
+	}
+	return ast.WalkContinue, nil
+}
+
+// wikiLinkNodes finds wiki links that are not parts of other links.
+// Links inside other links are turned into text.
+func wikiLinkNodes(doc ast.Node) []*wikiLinkNode {
+	var nodes []*wikiLinkNode
+	var nested []ast.Node
+	inLink := 0
+	ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) { // nolint: errcheck
+		switch node.Kind() {
+		case ast.KindLink, ast.KindImage:
+			if entering {
+				inLink++
+			} else {
+				inLink--
+			}
+		case kindWikiLink:
+			if !entering {
+				break
+			}
+			if inLink > 0 {
+				nested = append(nested, node)
+			} else {
+				nodes = append(nodes, node.(*wikiLinkNode))
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+	for _, node := range nested {
+		raw := []byte(node.(*wikiLinkNode).raw)
+		node.Parent().ReplaceChild(node.Parent(), node, ast.NewString(raw))
+	}
+	return nodes
+}
+
+// resolveWikiLinks sets URLs and titles of linked notes. Broken links
+// are marked with a class, and all links are rendered as plain text
+// if noteURL is nil.
+func resolveWikiLinks(doc ast.Node, noteURL NoteURL) {
+	for _, n := range wikiLinkNodes(doc) {
+		if noteURL == nil {
+			continue
+		}
+		url, title, found := noteURL(n.link)
+		if n.link.ID != 0 && title != "" {
+			n.label = title
+		}
+		n.url = url
+		n.broken = !found
+	}
+}
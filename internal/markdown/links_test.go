@@ -0,0 +1,69 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLinks(t *testing.T) {
+	t.Run("links by title and id", func(t *testing.T) {
+		md := "See [[Shopping list]] and [[note:12]].\n\n" +
+			"* [[ shopping LIST ]]\n" +
+			"* [[note:12]] [[note:x]]"
+		links := []WikiLink{
+			{Title: "Shopping list"},
+			{ID: 12},
+			{Title: "note:x"},
+		}
+		assert.Equal(t, links, ParseLinks(md))
+	})
+
+	t.Run("code and regular links are skipped", func(t *testing.T) {
+		md := "`[[Inline]]` [see [[Label]]](https://example.com)\n\n" +
+			"```\n" +
+			"[[Block]]\n" +
+			"```\n\n" +
+			"[[]] [[a]b]] [[Real]]"
+		links := []WikiLink{{Title: "Real"}}
+		assert.Equal(t, links, ParseLinks(md))
+	})
+
+	t.Run("no links", func(t *testing.T) {
+		assert.Nil(t, ParseLinks("Hello, [world](https://example.com)"))
+	})
+}
+
+func TestRenderWikiLinks(t *testing.T) {
+	noteURL := func(l WikiLink) (string, string, bool) {
+		switch {
+		case l.ID == 10:
+			return "/notes/10", "First & second", true
+		case l.Title == "Recipes":
+			return "/notes/20", "Recipes", true
+		default:
+			return "", "", false
+		}
+	}
+
+	t.Run("resolved and broken links", func(t *testing.T) {
+		md := "See [[Recipes]], [[note:10]], [[Missing]] and [[note:30]]."
+		html := `<p>See <a class="note-link" href="/notes/20">Recipes</a>, ` +
+			`<a class="note-link" href="/notes/10">First &amp; second</a>, ` +
+			`<span class="note-link broken">Missing</span> and ` +
+			`<span class="note-link broken">note:30</span>.</p>` + "\n"
+		assert.Equal(t, html, Render(md, Options{NoteURL: noteURL}))
+	})
+
+	t.Run("links without resolver", func(t *testing.T) {
+		md := "See [[Recipes]] and [[note:10]]."
+		html := "<p>See Recipes and note:10.</p>\n"
+		assert.Equal(t, html, Render(md, Options{}))
+	})
+
+	t.Run("links inside code", func(t *testing.T) {
+		md := "`[[Recipes]]`"
+		html := "<p><code>[[Recipes]]</code></p>\n"
+		assert.Equal(t, html, Render(md, Options{NoteURL: noteURL}))
+	})
+}
@@ -1,19 +1,316 @@
 package markdown
 
 import (
-	"github.com/Depado/bfchroma"
-	blackfriday "gopkg.in/russross/blackfriday.v2"
+	"bytes"
+	"regexp"
+	"strconv"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
 )
 
-// theme is a color theme for rendering markdown to HTML.
-const theme = "monokailight"
-
-// Render renders markdown to HTML.
-func Render(markdown string) (html string) {
-	r := bfchroma.NewRenderer(bfchroma.Style(theme))
-	b := blackfriday.Run(
-		[]byte(markdown),
-		blackfriday.WithRenderer(r),
-	)
-	return string(b)
+// DefaultTheme is a color theme for highlighting code, that is used
+// if users haven't chosen another one.
+const DefaultTheme = "monokailight"
+
+// AttachmentScheme is a scheme of links to note attachments,
+// e.g. ![screenshot](attachment:123).
+const AttachmentScheme = "attachment:"
+
+// AttachmentURL gets download URL of the attachment. Empty URL
+// leaves the link unresolved.
+type AttachmentURL func(id int) string
+
+// RawHTML is a mode of handling HTML in markdown.
+type RawHTML string
+
+// Modes of handling HTML in markdown.
+const (
+	// RawHTMLSanitize keeps HTML that is allowed by the policy
+	RawHTMLSanitize RawHTML = "sanitize"
+	// RawHTMLEscape shows HTML as text
+	RawHTMLEscape RawHTML = "escape"
+)
+
+// Options are options for rendering markdown.
+type Options struct {
+	// AttachmentURL resolves links and images that point to attachments,
+	// they are left as is if it's nil
+	AttachmentURL AttachmentURL
+	// NoteURL resolves wiki links to other notes, they are rendered
+	// as plain text if it's nil
+	NoteURL NoteURL
+	// LinksKey identifies targets that NoteURL resolves links to, e.g.
+	// their IDs and titles. It's used in cache keys instead of NoteURL,
+	// so it must change when the targets change
+	LinksKey string
+	// RawHTML is a mode of handling HTML in markdown, it's sanitized
+	// by default
+	RawHTML RawHTML
+	// Policy is an allowlist for the rendered HTML, DefaultPolicy
+	// is used if it's nil
+	Policy *Policy
+	// Theme makes highlighted code styled inline with the theme,
+	// code is styled with CSS classes if it's empty, see ThemeCSS
+	Theme string
+	// TableOfContents adds list of links to headings before the text
+	TableOfContents bool
+}
+
+// Renderer renders markdown to HTML.
+type Renderer interface {
+	Render(markdown string, opts Options) (html string)
+}
+
+// Config is a set of syntax extensions on top of CommonMark.
+type Config struct {
+	// GitHub Flavored Markdown extensions
+	Tables        bool
+	TaskLists     bool
+	Strikethrough bool
+	Autolinks     bool
+	// Footnotes like [^1]
+	Footnotes bool
+	// HeadingAnchors generates IDs of headings from their text,
+	// and allows setting them explicitly: # Title {#id}
+	HeadingAnchors bool
+	// DefinitionLists are terms followed by lines starting with ": "
+	DefinitionLists bool
+	// Typographer replaces quotes, dashes and ellipses with
+	// typographic characters, it's disabled if nil
+	Typographer *Typographer
+	// Highlight enables highlighting of code
+	Highlight bool
+	// Extensions are additional extensions of the parser and renderer
+	Extensions []goldmark.Extender
+}
+
+// Typographer is a set of typographic replacements. Keys are
+// the punctuation names: left-single-quote, right-single-quote,
+// left-double-quote, right-double-quote, en-dash, em-dash, ellipsis,
+// left-angle-quote, right-angle-quote and apostrophe. Defaults are
+// used for missing keys.
+type Typographer struct {
+	Substitutions map[string]string
+}
+
+// typographicPunctuation maps names of the punctuation to the parser's
+// constants.
+var typographicPunctuation = map[string]extension.TypographicPunctuation{
+	"left-single-quote":  extension.LeftSingleQuote,
+	"right-single-quote": extension.RightSingleQuote,
+	"left-double-quote":  extension.LeftDoubleQuote,
+	"right-double-quote": extension.RightDoubleQuote,
+	"en-dash":            extension.EnDash,
+	"em-dash":            extension.EmDash,
+	"ellipsis":           extension.Ellipsis,
+	"left-angle-quote":   extension.LeftAngleQuote,
+	"right-angle-quote":  extension.RightAngleQuote,
+	"apostrophe":         extension.Apostrophe,
+}
+
+// DefaultConfig makes config with all extensions enabled.
+func DefaultConfig() Config {
+	return Config{
+		Tables:          true,
+		TaskLists:       true,
+		Strikethrough:   true,
+		Autolinks:       true,
+		Footnotes:       true,
+		HeadingAnchors:  true,
+		DefinitionLists: true,
+		Typographer:     &Typographer{},
+		Highlight:       true,
+	}
+}
+
+// CommonMark is a renderer of CommonMark with optional extensions.
+// Wiki links to other notes are always supported.
+type CommonMark struct {
+	md goldmark.Markdown
+}
+
+// NewCommonMark creates new renderer.
+func NewCommonMark(cfg Config) *CommonMark {
+	exts := []goldmark.Extender{wikiLinks{}}
+	if cfg.Tables {
+		exts = append(exts, extension.NewTable(
+			extension.WithTableCellAlignMethod(extension.TableCellAlignAttribute),
+		))
+	}
+	if cfg.TaskLists {
+		exts = append(exts, extension.TaskList)
+	}
+	if cfg.Strikethrough {
+		exts = append(exts, extension.Strikethrough)
+	}
+	if cfg.Autolinks {
+		exts = append(exts, extension.Linkify)
+	}
+	if cfg.Footnotes {
+		exts = append(exts, extension.Footnote)
+	}
+	if cfg.DefinitionLists {
+		exts = append(exts, extension.DefinitionList)
+	}
+	if cfg.Typographer != nil {
+		subs := map[extension.TypographicPunctuation][]byte{}
+		for name, val := range cfg.Typographer.Substitutions {
+			if p, ok := typographicPunctuation[name]; ok {
+				subs[p] = []byte(val)
+			}
+		}
+		exts = append(exts, extension.NewTypographer(
+			extension.WithTypographicSubstitutions(subs),
+		))
+	}
+	if cfg.Highlight {
+		exts = append(exts, highlighting{})
+	}
+	exts = append(exts, cfg.Extensions...)
+
+	var parserOpts []parser.Option
+	if cfg.HeadingAnchors {
+		parserOpts = append(parserOpts,
+			parser.WithAutoHeadingID(),
+			parser.WithHeadingAttribute(),
+		)
+	}
+
+	return &CommonMark{md: goldmark.New(
+		goldmark.WithExtensions(exts...),
+		goldmark.WithParserOptions(parserOpts...),
+		// HTML is kept for the sanitizer
+		goldmark.WithRendererOptions(html.WithXHTML(), html.WithUnsafe()),
+	)}
+}
+
+// Render renders markdown to HTML. The result is always sanitized,
+// even if HTML in markdown is escaped, since links can still have
+// dangerous URLs.
+func (r *CommonMark) Render(markdown string, opts Options) string {
+	doc, source := r.parse(markdown, opts)
+	return r.html(doc, source, opts)
+}
+
+// RenderDocument renders markdown to HTML the same way as Render,
+// and gets outline and statistics of the text.
+func (r *CommonMark) RenderDocument(markdown string, opts Options) Document {
+	doc, source := r.parse(markdown, opts)
+	return Document{
+		HTML:    r.html(doc, source, opts),
+		Outline: outline(doc, source),
+		Stats:   stats(doc, source),
+	}
+}
+
+// parse parses markdown to the syntax tree, and prepares the tree
+// for rendering.
+func (r *CommonMark) parse(markdown string, opts Options) (ast.Node, []byte) {
+	source := []byte(markdown)
+	doc := r.md.Parser().Parse(text.NewReader(source))
+	if opts.RawHTML == RawHTMLEscape {
+		escapeRawHTML(doc, source)
+	}
+	resolveWikiLinks(doc, opts.NoteURL)
+	if opts.Theme != "" {
+		setTheme(doc, opts.Theme)
+	}
+	return doc, source
+}
+
+// html renders the tree to sanitized HTML.
+func (r *CommonMark) html(doc ast.Node, source []byte, opts Options) string {
+	var buf bytes.Buffer
+	if opts.TableOfContents {
+		buf.WriteString(tableOfContents(outline(doc, source)))
+	}
+	if err := r.md.Renderer().Render(&buf, source, doc); err != nil {
+		// Writing to buffer never fails, and node renderers don't
+		// return errors
+		panic(err)
+	}
+
+	policy := opts.Policy
+	if policy == nil {
+		policy = defaultPolicy
+	}
+	return resolveAttachments(policy.Sanitize(buf.String()), opts.AttachmentURL)
+}
+
+// defaultRenderer is used by Render function.
+var defaultRenderer = NewCommonMark(DefaultConfig())
+
+// Render renders markdown to HTML with the default config.
+func Render(markdown string, opts Options) (html string) {
+	return defaultRenderer.Render(markdown, opts)
+}
+
+// attachmentAttr matches attributes with links to attachments
+// in rendered HTML.
+var attachmentAttr = regexp.MustCompile(`(href|src)="` + AttachmentScheme + `(\d+)"`)
+
+// resolveAttachments replaces links to attachments in links and images
+// with download URLs.
+func resolveAttachments(html string, attachmentURL AttachmentURL) string {
+	if attachmentURL == nil {
+		return html
+	}
+	return attachmentAttr.ReplaceAllStringFunc(html, func(attr string) string {
+		m := attachmentAttr.FindStringSubmatch(attr)
+		id, err := strconv.Atoi(m[2])
+		if err != nil {
+			return attr
+		}
+		u := attachmentURL(id)
+		if u == "" {
+			return attr
+		}
+		return m[1] + `="` + htmlEscaper.Replace(u) + `"`
+	})
+}
+
+// escapeRawHTML turns HTML in the tree into text. HTML blocks
+// become paragraphs.
+func escapeRawHTML(doc ast.Node, source []byte) {
+	var nodes []ast.Node
+	ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) { // nolint: errcheck
+		if entering && (node.Kind() == ast.KindRawHTML || node.Kind() == ast.KindHTMLBlock) {
+			nodes = append(nodes, node)
+		}
+		return ast.WalkContinue, nil
+	})
+	for _, node := range nodes {
+		var literal []byte
+		switch n := node.(type) {
+		case *ast.RawHTML:
+			for i := 0; i < n.Segments.Len(); i++ {
+				s := n.Segments.At(i)
+				literal = append(literal, s.Value(source)...)
+			}
+			node.Parent().ReplaceChild(node.Parent(), node, rawText(literal))
+		case *ast.HTMLBlock:
+			for i := 0; i < n.Lines().Len(); i++ {
+				s := n.Lines().At(i)
+				literal = append(literal, s.Value(source)...)
+			}
+			if n.HasClosure() {
+				literal = append(literal, n.ClosureLine.Value(source)...)
+			}
+			p := ast.NewParagraph()
+			p.AppendChild(p, rawText(bytes.TrimRight(literal, "\n")))
+			node.Parent().ReplaceChild(node.Parent(), node, p)
+		}
+	}
+}
+
+// rawText makes node with text that is escaped as is.
+func rawText(literal []byte) ast.Node {
+	s := ast.NewString(literal)
+	s.SetRaw(true)
+	return s
 }
@@ -1,6 +1,7 @@
 package markdown
 
 import (
+	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -10,19 +11,19 @@ func TestRender(t *testing.T) {
 	t.Run("plain text", func(t *testing.T) {
 		md := "Hello, world"
 		html := "<p>Hello, world</p>\n"
-		assert.Equal(t, html, Render(md))
+		assert.Equal(t, html, Render(md, Options{}))
 	})
 
 	t.Run("text with special markdown characters", func(t *testing.T) {
 		md := "# Hello, world\n" +
 			"* one\n" +
 			"* two"
-		html := "<h1>Hello, world</h1>\n\n" +
+		html := "<h1 id=\"hello-world\">Hello, world</h1>\n" +
 			"<ul>\n" +
 			"<li>one</li>\n" +
 			"<li>two</li>\n" +
 			"</ul>\n"
-		assert.Equal(t, html, Render(md))
+		assert.Equal(t, html, Render(md, Options{}))
 	})
 
 	t.Run("code", func(t *testing.T) {
@@ -32,8 +33,132 @@ func TestRender(t *testing.T) {
 			"\t fmt.Println(\"Hi!\")\n" +
 			"}\n" +
 			"```"
-		// Some text inside styled "<pre>" tags
-		re := `<pre style=".+">(.|\s)+<\/pre>`
-		assert.Regexp(t, re, Render(md))
+		// Some text inside "<pre>" tags styled with classes
+		re := `<pre class="chroma">(.|\s)+<\/pre>`
+		assert.Regexp(t, re, Render(md, Options{}))
+	})
+
+	t.Run("code with inline styles", func(t *testing.T) {
+		md := "```go\nx := 1\n```"
+		re := `^<pre style="color:#272822;background-color:#fafafa">` +
+			`<span style="color:#[0-9a-f]+">x</span>`
+		assert.Regexp(t, re, Render(md, Options{Theme: "monokailight"}))
+	})
+
+	t.Run("attachments", func(t *testing.T) {
+		md := "![image](attachment:10) [file](attachment:20) [bad](attachment:x)"
+		html := `<p><img src="/attachments/10" alt="image" /> ` +
+			`<a href="/attachments/20">file</a> ` +
+			`<a href="attachment:x">bad</a></p>` + "\n"
+		url := func(id int) string {
+			return "/attachments/" + strconv.Itoa(id)
+		}
+		assert.Equal(t, html, Render(md, Options{AttachmentURL: url}))
+	})
+}
+
+func TestRenderRawHTML(t *testing.T) {
+	md := "<div onclick=\"alert(1)\">\n<b>Block</b>\n</div>\n\n" +
+		"Hello, <i>world</i><script>alert(1)</script>"
+
+	t.Run("sanitize", func(t *testing.T) {
+		html := "<div>\n<b>Block</b>\n</div>\n" +
+			"<p>Hello, <i>world</i></p>\n"
+		assert.Equal(t, html, Render(md, Options{}))
+		assert.Equal(t, html, Render(md, Options{RawHTML: RawHTMLSanitize}))
+	})
+
+	t.Run("escape", func(t *testing.T) {
+		html := "<p>&lt;div onclick=&quot;alert(1)&quot;&gt;\n" +
+			"&lt;b&gt;Block&lt;/b&gt;\n" +
+			"&lt;/div&gt;</p>\n" +
+			"<p>Hello, &lt;i&gt;world&lt;/i&gt;&lt;script&gt;alert(1)&lt;/script&gt;</p>\n"
+		assert.Equal(t, html, Render(md, Options{RawHTML: RawHTMLEscape}))
+	})
+
+	t.Run("code is highlighted", func(t *testing.T) {
+		md := "```go\nx := \"<b>\"\n```"
+		re := `^<pre class="chroma"><span class="nx">x</span>.*&lt;b&gt;`
+		assert.Regexp(t, re, Render(md, Options{}))
+	})
+}
+
+func TestRenderExtensions(t *testing.T) {
+	t.Run("tables", func(t *testing.T) {
+		md := "| a | b |\n|:--|--:|\n| 1 | 2 |"
+		html := "<table>\n<thead>\n<tr>\n" +
+			`<th align="left">a</th>` + "\n" +
+			`<th align="right">b</th>` + "\n" +
+			"</tr>\n</thead>\n<tbody>\n<tr>\n" +
+			`<td align="left">1</td>` + "\n" +
+			`<td align="right">2</td>` + "\n" +
+			"</tr>\n</tbody>\n</table>\n"
+		assert.Equal(t, html, Render(md, Options{}))
+	})
+
+	t.Run("task lists", func(t *testing.T) {
+		md := "- [ ] todo\n- [x] done\n\n<input type=\"text\" value=\"x\">"
+		html := "<ul>\n" +
+			`<li><input disabled="" type="checkbox" /> todo</li>` + "\n" +
+			`<li><input checked="" disabled="" type="checkbox" /> done</li>` + "\n" +
+			"</ul>\n"
+		assert.Equal(t, html, Render(md, Options{}))
+	})
+
+	t.Run("strikethrough and autolinks", func(t *testing.T) {
+		md := "~~old~~ see https://example.com."
+		html := `<p><del>old</del> see <a href="https://example.com">https://example.com</a>.</p>` + "\n"
+		assert.Equal(t, html, Render(md, Options{}))
+	})
+
+	t.Run("nested lists", func(t *testing.T) {
+		md := "1. one\n   - nested\n     - deeper\n2. two"
+		html := "<ol>\n<li>one\n<ul>\n<li>nested\n<ul>\n<li>deeper</li>\n" +
+			"</ul>\n</li>\n</ul>\n</li>\n<li>two</li>\n</ol>\n"
+		assert.Equal(t, html, Render(md, Options{}))
+	})
+
+	t.Run("footnotes", func(t *testing.T) {
+		md := "Text[^1].\n\n[^1]: Note."
+		html := `<p>Text<sup id="fnref:1"><a href="#fn:1" class="footnote-ref">1</a></sup>.</p>` + "\n" +
+			`<div class="footnotes">` + "\n<hr />\n<ol>\n" +
+			`<li id="fn:1">` + "\n" +
+			"<p>Note. " + `<a href="#fnref:1" class="footnote-backref">↩︎</a></p>` + "\n" +
+			"</li>\n</ol>\n</div>\n"
+		assert.Equal(t, html, Render(md, Options{}))
+	})
+
+	t.Run("heading anchors", func(t *testing.T) {
+		md := "## Hello, world\n\n## Custom {#my-id}"
+		html := `<h2 id="hello-world">Hello, world</h2>` + "\n" +
+			`<h2 id="my-id">Custom</h2>` + "\n"
+		assert.Equal(t, html, Render(md, Options{}))
+	})
+
+	t.Run("definition lists", func(t *testing.T) {
+		md := "Term\n: Definition"
+		html := "<dl>\n<dt>Term</dt>\n<dd>Definition</dd>\n</dl>\n"
+		assert.Equal(t, html, Render(md, Options{}))
+	})
+
+	t.Run("typographer", func(t *testing.T) {
+		md := `"Quoted" -- and --- ...`
+		assert.Equal(t, "<p>“Quoted” – and — …</p>\n", Render(md, Options{}))
+
+		r := NewCommonMark(Config{Typographer: &Typographer{
+			Substitutions: map[string]string{
+				"left-double-quote":  "&laquo;",
+				"right-double-quote": "&raquo;",
+			},
+		}})
+		assert.Equal(t, "<p>«Quoted» – and — …</p>\n", r.Render(md, Options{}))
+	})
+
+	t.Run("disabled extensions", func(t *testing.T) {
+		r := NewCommonMark(Config{})
+		md := "## Title\n\n~~old~~ \"new\" https://example.com"
+		html := "<h2>Title</h2>\n" +
+			"<p>~~old~~ &quot;new&quot; https://example.com</p>\n"
+		assert.Equal(t, html, r.Render(md, Options{}))
 	})
 }
@@ -0,0 +1,250 @@
+package markdown
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+var (
+	// styleValue matches CSS values that can't load anything or run
+	// scripts: colors, lengths and keywords.
+	styleValue = regexp.MustCompile(`^[#\w\s%.,-]+$`)
+	// classValue matches values of class attributes.
+	classValue = regexp.MustCompile(`^[\w\s-]+$`)
+	// htmlEscaper escapes text and attribute values the same way
+	// the markdown renderer does.
+	htmlEscaper = strings.NewReplacer(
+		"&", "&amp;",
+		"<", "&lt;",
+		">", "&gt;",
+		`"`, "&quot;",
+	)
+)
+
+// Policy is an allowlist of HTML elements and attributes. Elements
+// that are not allowed are removed, but their text is kept, except
+// for scripts and styles, which are removed completely.
+type Policy struct {
+	// Elements are allowed elements with their allowed attributes
+	Elements map[string][]string
+	// Attributes are allowed on all elements
+	Attributes []string
+	// URLSchemes are allowed schemes of href and src attributes,
+	// relative URLs are always allowed
+	URLSchemes []string
+	// StyleProperties are allowed CSS properties in style attributes,
+	// the rest of declarations are removed
+	StyleProperties []string
+}
+
+// DefaultPolicy makes policy that allows markup produced from markdown,
+// including highlighted code, task lists and footnotes, and common
+// formatting elements.
+func DefaultPolicy() *Policy {
+	headings := []string{"id"}
+	cells := []string{"align", "colspan", "rowspan"}
+	return &Policy{
+		Elements: map[string][]string{
+			"a":          {"href"},
+			"abbr":       nil,
+			"b":          nil,
+			"blockquote": {"cite"},
+			"br":         nil,
+			"code":       {"style"},
+			"dd":         nil,
+			"del":        nil,
+			"details":    {"open"},
+			"div":        nil,
+			"dl":         nil,
+			"dt":         nil,
+			"em":         nil,
+			"h1":         headings,
+			"h2":         headings,
+			"h3":         headings,
+			"h4":         headings,
+			"h5":         headings,
+			"h6":         headings,
+			"hr":         nil,
+			"i":          nil,
+			"img":        {"src", "alt", "width", "height"},
+			"input":      {"type", "checked", "disabled"},
+			"ins":        nil,
+			"kbd":        nil,
+			"li":         {"id"},
+			"mark":       nil,
+			"nav":        nil,
+			"ol":         {"start"},
+			"p":          nil,
+			"pre":        {"style"},
+			"s":          nil,
+			"small":      nil,
+			"span":       {"style"},
+			"strong":     nil,
+			"sub":        nil,
+			"summary":    nil,
+			"sup":        {"id"},
+			"table":      nil,
+			"tbody":      nil,
+			"td":         cells,
+			"tfoot":      nil,
+			"th":         cells,
+			"thead":      nil,
+			"tr":         nil,
+			"u":          nil,
+			"ul":         nil,
+		},
+		Attributes: []string{"class", "title"},
+		// Links to attachments are left as is if they can't be resolved
+		URLSchemes: []string{"http", "https", "mailto", strings.TrimSuffix(AttachmentScheme, ":")},
+		// Properties that are used by code highlighter
+		StyleProperties: []string{
+			"color",
+			"background-color",
+			"font-weight",
+			"font-style",
+			"text-decoration",
+			"display",
+			"width",
+			"margin-right",
+			"padding",
+			"tab-size",
+			"-moz-tab-size",
+			"-o-tab-size",
+		},
+	}
+}
+
+// defaultPolicy is used for rendering when no policy is set.
+var defaultPolicy = DefaultPolicy()
+
+// Sanitize removes elements and attributes that are not allowed
+// by the policy. Comments and doctypes are removed too.
+func (p *Policy) Sanitize(s string) string {
+	var b strings.Builder
+	z := html.NewTokenizer(strings.NewReader(s))
+	// Contents of scripts and styles are a single text token
+	skipText := false
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			// Reading from string fails only at the end
+			return b.String()
+		}
+
+		switch tt {
+		case html.TextToken:
+			if !skipText {
+				b.WriteString(htmlEscaper.Replace(string(z.Text())))
+			}
+			skipText = false
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tok := z.Token()
+			skipText = tt == html.StartTagToken &&
+				(tok.Data == "script" || tok.Data == "style")
+			if _, ok := p.Elements[tok.Data]; !ok || !p.allowInput(tok) {
+				continue
+			}
+			b.WriteString("<" + tok.Data)
+			for _, a := range tok.Attr {
+				if val, ok := p.attribute(tok.Data, a); ok {
+					b.WriteString(" " + a.Key + `="` + htmlEscaper.Replace(val) + `"`)
+				}
+			}
+			if tt == html.SelfClosingTagToken {
+				b.WriteString(" />")
+			} else {
+				b.WriteString(">")
+			}
+		case html.EndTagToken:
+			skipText = false
+			name, _ := z.TagName()
+			if _, ok := p.Elements[string(name)]; ok {
+				b.WriteString("</" + string(name) + ">")
+			}
+		default:
+			skipText = false
+		}
+	}
+}
+
+// attribute checks if the attribute is allowed on the element,
+// and cleans up its value.
+func (p *Policy) attribute(element string, a html.Attribute) (string, bool) {
+	if a.Namespace != "" || !(contains(p.Elements[element], a.Key) || contains(p.Attributes, a.Key)) {
+		return "", false
+	}
+	switch a.Key {
+	case "href", "src", "cite":
+		return a.Val, p.allowURL(a.Val)
+	case "style":
+		val := p.style(a.Val)
+		return val, val != ""
+	case "class":
+		return a.Val, classValue.MatchString(a.Val)
+	case "type":
+		return a.Val, element != "input" || a.Val == "checkbox"
+	}
+	return a.Val, true
+}
+
+// allowInput checks that inputs are checkboxes of task lists, other
+// elements are always allowed.
+func (p *Policy) allowInput(tok html.Token) bool {
+	if tok.Data != "input" {
+		return true
+	}
+	for _, a := range tok.Attr {
+		if a.Key == "type" && a.Namespace == "" {
+			_, ok := p.attribute(tok.Data, a)
+			return ok
+		}
+	}
+	return false
+}
+
+// allowURL checks if the URL is relative or has an allowed scheme.
+// URLs that browsers might interpret differently are not allowed.
+func (p *Policy) allowURL(s string) bool {
+	u, err := url.Parse(s)
+	if err != nil {
+		return false
+	}
+	if u.Scheme == "" {
+		// Browsers ignore some characters that are not allowed
+		// in URLs, so a colon before the path is treated as a scheme
+		colon := strings.Index(s, ":")
+		slash := strings.IndexAny(s, "/?#")
+		return colon < 0 || (slash >= 0 && slash < colon)
+	}
+	return contains(p.URLSchemes, strings.ToLower(u.Scheme))
+}
+
+// style removes declarations that are not allowed from the value
+// of style attribute.
+func (p *Policy) style(s string) string {
+	var clean []string
+	for _, decl := range strings.Split(s, ";") {
+		parts := strings.SplitN(decl, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		prop := strings.ToLower(strings.TrimSpace(parts[0]))
+		val := strings.TrimSpace(parts[1])
+		if contains(p.StyleProperties, prop) && styleValue.MatchString(val) {
+			clean = append(clean, prop+":"+val)
+		}
+	}
+	return strings.Join(clean, ";")
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,172 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/html"
+)
+
+// xssPayloads are known XSS vectors, mostly from the OWASP filter
+// evasion cheat sheet.
+var xssPayloads = []string{
+	`<script>alert(1)</script>`,
+	`<SCRIPT SRC=http://xss.rocks/xss.js></SCRIPT>`,
+	`<script>alert("</p><img src=x onerror=alert(1)>")</script>`,
+	`<IMG SRC="javascript:alert('XSS');">`,
+	`<IMG SRC=JaVaScRiPt:alert('XSS')>`,
+	"<IMG SRC=`javascript:alert(\"RSnake says, 'XSS'\")`>",
+	`<IMG """><SCRIPT>alert("XSS")</SCRIPT>"\>`,
+	`<IMG SRC=javascript:alert(String.fromCharCode(88,83,83))>`,
+	`<IMG SRC=# onmouseover="alert('xxs')">`,
+	`<IMG SRC=/ onerror="alert(String.fromCharCode(88,83,83))"></img>`,
+	`<img src=x onerror="&#0000106&#0000097&#0000118&#0000097&#0000115&#0000099&#0000114&#0000105&#0000112&#0000116&#0000058&#0000097&#0000108&#0000101&#0000114&#0000116&#0000040&#0000039&#0000088&#0000083&#0000083&#0000039&#0000041">`,
+	`<IMG SRC=&#106;&#97;&#118;&#97;&#115;&#99;&#114;&#105;&#112;&#116;&#58;&#97;&#108;&#101;&#114;&#116;&#40;&#39;&#88;&#83;&#83;&#39;&#41;>`,
+	`<IMG SRC=&#x6A&#x61&#x76&#x61&#x73&#x63&#x72&#x69&#x70&#x74&#x3A&#x61&#x6C&#x65&#x72&#x74&#x28&#x27&#x58&#x53&#x53&#x27&#x29>`,
+	`<IMG SRC="jav	ascript:alert('XSS');">`,
+	`<IMG SRC="jav&#x09;ascript:alert('XSS');">`,
+	`<IMG SRC="jav&#x0A;ascript:alert('XSS');">`,
+	`<IMG SRC=" &#14;  javascript:alert('XSS');">`,
+	`<SCRIPT/XSS SRC="http://xss.rocks/xss.js"></SCRIPT>`,
+	`<BODY onload!#$%&()*~+-_.,:;?@[/|\]^` + "`" + `=alert("XSS")>`,
+	`<<SCRIPT>alert("XSS");//\<</SCRIPT>`,
+	`<SCRIPT SRC=http://xss.rocks/xss.js?< B >`,
+	`<IMG SRC="` + "`" + `<javascript:alert>` + "`" + `('XSS')"`,
+	`<iframe src=http://xss.rocks/scriptlet.html <`,
+	`</TITLE><SCRIPT>alert("XSS");</SCRIPT>`,
+	`<INPUT TYPE="IMAGE" SRC="javascript:alert('XSS');">`,
+	`<BODY BACKGROUND="javascript:alert('XSS')">`,
+	`<IMG DYNSRC="javascript:alert('XSS')">`,
+	`<STYLE>li {list-style-image: url("javascript:alert('XSS')");}</STYLE><UL><LI>XSS</br>`,
+	`<svg/onload=alert('XSS')>`,
+	`<svg><script>alert(1)</script></svg>`,
+	`<math><mtext><table><mglyph><style><img src=x onerror=alert(1)>`,
+	`<LINK REL="stylesheet" HREF="javascript:alert('XSS');">`,
+	`<META HTTP-EQUIV="refresh" CONTENT="0;url=javascript:alert('XSS');">`,
+	`<META HTTP-EQUIV="refresh" CONTENT="0;url=data:text/html base64,PHNjcmlwdD5hbGVydCgnWFNTJyk8L3NjcmlwdD4K">`,
+	`<TABLE BACKGROUND="javascript:alert('XSS')">`,
+	`<TABLE><TD BACKGROUND="javascript:alert('XSS')">`,
+	`<DIV STYLE="background-image: url(javascript:alert('XSS'))">`,
+	`<DIV STYLE="background-image:\0075\0072\006C\0028'\006a\0061\0076\0061\0073\0063\0072\0069\0070\0074\003a\0061\006c\0065\0072\0074\0028.1027\0058.1053\0053\0027\0029'\0029">`,
+	`<DIV STYLE="width: expression(alert('XSS'));">`,
+	`<span style="color: red; position: fixed; top: 0; left: 0; width: 100%; height: 100%">`,
+	`<span style="color:expression(alert(1))">x</span>`,
+	`<IMG STYLE="xss:expr/*XSS*/ession(alert('XSS'))">`,
+	`<BASE HREF="javascript:alert('XSS');//">`,
+	`<OBJECT TYPE="text/x-scriptlet" DATA="http://xss.rocks/scriptlet.html"></OBJECT>`,
+	`<EMBED SRC="data:image/svg+xml;base64,PHN2ZyB4bWxuczpzdmc9Imh0dH A6Ly93d3cudzMub3JnLzIwMDAvc3ZnIiB4bWxucz0iaHR0cDovL3d3dy53My5vcmcv MjAwMC9zdmciIHhtbG5zOnhsaW5rPSJodHRwOi8vd3d3LnczLm9yZy8xOTk5L3hs aW5rIiB2ZXJzaW9uPSIxLjAiIHg9IjAiIHk9IjAiIHdpZHRoPSIxOTQiIGhlaWdodD0iMjAw IiBpZD0ieHNzIj48c2NyaXB0IHR5cGU9InRleHQvZWNtYXNjcmlwdCI+YWxlcnQoIlh TUyIpOzwvc2NyaXB0Pjwvc3ZnPg==" type="image/svg+xml" AllowScriptAccess="always"></EMBED>`,
+	`<a href="javascript:alert(1)">click</a>`,
+	`<a href="JAVASCRIPT:alert(1)">click</a>`,
+	`<a href=" javascript:alert(1)">click</a>`,
+	`<a href="java&#09;script:alert(1)">click</a>`,
+	`<a href="vbscript:msgbox(1)">click</a>`,
+	`<a href="data:text/html;base64,PHNjcmlwdD5hbGVydCgxKTwvc2NyaXB0Pg==">click</a>`,
+	`<a href="javascript&colon;alert(1)">click</a>`,
+	`<a href="x" onclick="alert(1)">click</a>`,
+	`<a href="/x" class="a" id="b" target="_top" xlink:href="javascript:alert(1)">click</a>`,
+	`<form action="javascript:alert(1)"><button>click</button></form>`,
+	`<details open ontoggle=alert(1)>`,
+	`<textarea><img src=x onerror=alert(1)></textarea>`,
+	`<noscript><p title="</noscript><img src=x onerror=alert(1)>">`,
+	`<title><img src=x onerror=alert(1)></title>`,
+	`<xmp><img src=x onerror=alert(1)></xmp>`,
+	`<!--<img src="--><img src=x onerror=alert(1)//">`,
+	`<![CDATA[<img src=x onerror=alert(1)>]]>`,
+	`<img src="x" alt="a"onerror="alert(1)">`,
+	`<img src="x` + "\x00" + `" onerror="alert(1)">`,
+	`<p class="x&quot; onclick=&quot;alert(1)">x</p>`,
+	`<p class='"><script>alert(1)</script>'>x</p>`,
+	`[click](javascript:alert(1))`,
+	`[click](JaVaScRiPt:alert(1))`,
+	`[click](vbscript:alert(1))`,
+	`![img](javascript:alert(1))`,
+	`[click](data:text/html;base64,PHNjcmlwdD5hbGVydCgxKTwvc2NyaXB0Pg==)`,
+	`<javascript:alert(1)>`,
+	`[[<img src=x onerror=alert(1)>]]`,
+}
+
+// assertSafe checks that HTML has only allowed elements and attributes,
+// and that URLs don't run scripts.
+func assertSafe(t *testing.T, s string) {
+	p := DefaultPolicy()
+	z := html.NewTokenizer(strings.NewReader(s))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			return
+		}
+		if tt == html.CommentToken || tt == html.DoctypeToken {
+			t.Errorf("unexpected %s in %q", tt, s)
+			continue
+		}
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			continue
+		}
+		tok := z.Token()
+		if _, ok := p.Elements[tok.Data]; !ok {
+			t.Errorf("unexpected element %q in %q", tok.Data, s)
+		}
+		for _, a := range tok.Attr {
+			if !contains(p.Elements[tok.Data], a.Key) && !contains(p.Attributes, a.Key) {
+				t.Errorf("unexpected attribute %q in %q", a.Key, s)
+			}
+			val := strings.ToLower(strings.Join(strings.Fields(a.Val), ""))
+			for _, bad := range []string{"script:", "data:", "expression", "url("} {
+				if strings.Contains(val, bad) {
+					t.Errorf("unsafe attribute value %q in %q", a.Val, s)
+				}
+			}
+		}
+	}
+}
+
+func TestPolicySanitize(t *testing.T) {
+	t.Run("xss payloads", func(t *testing.T) {
+		for _, payload := range xssPayloads {
+			for _, mode := range []RawHTML{RawHTMLSanitize, RawHTMLEscape} {
+				out := Render(payload, Options{RawHTML: mode})
+				assertSafe(t, out)
+				assert.NotContains(t, strings.ToLower(out), "<script", payload)
+			}
+			assertSafe(t, DefaultPolicy().Sanitize(payload))
+		}
+	})
+
+	t.Run("allowed markup", func(t *testing.T) {
+		in := `<p class="note-link" onclick="alert(1)">Hi &amp; <b>bye</b><br/>` +
+			`<a href="https://example.com/?a=1&amp;b=2" title="x">link</a> ` +
+			`<a href="/notes/1">note</a> <a href="#top">top</a> ` +
+			`<img src="attachment:10" alt="&quot;a&quot;"></p>`
+		out := `<p class="note-link">Hi &amp; <b>bye</b><br />` +
+			`<a href="https://example.com/?a=1&amp;b=2" title="x">link</a> ` +
+			`<a href="/notes/1">note</a> <a href="#top">top</a> ` +
+			`<img src="attachment:10" alt="&quot;a&quot;"></p>`
+		assert.Equal(t, out, DefaultPolicy().Sanitize(in))
+	})
+
+	t.Run("removed markup", func(t *testing.T) {
+		in := `<div><script>alert(1)</script><style>p {}</style><!-- x -->` +
+			`<iframe src="https://example.com"></iframe>` +
+			`<form><input value="x">text</form></div>`
+		out := `<div>text</div>`
+		assert.Equal(t, out, DefaultPolicy().Sanitize(in))
+	})
+
+	t.Run("styles", func(t *testing.T) {
+		in := `<span style="color: #fff; position: fixed; background: url(x.png); ` +
+			`font-weight:bold">x</span><span style="position:fixed">y</span>`
+		out := `<span style="color:#fff;font-weight:bold">x</span><span>y</span>`
+		assert.Equal(t, out, DefaultPolicy().Sanitize(in))
+	})
+
+	t.Run("custom policy", func(t *testing.T) {
+		p := &Policy{
+			Elements:   map[string][]string{"a": {"href"}},
+			URLSchemes: []string{"https"},
+		}
+		in := `<p><a href="https://example.com" class="x">a</a> <a href="http://example.com">b</a></p>`
+		out := `<a href="https://example.com">a</a> <a>b</a>`
+		assert.Equal(t, out, p.Sanitize(in))
+	})
+}
@@ -0,0 +1,45 @@
+package markdown
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// wikiLinkExamples are examples of the spec that conflict with syntax
+// of wiki links, double brackets are not links or text there.
+var wikiLinkExamples = map[int]bool{547: true, 558: true, 589: true}
+
+// TestCommonMarkSpec checks that the renderer without extensions
+// conforms to CommonMark 0.30 (testdata/commonmark_spec.json). Output
+// of the parser is checked before sanitizing and highlighting.
+func TestCommonMarkSpec(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/commonmark_spec.json")
+	if err != nil {
+		t.Fatalf("Failed to read spec: %v", err)
+	}
+	var examples []struct {
+		Markdown string `json:"markdown"`
+		HTML     string `json:"html"`
+		Example  int    `json:"example"`
+		Section  string `json:"section"`
+	}
+	if err = json.Unmarshal(data, &examples); err != nil {
+		t.Fatalf("Failed to parse spec: %v", err)
+	}
+
+	r := NewCommonMark(Config{})
+	for _, e := range examples {
+		if wikiLinkExamples[e.Example] {
+			continue
+		}
+		doc, source := r.parse(e.Markdown, Options{})
+		var buf bytes.Buffer
+		assert.NoError(t, r.md.Renderer().Render(&buf, source, doc))
+		html := buf.String()
+		assert.Equal(t, e.HTML, html, "example %d (%s)", e.Example, e.Section)
+	}
+}
@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"strconv"
+	"time"
+)
+
+// BlobStore keeps binary objects, like contents of attached files.
+type BlobStore interface {
+	// Put saves object under the key, overwriting the existing one
+	Put(key string, r io.Reader) error
+	// Get opens object for reading, returns domain.ErrNotFound
+	// if there is no such object
+	Get(key string) (Blob, error)
+	// Delete deletes object, it's not an error if there is no such object
+	Delete(key string) error
+}
+
+// Blob is a binary object from the store. Seeking is required to
+// serve range requests.
+type Blob interface {
+	io.ReadSeeker
+	io.Closer
+}
+
+// BlobPresigner is implemented by blob stores that can make temporary
+// links for downloading blobs directly from the store.
+type BlobPresigner interface {
+	Presign(key string, ttl time.Duration, h BlobHeaders) (string, error)
+}
+
+// BlobHeaders are response headers for downloading a blob.
+type BlobHeaders struct {
+	ContentType        string
+	ContentDisposition string
+}
+
+// NewBlobKey makes random key for saving file of the user to blob store.
+func NewBlobKey(userID int) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return strconv.Itoa(userID) + "/" + hex.EncodeToString(b), nil
+}
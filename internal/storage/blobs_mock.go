@@ -0,0 +1,169 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/storage/blobs.go
+
+// Package storage is a generated GoMock package.
+package storage
+
+import (
+	gomock "github.com/golang/mock/gomock"
+	io "io"
+	reflect "reflect"
+	time "time"
+)
+
+// MockBlobStore is a mock of BlobStore interface
+type MockBlobStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockBlobStoreMockRecorder
+}
+
+// MockBlobStoreMockRecorder is the mock recorder for MockBlobStore
+type MockBlobStoreMockRecorder struct {
+	mock *MockBlobStore
+}
+
+// NewMockBlobStore creates a new mock instance
+func NewMockBlobStore(ctrl *gomock.Controller) *MockBlobStore {
+	mock := &MockBlobStore{ctrl: ctrl}
+	mock.recorder = &MockBlobStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockBlobStore) EXPECT() *MockBlobStoreMockRecorder {
+	return m.recorder
+}
+
+// Put mocks base method
+func (m *MockBlobStore) Put(key string, r io.Reader) error {
+	ret := m.ctrl.Call(m, "Put", key, r)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Put indicates an expected call of Put
+func (mr *MockBlobStoreMockRecorder) Put(key, r interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Put", reflect.TypeOf((*MockBlobStore)(nil).Put), key, r)
+}
+
+// Get mocks base method
+func (m *MockBlobStore) Get(key string) (Blob, error) {
+	ret := m.ctrl.Call(m, "Get", key)
+	ret0, _ := ret[0].(Blob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get
+func (mr *MockBlobStoreMockRecorder) Get(key interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockBlobStore)(nil).Get), key)
+}
+
+// Delete mocks base method
+func (m *MockBlobStore) Delete(key string) error {
+	ret := m.ctrl.Call(m, "Delete", key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockBlobStoreMockRecorder) Delete(key interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockBlobStore)(nil).Delete), key)
+}
+
+// MockBlob is a mock of Blob interface
+type MockBlob struct {
+	ctrl     *gomock.Controller
+	recorder *MockBlobMockRecorder
+}
+
+// MockBlobMockRecorder is the mock recorder for MockBlob
+type MockBlobMockRecorder struct {
+	mock *MockBlob
+}
+
+// NewMockBlob creates a new mock instance
+func NewMockBlob(ctrl *gomock.Controller) *MockBlob {
+	mock := &MockBlob{ctrl: ctrl}
+	mock.recorder = &MockBlobMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockBlob) EXPECT() *MockBlobMockRecorder {
+	return m.recorder
+}
+
+// Read mocks base method
+func (m *MockBlob) Read(p []byte) (int, error) {
+	ret := m.ctrl.Call(m, "Read", p)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Read indicates an expected call of Read
+func (mr *MockBlobMockRecorder) Read(p interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockBlob)(nil).Read), p)
+}
+
+// Seek mocks base method
+func (m *MockBlob) Seek(offset int64, whence int) (int64, error) {
+	ret := m.ctrl.Call(m, "Seek", offset, whence)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Seek indicates an expected call of Seek
+func (mr *MockBlobMockRecorder) Seek(offset, whence interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Seek", reflect.TypeOf((*MockBlob)(nil).Seek), offset, whence)
+}
+
+// Close mocks base method
+func (m *MockBlob) Close() error {
+	ret := m.ctrl.Call(m, "Close")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close
+func (mr *MockBlobMockRecorder) Close() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockBlob)(nil).Close))
+}
+
+// MockBlobPresigner is a mock of BlobPresigner interface
+type MockBlobPresigner struct {
+	ctrl     *gomock.Controller
+	recorder *MockBlobPresignerMockRecorder
+}
+
+// MockBlobPresignerMockRecorder is the mock recorder for MockBlobPresigner
+type MockBlobPresignerMockRecorder struct {
+	mock *MockBlobPresigner
+}
+
+// NewMockBlobPresigner creates a new mock instance
+func NewMockBlobPresigner(ctrl *gomock.Controller) *MockBlobPresigner {
+	mock := &MockBlobPresigner{ctrl: ctrl}
+	mock.recorder = &MockBlobPresignerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockBlobPresigner) EXPECT() *MockBlobPresignerMockRecorder {
+	return m.recorder
+}
+
+// Presign mocks base method
+func (m *MockBlobPresigner) Presign(key string, ttl time.Duration, h BlobHeaders) (string, error) {
+	ret := m.ctrl.Call(m, "Presign", key, ttl, h)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Presign indicates an expected call of Presign
+func (mr *MockBlobPresignerMockRecorder) Presign(key, ttl, h interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Presign", reflect.TypeOf((*MockBlobPresigner)(nil).Presign), key, ttl, h)
+}
@@ -0,0 +1,99 @@
+// Package filesystem implements blob store on top of local file system.
+package filesystem
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/tetafro/nott-backend-go/internal/domain"
+	"github.com/tetafro/nott-backend-go/internal/storage"
+)
+
+// BlobStore keeps blobs as files in a directory. Keys are used as
+// relative file paths.
+type BlobStore struct {
+	dir string
+}
+
+// NewBlobStore creates new blob store in the directory. The directory
+// is created if it doesn't exist.
+func NewBlobStore(dir string) (*BlobStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, errors.Wrap(err, "create directory")
+	}
+	return &BlobStore{dir: dir}, nil
+}
+
+// Put saves blob to a file. Data is written to a temporary file first,
+// so readers never see partially written blobs.
+func (s *BlobStore) Put(key string, r io.Reader) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return errors.Wrap(err, "create directory")
+	}
+
+	f, err := ioutil.TempFile(filepath.Dir(path), ".upload-")
+	if err != nil {
+		return errors.Wrap(err, "create temporary file")
+	}
+	defer os.Remove(f.Name()) // nolint: errcheck
+
+	if _, err = io.Copy(f, r); err != nil {
+		f.Close() // nolint: errcheck,gosec
+		return errors.Wrap(err, "write file")
+	}
+	if err = f.Close(); err != nil {
+		return errors.Wrap(err, "close file")
+	}
+	if err = os.Rename(f.Name(), path); err != nil {
+		return errors.Wrap(err, "rename file")
+	}
+	return nil
+}
+
+// Get opens blob file.
+func (s *BlobStore) Get(key string) (storage.Blob, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path) // nolint: gosec
+	if os.IsNotExist(err) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "open file")
+	}
+	return f, nil
+}
+
+// Delete deletes blob file.
+func (s *BlobStore) Delete(key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "remove file")
+	}
+	return nil
+}
+
+// path converts key to file path, making sure it stays inside
+// the store directory.
+func (s *BlobStore) path(key string) (string, error) {
+	clean := filepath.Clean("/" + key)
+	if key == "" || clean == "/" || strings.HasPrefix(filepath.Base(clean), ".") {
+		return "", errors.Errorf("invalid key %q", key)
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(clean)), nil
+}
@@ -0,0 +1,58 @@
+package filesystem
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tetafro/nott-backend-go/internal/domain"
+)
+
+func TestBlobStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "blobs")
+	if err != nil {
+		t.Fatalf("Failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir) // nolint: errcheck
+
+	s, err := NewBlobStore(filepath.Join(dir, "store"))
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+
+	t.Run("put, get and delete blob", func(t *testing.T) {
+		err := s.Put("1/abc", bytes.NewBufferString("hello, world"))
+		assert.NoError(t, err)
+
+		b, err := s.Get("1/abc")
+		if !assert.NoError(t, err) {
+			return
+		}
+		data, err := ioutil.ReadAll(b)
+		assert.NoError(t, err)
+		assert.NoError(t, b.Close())
+		assert.Equal(t, "hello, world", string(data))
+
+		assert.NoError(t, s.Delete("1/abc"))
+		_, err = s.Get("1/abc")
+		assert.Equal(t, domain.ErrNotFound, err)
+	})
+
+	t.Run("delete non-existing blob", func(t *testing.T) {
+		assert.NoError(t, s.Delete("1/xyz"))
+	})
+
+	t.Run("keys cannot escape the directory", func(t *testing.T) {
+		err := s.Put("../../outside", bytes.NewBufferString("x"))
+		assert.NoError(t, err)
+		_, err = os.Stat(filepath.Join(dir, "store", "outside"))
+		assert.NoError(t, err)
+
+		assert.Error(t, s.Put("", bytes.NewBufferString("x")))
+		assert.Error(t, s.Put("1/.upload-x", bytes.NewBufferString("x")))
+	})
+}
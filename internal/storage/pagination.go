@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Fields that lists of objects can be sorted by.
+const (
+	SortTitle     = "title"
+	SortCreatedAt = "created_at"
+	SortUpdatedAt = "updated_at"
+)
+
+// Page defines a part of sorted list of objects.
+type Page struct {
+	// Limit is a maximum number of objects on the page, 0 for no limit
+	Limit int
+	Sort  Sort
+	// After points to the last object of the previous page
+	After *Cursor
+}
+
+// Validate validates page.
+func (p Page) Validate() error {
+	if p.Limit < 0 {
+		return errors.New("limit cannot be negative")
+	}
+	if p.After == nil {
+		return nil
+	}
+	if p.Sort.Field == SortTitle && p.After.Title == nil {
+		return errors.New("cursor doesn't match sort order")
+	}
+	if p.Sort.Field != SortTitle && p.After.Time == nil {
+		return errors.New("cursor doesn't match sort order")
+	}
+	return nil
+}
+
+// Sort is a sort order.
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+// ParseSort parses sort order from string. The string is a field name
+// prefixed with "-" for descending order, e.g. "title" or "-created_at".
+// Empty string means sorting by creation time.
+func ParseSort(s string) (Sort, error) {
+	var sort Sort
+	if strings.HasPrefix(s, "-") {
+		sort.Desc = true
+		s = s[1:]
+	}
+	switch s {
+	case "":
+		sort.Field = SortCreatedAt
+	case SortTitle, SortCreatedAt, SortUpdatedAt:
+		sort.Field = s
+	default:
+		return Sort{}, errors.Errorf("unknown sort field: %s", s)
+	}
+	return sort, nil
+}
+
+// Cursor is a position in a sorted list of objects. It holds ID and
+// the value of sort field of an object.
+type Cursor struct {
+	ID    int        `json:"id"`
+	Title *string    `json:"title,omitempty"`
+	Time  *time.Time `json:"time,omitempty"`
+}
+
+// NewCursor creates cursor that points to the object with given fields.
+// Objects that have never been updated are sorted by creation time
+// when sorting by update time.
+func NewCursor(s Sort, id int, title string, createdAt time.Time, updatedAt *time.Time) Cursor {
+	c := Cursor{ID: id}
+	switch {
+	case s.Field == SortTitle:
+		c.Title = &title
+	case s.Field == SortUpdatedAt && updatedAt != nil:
+		c.Time = updatedAt
+	default:
+		c.Time = &createdAt
+	}
+	return c
+}
+
+// DecodeCursor decodes cursor from opaque string.
+func DecodeCursor(s string) (Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, errors.Wrap(err, "decode base64")
+	}
+	var c Cursor
+	if err = json.Unmarshal(b, &c); err != nil {
+		return Cursor{}, errors.Wrap(err, "unmarshal json")
+	}
+	if c.ID == 0 || (c.Title == nil) == (c.Time == nil) {
+		return Cursor{}, errors.New("invalid cursor")
+	}
+	return c, nil
+}
+
+// Encode encodes cursor to opaque string.
+func (c Cursor) Encode() string {
+	b, err := json.Marshal(c)
+	if err != nil {
+		// Never happens, the struct only has plain fields
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSort(t *testing.T) {
+	cases := []struct {
+		title string
+		input string
+		sort  Sort
+		err   bool
+	}{
+		{
+			title: "default",
+			input: "",
+			sort:  Sort{Field: SortCreatedAt},
+		},
+		{
+			title: "ascending",
+			input: "title",
+			sort:  Sort{Field: SortTitle},
+		},
+		{
+			title: "descending",
+			input: "-updated_at",
+			sort:  Sort{Field: SortUpdatedAt, Desc: true},
+		},
+		{
+			title: "unknown field",
+			input: "-text",
+			err:   true,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.title, func(t *testing.T) {
+			sort, err := ParseSort(tt.input)
+			if tt.err {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.sort, sort)
+			}
+		})
+	}
+}
+
+func TestCursor(t *testing.T) {
+	created := time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)
+	updated := created.Add(time.Hour)
+
+	t.Run("encode and decode title cursor", func(t *testing.T) {
+		c := NewCursor(Sort{Field: SortTitle}, 10, "Hello", created, &updated)
+		d, err := DecodeCursor(c.Encode())
+		assert.NoError(t, err)
+		assert.Equal(t, c, d)
+		assert.Equal(t, "Hello", *d.Title)
+		assert.Nil(t, d.Time)
+	})
+
+	t.Run("encode and decode time cursor", func(t *testing.T) {
+		c := NewCursor(Sort{Field: SortUpdatedAt}, 10, "Hello", created, &updated)
+		d, err := DecodeCursor(c.Encode())
+		assert.NoError(t, err)
+		assert.Equal(t, 10, d.ID)
+		assert.True(t, updated.Equal(*d.Time))
+	})
+
+	t.Run("use creation time for objects without updates", func(t *testing.T) {
+		c := NewCursor(Sort{Field: SortUpdatedAt}, 10, "Hello", created, nil)
+		assert.True(t, created.Equal(*c.Time))
+	})
+
+	t.Run("fail to decode malformed cursor", func(t *testing.T) {
+		_, err := DecodeCursor("qwerty")
+		assert.Error(t, err)
+	})
+
+	t.Run("fail to decode empty cursor", func(t *testing.T) {
+		_, err := DecodeCursor(Cursor{}.Encode())
+		assert.Error(t, err)
+	})
+
+	t.Run("fail to use cursor with another sort order", func(t *testing.T) {
+		c := NewCursor(Sort{Field: SortTitle}, 10, "Hello", created, nil)
+		p := Page{Sort: Sort{Field: SortCreatedAt}, After: &c}
+		assert.Error(t, p.Validate())
+	})
+}
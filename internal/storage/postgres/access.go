@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+
+	"github.com/tetafro/nott-backend-go/internal/domain"
+)
+
+// Conditions for selecting objects that are available to a user with
+// at least the given role. The user either owns the object, or it's
+// shared with them directly or through one of the parent folders.
+// Arguments are: user ID, user ID, role.
+const (
+	folderAccess = "user_id = ? OR id IN " +
+		"(SELECT id FROM shared_folders(?) WHERE role >= ?)"
+	notepadAccess = "user_id = ? OR id IN " +
+		"(SELECT id FROM shared_notepads(?) WHERE role >= ?)"
+	noteAccess = "user_id = ? OR notepad_id IN " +
+		"(SELECT id FROM shared_notepads(?) WHERE role >= ?)"
+)
+
+// accessArgs makes arguments for access conditions.
+func accessArgs(userID int, role domain.Role) []interface{} {
+	return []interface{}{userID, userID, role}
+}
+
+// folderRole gets role of the user on the folder.
+func folderRole(tx *gorm.DB, f domain.Folder, userID int) (domain.Role, error) {
+	if f.UserID == userID {
+		return domain.RoleOwner, nil
+	}
+	return sharedRole(tx, "shared_folders", f.ID, userID)
+}
+
+// notepadRole gets role of the user on the notepad.
+func notepadRole(tx *gorm.DB, n domain.Notepad, userID int) (domain.Role, error) {
+	if n.UserID == userID {
+		return domain.RoleOwner, nil
+	}
+	return sharedRole(tx, "shared_notepads", n.ID, userID)
+}
+
+// noteRole gets role of the user on the note, which is the same
+// as the role on its notepad.
+func noteRole(tx *gorm.DB, n domain.Note, userID int) (domain.Role, error) {
+	if n.UserID == userID {
+		return domain.RoleOwner, nil
+	}
+	return sharedRole(tx, "shared_notepads", n.NotepadID, userID)
+}
+
+// sharedRole gets role of the user on the object of another user
+// using one of the shared_* database functions.
+func sharedRole(tx *gorm.DB, function string, id, userID int) (domain.Role, error) {
+	var r struct {
+		Role domain.Role `gorm:"column:role"`
+	}
+	err := tx.Raw("SELECT COALESCE(MAX(role), 0) AS role FROM "+function+"(?) WHERE id = ?", userID, id).
+		Scan(&r).
+		Error
+	if err != nil {
+		return domain.RoleNone, errors.Wrap(err, "query error")
+	}
+	return r.Role, nil
+}
+
+// requireRole checks if the role allows the action. Objects that are
+// not available to the user at all are reported as missing, so their
+// existence is not revealed.
+func requireRole(have, need domain.Role, missing error) error {
+	if have == domain.RoleNone {
+		return missing
+	}
+	if have < need {
+		return domain.ErrForbidden
+	}
+	return nil
+}
+
+// sameParent checks if the parent is not changed.
+func sameParent(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
@@ -0,0 +1,35 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tetafro/nott-backend-go/internal/domain"
+)
+
+func TestSharedRole(t *testing.T) {
+	t.Run("Get role on a folder in a cycle", func(t *testing.T) {
+		db := testDB(t)
+		defer db.Close() // nolint: errcheck
+		migrateTo(t, db, 0)
+		execFile(t, db, "testdata/folder_cycles.sql")
+
+		// Folder 5 of the cycle is shared with another user
+		err := db.Exec(
+			"INSERT INTO \"user\" (id, email, password, created_at) VALUES (2, 'eve@example.com', 'hash', NOW());" +
+				"INSERT INTO \"grant\" (user_id, folder_id, role, invited_by, accepted_at, created_at) " +
+				"VALUES (2, 5, 2, 1, NOW(), NOW())",
+		).Error
+		assert.NoError(t, err)
+
+		for _, id := range []int{4, 5, 6, 7} {
+			role, err := folderRole(db, domain.Folder{ID: id, UserID: 1}, 2)
+			assert.NoError(t, err)
+			assert.Equal(t, domain.RoleEditor, role)
+		}
+		role, err := folderRole(db, domain.Folder{ID: 1, UserID: 1}, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.RoleNone, role)
+	})
+}
@@ -0,0 +1,164 @@
+package postgres
+
+import (
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+
+	"github.com/tetafro/nott-backend-go/internal/domain"
+	"github.com/tetafro/nott-backend-go/internal/storage"
+)
+
+// AttachmentsRepo is an attachments repository that uses PostgreSQL
+// as a backend. Only metadata is kept here, contents of the files
+// are in a blob store.
+type AttachmentsRepo struct {
+	db *gorm.DB
+}
+
+// NewAttachmentsRepo creates new PostgreSQL repository for attachments.
+func NewAttachmentsRepo(db *gorm.DB) *AttachmentsRepo {
+	return &AttachmentsRepo{db: db}
+}
+
+// Get gets attachments from repository. Attachments of notes
+// in trash are skipped. Filtering by user gets attachments of all
+// notes that are available to the user.
+func (r *AttachmentsRepo) Get(f storage.AttachmentsFilter) ([]domain.Attachment, error) {
+	aa := []domain.Attachment{}
+
+	q := r.db.Select("attachment.*").
+		Joins("JOIN note ON note.id = attachment.note_id AND note.deleted_at IS NULL")
+	if f.ID != nil {
+		q = q.Where("attachment.id = ?", *f.ID)
+	}
+	if f.UserID != nil {
+		q = q.Where("attachment.note_id IN (SELECT id FROM note WHERE "+noteAccess+")",
+			accessArgs(*f.UserID, domain.RoleViewer)...)
+	}
+	if f.NoteID != nil {
+		q = q.Where("attachment.note_id = ?", *f.NoteID)
+	}
+
+	if err := q.Order("attachment.id").Find(&aa).Error; err != nil {
+		return nil, errors.Wrap(err, "query error")
+	}
+
+	return aa, nil
+}
+
+// Usage gets total size of user's attachments in bytes.
+func (r *AttachmentsRepo) Usage(userID int) (int64, error) {
+	return attachmentsUsage(r.db, userID)
+}
+
+// Create creates attachment in repository. Returns domain.ErrQuotaExceeded
+// if total size of user's attachments becomes larger than the quota
+// (0 for no limit). The user must be able to edit the note, the size
+// of the attachment counts towards their quota even if the note belongs
+// to another user.
+func (r *AttachmentsRepo) Create(a domain.Attachment, quota int64) (domain.Attachment, error) {
+	err := transact(r.db, func(tx *gorm.DB) (err error) {
+		// Concurrent uploads of the same user must not exceed the quota
+		err = tx.Exec("SELECT pg_advisory_xact_lock(hashtext('attachment'), ?)", a.UserID).Error
+		if err != nil {
+			return errors.Wrap(err, "lock attachments")
+		}
+
+		var note domain.Note
+		err = tx.Set("gorm:query_option", "FOR SHARE").
+			Select("id, user_id, notepad_id").
+			Where("id = ?", a.NoteID).
+			Find(&note).
+			Error
+		if err == gorm.ErrRecordNotFound {
+			return domain.ErrNotFound
+		}
+		if err != nil {
+			return errors.Wrap(err, "check note in database")
+		}
+		role, err := noteRole(tx, note, a.UserID)
+		if err != nil {
+			return errors.Wrap(err, "get role")
+		}
+		if err = requireRole(role, domain.RoleEditor, domain.ErrNotFound); err != nil {
+			return err
+		}
+
+		if quota > 0 {
+			used, err := attachmentsUsage(tx, a.UserID)
+			if err != nil {
+				return errors.Wrap(err, "get usage")
+			}
+			if used+a.Size > quota {
+				return domain.ErrQuotaExceeded
+			}
+		}
+
+		if err = tx.Create(&a).Error; err != nil {
+			return errors.Wrap(err, "query error")
+		}
+		return nil
+	})
+	if err != nil {
+		return domain.Attachment{}, err
+	}
+	return a, nil
+}
+
+// Delete deletes attachment from repository. The blob is deleted later,
+// see DeletedKeys. UserID of the attachment is the user who deletes it,
+// it can be the uploader or anyone who can edit the note.
+func (r *AttachmentsRepo) Delete(a domain.Attachment) error {
+	err := r.db.Where("id = ?", a.ID).
+		Where("user_id = ? OR note_id IN (SELECT id FROM note WHERE "+noteAccess+")",
+			append([]interface{}{a.UserID}, accessArgs(a.UserID, domain.RoleEditor)...)...).
+		Delete(&domain.Attachment{}).
+		Error
+	if err != nil {
+		return errors.Wrap(err, "query error")
+	}
+	return nil
+}
+
+// DeletedKeys gets blob keys of deleted attachments, oldest first.
+// Attachments are also deleted together with their notes, so the keys
+// are collected by a database trigger.
+func (r *AttachmentsRepo) DeletedKeys(limit int) ([]string, error) {
+	keys := []string{}
+	err := r.db.Table("attachment_deleted").
+		Order("deleted_at").
+		Limit(limit).
+		Pluck("key", &keys).
+		Error
+	if err != nil {
+		return nil, errors.Wrap(err, "query error")
+	}
+	return keys, nil
+}
+
+// ForgetKeys removes keys from the list of deleted attachments.
+func (r *AttachmentsRepo) ForgetKeys(keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	err := r.db.Exec("DELETE FROM attachment_deleted WHERE key IN (?)", keys).Error
+	if err != nil {
+		return errors.Wrap(err, "query error")
+	}
+	return nil
+}
+
+func attachmentsUsage(db *gorm.DB, userID int) (int64, error) {
+	var usage struct {
+		Size int64 `gorm:"column:size"`
+	}
+	err := db.Table("attachment").
+		Select("COALESCE(SUM(size), 0) AS size").
+		Where("user_id = ?", userID).
+		Scan(&usage).
+		Error
+	if err != nil {
+		return 0, errors.Wrap(err, "query error")
+	}
+	return usage.Size, nil
+}
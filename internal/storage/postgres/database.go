@@ -45,13 +45,9 @@ func Connect(conn string, log logrus.FieldLogger, debug bool) (*gorm.DB, error)
 // Migrate applies migration from the given directory
 // to the database.
 func Migrate(db *gorm.DB, migrations string) error {
-	drv, err := postgres.WithInstance(db.DB(), &postgres.Config{})
-	if err != nil {
-		return errors.Wrap(err, "init driver")
-	}
-	m, err := migrate.NewWithDatabaseInstance("file://"+migrations, "postgres", drv)
+	m, err := newMigrator(db, migrations)
 	if err != nil {
-		return errors.Wrap(err, "init migrator")
+		return err
 	}
 	if err = m.Up(); err != nil && err != migrate.ErrNoChange {
 		return errors.Wrap(err, "migration error")
@@ -59,6 +55,19 @@ func Migrate(db *gorm.DB, migrations string) error {
 	return nil
 }
 
+// newMigrator creates migrator with migrations from the directory.
+func newMigrator(db *gorm.DB, migrations string) (*migrate.Migrate, error) {
+	drv, err := postgres.WithInstance(db.DB(), &postgres.Config{})
+	if err != nil {
+		return nil, errors.Wrap(err, "init driver")
+	}
+	m, err := migrate.NewWithDatabaseInstance("file://"+migrations, "postgres", drv)
+	if err != nil {
+		return nil, errors.Wrap(err, "init migrator")
+	}
+	return m, nil
+}
+
 // updateTimeStampForCreateCallback will set CreatedAt when creating.
 func updateTimeStampForCreateCallback(scope *gorm.Scope) {
 	if scope.HasError() {
@@ -0,0 +1,80 @@
+package postgres
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang-migrate/migrate"
+	"github.com/jinzhu/gorm"
+	"github.com/sirupsen/logrus"
+)
+
+// testMigrations is a directory with migrations relative to the package.
+const testMigrations = "../../../migrations"
+
+// testDB connects to the test database, e.g. started with:
+//
+//	docker run -p 5432:5432 -e POSTGRES_PASSWORD=postgres postgres:10
+//
+// and set as POSTGRES_TEST_CONN="host=localhost user=postgres
+// password=postgres sslmode=disable". Everything in the database
+// is deleted. Tests are skipped if the database is not set.
+func testDB(t *testing.T) *gorm.DB {
+	conn := os.Getenv("POSTGRES_TEST_CONN")
+	if conn == "" {
+		t.Skip("POSTGRES_TEST_CONN is not set")
+	}
+
+	log := logrus.New()
+	log.Out = ioutil.Discard
+	db, err := Connect(conn, log, false)
+	if err != nil {
+		t.Fatalf("Failed to connect to database: %v", err)
+	}
+	if err = db.Exec("DROP SCHEMA public CASCADE; CREATE SCHEMA public").Error; err != nil {
+		t.Fatalf("Failed to clean database: %v", err)
+	}
+	return db
+}
+
+// migrateTo applies migrations up to the version, or all of them
+// if the version is 0.
+func migrateTo(t *testing.T, db *gorm.DB, version uint) {
+	m, err := newMigrator(db, testMigrations)
+	if err != nil {
+		t.Fatalf("Failed to init migrations: %v", err)
+	}
+	if version == 0 {
+		err = m.Up()
+	} else {
+		err = m.Migrate(version)
+	}
+	if err != nil && err != migrate.ErrNoChange {
+		t.Fatalf("Failed to apply migrations: %v", err)
+	}
+}
+
+// execFile executes SQL statements from the file.
+func execFile(t *testing.T, db *gorm.DB, name string) {
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	if err = db.Exec(string(data)).Error; err != nil {
+		t.Fatalf("Failed to execute %s: %v", name, err)
+	}
+}
+
+// updatedAt gets update time of the object in the table.
+func updatedAt(t *testing.T, db *gorm.DB, table string, id int) *time.Time {
+	var row struct {
+		UpdatedAt *time.Time `gorm:"column:updated_at"`
+	}
+	err := db.Table(table).Select("updated_at").Where("id = ?", id).Scan(&row).Error
+	if err != nil {
+		t.Fatalf("Failed to get update time: %v", err)
+	}
+	return row.UpdatedAt
+}
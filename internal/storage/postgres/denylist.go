@@ -0,0 +1,57 @@
+package postgres
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// Denylist is a list of revoked access tokens that uses PostgreSQL
+// as a backend.
+type Denylist struct {
+	db *gorm.DB
+}
+
+// NewDenylist creates new PostgreSQL denylist.
+func NewDenylist(db *gorm.DB) *Denylist {
+	return &Denylist{db: db}
+}
+
+// Revoke adds token to the list.
+func (d *Denylist) Revoke(id string, expiresAt time.Time) error {
+	err := d.db.Exec(
+		"INSERT INTO revoked_token (id, expires_at) VALUES (?, ?) "+
+			"ON CONFLICT DO NOTHING",
+		id, expiresAt.UTC(),
+	).Error
+	if err != nil {
+		return errors.Wrap(err, "query error")
+	}
+	return nil
+}
+
+// Revoked checks if the token is in the list.
+func (d *Denylist) Revoked(id string) (bool, error) {
+	var n int
+	err := d.db.Table("revoked_token").
+		Where("id = ?", id).
+		Count(&n).
+		Error
+	if err != nil {
+		return false, errors.Wrap(err, "query error")
+	}
+	return n > 0, nil
+}
+
+// DeleteExpired removes expired tokens, they are rejected anyway.
+func (d *Denylist) DeleteExpired() error {
+	err := d.db.Exec(
+		"DELETE FROM revoked_token WHERE expires_at < ?",
+		time.Now().UTC(),
+	).Error
+	if err != nil {
+		return errors.Wrap(err, "query error")
+	}
+	return nil
+}
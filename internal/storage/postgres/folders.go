@@ -1,6 +1,8 @@
 package postgres
 
 import (
+	"time"
+
 	"github.com/jinzhu/gorm"
 	"github.com/pkg/errors"
 
@@ -27,9 +29,11 @@ func (r *FoldersRepo) Get(f storage.FoldersFilter) ([]domain.Folder, error) {
 		q = q.Where("id = ?", *f.ID)
 	}
 	if f.UserID != nil {
-		q = q.Where("user_id = ?", *f.UserID)
+		q = q.Where(folderAccess, accessArgs(*f.UserID, domain.RoleViewer)...)
 	}
 
+	q = paginate(q, f.Page)
+
 	if err := q.Find(&ff).Error; err != nil {
 		return nil, errors.Wrap(err, "query error")
 	}
@@ -37,9 +41,28 @@ func (r *FoldersRepo) Get(f storage.FoldersFilter) ([]domain.Folder, error) {
 	return ff, nil
 }
 
-// Create creates folder in repository.
+// Create creates folder in repository. UserID of the folder is the user
+// who creates it, subfolders get the owner of the parent folder.
 func (r *FoldersRepo) Create(f domain.Folder) (domain.Folder, error) {
 	err := transact(r.db, func(tx *gorm.DB) (err error) {
+		if f.ParentID != nil {
+			parent, err := getParentFolder(tx, *f.ParentID)
+			if err != nil {
+				return err
+			}
+			role, err := folderRole(tx, parent, f.UserID)
+			if err != nil {
+				return errors.Wrap(err, "get role")
+			}
+			if err = requireRole(role, domain.RoleEditor, domain.ErrParentNotFound); err != nil {
+				return err
+			}
+			f.UserID = parent.UserID
+			if err = f.CheckParent(parent); err != nil {
+				return err
+			}
+		}
+
 		if err = tx.Create(&f).Error; err != nil {
 			return errors.Wrap(err, "query error")
 		}
@@ -51,19 +74,21 @@ func (r *FoldersRepo) Create(f domain.Folder) (domain.Folder, error) {
 	return f, nil
 }
 
-// Update updates folder in repository.
+// Update updates folder in repository. UserID of the folder is the user
+// who updates it.
 func (r *FoldersRepo) Update(f domain.Folder) (domain.Folder, error) {
 	err := transact(r.db, func(tx *gorm.DB) (err error) {
-		// Check if folder exists
-		err = tx.Select("id").
-			Where("id = ? AND user_id = ?", f.ID, f.UserID).
-			Find(&domain.Folder{}).
-			Error
-		if err == gorm.ErrRecordNotFound {
-			return domain.ErrNotFound
-		}
+		folder, err := getFolder(tx, f.ID, f.UserID)
 		if err != nil {
-			return errors.Wrap(err, "check folder in database")
+			return err
+		}
+
+		userID := f.UserID
+		f.UserID = folder.UserID
+		if !sameParent(folder.ParentID, f.ParentID) {
+			if err = setParentFolder(tx, f, userID); err != nil {
+				return err
+			}
 		}
 
 		// NOTE: Save() method doesn't return ErrRecordNotFound, but
@@ -81,17 +106,294 @@ func (r *FoldersRepo) Update(f domain.Folder) (domain.Folder, error) {
 	return f, nil
 }
 
-// Delete deletes folder in repository.
-func (r *FoldersRepo) Delete(f domain.Folder) error {
+// Move moves folder into another folder, or to the top level if
+// the parent is empty. UserID of the folder is the user who moves it.
+func (r *FoldersRepo) Move(f domain.Folder) (domain.Folder, error) {
+	var folder domain.Folder
 	err := transact(r.db, func(tx *gorm.DB) (err error) {
-		err = tx.Where("id = ? AND user_id = ?", f.ID, f.UserID).Delete(&domain.Folder{}).Error
+		folder, err = getFolder(tx, f.ID, f.UserID)
+		if err != nil {
+			return err
+		}
+
+		userID := f.UserID
+		f.UserID = folder.UserID
+		if sameParent(folder.ParentID, f.ParentID) {
+			return nil
+		}
+		if err = setParentFolder(tx, f, userID); err != nil {
+			return err
+		}
+
+		// Map updates skip the timestamp callback, so it's set here
+		err = tx.Model(&folder).Updates(map[string]interface{}{
+			"parent_id":  f.ParentID,
+			"updated_at": gorm.NowFunc(),
+		}).Error
 		if err != nil {
 			return errors.Wrap(err, "query error")
 		}
 		return nil
 	})
+	if err != nil {
+		return domain.Folder{}, err
+	}
+	return folder, nil
+}
+
+// getFolder gets folder that is going to be changed by the user, and
+// locks it until the end of transaction.
+func getFolder(tx *gorm.DB, id, userID int) (domain.Folder, error) {
+	var f domain.Folder
+	err := tx.Set("gorm:query_option", "FOR UPDATE").
+		Where("id = ?", id).
+		Find(&f).
+		Error
+	if err == gorm.ErrRecordNotFound {
+		return domain.Folder{}, domain.ErrNotFound
+	}
+	if err != nil {
+		return domain.Folder{}, errors.Wrap(err, "check folder in database")
+	}
+
+	role, err := folderRole(tx, f, userID)
+	if err != nil {
+		return domain.Folder{}, errors.Wrap(err, "get role")
+	}
+	if err = requireRole(role, domain.RoleEditor, domain.ErrNotFound); err != nil {
+		return domain.Folder{}, err
+	}
+	return f, nil
+}
+
+// setParentFolder checks if the folder can be put into its new parent.
+// The parent must be editable by the user and must not be the folder
+// itself or one of its subfolders. Only the owner can move the folder
+// to the top level.
+func setParentFolder(tx *gorm.DB, f domain.Folder, userID int) error {
+	// Row locks are not enough here: two concurrent moves of different
+	// folders can still make a cycle, so all changes of the owner's
+	// hierarchy are serialized until the end of transaction
+	err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext('folder'), ?)", f.UserID).Error
+	if err != nil {
+		return errors.Wrap(err, "lock folders")
+	}
+
+	if f.ParentID == nil {
+		if userID != f.UserID {
+			return domain.ErrForbidden
+		}
+		return nil
+	}
+
+	parent, err := getParentFolder(tx, *f.ParentID)
+	if err != nil {
+		return err
+	}
+	role, err := folderRole(tx, parent, userID)
+	if err != nil {
+		return errors.Wrap(err, "get role")
+	}
+	if err = requireRole(role, domain.RoleEditor, domain.ErrParentNotFound); err != nil {
+		return err
+	}
+	if err = f.CheckParent(parent); err != nil {
+		return err
+	}
+
+	ids, err := folderSubtree(tx, f.ID, nil)
+	if err != nil {
+		return errors.Wrap(err, "get subtree")
+	}
+	for _, id := range ids {
+		if id == parent.ID {
+			return domain.ErrCycle
+		}
+	}
+	return nil
+}
+
+// Delete moves folder to trash together with all its subfolders,
+// notepads and notes. All of them get the same deletion time, which
+// is used to restore the whole subtree later. Folders of other users
+// are moved to the trash of their owners.
+func (r *FoldersRepo) Delete(f domain.Folder) error {
+	err := transact(r.db, func(tx *gorm.DB) (err error) {
+		folder, err := getFolder(tx, f.ID, f.UserID)
+		if err == domain.ErrNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		ids, err := folderSubtree(tx, folder.ID, nil)
+		if err != nil {
+			return errors.Wrap(err, "get subtree")
+		}
+		now := gorm.NowFunc()
+		if err = setDeletedAt(tx, ids, nil, &now); err != nil {
+			return errors.Wrap(err, "move subtree to trash")
+		}
+		return nil
+	})
 	if err != nil {
 		return err
 	}
 	return nil
 }
+
+// folderSubtree gets IDs of the folder and all its descendants that
+// have the given deletion time (nil for folders that are not in trash).
+// Folders that are already in the result are not visited again,
+// so the query ends even if there is a cycle.
+func folderSubtree(tx *gorm.DB, id int, deletedAt *time.Time) ([]int, error) {
+	q := `WITH RECURSIVE tree AS (
+			SELECT id FROM folder
+			WHERE id = ? AND deleted_at IS NOT DISTINCT FROM ?
+			UNION
+			SELECT f.id FROM folder f
+			JOIN tree t ON f.parent_id = t.id
+			WHERE f.deleted_at IS NOT DISTINCT FROM ?
+		)
+		SELECT id FROM tree`
+	rows, err := tx.Raw(q, id, deletedAt, deletedAt).Rows()
+	if err != nil {
+		return nil, errors.Wrap(err, "query error")
+	}
+	defer rows.Close() // nolint: errcheck
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, errors.Wrap(err, "scan row")
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// setDeletedAt changes deletion time of the folders and their notepads
+// and notes. Notepads and notes are only changed if their deletion time
+// is equal to the given one (nil for objects that are not in trash).
+func setDeletedAt(tx *gorm.DB, folderIDs []int, from, to *time.Time) error {
+	if len(folderIDs) == 0 {
+		return nil
+	}
+	err := tx.Exec(
+		"UPDATE note SET deleted_at = ? "+
+			"WHERE deleted_at IS NOT DISTINCT FROM ? AND notepad_id IN ("+
+			"SELECT id FROM notepad "+
+			"WHERE folder_id IN (?) AND deleted_at IS NOT DISTINCT FROM ?)",
+		to, from, folderIDs, from,
+	).Error
+	if err != nil {
+		return errors.Wrap(err, "update notes")
+	}
+	err = tx.Exec(
+		"UPDATE notepad SET deleted_at = ? "+
+			"WHERE folder_id IN (?) AND deleted_at IS NOT DISTINCT FROM ?",
+		to, folderIDs, from,
+	).Error
+	if err != nil {
+		return errors.Wrap(err, "update notepads")
+	}
+	err = tx.Exec(
+		"UPDATE folder SET deleted_at = ? WHERE id IN (?)",
+		to, folderIDs,
+	).Error
+	if err != nil {
+		return errors.Wrap(err, "update folders")
+	}
+	return nil
+}
+
+// GetTree gets folders with their notepads and notes as a tree.
+func (r *FoldersRepo) GetTree(f storage.TreeFilter) ([]domain.FolderNode, error) {
+	var (
+		start = "parent_id IS NULL"
+		args  []interface{}
+	)
+	switch {
+	case f.RootID != nil && f.UserID != nil:
+		// The root can be a folder shared with the user
+		start = "id = ? AND (" + folderAccess + ")"
+		args = append(args, *f.RootID)
+		args = append(args, accessArgs(*f.UserID, domain.RoleViewer)...)
+	case f.RootID != nil:
+		start = "id = ?"
+		args = append(args, *f.RootID)
+	case f.UserID != nil:
+		start += " AND user_id = ?"
+		args = append(args, *f.UserID)
+	}
+	depth := f.Depth
+	if depth == 0 || depth > domain.MaxTreeDepth {
+		depth = domain.MaxTreeDepth
+	}
+	args = append(args, depth)
+	// Folders that are already on the path from the root are not
+	// visited again, so the query ends even if there is a cycle
+	q := `WITH RECURSIVE tree AS (
+			SELECT id, parent_id, title, 1 AS level, ARRAY[id] AS path FROM folder
+			WHERE ` + start + ` AND deleted_at IS NULL
+			UNION ALL
+			SELECT f.id, f.parent_id, f.title, t.level + 1, t.path || f.id FROM folder f
+			JOIN tree t ON f.parent_id = t.id
+			WHERE t.level < ? AND NOT f.id = ANY(t.path) AND f.deleted_at IS NULL
+		)
+		SELECT t.id, t.parent_id, t.title,
+			(SELECT COUNT(*) FROM folder c
+				WHERE c.parent_id = t.id AND c.deleted_at IS NULL) AS folders_count,
+			(SELECT COUNT(*) FROM notepad n
+				WHERE n.folder_id = t.id AND n.deleted_at IS NULL) AS notepads_count
+		FROM tree t
+		ORDER BY t.level, t.title, t.id`
+
+	folders := []domain.FolderNode{}
+	if err := r.db.Raw(q, args...).Scan(&folders).Error; err != nil {
+		return nil, errors.Wrap(err, "get folders")
+	}
+	if f.RootID != nil && len(folders) == 0 {
+		return nil, domain.ErrNotFound
+	}
+	if len(folders) == 0 {
+		return []domain.FolderNode{}, nil
+	}
+
+	folderIDs := make([]int, len(folders))
+	for i := range folders {
+		folderIDs[i] = folders[i].ID
+	}
+	notepads := []domain.NotepadNode{}
+	err := r.db.Table("notepad n").
+		Select("n.id, n.folder_id, n.title, "+
+			"(SELECT COUNT(*) FROM note WHERE notepad_id = n.id AND deleted_at IS NULL) AS notes_count").
+		Where("n.folder_id IN (?) AND n.deleted_at IS NULL", folderIDs).
+		Order("n.title, n.id").
+		Scan(&notepads).
+		Error
+	if err != nil {
+		return nil, errors.Wrap(err, "get notepads")
+	}
+
+	notes := []domain.NoteNode{}
+	if f.Notes && len(notepads) > 0 {
+		notepadIDs := make([]int, len(notepads))
+		for i := range notepads {
+			notepadIDs[i] = notepads[i].ID
+		}
+		err = r.db.Table("note").
+			Select("id, notepad_id, title").
+			Where("notepad_id IN (?) AND deleted_at IS NULL", notepadIDs).
+			Order("title, id").
+			Scan(&notes).
+			Error
+		if err != nil {
+			return nil, errors.Wrap(err, "get notes")
+		}
+	}
+
+	return domain.BuildTree(folders, notepads, notes), nil
+}
@@ -0,0 +1,69 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tetafro/nott-backend-go/internal/domain"
+	"github.com/tetafro/nott-backend-go/internal/storage"
+)
+
+func TestFoldersRepo(t *testing.T) {
+	t.Run("Delete folder in a cycle", func(t *testing.T) {
+		db := testDB(t)
+		defer db.Close() // nolint: errcheck
+		migrateTo(t, db, 0)
+
+		// Cycles can't be made with Move, but can be left in the data
+		execFile(t, db, "testdata/folder_cycles.sql")
+
+		repo := NewFoldersRepo(db)
+		assert.NoError(t, repo.Delete(domain.Folder{ID: 4, UserID: 1}))
+
+		var deleted []int
+		err := db.Table("folder").Where("deleted_at IS NOT NULL").Order("id").Pluck("id", &deleted).Error
+		assert.NoError(t, err)
+		assert.Equal(t, []int{4, 5, 6, 7}, deleted)
+	})
+
+	t.Run("Update time of moved folder", func(t *testing.T) {
+		db := testDB(t)
+		defer db.Close() // nolint: errcheck
+		migrateTo(t, db, 0)
+		execFile(t, db, "testdata/objects.sql")
+
+		parentID := 1
+		repo := NewFoldersRepo(db)
+		_, err := repo.Move(domain.Folder{ID: 2, UserID: 1, ParentID: &parentID})
+		assert.NoError(t, err)
+
+		if updated := updatedAt(t, db, "folder", 2); assert.NotNil(t, updated) {
+			assert.WithinDuration(t, time.Now(), *updated, time.Minute)
+		}
+	})
+
+	t.Run("Get tree from a folder in a cycle", func(t *testing.T) {
+		db := testDB(t)
+		defer db.Close() // nolint: errcheck
+		migrateTo(t, db, 0)
+		execFile(t, db, "testdata/folder_cycles.sql")
+
+		userID, rootID := 1, 4
+		repo := NewFoldersRepo(db)
+		tree, err := repo.GetTree(storage.TreeFilter{UserID: &userID, RootID: &rootID})
+		assert.NoError(t, err)
+
+		var ids []int
+		var walk func(nodes []domain.FolderNode)
+		walk = func(nodes []domain.FolderNode) {
+			for _, n := range nodes {
+				ids = append(ids, n.ID)
+				walk(n.Folders)
+			}
+		}
+		walk(tree)
+		assert.Equal(t, []int{4, 5, 6, 7}, ids)
+	})
+}
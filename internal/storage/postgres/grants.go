@@ -0,0 +1,261 @@
+package postgres
+
+import (
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+
+	"github.com/tetafro/nott-backend-go/internal/auth"
+	"github.com/tetafro/nott-backend-go/internal/domain"
+	"github.com/tetafro/nott-backend-go/internal/storage"
+)
+
+// GrantsRepo is a grants repository that uses PostgreSQL as a backend.
+type GrantsRepo struct {
+	db *gorm.DB
+}
+
+// NewGrantsRepo creates new PostgreSQL repository for grants.
+func NewGrantsRepo(db *gorm.DB) *GrantsRepo {
+	return &GrantsRepo{db: db}
+}
+
+// managedObjects is a condition for grants of objects that a user can
+// share. Arguments are access arguments for folders and for notepads.
+const managedObjects = "folder_id IN (SELECT id FROM folder WHERE " + folderAccess + ") OR " +
+	"notepad_id IN (SELECT id FROM notepad WHERE " + notepadAccess + ")"
+
+// Get gets grants from repository. Grants of objects in trash
+// are skipped.
+func (r *GrantsRepo) Get(f storage.GrantsFilter) ([]domain.Grant, error) {
+	gg := []domain.Grant{}
+
+	q := r.db.Where(
+		"folder_id IN (SELECT id FROM folder WHERE deleted_at IS NULL) OR " +
+			"notepad_id IN (SELECT id FROM notepad WHERE deleted_at IS NULL)",
+	)
+	if f.ID != nil {
+		q = q.Where("id = ?", *f.ID)
+	}
+	if f.UserID != nil {
+		q = q.Where("user_id = ?", *f.UserID)
+	}
+	if f.ManagerID != nil {
+		args := append(
+			accessArgs(*f.ManagerID, domain.RoleOwner),
+			accessArgs(*f.ManagerID, domain.RoleOwner)...,
+		)
+		q = q.Where(managedObjects, args...)
+	}
+	if f.FolderID != nil {
+		q = q.Where("folder_id = ?", *f.FolderID)
+	}
+	if f.NotepadID != nil {
+		q = q.Where("notepad_id = ?", *f.NotepadID)
+	}
+	if f.Accepted != nil {
+		if *f.Accepted {
+			q = q.Where("accepted_at IS NOT NULL")
+		} else {
+			q = q.Where("accepted_at IS NULL")
+		}
+	}
+
+	if err := q.Order("id").Find(&gg).Error; err != nil {
+		return nil, errors.Wrap(err, "query error")
+	}
+	if err := setGrantTitles(r.db, gg); err != nil {
+		return nil, errors.Wrap(err, "get titles")
+	}
+
+	return gg, nil
+}
+
+// Invite gives the user with the email a role on the folder or notepad.
+// InvitedBy of the grant is the user who shares the object, they must
+// have the owner role. If the user already has a grant for the object,
+// its role is changed.
+func (r *GrantsRepo) Invite(g domain.Grant, email string) (domain.Grant, error) {
+	err := transact(r.db, func(tx *gorm.DB) (err error) {
+		var (
+			ownerID int
+			role    domain.Role
+		)
+		// Objects are locked, so concurrent invitations of the same
+		// user don't conflict
+		q := tx.Set("gorm:query_option", "FOR UPDATE")
+		if g.FolderID != nil {
+			var folder domain.Folder
+			err = q.Where("id = ?", *g.FolderID).Find(&folder).Error
+			if err == nil {
+				ownerID = folder.UserID
+				role, err = folderRole(tx, folder, g.InvitedBy)
+			}
+		} else {
+			var notepad domain.Notepad
+			err = q.Where("id = ?", *g.NotepadID).Find(&notepad).Error
+			if err == nil {
+				ownerID = notepad.UserID
+				role, err = notepadRole(tx, notepad, g.InvitedBy)
+			}
+		}
+		if err == gorm.ErrRecordNotFound {
+			return domain.ErrNotFound
+		}
+		if err != nil {
+			return errors.Wrap(err, "check shared object")
+		}
+		if err = requireRole(role, domain.RoleOwner, domain.ErrNotFound); err != nil {
+			return err
+		}
+
+		var user auth.User
+		err = tx.Select("id").Where("email = ?", email).Find(&user).Error
+		if err == gorm.ErrRecordNotFound {
+			return domain.ErrUserNotFound
+		}
+		if err != nil {
+			return errors.Wrap(err, "get user")
+		}
+		if user.ID == ownerID || user.ID == g.InvitedBy {
+			return domain.ErrGrantToOwner
+		}
+		g.UserID = user.ID
+
+		var existing domain.Grant
+		q = tx.Where("user_id = ?", g.UserID)
+		if g.FolderID != nil {
+			q = q.Where("folder_id = ?", *g.FolderID)
+		} else {
+			q = q.Where("notepad_id = ?", *g.NotepadID)
+		}
+		err = q.Find(&existing).Error
+		if err == gorm.ErrRecordNotFound {
+			if err = tx.Create(&g).Error; err != nil {
+				return errors.Wrap(err, "query error")
+			}
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, "check grant in database")
+		}
+
+		err = tx.Model(&existing).Updates(map[string]interface{}{
+			"role":       g.Role,
+			"invited_by": g.InvitedBy,
+		}).Error
+		if err != nil {
+			return errors.Wrap(err, "query error")
+		}
+		existing.Role = g.Role
+		existing.InvitedBy = g.InvitedBy
+		g = existing
+		return nil
+	})
+	if err != nil {
+		return domain.Grant{}, err
+	}
+	gg := []domain.Grant{g}
+	if err = setGrantTitles(r.db, gg); err != nil {
+		return domain.Grant{}, errors.Wrap(err, "get title")
+	}
+	return gg[0], nil
+}
+
+// Accept accepts grant given to the user.
+func (r *GrantsRepo) Accept(id, userID int) (domain.Grant, error) {
+	var g domain.Grant
+	err := transact(r.db, func(tx *gorm.DB) (err error) {
+		err = tx.Set("gorm:query_option", "FOR UPDATE").
+			Where("id = ? AND user_id = ?", id, userID).
+			Find(&g).
+			Error
+		if err == gorm.ErrRecordNotFound {
+			return domain.ErrNotFound
+		}
+		if err != nil {
+			return errors.Wrap(err, "check grant in database")
+		}
+		if g.AcceptedAt != nil {
+			return nil
+		}
+
+		now := gorm.NowFunc()
+		if err = tx.Model(&g).Update("accepted_at", now).Error; err != nil {
+			return errors.Wrap(err, "query error")
+		}
+		g.AcceptedAt = &now
+		return nil
+	})
+	if err != nil {
+		return domain.Grant{}, err
+	}
+	gg := []domain.Grant{g}
+	if err = setGrantTitles(r.db, gg); err != nil {
+		return domain.Grant{}, errors.Wrap(err, "get title")
+	}
+	return gg[0], nil
+}
+
+// Delete deletes grant given to the user, or grant of the object
+// that the user can share.
+func (r *GrantsRepo) Delete(id, userID int) error {
+	args := append([]interface{}{userID}, accessArgs(userID, domain.RoleOwner)...)
+	args = append(args, accessArgs(userID, domain.RoleOwner)...)
+	err := r.db.Where("id = ?", id).
+		Where("user_id = ? OR "+managedObjects, args...).
+		Delete(&domain.Grant{}).
+		Error
+	if err != nil {
+		return errors.Wrap(err, "query error")
+	}
+	return nil
+}
+
+// setGrantTitles sets titles of the shared folders and notepads.
+func setGrantTitles(db *gorm.DB, gg []domain.Grant) error {
+	var folderIDs, notepadIDs []int
+	for _, g := range gg {
+		if g.FolderID != nil {
+			folderIDs = append(folderIDs, *g.FolderID)
+		}
+		if g.NotepadID != nil {
+			notepadIDs = append(notepadIDs, *g.NotepadID)
+		}
+	}
+
+	type title struct {
+		ID    int    `gorm:"column:id"`
+		Title string `gorm:"column:title"`
+	}
+	folders := map[int]string{}
+	if len(folderIDs) > 0 {
+		var tt []title
+		err := db.Table("folder").Select("id, title").Where("id IN (?)", folderIDs).Scan(&tt).Error
+		if err != nil {
+			return errors.Wrap(err, "get folders")
+		}
+		for _, t := range tt {
+			folders[t.ID] = t.Title
+		}
+	}
+	notepads := map[int]string{}
+	if len(notepadIDs) > 0 {
+		var tt []title
+		err := db.Table("notepad").Select("id, title").Where("id IN (?)", notepadIDs).Scan(&tt).Error
+		if err != nil {
+			return errors.Wrap(err, "get notepads")
+		}
+		for _, t := range tt {
+			notepads[t.ID] = t.Title
+		}
+	}
+
+	for i, g := range gg {
+		if g.FolderID != nil {
+			gg[i].Title = folders[*g.FolderID]
+		} else if g.NotepadID != nil {
+			gg[i].Title = notepads[*g.NotepadID]
+		}
+	}
+	return nil
+}
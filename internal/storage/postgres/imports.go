@@ -0,0 +1,281 @@
+package postgres
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+
+	"github.com/tetafro/nott-backend-go/internal/domain"
+	"github.com/tetafro/nott-backend-go/internal/storage"
+)
+
+// importTimeout is a time after which running import is considered
+// to be abandoned, e.g. because the server was restarted. Running
+// imports are updated after each notepad.
+const importTimeout = 10 * time.Minute
+
+// ImportsRepo is an imports repository that uses PostgreSQL as a backend.
+type ImportsRepo struct {
+	db *gorm.DB
+}
+
+// NewImportsRepo creates new PostgreSQL repository for imports.
+func NewImportsRepo(db *gorm.DB) *ImportsRepo {
+	return &ImportsRepo{db: db}
+}
+
+// Get gets imports with their errors from repository.
+func (r *ImportsRepo) Get(f storage.ImportsFilter) ([]domain.Import, error) {
+	ii := []domain.Import{}
+
+	q := r.db
+	if f.ID != nil {
+		q = q.Where("id = ?", *f.ID)
+	}
+	if f.UserID != nil {
+		q = q.Where("user_id = ?", *f.UserID)
+	}
+
+	if err := q.Order("id").Find(&ii).Error; err != nil {
+		return nil, errors.Wrap(err, "query error")
+	}
+	if len(ii) == 0 {
+		return ii, nil
+	}
+
+	ids := make([]int, len(ii))
+	for i := range ii {
+		ids[i] = ii[i].ID
+	}
+	var ee []domain.ImportError
+	err := r.db.Where("import_id IN (?)", ids).Order("id").Find(&ee).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "get errors")
+	}
+	errs := map[int][]domain.ImportError{}
+	for _, e := range ee {
+		errs[e.ImportID] = append(errs[e.ImportID], e)
+	}
+	for i := range ii {
+		ii[i].Errors = errs[ii[i].ID]
+		if ii[i].Errors == nil {
+			ii[i].Errors = []domain.ImportError{}
+		}
+	}
+
+	return ii, nil
+}
+
+// Create creates import in repository.
+func (r *ImportsRepo) Create(i domain.Import) (domain.Import, error) {
+	if err := r.db.Create(&i).Error; err != nil {
+		return domain.Import{}, errors.Wrap(err, "query error")
+	}
+	i.Errors = []domain.ImportError{}
+	return i, nil
+}
+
+// Claim gets the oldest pending import and marks it as running.
+// Several workers can claim imports at the same time, each import
+// is given to only one of them. Abandoned imports are marked as failed.
+func (r *ImportsRepo) Claim() (domain.Import, error) {
+	now := time.Now().UTC()
+	err := r.db.Exec(
+		"UPDATE import SET status = ?, finished_at = ?, updated_at = ? "+
+			"WHERE status = ? AND updated_at < ?",
+		domain.ImportFailed, now, now, domain.ImportRunning, now.Add(-importTimeout),
+	).Error
+	if err != nil {
+		return domain.Import{}, errors.Wrap(err, "fail abandoned imports")
+	}
+
+	var i domain.Import
+	err = r.db.Raw(
+		"UPDATE import SET status = ?, updated_at = ? WHERE id = ("+
+			"SELECT id FROM import WHERE status = ? "+
+			"ORDER BY created_at LIMIT 1 FOR UPDATE SKIP LOCKED"+
+			") RETURNING *",
+		domain.ImportRunning, now, domain.ImportPending,
+	).Scan(&i).Error
+	if err == gorm.ErrRecordNotFound {
+		return domain.Import{}, domain.ErrNotFound
+	}
+	if err != nil {
+		return domain.Import{}, errors.Wrap(err, "query error")
+	}
+	return i, nil
+}
+
+// Update saves status, counters and the folder of the import. Update
+// time is changed too, so running import is not considered abandoned.
+func (r *ImportsRepo) Update(i domain.Import) (domain.Import, error) {
+	now := time.Now().UTC()
+	err := r.db.Model(&domain.Import{ID: i.ID}).Updates(map[string]interface{}{
+		"status":      i.Status,
+		"folder_id":   i.FolderID,
+		"notepads":    i.Notepads,
+		"notes":       i.Notes,
+		"finished_at": i.FinishedAt,
+		"updated_at":  now,
+	}).Error
+	if err != nil {
+		return domain.Import{}, errors.Wrap(err, "query error")
+	}
+	i.UpdatedAt = &now
+	return i, nil
+}
+
+// AddError saves error of an item that was skipped.
+func (r *ImportsRepo) AddError(e domain.ImportError) error {
+	if err := r.db.Create(&e).Error; err != nil {
+		return errors.Wrap(err, "query error")
+	}
+	return nil
+}
+
+// ImportNotepad creates notepad with its notes inside the folder. Path
+// is a list of subfolder titles, existing subfolders are reused, so
+// notepads from one directory get into one folder. UserID of the notepad
+// must be the owner of the folder. Either everything is created,
+// or nothing.
+func (r *ImportsRepo) ImportNotepad(
+	folderID int,
+	path []string,
+	n domain.Notepad,
+	notes []domain.Note,
+) (domain.Notepad, error) {
+	err := transact(r.db, func(tx *gorm.DB) (err error) {
+		folder, err := getParentFolder(tx, folderID)
+		if err != nil {
+			return err
+		}
+		for _, title := range path {
+			if folder, err = importFolder(tx, folder, title); err != nil {
+				return errors.Wrapf(err, "import folder %q", title)
+			}
+		}
+
+		n.FolderID = folder.ID
+		if err = n.CheckParent(folder); err != nil {
+			return err
+		}
+		if err = n.Validate(); err != nil {
+			return err
+		}
+		if err = tx.Create(&n).Error; err != nil {
+			return errors.Wrap(err, "query error")
+		}
+
+		for _, note := range notes {
+			note.UserID = n.UserID
+			note.NotepadID = n.ID
+			if err = note.Validate(); err != nil {
+				return errors.Wrapf(err, "invalid note %q", note.Title)
+			}
+			if err = tx.Create(&note).Error; err != nil {
+				return errors.Wrap(err, "create note")
+			}
+			if err = setNoteTags(tx, &note); err != nil {
+				return errors.Wrap(err, "set tags")
+			}
+			if err = setNoteLinks(tx, note); err != nil {
+				return errors.Wrap(err, "set links")
+			}
+			if err = createRevision(tx, note); err != nil {
+				return errors.Wrap(err, "create revision")
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return domain.Notepad{}, err
+	}
+	return n, nil
+}
+
+// ImportFolder creates subfolders in the path inside the folder,
+// existing subfolders are reused. The last subfolder is returned.
+func (r *ImportsRepo) ImportFolder(folderID int, path []string) (domain.Folder, error) {
+	var folder domain.Folder
+	err := transact(r.db, func(tx *gorm.DB) (err error) {
+		if folder, err = getParentFolder(tx, folderID); err != nil {
+			return err
+		}
+		for _, title := range path {
+			if folder, err = importFolder(tx, folder, title); err != nil {
+				return errors.Wrapf(err, "import folder %q", title)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return domain.Folder{}, err
+	}
+	return folder, nil
+}
+
+// ReserveIDs takes IDs for notes and attachments from their sequences.
+// Taken IDs are never given to other objects, so imported objects
+// can be created with them.
+func (r *ImportsRepo) ReserveIDs(notes, attachments int) ([]int, []int, error) {
+	n, err := reserveIDs(r.db, "note", notes)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "reserve note ids")
+	}
+	a, err := reserveIDs(r.db, "attachment", attachments)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "reserve attachment ids")
+	}
+	return n, a, nil
+}
+
+// reserveIDs takes n next values of ID sequence of the table.
+func reserveIDs(db *gorm.DB, table string, n int) ([]int, error) {
+	if n == 0 {
+		return nil, nil
+	}
+	var rows []struct {
+		ID int `gorm:"column:id"`
+	}
+	err := db.Raw(
+		"SELECT nextval(pg_get_serial_sequence(?, 'id')) AS id FROM generate_series(1, ?)",
+		table, n,
+	).Scan(&rows).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "query error")
+	}
+	ids := make([]int, len(rows))
+	for i := range rows {
+		ids[i] = rows[i].ID
+	}
+	return ids, nil
+}
+
+// importFolder gets subfolder of the parent by title, or creates it.
+func importFolder(tx *gorm.DB, parent domain.Folder, title string) (domain.Folder, error) {
+	f := domain.Folder{UserID: parent.UserID, ParentID: &parent.ID, Title: title}
+	if err := f.Validate(); err != nil {
+		return domain.Folder{}, err
+	}
+	if err := f.CheckParent(parent); err != nil {
+		return domain.Folder{}, err
+	}
+
+	var existing domain.Folder
+	err := tx.Where("parent_id = ? AND title = ? AND deleted_at IS NULL", parent.ID, title).
+		Order("id").
+		First(&existing).
+		Error
+	if err == nil {
+		return existing, nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return domain.Folder{}, errors.Wrap(err, "query error")
+	}
+
+	if err = tx.Create(&f).Error; err != nil {
+		return domain.Folder{}, errors.Wrap(err, "query error")
+	}
+	return f, nil
+}
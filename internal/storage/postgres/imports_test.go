@@ -0,0 +1,46 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tetafro/nott-backend-go/internal/domain"
+	"github.com/tetafro/nott-backend-go/internal/storage"
+)
+
+func TestImportsRepo(t *testing.T) {
+	t.Run("Keep running import that is updated", func(t *testing.T) {
+		db := testDB(t)
+		defer db.Close() // nolint: errcheck
+		migrateTo(t, db, 0)
+
+		repo := NewImportsRepo(db)
+		i, err := repo.Create(domain.Import{
+			UserID: 1, Format: domain.ImportMarkdown, Name: "a.zip", Key: "a",
+			Status: domain.ImportPending,
+		})
+		assert.NoError(t, err)
+		i, err = repo.Claim()
+		assert.NoError(t, err)
+
+		// The import has been running for longer than the timeout,
+		// and it's updated after each notepad
+		old := time.Now().UTC().Add(-2 * importTimeout)
+		assert.NoError(t, db.Exec("UPDATE import SET updated_at = ?", old).Error)
+		i.Notepads = 1
+		_, err = repo.Update(i)
+		assert.NoError(t, err)
+
+		_, err = repo.Claim()
+		assert.Equal(t, domain.ErrNotFound, err)
+
+		ii, err := repo.Get(storage.ImportsFilter{ID: &i.ID})
+		assert.NoError(t, err)
+		if assert.Len(t, ii, 1) {
+			assert.Equal(t, domain.ImportRunning, ii[0].Status)
+			assert.Equal(t, 1, ii[0].Notepads)
+		}
+	})
+}
@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+
+	"github.com/tetafro/nott-backend-go/internal/domain"
+	"github.com/tetafro/nott-backend-go/internal/markdown"
+	"github.com/tetafro/nott-backend-go/internal/storage"
+)
+
+// GetLinks gets wiki links of notes with their targets. Links of notes
+// in trash are skipped. Filtering by user also skips links to notes
+// that are not available to the user.
+func (r *NotesRepo) GetLinks(f storage.LinksFilter) ([]domain.NoteLink, error) {
+	ll := []domain.NoteLink{}
+
+	q := r.db.Table("note_link_target").
+		Select("note_id, link_id, COALESCE(link_title, '') AS link_title, " +
+			"target_id, COALESCE(target_title, '') AS target_title, " +
+			"target_id IS NULL AS broken").
+		Where("note_id IN (SELECT id FROM note WHERE deleted_at IS NULL)")
+	if f.NoteID != nil {
+		q = q.Where("note_id = ?", *f.NoteID)
+	}
+	if f.UserID != nil {
+		q = q.Where("note_id IN (SELECT id FROM note WHERE "+noteAccess+")",
+			accessArgs(*f.UserID, domain.RoleViewer)...).
+			Where("target_id IS NULL OR target_id IN (SELECT id FROM note WHERE "+noteAccess+")",
+				accessArgs(*f.UserID, domain.RoleViewer)...)
+	}
+
+	if err := q.Order("note_id, link_id, lower(link_title)").Scan(&ll).Error; err != nil {
+		return nil, errors.Wrap(err, "query error")
+	}
+	return ll, nil
+}
+
+// GetGraph gets notes available to the user and wiki links between them.
+func (r *NotesRepo) GetGraph(userID int) (domain.NoteGraph, error) {
+	notes := []domain.NoteNode{}
+	err := r.db.Table("note").
+		Select("id, notepad_id, title").
+		Where("deleted_at IS NULL").
+		Where(noteAccess, accessArgs(userID, domain.RoleViewer)...).
+		Order("id").
+		Scan(&notes).
+		Error
+	if err != nil {
+		return domain.NoteGraph{}, errors.Wrap(err, "get notes")
+	}
+
+	links, err := r.GetLinks(storage.LinksFilter{UserID: &userID})
+	if err != nil {
+		return domain.NoteGraph{}, errors.Wrap(err, "get links")
+	}
+
+	return domain.BuildGraph(notes, links), nil
+}
+
+// setNoteLinks replaces wiki links of the note with the ones
+// from its text.
+func setNoteLinks(tx *gorm.DB, n domain.Note) error {
+	err := tx.Exec("DELETE FROM note_link WHERE note_id = ?", n.ID).Error
+	if err != nil {
+		return errors.Wrap(err, "delete links")
+	}
+
+	for _, l := range markdown.ParseLinks(n.Text) {
+		var (
+			id    *int
+			title *string
+		)
+		if l.ID != 0 {
+			id = &l.ID
+		} else {
+			title = &l.Title
+		}
+		err = tx.Exec(
+			"INSERT INTO note_link (note_id, link_id, link_title) VALUES (?, ?, ?)",
+			n.ID, id, title,
+		).Error
+		if err != nil {
+			return errors.Wrap(err, "create link")
+		}
+	}
+	return nil
+}
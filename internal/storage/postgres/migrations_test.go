@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrations(t *testing.T) {
+	t.Run("Apply all migrations", func(t *testing.T) {
+		db := testDB(t)
+		defer db.Close() // nolint: errcheck
+
+		migrateTo(t, db, 0)
+	})
+
+	t.Run("Move objects out of parents of other users", func(t *testing.T) {
+		db := testDB(t)
+		defer db.Close() // nolint: errcheck
+
+		migrateTo(t, db, 4)
+		execFile(t, db, "testdata/cross_user.sql")
+		migrateTo(t, db, 5)
+
+		type row struct {
+			ID       int    `gorm:"column:id"`
+			UserID   int    `gorm:"column:user_id"`
+			ParentID *int   `gorm:"column:parent_id"`
+			Title    string `gorm:"column:title"`
+		}
+		var folders, notepads, notes []row
+		err := db.Raw("SELECT id, user_id, parent_id, title FROM folder ORDER BY id").
+			Scan(&folders).Error
+		assert.NoError(t, err)
+		err = db.Raw("SELECT id, user_id, folder_id AS parent_id, title FROM notepad ORDER BY id").
+			Scan(&notepads).Error
+		assert.NoError(t, err)
+		err = db.Raw("SELECT id, user_id, notepad_id AS parent_id, title FROM note ORDER BY id").
+			Scan(&notes).Error
+		assert.NoError(t, err)
+
+		one, two, three := 1, 2, 3
+		assert.Equal(t, []row{
+			{ID: 1, UserID: 1, Title: "Bob"},
+			{ID: 2, UserID: 2, Title: "Eve in Bob"},
+			{ID: 3, UserID: 2, Title: "Recovered"},
+		}, folders)
+		assert.Equal(t, []row{
+			{ID: 1, UserID: 1, ParentID: &one, Title: "Bob"},
+			{ID: 2, UserID: 2, ParentID: &three, Title: "Eve in Bob"},
+			{ID: 3, UserID: 2, ParentID: &three, Title: "Recovered"},
+		}, notepads)
+		assert.Equal(t, []row{
+			{ID: 1, UserID: 1, ParentID: &one, Title: "Bob"},
+			{ID: 2, UserID: 2, ParentID: &three, Title: "Eve in Bob"},
+			{ID: 3, UserID: 2, ParentID: &two, Title: "Eve in Eve"},
+		}, notes)
+	})
+
+	t.Run("Break folder cycles", func(t *testing.T) {
+		db := testDB(t)
+		defer db.Close() // nolint: errcheck
+
+		migrateTo(t, db, 17)
+		execFile(t, db, "testdata/folder_cycles.sql")
+		migrateTo(t, db, 18)
+
+		var folders []struct {
+			ID       int  `gorm:"column:id"`
+			ParentID *int `gorm:"column:parent_id"`
+		}
+		err := db.Raw("SELECT id, parent_id FROM folder ORDER BY id").Scan(&folders).Error
+		assert.NoError(t, err)
+
+		parents := map[int]*int{}
+		for _, f := range folders {
+			parents[f.ID] = f.ParentID
+		}
+		one, four, five, six := 1, 4, 5, 6
+		assert.Equal(t, map[int]*int{
+			1: nil,
+			2: &one,
+			3: nil,
+			4: nil,
+			5: &four,
+			6: &five,
+			7: &six,
+		}, parents)
+	})
+}
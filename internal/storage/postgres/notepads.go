@@ -27,12 +27,14 @@ func (r *NotepadsRepo) Get(f storage.NotepadsFilter) ([]domain.Notepad, error) {
 		q = q.Where("id = ?", *f.ID)
 	}
 	if f.UserID != nil {
-		q = q.Where("user_id = ?", *f.UserID)
+		q = q.Where(notepadAccess, accessArgs(*f.UserID, domain.RoleViewer)...)
 	}
 	if f.FolderID != nil {
 		q = q.Where("folder_id = ?", *f.FolderID)
 	}
 
+	q = paginate(q, f.Page)
+
 	if err := q.Find(&n).Error; err != nil {
 		return nil, errors.Wrap(err, "query error")
 	}
@@ -40,9 +42,26 @@ func (r *NotepadsRepo) Get(f storage.NotepadsFilter) ([]domain.Notepad, error) {
 	return n, nil
 }
 
-// Create creates notepad in repository.
+// Create creates notepad in repository. UserID of the notepad is the user
+// who creates it, the notepad gets the owner of the folder.
 func (r *NotepadsRepo) Create(n domain.Notepad) (domain.Notepad, error) {
 	err := transact(r.db, func(tx *gorm.DB) (err error) {
+		folder, err := getParentFolder(tx, n.FolderID)
+		if err != nil {
+			return err
+		}
+		role, err := folderRole(tx, folder, n.UserID)
+		if err != nil {
+			return errors.Wrap(err, "get role")
+		}
+		if err = requireRole(role, domain.RoleEditor, domain.ErrParentNotFound); err != nil {
+			return err
+		}
+		n.UserID = folder.UserID
+		if err = n.CheckParent(folder); err != nil {
+			return err
+		}
+
 		if err = tx.Create(&n).Error; err != nil {
 			return errors.Wrap(err, "query error")
 		}
@@ -54,19 +73,16 @@ func (r *NotepadsRepo) Create(n domain.Notepad) (domain.Notepad, error) {
 	return n, nil
 }
 
-// Update updates notepad in repository.
+// Update updates notepad in repository. UserID of the notepad is the user
+// who updates it.
 func (r *NotepadsRepo) Update(n domain.Notepad) (domain.Notepad, error) {
 	err := transact(r.db, func(tx *gorm.DB) (err error) {
-		// Check if notepad exists
-		err = tx.Select("id").
-			Where("id = ? AND user_id = ?", n.ID, n.UserID).
-			Find(&domain.Notepad{}).
-			Error
-		if err == gorm.ErrRecordNotFound {
-			return domain.ErrNotFound
-		}
+		notepad, err := getNotepad(tx, n.ID, n.UserID)
 		if err != nil {
-			return errors.Wrap(err, "check notepad in database")
+			return err
+		}
+		if err = setNotepadFolder(tx, notepad, &n); err != nil {
+			return err
 		}
 
 		// NOTE: Save() method doesn't return ErrRecordNotFound, but
@@ -84,13 +100,63 @@ func (r *NotepadsRepo) Update(n domain.Notepad) (domain.Notepad, error) {
 	return n, nil
 }
 
-// Delete deletes notepad in repository.
+// Move moves notepad into another folder. UserID of the notepad is
+// the user who moves it.
+func (r *NotepadsRepo) Move(n domain.Notepad) (domain.Notepad, error) {
+	var notepad domain.Notepad
+	err := transact(r.db, func(tx *gorm.DB) (err error) {
+		notepad, err = getNotepad(tx, n.ID, n.UserID)
+		if err != nil {
+			return err
+		}
+		if err = setNotepadFolder(tx, notepad, &n); err != nil {
+			return err
+		}
+
+		// Map updates skip the timestamp callback, so it's set here
+		err = tx.Model(&notepad).Updates(map[string]interface{}{
+			"folder_id":  n.FolderID,
+			"updated_at": gorm.NowFunc(),
+		}).Error
+		if err != nil {
+			return errors.Wrap(err, "query error")
+		}
+		return nil
+	})
+	if err != nil {
+		return domain.Notepad{}, err
+	}
+	return notepad, nil
+}
+
+// Delete moves notepad to trash together with all its notes. Notepads
+// of other users are moved to the trash of their owners.
 func (r *NotepadsRepo) Delete(n domain.Notepad) error {
 	err := transact(r.db, func(tx *gorm.DB) (err error) {
-		err = tx.Where("id = ? AND user_id = ?", n.ID, n.UserID).Delete(&domain.Notepad{}).Error
+		notepad, err := getNotepad(tx, n.ID, n.UserID)
+		if err == domain.ErrNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		now := gorm.NowFunc()
+		err = tx.Exec(
+			"UPDATE notepad SET deleted_at = ? WHERE id = ?",
+			now, notepad.ID,
+		).Error
 		if err != nil {
 			return errors.Wrap(err, "query error")
 		}
+		err = tx.Exec(
+			"UPDATE note SET deleted_at = ? "+
+				"WHERE notepad_id = ? AND deleted_at IS NULL",
+			now, notepad.ID,
+		).Error
+		if err != nil {
+			return errors.Wrap(err, "move notes to trash")
+		}
 		return nil
 	})
 	if err != nil {
@@ -98,3 +164,51 @@ func (r *NotepadsRepo) Delete(n domain.Notepad) error {
 	}
 	return nil
 }
+
+// getNotepad gets notepad that is going to be changed by the user, and
+// locks it until the end of transaction.
+func getNotepad(tx *gorm.DB, id, userID int) (domain.Notepad, error) {
+	var n domain.Notepad
+	err := tx.Set("gorm:query_option", "FOR UPDATE").
+		Where("id = ?", id).
+		Find(&n).
+		Error
+	if err == gorm.ErrRecordNotFound {
+		return domain.Notepad{}, domain.ErrNotFound
+	}
+	if err != nil {
+		return domain.Notepad{}, errors.Wrap(err, "check notepad in database")
+	}
+
+	role, err := notepadRole(tx, n, userID)
+	if err != nil {
+		return domain.Notepad{}, errors.Wrap(err, "get role")
+	}
+	if err = requireRole(role, domain.RoleEditor, domain.ErrNotFound); err != nil {
+		return domain.Notepad{}, err
+	}
+	return n, nil
+}
+
+// setNotepadFolder checks if the notepad can be put into the folder
+// set in n, and sets the owner of the notepad. UserID of n is the user
+// who makes the change, the new folder must be editable by them.
+func setNotepadFolder(tx *gorm.DB, notepad domain.Notepad, n *domain.Notepad) error {
+	userID := n.UserID
+	n.UserID = notepad.UserID
+
+	folder, err := getParentFolder(tx, n.FolderID)
+	if err != nil {
+		return err
+	}
+	if n.FolderID != notepad.FolderID {
+		role, err := folderRole(tx, folder, userID)
+		if err != nil {
+			return errors.Wrap(err, "get role")
+		}
+		if err = requireRole(role, domain.RoleEditor, domain.ErrParentNotFound); err != nil {
+			return err
+		}
+	}
+	return n.CheckParent(folder)
+}
@@ -18,34 +18,117 @@ func NewNotesRepo(db *gorm.DB) *NotesRepo {
 	return &NotesRepo{db: db}
 }
 
+// searchConfig is a text search configuration used for notes.
+// Should be the same as the one used in migrations.
+const searchConfig = "simple"
+
+// searchLimit is a maximum number of search hits.
+const searchLimit = 50
+
 // Get gets notes from repository.
 func (r *NotesRepo) Get(f storage.NotesFilter) ([]domain.Note, error) {
 	n := []domain.Note{}
 
-	q := r.db
-	if f.ID != nil {
-		q = q.Where("id = ?", *f.ID)
-	}
-	if f.UserID != nil {
-		q = q.Where("user_id = ?", *f.UserID)
-	}
-	if f.NotepadID != nil {
-		q = q.Where("notepad_id = ?", *f.NotepadID)
+	q := filterNotes(r.db, f)
+	if f.Query != nil && f.Page == nil {
+		q = q.Order("ts_rank(search, query) DESC")
 	}
+	q = paginate(q, f.Page)
 
 	if err := q.Find(&n).Error; err != nil {
 		return nil, errors.Wrap(err, "query error")
 	}
 
+	ids := make([]int, len(n))
+	for i := range n {
+		ids[i] = n[i].ID
+	}
+	tags, err := getNoteTags(r.db, ids)
+	if err != nil {
+		return nil, errors.Wrap(err, "get tags")
+	}
+	for i := range n {
+		n[i].Tags = tags[n[i].ID]
+	}
+
 	return n, nil
 }
 
-// Create creates note in repository.
+// Search makes full-text search over notes and returns the best
+// matching ones.
+func (r *NotesRepo) Search(f storage.NotesFilter) ([]domain.NoteHit, error) {
+	if f.Query == nil {
+		return nil, errors.New("empty search query")
+	}
+
+	hits := []domain.NoteHit{}
+
+	// Text of the note is not escaped by the database, so matched words
+	// are delimited with placeholders, that are replaced with tags after
+	// escaping
+	headline := "ts_headline('" + searchConfig + "', text, query, ?)"
+	options := "StartSel=" + domain.SnippetStartSel + ", StopSel=" + domain.SnippetStopSel + ", MaxFragments=3"
+	// Gorm can't filter out deleted notes by itself here,
+	// since it doesn't know the model
+	err := filterNotes(r.db.Table("note"), f).
+		Where("note.deleted_at IS NULL").
+		Select("note.*, ts_rank(search, query) AS rank, "+headline+" AS snippet", options).
+		Order("rank DESC").
+		Limit(searchLimit).
+		Scan(&hits).
+		Error
+	if err != nil {
+		return nil, errors.Wrap(err, "query error")
+	}
+
+	ids := make([]int, len(hits))
+	for i := range hits {
+		ids[i] = hits[i].ID
+	}
+	tags, err := getNoteTags(r.db, ids)
+	if err != nil {
+		return nil, errors.Wrap(err, "get tags")
+	}
+	for i := range hits {
+		hits[i].Tags = tags[hits[i].ID]
+		hits[i].Snippet = domain.MarkSnippet(hits[i].Snippet)
+	}
+
+	return hits, nil
+}
+
+// Create creates note in repository. UserID of the note is the user
+// who creates it, the note gets the owner of the notepad.
 func (r *NotesRepo) Create(n domain.Note) (domain.Note, error) {
 	err := transact(r.db, func(tx *gorm.DB) (err error) {
+		notepad, err := getParentNotepad(tx, n.NotepadID)
+		if err != nil {
+			return err
+		}
+		role, err := notepadRole(tx, notepad, n.UserID)
+		if err != nil {
+			return errors.Wrap(err, "get role")
+		}
+		if err = requireRole(role, domain.RoleEditor, domain.ErrParentNotFound); err != nil {
+			return err
+		}
+		n.UserID = notepad.UserID
+		if err = n.CheckParent(notepad); err != nil {
+			return err
+		}
+
 		if err = tx.Create(&n).Error; err != nil {
 			return errors.Wrap(err, "query error")
 		}
+		if err = setNoteTags(tx, &n); err != nil {
+			return errors.Wrap(err, "set tags")
+		}
+		if err = setNoteLinks(tx, n); err != nil {
+			return errors.Wrap(err, "set links")
+		}
+		if err = createRevision(tx, n); err != nil {
+			return errors.Wrap(err, "create revision")
+		}
 		return nil
 	})
 	if err != nil {
@@ -54,19 +137,17 @@ func (r *NotesRepo) Create(n domain.Note) (domain.Note, error) {
 	return n, nil
 }
 
-// Update updates note in repository.
+// Update updates note in repository. UserID of the note is the user
+// who updates it.
 func (r *NotesRepo) Update(n domain.Note) (domain.Note, error) {
 	err := transact(r.db, func(tx *gorm.DB) (err error) {
-		// Check if note exists
-		err = tx.Select("id").
-			Where("id = ? AND user_id = ?", n.ID, n.UserID).
-			Find(&domain.Note{}).
-			Error
-		if err == gorm.ErrRecordNotFound {
-			return domain.ErrNotFound
-		}
+		// Check if note exists, and lock it until revision is written
+		note, err := getNote(tx, n.ID, n.UserID)
 		if err != nil {
-			return errors.Wrap(err, "check note in database")
+			return err
+		}
+		if err = setNoteNotepad(tx, note, &n); err != nil {
+			return err
 		}
 
 		// NOTE: Save() method doesn't return ErrRecordNotFound, but
@@ -75,6 +156,16 @@ func (r *NotesRepo) Update(n domain.Note) (domain.Note, error) {
 		if err = tx.Save(&n).Error; err != nil {
 			return errors.Wrap(err, "query error")
 		}
+		if err = setNoteTags(tx, &n); err != nil {
+			return errors.Wrap(err, "set tags")
+		}
+		if err = setNoteLinks(tx, n); err != nil {
+			return errors.Wrap(err, "set links")
+		}
+
+		if err = createRevision(tx, n); err != nil {
+			return errors.Wrap(err, "create revision")
+		}
 
 		return nil
 	})
@@ -84,17 +175,248 @@ func (r *NotesRepo) Update(n domain.Note) (domain.Note, error) {
 	return n, nil
 }
 
-// Delete deletes note in repository.
+// Move moves note into another notepad. Content of the note is not
+// changed, so no new revision is created. UserID of the note is the user
+// who moves it.
+func (r *NotesRepo) Move(n domain.Note) (domain.Note, error) {
+	var note domain.Note
+	err := transact(r.db, func(tx *gorm.DB) (err error) {
+		note, err = getNote(tx, n.ID, n.UserID)
+		if err != nil {
+			return err
+		}
+		if err = setNoteNotepad(tx, note, &n); err != nil {
+			return err
+		}
+
+		// Map updates skip the timestamp callback, so it's set here
+		err = tx.Model(&note).Updates(map[string]interface{}{
+			"notepad_id": n.NotepadID,
+			"updated_at": gorm.NowFunc(),
+		}).Error
+		if err != nil {
+			return errors.Wrap(err, "query error")
+		}
+
+		tags, err := getNoteTags(tx, []int{note.ID})
+		if err != nil {
+			return errors.Wrap(err, "get tags")
+		}
+		note.Tags = tags[note.ID]
+		return nil
+	})
+	if err != nil {
+		return domain.Note{}, err
+	}
+	return note, nil
+}
+
+// Delete moves note to trash. Notes of other users are moved
+// to the trash of their owners.
 func (r *NotesRepo) Delete(n domain.Note) error {
 	err := transact(r.db, func(tx *gorm.DB) (err error) {
-		err = tx.Where("id = ? AND user_id = ?", n.ID, n.UserID).Delete(&domain.Note{}).Error
+		note, err := getNote(tx, n.ID, n.UserID)
+		if err == domain.ErrNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		// Gorm sets deleted_at instead of deleting the row,
+		// because domain.Note has DeletedAt field
+		err = tx.Where("id = ?", note.ID).Delete(&domain.Note{}).Error
+		if err != nil {
+			return errors.Wrap(err, "query error")
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetRevisions gets note revisions from repository, latest first.
+func (r *NotesRepo) GetRevisions(f storage.RevisionsFilter) ([]domain.NoteRevision, error) {
+	rr := []domain.NoteRevision{}
+
+	// Revisions of notes in trash are not available
+	q := r.db.Where("note_id IN (SELECT id FROM note WHERE deleted_at IS NULL)")
+	if f.NoteID != nil {
+		q = q.Where("note_id = ?", *f.NoteID)
+	}
+	if f.UserID != nil {
+		q = q.Where("note_id IN (SELECT id FROM note WHERE "+noteAccess+")",
+			accessArgs(*f.UserID, domain.RoleViewer)...)
+	}
+	if f.Number != nil {
+		q = q.Where("number = ?", *f.Number)
+	}
+
+	if err := q.Order("number DESC").Find(&rr).Error; err != nil {
+		return nil, errors.Wrap(err, "query error")
+	}
+
+	return rr, nil
+}
+
+// Restore sets note title and text to the ones from the given revision.
+// Restoring creates a new revision, so history is never rewritten.
+// UserID of the note is the user who restores it.
+func (r *NotesRepo) Restore(n domain.Note, revision int) (domain.Note, error) {
+	err := transact(r.db, func(tx *gorm.DB) (err error) {
+		n, err = getNote(tx, n.ID, n.UserID)
 		if err != nil {
+			return err
+		}
+
+		var rev domain.NoteRevision
+		err = tx.Where("note_id = ? AND number = ?", n.ID, revision).
+			Find(&rev).
+			Error
+		if err == gorm.ErrRecordNotFound {
+			return domain.ErrNotFound
+		}
+		if err != nil {
+			return errors.Wrap(err, "get revision from database")
+		}
+
+		// Explicit tags are kept, parsed ones follow the restored text
+		tags, err := getNoteTags(tx, []int{n.ID})
+		if err != nil {
+			return errors.Wrap(err, "get tags")
+		}
+		n.Tags = tags[n.ID]
+
+		n.Title = rev.Title
+		n.Text = rev.Text
+		n.UpdatedAt = nil // let gorm callback set the new time
+		if err = tx.Save(&n).Error; err != nil {
 			return errors.Wrap(err, "query error")
 		}
+		if err = setNoteTags(tx, &n); err != nil {
+			return errors.Wrap(err, "set tags")
+		}
+		if err = setNoteLinks(tx, n); err != nil {
+			return errors.Wrap(err, "set links")
+		}
+
+		if err = createRevision(tx, n); err != nil {
+			return errors.Wrap(err, "create revision")
+		}
+
 		return nil
 	})
+	if err != nil {
+		return domain.Note{}, err
+	}
+	return n, nil
+}
+
+// getNote gets note that is going to be changed by the user, and
+// locks it until the end of transaction.
+func getNote(tx *gorm.DB, id, userID int) (domain.Note, error) {
+	var n domain.Note
+	err := tx.Set("gorm:query_option", "FOR UPDATE").
+		Where("id = ?", id).
+		Find(&n).
+		Error
+	if err == gorm.ErrRecordNotFound {
+		return domain.Note{}, domain.ErrNotFound
+	}
+	if err != nil {
+		return domain.Note{}, errors.Wrap(err, "check note in database")
+	}
+
+	role, err := noteRole(tx, n, userID)
+	if err != nil {
+		return domain.Note{}, errors.Wrap(err, "get role")
+	}
+	if err = requireRole(role, domain.RoleEditor, domain.ErrNotFound); err != nil {
+		return domain.Note{}, err
+	}
+	return n, nil
+}
+
+// setNoteNotepad checks if the note can be put into the notepad set
+// in n, and sets the owner of the note. UserID of n is the user who
+// makes the change, the new notepad must be editable by them.
+func setNoteNotepad(tx *gorm.DB, note domain.Note, n *domain.Note) error {
+	userID := n.UserID
+	n.UserID = note.UserID
+
+	notepad, err := getParentNotepad(tx, n.NotepadID)
 	if err != nil {
 		return err
 	}
+	if n.NotepadID != note.NotepadID {
+		role, err := notepadRole(tx, notepad, userID)
+		if err != nil {
+			return errors.Wrap(err, "get role")
+		}
+		if err = requireRole(role, domain.RoleEditor, domain.ErrParentNotFound); err != nil {
+			return err
+		}
+	}
+	return n.CheckParent(notepad)
+}
+
+// createRevision saves current state of the note as its next revision.
+// Must be called inside a transaction that has the note locked.
+func createRevision(tx *gorm.DB, n domain.Note) error {
+	var last struct {
+		Number int `gorm:"column:number"`
+	}
+	err := tx.Table("note_revision").
+		Select("COALESCE(MAX(number), 0) AS number").
+		Where("note_id = ?", n.ID).
+		Scan(&last).
+		Error
+	if err != nil {
+		return errors.Wrap(err, "get last revision number")
+	}
+
+	rev := domain.NoteRevision{
+		NoteID: n.ID,
+		UserID: n.UserID,
+		Number: last.Number + 1,
+		Title:  n.Title,
+		Text:   n.Text,
+	}
+	if err = tx.Create(&rev).Error; err != nil {
+		return errors.Wrap(err, "query error")
+	}
 	return nil
 }
+
+// filterNotes applies filter to the notes query.
+func filterNotes(q *gorm.DB, f storage.NotesFilter) *gorm.DB {
+	if f.ID != nil {
+		q = q.Where("id = ?", *f.ID)
+	}
+	if f.UserID != nil {
+		q = q.Where(noteAccess, accessArgs(*f.UserID, domain.RoleViewer)...)
+	}
+	if f.NotepadID != nil {
+		q = q.Where("notepad_id = ?", *f.NotepadID)
+	}
+	if len(f.Tags) > 0 {
+		tagged := "SELECT nt.note_id FROM note_tag nt " +
+			"JOIN tag t ON t.id = nt.tag_id WHERE t.name IN (?)"
+		if f.AllTags {
+			q = q.Where("id IN ("+tagged+" GROUP BY nt.note_id HAVING COUNT(*) = ?)",
+				f.Tags, len(f.Tags))
+		} else {
+			q = q.Where("id IN ("+tagged+")", f.Tags)
+		}
+	}
+	if f.LinksTo != nil {
+		q = q.Where("id IN (SELECT note_id FROM note_link_target WHERE target_id = ?)", *f.LinksTo)
+	}
+	if f.Query != nil {
+		q = q.Joins("CROSS JOIN plainto_tsquery('"+searchConfig+"', ?) AS query", *f.Query).
+			Where("search @@ query")
+	}
+	return q
+}
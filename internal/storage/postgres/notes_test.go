@@ -0,0 +1,27 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tetafro/nott-backend-go/internal/domain"
+)
+
+func TestNotesRepo(t *testing.T) {
+	t.Run("Update time of moved note", func(t *testing.T) {
+		db := testDB(t)
+		defer db.Close() // nolint: errcheck
+		migrateTo(t, db, 0)
+		execFile(t, db, "testdata/objects.sql")
+
+		repo := NewNotesRepo(db)
+		_, err := repo.Move(domain.Note{ID: 1, UserID: 1, NotepadID: 2})
+		assert.NoError(t, err)
+
+		if updated := updatedAt(t, db, "note", 1); assert.NotNil(t, updated) {
+			assert.WithinDuration(t, time.Now(), *updated, time.Minute)
+		}
+	})
+}
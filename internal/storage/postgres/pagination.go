@@ -0,0 +1,46 @@
+package postgres
+
+import (
+	"github.com/jinzhu/gorm"
+
+	"github.com/tetafro/nott-backend-go/internal/storage"
+)
+
+// paginate applies sort order, cursor and limit to the query.
+// Keyset pagination is used: objects are ordered by the sort field
+// and then by ID, and the page starts right after the cursor.
+func paginate(q *gorm.DB, p *storage.Page) *gorm.DB {
+	if p == nil {
+		return q
+	}
+
+	var col string
+	switch p.Sort.Field {
+	case storage.SortTitle:
+		col = "title"
+	case storage.SortUpdatedAt:
+		// Objects that have never been updated have NULL in updated_at
+		col = "COALESCE(updated_at, created_at)"
+	default:
+		col = "created_at"
+	}
+	dir, cmp := "ASC", ">"
+	if p.Sort.Desc {
+		dir, cmp = "DESC", "<"
+	}
+
+	if p.After != nil {
+		var val interface{}
+		if p.After.Title != nil {
+			val = *p.After.Title
+		} else {
+			val = *p.After.Time
+		}
+		q = q.Where("("+col+", id) "+cmp+" (?, ?)", val, p.After.ID)
+	}
+	q = q.Order(col + " " + dir).Order("id " + dir)
+	if p.Limit > 0 {
+		q = q.Limit(p.Limit)
+	}
+	return q
+}
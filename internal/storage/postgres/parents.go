@@ -0,0 +1,44 @@
+package postgres
+
+import (
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+
+	"github.com/tetafro/nott-backend-go/internal/domain"
+)
+
+// getParentFolder gets folder that is going to contain another object.
+// The folder is locked until the end of transaction, so it can't be
+// deleted in the meantime.
+func getParentFolder(tx *gorm.DB, id int) (domain.Folder, error) {
+	var f domain.Folder
+	err := tx.Set("gorm:query_option", "FOR SHARE").
+		Where("id = ?", id).
+		Find(&f).
+		Error
+	if err == gorm.ErrRecordNotFound {
+		return domain.Folder{}, domain.ErrParentNotFound
+	}
+	if err != nil {
+		return domain.Folder{}, errors.Wrap(err, "query error")
+	}
+	return f, nil
+}
+
+// getParentNotepad gets notepad that is going to contain a note.
+// The notepad is locked until the end of transaction, so it can't be
+// deleted in the meantime.
+func getParentNotepad(tx *gorm.DB, id int) (domain.Notepad, error) {
+	var n domain.Notepad
+	err := tx.Set("gorm:query_option", "FOR SHARE").
+		Where("id = ?", id).
+		Find(&n).
+		Error
+	if err == gorm.ErrRecordNotFound {
+		return domain.Notepad{}, domain.ErrParentNotFound
+	}
+	if err != nil {
+		return domain.Notepad{}, errors.Wrap(err, "query error")
+	}
+	return n, nil
+}
@@ -0,0 +1,85 @@
+package postgres
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+
+	"github.com/tetafro/nott-backend-go/internal/auth"
+	"github.com/tetafro/nott-backend-go/internal/domain"
+)
+
+// PasswordResetsRepo is a password reset tokens repository that uses
+// PostgreSQL as a backend.
+type PasswordResetsRepo struct {
+	db *gorm.DB
+}
+
+// NewPasswordResetsRepo creates new PostgreSQL repository for password
+// reset tokens.
+func NewPasswordResetsRepo(db *gorm.DB) *PasswordResetsRepo {
+	return &PasswordResetsRepo{db: db}
+}
+
+// Create creates password reset token in repository. Only the last
+// requested token can be used.
+func (r *PasswordResetsRepo) Create(pr auth.PasswordReset) (auth.PasswordReset, error) {
+	err := transact(r.db, func(tx *gorm.DB) error {
+		err := tx.Where("user_id = ? AND used_at IS NULL", pr.UserID).
+			Delete(&auth.PasswordReset{}).
+			Error
+		if err != nil {
+			return errors.Wrap(err, "delete previous tokens")
+		}
+		if err = tx.Create(&pr).Error; err != nil {
+			return errors.Wrap(err, "create token")
+		}
+		return nil
+	})
+	if err != nil {
+		return auth.PasswordReset{}, err
+	}
+	return pr, nil
+}
+
+// Use marks password reset token as used.
+func (r *PasswordResetsRepo) Use(hash string) (auth.PasswordReset, error) {
+	var pr auth.PasswordReset
+	err := transact(r.db, func(tx *gorm.DB) (err error) {
+		err = tx.Set("gorm:query_option", "FOR UPDATE").
+			Where("hash = ?", hash).
+			Find(&pr).
+			Error
+		if err == gorm.ErrRecordNotFound {
+			return domain.ErrNotFound
+		}
+		if err != nil {
+			return errors.Wrap(err, "get token")
+		}
+
+		now := time.Now().UTC()
+		if pr.UsedAt != nil || now.After(pr.ExpiresAt) {
+			return domain.ErrNotFound
+		}
+		if err = tx.Model(&pr).Update("used_at", now).Error; err != nil {
+			return errors.Wrap(err, "mark token as used")
+		}
+		return nil
+	})
+	if err != nil {
+		return auth.PasswordReset{}, err
+	}
+	return pr, nil
+}
+
+// DeleteExpired deletes expired tokens.
+func (r *PasswordResetsRepo) DeleteExpired() error {
+	err := r.db.Where("expires_at < ?", time.Now().UTC()).
+		Delete(&auth.PasswordReset{}).
+		Error
+	if err != nil {
+		return errors.Wrap(err, "query error")
+	}
+	return nil
+}
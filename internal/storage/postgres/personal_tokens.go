@@ -0,0 +1,71 @@
+package postgres
+
+import (
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+
+	"github.com/tetafro/nott-backend-go/internal/auth"
+	"github.com/tetafro/nott-backend-go/internal/domain"
+	"github.com/tetafro/nott-backend-go/internal/storage"
+)
+
+// PersonalTokensRepo is a personal access tokens repository that uses
+// PostgreSQL as a backend.
+type PersonalTokensRepo struct {
+	db *gorm.DB
+}
+
+// NewPersonalTokensRepo creates new PostgreSQL repository for personal
+// access tokens.
+func NewPersonalTokensRepo(db *gorm.DB) *PersonalTokensRepo {
+	return &PersonalTokensRepo{db: db}
+}
+
+// Get gets personal access tokens from repository.
+func (r *PersonalTokensRepo) Get(f storage.PersonalTokensFilter) ([]auth.PersonalToken, error) {
+	tt := []auth.PersonalToken{}
+
+	q := r.db
+	if f.UserID != nil {
+		q = q.Where("user_id = ?", *f.UserID)
+	}
+	if f.Hash != nil {
+		q = q.Where("hash = ?", *f.Hash)
+	}
+
+	if err := q.Order("id").Find(&tt).Error; err != nil {
+		return nil, errors.Wrap(err, "query error")
+	}
+
+	return tt, nil
+}
+
+// Create creates personal access token in repository.
+func (r *PersonalTokensRepo) Create(t auth.PersonalToken) (auth.PersonalToken, error) {
+	if err := r.db.Create(&t).Error; err != nil {
+		return auth.PersonalToken{}, errors.Wrap(err, "query error")
+	}
+	return t, nil
+}
+
+// Delete deletes personal access token from repository.
+func (r *PersonalTokensRepo) Delete(id, userID int) error {
+	q := r.db.Where("id = ? AND user_id = ?", id, userID).
+		Delete(&auth.PersonalToken{})
+	if err := q.Error; err != nil {
+		return errors.Wrap(err, "query error")
+	}
+	if q.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteAll deletes all personal access tokens of the user.
+func (r *PersonalTokensRepo) DeleteAll(userID int) error {
+	err := r.db.Where("user_id = ?", userID).Delete(&auth.PersonalToken{}).Error
+	if err != nil {
+		return errors.Wrap(err, "query error")
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package postgres
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+
+	"github.com/tetafro/nott-backend-go/internal/auth"
+	"github.com/tetafro/nott-backend-go/internal/domain"
+)
+
+// RefreshTokensRepo is a refresh tokens repository that uses
+// PostgreSQL as a backend.
+type RefreshTokensRepo struct {
+	db *gorm.DB
+}
+
+// NewRefreshTokensRepo creates new PostgreSQL repository for refresh
+// tokens.
+func NewRefreshTokensRepo(db *gorm.DB) *RefreshTokensRepo {
+	return &RefreshTokensRepo{db: db}
+}
+
+// Create creates refresh token in repository.
+func (r *RefreshTokensRepo) Create(t auth.RefreshToken) (auth.RefreshToken, error) {
+	if err := r.db.Create(&t).Error; err != nil {
+		return auth.RefreshToken{}, errors.Wrap(err, "query error")
+	}
+	return t, nil
+}
+
+// Rotate replaces the token with the next one of the same family.
+func (r *RefreshTokensRepo) Rotate(hash string, next auth.RefreshToken) (auth.RefreshToken, error) {
+	var reused bool
+	err := transact(r.db, func(tx *gorm.DB) (err error) {
+		var t auth.RefreshToken
+		err = tx.Set("gorm:query_option", "FOR UPDATE").
+			Where("hash = ?", hash).
+			Find(&t).
+			Error
+		if err == gorm.ErrRecordNotFound {
+			return domain.ErrNotFound
+		}
+		if err != nil {
+			return errors.Wrap(err, "get token")
+		}
+
+		now := time.Now().UTC()
+		if t.RevokedAt != nil || now.After(t.ExpiresAt) {
+			return domain.ErrNotFound
+		}
+		if t.UsedAt != nil {
+			// The token has been stolen, either by the one who used
+			// it first, or the one who uses it now
+			reused = true
+			return r.revokeFamily(tx, t.Family)
+		}
+
+		err = tx.Model(&t).Update("used_at", now).Error
+		if err != nil {
+			return errors.Wrap(err, "mark token as used")
+		}
+
+		next.UserID = t.UserID
+		next.SessionID = t.SessionID
+		next.Family = t.Family
+		if err = tx.Create(&next).Error; err != nil {
+			return errors.Wrap(err, "create token")
+		}
+		return nil
+	})
+	if err != nil {
+		return auth.RefreshToken{}, err
+	}
+	if reused {
+		return auth.RefreshToken{}, auth.ErrTokenReused
+	}
+	return next, nil
+}
+
+// Revoke revokes all tokens of the family of the token.
+func (r *RefreshTokensRepo) Revoke(hash string, userID int) error {
+	var t auth.RefreshToken
+	err := r.db.Select("family").
+		Where("hash = ? AND user_id = ?", hash, userID).
+		Find(&t).
+		Error
+	if err == gorm.ErrRecordNotFound {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "get token")
+	}
+	return r.revokeFamily(r.db, t.Family)
+}
+
+// DeleteExpired deletes expired tokens. Expired tokens cannot be used
+// anyway, so reusing them is not tracked.
+func (r *RefreshTokensRepo) DeleteExpired() error {
+	err := r.db.Where("expires_at < ?", time.Now().UTC()).
+		Delete(&auth.RefreshToken{}).
+		Error
+	if err != nil {
+		return errors.Wrap(err, "query error")
+	}
+	return nil
+}
+
+func (r *RefreshTokensRepo) revokeFamily(db *gorm.DB, family string) error {
+	err := db.Model(&auth.RefreshToken{}).
+		Where("family = ? AND revoked_at IS NULL", family).
+		Update("revoked_at", time.Now().UTC()).
+		Error
+	if err != nil {
+		return errors.Wrap(err, "revoke family")
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// RenderCache is a cache of rendered markdown that uses PostgreSQL
+// as a backend. It's shared between instances of the application
+// and survives restarts. Keys are hashes of the content, so entries
+// are never updated. Entries are not removed when they are added,
+// the cache grows until Trim removes the entries that were added
+// first, regardless of how often they are used.
+type RenderCache struct {
+	db   *gorm.DB
+	size int
+}
+
+// NewRenderCache creates new PostgreSQL cache of rendered markdown,
+// that keeps size entries after every trim. Size must be positive.
+func NewRenderCache(db *gorm.DB, size int) *RenderCache {
+	return &RenderCache{db: db, size: size}
+}
+
+// Get gets HTML by key.
+func (c *RenderCache) Get(key string) (string, bool, error) {
+	var entry struct {
+		HTML string `gorm:"column:html"`
+	}
+	err := c.db.Table("render_cache").
+		Select("html").
+		Where("key = ?", key).
+		Scan(&entry).
+		Error
+	if err == gorm.ErrRecordNotFound {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, errors.Wrap(err, "query error")
+	}
+	return entry.HTML, true, nil
+}
+
+// Set saves HTML.
+func (c *RenderCache) Set(key, html string) error {
+	err := c.db.Exec(
+		"INSERT INTO render_cache (key, html, created_at) VALUES (?, ?, ?) "+
+			"ON CONFLICT DO NOTHING",
+		key, html, time.Now().UTC(),
+	).Error
+	if err != nil {
+		return errors.Wrap(err, "query error")
+	}
+	return nil
+}
+
+// Trim removes the oldest entries that don't fit into the size. Entries
+// added at the same time as the last one that fits are kept too.
+func (c *RenderCache) Trim() error {
+	err := c.db.Exec(
+		"DELETE FROM render_cache WHERE created_at < ("+
+			"SELECT created_at FROM render_cache "+
+			"ORDER BY created_at DESC OFFSET ? LIMIT 1"+
+			")",
+		c.size-1,
+	).Error
+	if err != nil {
+		return errors.Wrap(err, "query error")
+	}
+	return nil
+}
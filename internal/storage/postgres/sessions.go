@@ -0,0 +1,128 @@
+package postgres
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+
+	"github.com/tetafro/nott-backend-go/internal/auth"
+	"github.com/tetafro/nott-backend-go/internal/domain"
+	"github.com/tetafro/nott-backend-go/internal/storage"
+)
+
+// sessionCreationTime is how long a new session is kept without
+// refresh tokens, they are created right after the session.
+const sessionCreationTime = time.Minute
+
+// SessionsRepo is a sessions repository that uses PostgreSQL
+// as a backend.
+type SessionsRepo struct {
+	db *gorm.DB
+}
+
+// NewSessionsRepo creates new PostgreSQL repository for sessions.
+func NewSessionsRepo(db *gorm.DB) *SessionsRepo {
+	return &SessionsRepo{db: db}
+}
+
+// Get gets sessions from repository, the most recently seen first.
+func (r *SessionsRepo) Get(f storage.SessionsFilter) ([]auth.Session, error) {
+	ss := []auth.Session{}
+
+	q := r.db
+	if f.ID != nil {
+		q = q.Where("id = ?", *f.ID)
+	}
+	if f.UserID != nil {
+		q = q.Where("user_id = ?", *f.UserID)
+	}
+
+	if err := q.Order("last_seen_at DESC, id").Find(&ss).Error; err != nil {
+		return nil, errors.Wrap(err, "query error")
+	}
+
+	return ss, nil
+}
+
+// Create creates session in repository.
+func (r *SessionsRepo) Create(s auth.Session) (auth.Session, error) {
+	if s.LastSeenAt.IsZero() {
+		s.LastSeenAt = time.Now().UTC()
+	}
+	if err := r.db.Create(&s).Error; err != nil {
+		return auth.Session{}, errors.Wrap(err, "query error")
+	}
+	return s, nil
+}
+
+// Touch updates last seen time and IP address of the session.
+func (r *SessionsRepo) Touch(id int, ip string) error {
+	err := r.db.Model(&auth.Session{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"last_seen_at": time.Now().UTC(),
+			"ip":           ip,
+		}).
+		Error
+	if err != nil {
+		return errors.Wrap(err, "query error")
+	}
+	return nil
+}
+
+// Delete deletes session from repository. Its refresh tokens
+// are deleted by cascade.
+func (r *SessionsRepo) Delete(id, userID int) error {
+	q := r.db.Where("id = ? AND user_id = ?", id, userID).
+		Delete(&auth.Session{})
+	if err := q.Error; err != nil {
+		return errors.Wrap(err, "query error")
+	}
+	if q.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteOthers deletes all sessions of the user except the given one.
+func (r *SessionsRepo) DeleteOthers(id, userID int) ([]int, error) {
+	rows, err := r.db.Raw(
+		`DELETE FROM "session" WHERE user_id = ? AND id != ? RETURNING id`,
+		userID, id,
+	).Rows()
+	if err != nil {
+		return nil, errors.Wrap(err, "query error")
+	}
+	defer rows.Close()
+
+	ids := []int{}
+	for rows.Next() {
+		var sid int
+		if err := rows.Scan(&sid); err != nil {
+			return nil, errors.Wrap(err, "scan row")
+		}
+		ids = append(ids, sid)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "read rows")
+	}
+	return ids, nil
+}
+
+// DeleteExpired deletes sessions that cannot be continued, because
+// all their refresh tokens are expired or revoked.
+func (r *SessionsRepo) DeleteExpired() error {
+	now := time.Now().UTC()
+	err := r.db.Exec(
+		`DELETE FROM "session" s WHERE s.created_at < ? AND NOT EXISTS (`+
+			`SELECT 1 FROM refresh_token t WHERE t.session_id = s.id `+
+			`AND t.expires_at > ? AND t.revoked_at IS NULL AND t.used_at IS NULL`+
+			`)`,
+		now.Add(-sessionCreationTime), now,
+	).Error
+	if err != nil {
+		return errors.Wrap(err, "query error")
+	}
+	return nil
+}
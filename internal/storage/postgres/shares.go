@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+
+	"github.com/tetafro/nott-backend-go/internal/domain"
+	"github.com/tetafro/nott-backend-go/internal/storage"
+)
+
+// SharesRepo is a public shares repository that uses PostgreSQL
+// as a backend.
+type SharesRepo struct {
+	db *gorm.DB
+}
+
+// NewSharesRepo creates new PostgreSQL repository for shares.
+func NewSharesRepo(db *gorm.DB) *SharesRepo {
+	return &SharesRepo{db: db}
+}
+
+// Get gets shares from repository.
+func (r *SharesRepo) Get(f storage.SharesFilter) ([]domain.Share, error) {
+	ss := []domain.Share{}
+
+	q := r.db
+	if f.ID != nil {
+		q = q.Where("id = ?", *f.ID)
+	}
+	if f.UserID != nil {
+		q = q.Where("user_id = ?", *f.UserID)
+	}
+	if f.Token != nil {
+		q = q.Where("token = ?", *f.Token)
+	}
+	if f.Active {
+		q = q.Where("expires_at IS NULL OR expires_at > ?", time.Now().UTC())
+	}
+
+	if err := q.Order("id").Find(&ss).Error; err != nil {
+		return nil, errors.Wrap(err, "query error")
+	}
+
+	return ss, nil
+}
+
+// Create creates share in repository. Returns domain.ErrNotFound
+// if the shared note or notepad doesn't exist. Objects of other users
+// can only be shared by users with the owner role.
+func (r *SharesRepo) Create(s domain.Share) (domain.Share, error) {
+	err := transact(r.db, func(tx *gorm.DB) (err error) {
+		q := tx.Set("gorm:query_option", "FOR SHARE").Select("id")
+		if s.NoteID != nil {
+			err = q.Where("id = ?", *s.NoteID).
+				Where(noteAccess, accessArgs(s.UserID, domain.RoleOwner)...).
+				Find(&domain.Note{}).
+				Error
+		} else {
+			err = q.Where("id = ?", *s.NotepadID).
+				Where(notepadAccess, accessArgs(s.UserID, domain.RoleOwner)...).
+				Find(&domain.Notepad{}).
+				Error
+		}
+		if err == gorm.ErrRecordNotFound {
+			return domain.ErrNotFound
+		}
+		if err != nil {
+			return errors.Wrap(err, "check shared object in database")
+		}
+
+		if err = tx.Create(&s).Error; err != nil {
+			return errors.Wrap(err, "query error")
+		}
+		return nil
+	})
+	if err != nil {
+		return domain.Share{}, err
+	}
+	return s, nil
+}
+
+// Delete deletes share from repository, so the link stops working.
+func (r *SharesRepo) Delete(s domain.Share) error {
+	err := r.db.Where("id = ? AND user_id = ?", s.ID, s.UserID).
+		Delete(&domain.Share{}).
+		Error
+	if err != nil {
+		return errors.Wrap(err, "query error")
+	}
+	return nil
+}
@@ -0,0 +1,301 @@
+package postgres
+
+import (
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+
+	"github.com/tetafro/nott-backend-go/internal/domain"
+	"github.com/tetafro/nott-backend-go/internal/storage"
+)
+
+// TagsRepo is a tags repository that uses PostgreSQL as a backend.
+type TagsRepo struct {
+	db *gorm.DB
+}
+
+// NewTagsRepo creates new PostgreSQL repository for tags.
+func NewTagsRepo(db *gorm.DB) *TagsRepo {
+	return &TagsRepo{db: db}
+}
+
+// Get gets tags from repository.
+func (r *TagsRepo) Get(f storage.TagsFilter) ([]domain.Tag, error) {
+	tt := []domain.Tag{}
+
+	q := r.db
+	if f.ID != nil {
+		q = q.Where("id = ?", *f.ID)
+	}
+	if f.UserID != nil {
+		q = q.Where("user_id = ?", *f.UserID)
+	}
+
+	if err := q.Order("name").Find(&tt).Error; err != nil {
+		return nil, errors.Wrap(err, "query error")
+	}
+
+	return tt, nil
+}
+
+// Create creates tag in repository.
+func (r *TagsRepo) Create(t domain.Tag) (domain.Tag, error) {
+	err := transact(r.db, func(tx *gorm.DB) (err error) {
+		if err = checkTagName(tx, t); err != nil {
+			return err
+		}
+		if err = tx.Create(&t).Error; err != nil {
+			return errors.Wrap(err, "query error")
+		}
+		return nil
+	})
+	if err != nil {
+		return domain.Tag{}, err
+	}
+	return t, nil
+}
+
+// Update renames tag together with its hashtags in texts of the notes.
+func (r *TagsRepo) Update(t domain.Tag) (domain.Tag, error) {
+	var tag domain.Tag
+	err := transact(r.db, func(tx *gorm.DB) (err error) {
+		tag, err = getTag(tx, t.ID, t.UserID)
+		if err != nil {
+			return err
+		}
+		if err = checkTagName(tx, t); err != nil {
+			return err
+		}
+		if err = renameHashtags(tx, tag, t.Name); err != nil {
+			return errors.Wrap(err, "rename hashtags")
+		}
+		if err = tx.Model(&tag).Update("name", t.Name).Error; err != nil {
+			return errors.Wrap(err, "query error")
+		}
+		return nil
+	})
+	if err != nil {
+		return domain.Tag{}, err
+	}
+	return tag, nil
+}
+
+// Merge attaches notes of one tag to another tag, and deletes
+// the first one. Hashtags of the first tag in texts of the notes
+// are renamed.
+func (r *TagsRepo) Merge(from, to domain.Tag) (domain.Tag, error) {
+	if from.ID == to.ID {
+		return domain.Tag{}, errors.New("cannot merge tag into itself")
+	}
+	err := transact(r.db, func(tx *gorm.DB) (err error) {
+		if from, err = getTag(tx, from.ID, from.UserID); err != nil {
+			return err
+		}
+		if to, err = getTag(tx, to.ID, to.UserID); err != nil {
+			return err
+		}
+
+		if err = renameHashtags(tx, from, to.Name); err != nil {
+			return errors.Wrap(err, "rename hashtags")
+		}
+		err = tx.Exec(
+			"INSERT INTO note_tag (note_id, tag_id, explicit) "+
+				"SELECT note_id, ?, explicit FROM note_tag WHERE tag_id = ? "+
+				"ON CONFLICT (note_id, tag_id) DO UPDATE "+
+				"SET explicit = note_tag.explicit OR EXCLUDED.explicit",
+			to.ID, from.ID,
+		).Error
+		if err != nil {
+			return errors.Wrap(err, "move notes")
+		}
+		if err = tx.Delete(&from).Error; err != nil {
+			return errors.Wrap(err, "delete tag")
+		}
+		return nil
+	})
+	if err != nil {
+		return domain.Tag{}, err
+	}
+	return to, nil
+}
+
+// Delete deletes tag from repository and detaches it from notes.
+// Hashes of its hashtags are removed from texts of the notes, and
+// the words are left.
+func (r *TagsRepo) Delete(t domain.Tag) error {
+	err := transact(r.db, func(tx *gorm.DB) (err error) {
+		t, err = getTag(tx, t.ID, t.UserID)
+		if err == domain.ErrNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err = renameHashtags(tx, t, ""); err != nil {
+			return errors.Wrap(err, "remove hashtags")
+		}
+		if err = tx.Delete(&t).Error; err != nil {
+			return errors.Wrap(err, "query error")
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// renameHashtags renames hashtags of the tag in texts of its notes,
+// including the ones in trash, so the old name is not parsed from
+// the texts again when the notes are saved. Hashes are removed if
+// the name is empty.
+func renameHashtags(tx *gorm.DB, t domain.Tag, name string) error {
+	var notes []domain.Note
+	err := tx.Unscoped().
+		Where("id IN (SELECT note_id FROM note_tag WHERE tag_id = ?)", t.ID).
+		Find(&notes).
+		Error
+	if err != nil {
+		return errors.Wrap(err, "get notes")
+	}
+
+	for _, n := range notes {
+		text := domain.ReplaceTag(n.Text, t.Name, name)
+		if text == n.Text {
+			continue
+		}
+		n.Text = text
+		// Map updates skip the timestamp callback, so it's set here
+		err = tx.Unscoped().Model(&n).Updates(map[string]interface{}{
+			"text":       n.Text,
+			"updated_at": gorm.NowFunc(),
+		}).Error
+		if err != nil {
+			return errors.Wrap(err, "update note")
+		}
+		if err = createRevision(tx, n); err != nil {
+			return errors.Wrap(err, "create revision")
+		}
+	}
+	return nil
+}
+
+// getTag gets tag of the user and locks it until the end
+// of transaction.
+func getTag(tx *gorm.DB, id, userID int) (domain.Tag, error) {
+	var t domain.Tag
+	err := tx.Set("gorm:query_option", "FOR UPDATE").
+		Where("id = ? AND user_id = ?", id, userID).
+		Find(&t).
+		Error
+	if err == gorm.ErrRecordNotFound {
+		return domain.Tag{}, domain.ErrNotFound
+	}
+	if err != nil {
+		return domain.Tag{}, errors.Wrap(err, "query error")
+	}
+	return t, nil
+}
+
+// checkTagName checks that the user has no other tag with the same name.
+func checkTagName(tx *gorm.DB, t domain.Tag) error {
+	var count int
+	err := tx.Model(&domain.Tag{}).
+		Where("user_id = ? AND name = ? AND id <> ?", t.UserID, t.Name, t.ID).
+		Count(&count).
+		Error
+	if err != nil {
+		return errors.Wrap(err, "query error")
+	}
+	if count > 0 {
+		return domain.ErrTagExists
+	}
+	return nil
+}
+
+// setNoteTags replaces tags of the note, creating the missing ones.
+// Tags of the note are explicitly set ones and #hashtags from the text,
+// the note gets all of them. Tags that were only parsed from the text
+// are not taken as explicit when clients send them back, so removing
+// a hashtag from the text detaches the tag.
+func setNoteTags(tx *gorm.DB, n *domain.Note) error {
+	var parsed []string
+	err := tx.Table("note_tag nt").
+		Joins("JOIN tag t ON t.id = nt.tag_id").
+		Where("nt.note_id = ? AND NOT nt.explicit", n.ID).
+		Pluck("t.name", &parsed).
+		Error
+	if err != nil {
+		return errors.Wrap(err, "get parsed tags")
+	}
+	wasParsed := map[string]bool{}
+	for _, name := range parsed {
+		wasParsed[name] = true
+	}
+	explicit := map[string]bool{}
+	tags := []string{}
+	for _, name := range domain.NormalizeTags(n.Tags) {
+		if !wasParsed[name] {
+			explicit[name] = true
+			tags = append(tags, name)
+		}
+	}
+	n.Tags = tags
+	n.Tags = n.CollectTags()
+
+	err = tx.Exec("DELETE FROM note_tag WHERE note_id = ?", n.ID).Error
+	if err != nil {
+		return errors.Wrap(err, "detach tags")
+	}
+
+	now := gorm.NowFunc()
+	for _, name := range n.Tags {
+		err = tx.Exec(
+			"INSERT INTO tag (user_id, name, created_at) VALUES (?, ?, ?) "+
+				"ON CONFLICT (user_id, name) DO NOTHING",
+			n.UserID, name, now,
+		).Error
+		if err != nil {
+			return errors.Wrap(err, "create tag")
+		}
+		err = tx.Exec(
+			"INSERT INTO note_tag (note_id, tag_id, explicit) "+
+				"SELECT ?, id, ? FROM tag WHERE user_id = ? AND name = ?",
+			n.ID, explicit[name], n.UserID, name,
+		).Error
+		if err != nil {
+			return errors.Wrap(err, "attach tag")
+		}
+	}
+	return nil
+}
+
+// getNoteTags gets names of tags for each of the notes.
+func getNoteTags(db *gorm.DB, noteIDs []int) (map[int][]string, error) {
+	tags := map[int][]string{}
+	if len(noteIDs) == 0 {
+		return tags, nil
+	}
+
+	rows, err := db.Raw(
+		"SELECT nt.note_id, t.name FROM note_tag nt "+
+			"JOIN tag t ON t.id = nt.tag_id "+
+			"WHERE nt.note_id IN (?) ORDER BY t.name",
+		noteIDs,
+	).Rows()
+	if err != nil {
+		return nil, errors.Wrap(err, "query error")
+	}
+	defer rows.Close() // nolint: errcheck
+
+	for rows.Next() {
+		var (
+			id   int
+			name string
+		)
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, errors.Wrap(err, "scan row")
+		}
+		tags[id] = append(tags[id], name)
+	}
+	return tags, rows.Err()
+}
@@ -0,0 +1,102 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tetafro/nott-backend-go/internal/domain"
+	"github.com/tetafro/nott-backend-go/internal/storage"
+)
+
+func TestTagsRepo(t *testing.T) {
+	// setup creates a note and gets IDs of the tags by names
+	setup := func(t *testing.T, text string) (*TagsRepo, *NotesRepo, domain.Note, map[string]int) {
+		db := testDB(t)
+		migrateTo(t, db, 0)
+		execFile(t, db, "testdata/objects.sql")
+
+		notes := NewNotesRepo(db)
+		n, err := notes.Create(domain.Note{UserID: 1, NotepadID: 1, Title: "Tags", Text: text})
+		if err != nil {
+			t.Fatalf("Failed to create note: %v", err)
+		}
+
+		tags := NewTagsRepo(db)
+		userID := 1
+		tt, err := tags.Get(storage.TagsFilter{UserID: &userID})
+		if err != nil {
+			t.Fatalf("Failed to get tags: %v", err)
+		}
+		ids := map[string]int{}
+		for _, tag := range tt {
+			ids[tag.Name] = tag.ID
+		}
+		return tags, notes, n, ids
+	}
+
+	// check checks the text and the tags of the note
+	check := func(t *testing.T, repo *TagsRepo, n domain.Note, text string, tags []string) {
+		var note domain.Note
+		err := repo.db.Unscoped().Where("id = ?", n.ID).Find(&note).Error
+		assert.NoError(t, err)
+		assert.Equal(t, text, note.Text)
+
+		noteTags, err := getNoteTags(repo.db, []int{n.ID})
+		assert.NoError(t, err)
+		assert.Equal(t, tags, noteTags[n.ID])
+	}
+
+	t.Run("Rename tag in notes", func(t *testing.T) {
+		tags, notes, n, ids := setup(t, "#Work and #home")
+		defer tags.db.Close() // nolint: errcheck
+
+		_, err := tags.Update(domain.Tag{ID: ids["work"], UserID: 1, Name: "job"})
+		assert.NoError(t, err)
+		check(t, tags, n, "#job and #home", []string{"home", "job"})
+
+		// Old name is not parsed again when the note is saved
+		n.Text = "#job and #home"
+		n.Tags = []string{"home", "job"}
+		_, err = notes.Update(n)
+		assert.NoError(t, err)
+		check(t, tags, n, "#job and #home", []string{"home", "job"})
+	})
+
+	t.Run("Merge tag in notes", func(t *testing.T) {
+		tags, _, n, ids := setup(t, "#one, #two")
+		defer tags.db.Close() // nolint: errcheck
+
+		_, err := tags.Merge(
+			domain.Tag{ID: ids["one"], UserID: 1},
+			domain.Tag{ID: ids["two"], UserID: 1},
+		)
+		assert.NoError(t, err)
+		check(t, tags, n, "#two, #two", []string{"two"})
+	})
+
+	t.Run("Delete tag from notes", func(t *testing.T) {
+		tags, _, n, ids := setup(t, "#work and #home")
+		defer tags.db.Close() // nolint: errcheck
+
+		err := tags.Delete(domain.Tag{ID: ids["work"], UserID: 1})
+		assert.NoError(t, err)
+		check(t, tags, n, "work and #home", []string{"home"})
+	})
+
+	t.Run("Remove hashtag from note", func(t *testing.T) {
+		tags, notes, n, _ := setup(t, "#work")
+		defer tags.db.Close() // nolint: errcheck
+
+		// Clients send back all tags of the note
+		n.Tags = []string{"home", "work"}
+		n, err := notes.Update(n)
+		assert.NoError(t, err)
+		check(t, tags, n, "#work", []string{"home", "work"})
+
+		n.Text = "No tags"
+		n, err = notes.Update(n)
+		assert.NoError(t, err)
+		check(t, tags, n, "No tags", []string{"home"})
+	})
+}
@@ -0,0 +1,23 @@
+-- Objects of one user attached to parents of another user, that
+-- were allowed before ownership checks. User 1 is created by the
+-- first migration.
+INSERT INTO "user" (id, email, password, created_at)
+VALUES (2, 'eve@example.com', 'hash', NOW());
+
+INSERT INTO "folder" (id, user_id, parent_id, title, created_at) VALUES
+    (1, 1, NULL, 'Bob', NOW()),
+    (2, 2, 1, 'Eve in Bob', NOW());
+
+INSERT INTO "notepad" (id, user_id, folder_id, title, created_at) VALUES
+    (1, 1, 1, 'Bob', NOW()),
+    (2, 2, 1, 'Eve in Bob', NOW());
+
+INSERT INTO "note" (id, user_id, notepad_id, title, text, created_at) VALUES
+    (1, 1, 1, 'Bob', '', NOW()),
+    (2, 2, 1, 'Eve in Bob', '', NOW()),
+    (3, 2, 2, 'Eve in Eve', '', NOW());
+
+SELECT setval(pg_get_serial_sequence('"user"', 'id'), 2);
+SELECT setval(pg_get_serial_sequence('folder', 'id'), 2);
+SELECT setval(pg_get_serial_sequence('notepad', 'id'), 2);
+SELECT setval(pg_get_serial_sequence('note', 'id'), 3);
@@ -0,0 +1,15 @@
+-- Folders in cycles, that were allowed before moves were checked.
+-- User 1 is created by the first migration.
+INSERT INTO "folder" (id, user_id, parent_id, title, created_at) VALUES
+    (1, 1, NULL, 'Root', NOW()),
+    (2, 1, 1, 'Not in cycle', NOW()),
+    (3, 1, NULL, 'Self', NOW()),
+    (4, 1, NULL, 'Cycle A', NOW()),
+    (5, 1, 4, 'Cycle B', NOW()),
+    (6, 1, 5, 'Cycle C', NOW()),
+    (7, 1, 6, 'Under cycle', NOW());
+
+UPDATE "folder" SET parent_id = 3 WHERE id = 3;
+UPDATE "folder" SET parent_id = 6 WHERE id = 4;
+
+SELECT setval(pg_get_serial_sequence('folder', 'id'), 7);
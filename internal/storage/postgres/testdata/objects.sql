@@ -0,0 +1,16 @@
+-- Folders, notepads and notes of one user, that were last updated
+-- long ago. User 1 is created by the first migration.
+INSERT INTO "folder" (id, user_id, parent_id, title, created_at, updated_at) VALUES
+    (1, 1, NULL, 'First', '2020-01-01', '2020-01-01'),
+    (2, 1, NULL, 'Second', '2020-01-01', '2020-01-01');
+
+INSERT INTO "notepad" (id, user_id, folder_id, title, created_at, updated_at) VALUES
+    (1, 1, 1, 'First', '2020-01-01', '2020-01-01'),
+    (2, 1, 2, 'Second', '2020-01-01', '2020-01-01');
+
+INSERT INTO "note" (id, user_id, notepad_id, title, text, created_at, updated_at) VALUES
+    (1, 1, 1, 'First', 'Hello', '2020-01-01', '2020-01-01');
+
+SELECT setval(pg_get_serial_sequence('folder', 'id'), 2);
+SELECT setval(pg_get_serial_sequence('notepad', 'id'), 2);
+SELECT setval(pg_get_serial_sequence('note', 'id'), 1);
@@ -0,0 +1,227 @@
+package postgres
+
+import (
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+
+	"github.com/tetafro/nott-backend-go/internal/domain"
+	"github.com/tetafro/nott-backend-go/internal/storage"
+)
+
+// TrashRepo is a trash repository that uses PostgreSQL as a backend.
+type TrashRepo struct {
+	db *gorm.DB
+}
+
+// NewTrashRepo creates new PostgreSQL repository for trash.
+func NewTrashRepo(db *gorm.DB) *TrashRepo {
+	return &TrashRepo{db: db}
+}
+
+// Get gets objects that were explicitly moved to trash by the user.
+// Objects that were deleted together with their parent are skipped.
+func (r *TrashRepo) Get(userID int) (domain.Trash, error) {
+	t := domain.Trash{
+		Folders:  []domain.Folder{},
+		Notepads: []domain.Notepad{},
+		Notes:    []domain.Note{},
+	}
+
+	err := r.db.Table("folder f").
+		Select("f.*").
+		Joins("LEFT JOIN folder p ON p.id = f.parent_id").
+		Where("f.user_id = ? AND f.deleted_at IS NOT NULL", userID).
+		Where("p.deleted_at IS DISTINCT FROM f.deleted_at").
+		Order("f.deleted_at DESC").
+		Scan(&t.Folders).
+		Error
+	if err != nil {
+		return domain.Trash{}, errors.Wrap(err, "get folders")
+	}
+
+	err = r.db.Table("notepad n").
+		Select("n.*").
+		Joins("JOIN folder f ON f.id = n.folder_id").
+		Where("n.user_id = ? AND n.deleted_at IS NOT NULL", userID).
+		Where("f.deleted_at IS DISTINCT FROM n.deleted_at").
+		Order("n.deleted_at DESC").
+		Scan(&t.Notepads).
+		Error
+	if err != nil {
+		return domain.Trash{}, errors.Wrap(err, "get notepads")
+	}
+
+	err = r.db.Table("note n").
+		Select("n.*").
+		Joins("JOIN notepad p ON p.id = n.notepad_id").
+		Where("n.user_id = ? AND n.deleted_at IS NOT NULL", userID).
+		Where("p.deleted_at IS DISTINCT FROM n.deleted_at").
+		Order("n.deleted_at DESC").
+		Scan(&t.Notes).
+		Error
+	if err != nil {
+		return domain.Trash{}, errors.Wrap(err, "get notes")
+	}
+
+	return t, nil
+}
+
+// Restore restores object from trash together with all the children
+// that were deleted with it.
+func (r *TrashRepo) Restore(item domain.TrashItem) error {
+	return transact(r.db, func(tx *gorm.DB) error {
+		switch item.Type {
+		case domain.TrashFolder:
+			return restoreFolder(tx, item)
+		case domain.TrashNotepad:
+			return restoreNotepad(tx, item)
+		case domain.TrashNote:
+			return restoreNote(tx, item)
+		}
+		return errors.Errorf("unknown object type: %s", item.Type)
+	})
+}
+
+// Purge deletes object from trash permanently. Children are
+// deleted by the database.
+func (r *TrashRepo) Purge(item domain.TrashItem) error {
+	var table string
+	switch item.Type {
+	case domain.TrashFolder:
+		table = "folder"
+	case domain.TrashNotepad:
+		table = "notepad"
+	case domain.TrashNote:
+		table = "note"
+	default:
+		return errors.Errorf("unknown object type: %s", item.Type)
+	}
+
+	q := r.db.Exec(
+		"DELETE FROM "+table+" WHERE id = ? AND user_id = ? AND deleted_at IS NOT NULL",
+		item.ID, item.UserID,
+	)
+	if q.Error != nil {
+		return errors.Wrap(q.Error, "query error")
+	}
+	if q.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// Empty permanently deletes objects from trash.
+func (r *TrashRepo) Empty(f storage.TrashFilter) error {
+	models := []interface{}{&domain.Note{}, &domain.Notepad{}, &domain.Folder{}}
+	return transact(r.db, func(tx *gorm.DB) error {
+		for _, m := range models {
+			q := tx.Unscoped().Where("deleted_at IS NOT NULL")
+			if f.UserID != nil {
+				q = q.Where("user_id = ?", *f.UserID)
+			}
+			if f.DeletedBefore != nil {
+				q = q.Where("deleted_at < ?", *f.DeletedBefore)
+			}
+			if err := q.Delete(m).Error; err != nil {
+				return errors.Wrapf(err, "purge %T", m)
+			}
+		}
+		return nil
+	})
+}
+
+func restoreFolder(tx *gorm.DB, item domain.TrashItem) error {
+	var f domain.Folder
+	err := tx.Unscoped().
+		Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", item.ID, item.UserID).
+		Find(&f).
+		Error
+	if err == gorm.ErrRecordNotFound {
+		return domain.ErrNotFound
+	}
+	if err != nil {
+		return errors.Wrap(err, "get folder")
+	}
+
+	if f.ParentID != nil {
+		err = tx.Select("id").Where("id = ?", *f.ParentID).Find(&domain.Folder{}).Error
+		if err == gorm.ErrRecordNotFound {
+			return domain.ErrParentDeleted
+		}
+		if err != nil {
+			return errors.Wrap(err, "check parent folder")
+		}
+	}
+
+	ids, err := folderSubtree(tx, f.ID, f.DeletedAt)
+	if err != nil {
+		return errors.Wrap(err, "get subtree")
+	}
+	if err = setDeletedAt(tx, ids, f.DeletedAt, nil); err != nil {
+		return errors.Wrap(err, "restore subtree")
+	}
+	return nil
+}
+
+func restoreNotepad(tx *gorm.DB, item domain.TrashItem) error {
+	var n domain.Notepad
+	err := tx.Unscoped().
+		Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", item.ID, item.UserID).
+		Find(&n).
+		Error
+	if err == gorm.ErrRecordNotFound {
+		return domain.ErrNotFound
+	}
+	if err != nil {
+		return errors.Wrap(err, "get notepad")
+	}
+
+	err = tx.Select("id").Where("id = ?", n.FolderID).Find(&domain.Folder{}).Error
+	if err == gorm.ErrRecordNotFound {
+		return domain.ErrParentDeleted
+	}
+	if err != nil {
+		return errors.Wrap(err, "check folder")
+	}
+
+	err = tx.Exec(
+		"UPDATE note SET deleted_at = NULL WHERE notepad_id = ? AND deleted_at = ?",
+		n.ID, n.DeletedAt,
+	).Error
+	if err != nil {
+		return errors.Wrap(err, "restore notes")
+	}
+	err = tx.Exec("UPDATE notepad SET deleted_at = NULL WHERE id = ?", n.ID).Error
+	if err != nil {
+		return errors.Wrap(err, "restore notepad")
+	}
+	return nil
+}
+
+func restoreNote(tx *gorm.DB, item domain.TrashItem) error {
+	var n domain.Note
+	err := tx.Unscoped().
+		Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", item.ID, item.UserID).
+		Find(&n).
+		Error
+	if err == gorm.ErrRecordNotFound {
+		return domain.ErrNotFound
+	}
+	if err != nil {
+		return errors.Wrap(err, "get note")
+	}
+
+	err = tx.Select("id").Where("id = ?", n.NotepadID).Find(&domain.Notepad{}).Error
+	if err == gorm.ErrRecordNotFound {
+		return domain.ErrParentDeleted
+	}
+	if err != nil {
+		return errors.Wrap(err, "check notepad")
+	}
+
+	err = tx.Exec("UPDATE note SET deleted_at = NULL WHERE id = ?", n.ID).Error
+	if err != nil {
+		return errors.Wrap(err, "restore note")
+	}
+	return nil
+}
@@ -18,11 +18,11 @@ func NewUsersRepo(db *gorm.DB) *UsersRepo {
 	return &UsersRepo{db: db}
 }
 
-// GetByID gets user by his email from repository.
+// GetByID gets user by ID from repository.
 func (r *UsersRepo) GetByID(id int) (auth.User, error) {
 	var u auth.User
 
-	err := r.db.Find(&u).Error
+	err := r.db.Where("id = ?", id).Find(&u).Error
 	if err == gorm.ErrRecordNotFound {
 		return auth.User{}, domain.ErrNotFound
 	}
@@ -74,13 +74,22 @@ func (r *UsersRepo) Update(u auth.User) (auth.User, error) {
 			return errors.Wrap(err, "check user in database")
 		}
 
-		// NOTE: Save() method doesn't return ErrRecordNotFound, but
-		// instead makes INSERT. But this is the only method that updates
-		// all fields of the structure (even if they are empty).
-		if err = tx.Save(&u).Error; err != nil {
+		// Only profile fields are updated, password and timestamps
+		// of creation are not in the request
+		err = tx.Model(&auth.User{}).
+			Where("id = ?", u.ID).
+			Updates(map[string]interface{}{
+				"email": u.Email,
+				"theme": u.Theme,
+			}).
+			Error
+		if err != nil {
 			return errors.Wrap(err, "query error")
 		}
 
+		if err = tx.Where("id = ?", u.ID).Find(&u).Error; err != nil {
+			return errors.Wrap(err, "get user")
+		}
 		return nil
 	})
 	if err != nil {
@@ -88,3 +97,17 @@ func (r *UsersRepo) Update(u auth.User) (auth.User, error) {
 	}
 	return u, nil
 }
+
+// UpdatePassword updates hash of user's password in repository.
+func (r *UsersRepo) UpdatePassword(id int, hash string) error {
+	q := r.db.Model(&auth.User{}).
+		Where("id = ?", id).
+		Update("password", hash)
+	if err := q.Error; err != nil {
+		return errors.Wrap(err, "query error")
+	}
+	if q.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
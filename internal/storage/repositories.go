@@ -1,6 +1,8 @@
 package storage
 
 import (
+	"time"
+
 	"github.com/tetafro/nott-backend-go/internal/auth"
 	"github.com/tetafro/nott-backend-go/internal/domain"
 )
@@ -10,7 +12,63 @@ type UsersRepo interface {
 	GetByID(id int) (auth.User, error)
 	GetByEmail(email string) (auth.User, error)
 	Create(auth.User) (auth.User, error)
+	// Update updates profile of the user, password is not changed
 	Update(auth.User) (auth.User, error)
+	// UpdatePassword sets hash of the user's password, returns
+	// domain.ErrNotFound if there is no such user
+	UpdatePassword(id int, hash string) error
+}
+
+// PasswordResetsRepo deals with password reset tokens.
+type PasswordResetsRepo interface {
+	// Create creates the token, other unused tokens of the user
+	// are deleted
+	Create(auth.PasswordReset) (auth.PasswordReset, error)
+	// Use marks the token with the hash as used, returns
+	// domain.ErrNotFound if the token doesn't exist, or it's expired
+	// or used
+	Use(hash string) (auth.PasswordReset, error)
+	DeleteExpired() error
+}
+
+// SessionsRepo deals with sessions of users.
+type SessionsRepo interface {
+	Get(SessionsFilter) ([]auth.Session, error)
+	Create(auth.Session) (auth.Session, error)
+	// Touch updates last seen time and IP address of the session
+	Touch(id int, ip string) error
+	// Delete deletes the user's session with its refresh tokens,
+	// returns domain.ErrNotFound if there is no such session
+	Delete(id, userID int) error
+	// DeleteOthers deletes all sessions of the user except the given
+	// one, and returns IDs of the deleted sessions
+	DeleteOthers(id, userID int) ([]int, error)
+	// DeleteExpired deletes sessions that have no valid refresh tokens
+	DeleteExpired() error
+}
+
+// RefreshTokensRepo deals with refresh tokens of users.
+type RefreshTokensRepo interface {
+	Create(auth.RefreshToken) (auth.RefreshToken, error)
+	// Rotate marks the token with the hash as used, and creates the next
+	// token of its family. Reusing the token revokes the whole family
+	// and returns auth.ErrTokenReused. Returns domain.ErrNotFound
+	// if the token doesn't exist, or it's expired or revoked
+	Rotate(hash string, next auth.RefreshToken) (auth.RefreshToken, error)
+	// Revoke revokes the whole family of the user's token
+	Revoke(hash string, userID int) error
+	DeleteExpired() error
+}
+
+// PersonalTokensRepo deals with personal access tokens of users.
+type PersonalTokensRepo interface {
+	Get(PersonalTokensFilter) ([]auth.PersonalToken, error)
+	Create(auth.PersonalToken) (auth.PersonalToken, error)
+	// Delete deletes the user's token, returns domain.ErrNotFound
+	// if there is no such token
+	Delete(id, userID int) error
+	// DeleteAll deletes all tokens of the user
+	DeleteAll(userID int) error
 }
 
 // FoldersRepo deals with folders repository.
@@ -19,6 +77,8 @@ type FoldersRepo interface {
 	Create(domain.Folder) (domain.Folder, error)
 	Update(domain.Folder) (domain.Folder, error)
 	Delete(domain.Folder) error
+	Move(domain.Folder) (domain.Folder, error)
+	GetTree(TreeFilter) ([]domain.FolderNode, error)
 }
 
 // NotepadsRepo deals with notepads repository.
@@ -27,20 +87,115 @@ type NotepadsRepo interface {
 	Create(domain.Notepad) (domain.Notepad, error)
 	Update(domain.Notepad) (domain.Notepad, error)
 	Delete(domain.Notepad) error
+	Move(domain.Notepad) (domain.Notepad, error)
 }
 
 // NotesRepo deals with notes repository.
 type NotesRepo interface {
 	Get(NotesFilter) ([]domain.Note, error)
+	Search(NotesFilter) ([]domain.NoteHit, error)
 	Create(domain.Note) (domain.Note, error)
 	Update(domain.Note) (domain.Note, error)
 	Delete(domain.Note) error
+	Move(domain.Note) (domain.Note, error)
+	GetRevisions(RevisionsFilter) ([]domain.NoteRevision, error)
+	Restore(n domain.Note, revision int) (domain.Note, error)
+	GetLinks(LinksFilter) ([]domain.NoteLink, error)
+	// GetGraph gets notes available to the user and links between them
+	GetGraph(userID int) (domain.NoteGraph, error)
+}
+
+// TrashRepo deals with deleted folders, notepads and notes.
+type TrashRepo interface {
+	Get(userID int) (domain.Trash, error)
+	Restore(domain.TrashItem) error
+	Purge(domain.TrashItem) error
+	Empty(TrashFilter) error
+}
+
+// TagsRepo deals with tags repository.
+type TagsRepo interface {
+	Get(TagsFilter) ([]domain.Tag, error)
+	Create(domain.Tag) (domain.Tag, error)
+	Update(domain.Tag) (domain.Tag, error)
+	Merge(from, to domain.Tag) (domain.Tag, error)
+	Delete(domain.Tag) error
+}
+
+// AttachmentsRepo deals with attachments repository.
+type AttachmentsRepo interface {
+	Get(AttachmentsFilter) ([]domain.Attachment, error)
+	// Usage gets total size of user's attachments
+	Usage(userID int) (int64, error)
+	// Create creates attachment if it fits into user's quota
+	Create(a domain.Attachment, quota int64) (domain.Attachment, error)
+	Delete(domain.Attachment) error
+	// DeletedKeys gets blob keys of deleted attachments
+	DeletedKeys(limit int) ([]string, error)
+	// ForgetKeys removes keys from the list of deleted attachments,
+	// it's called when the blobs are deleted
+	ForgetKeys([]string) error
+}
+
+// SharesRepo deals with public shares of notes and notepads.
+type SharesRepo interface {
+	Get(SharesFilter) ([]domain.Share, error)
+	Create(domain.Share) (domain.Share, error)
+	Delete(domain.Share) error
+}
+
+// GrantsRepo deals with access of users to folders and notepads
+// of other users.
+type GrantsRepo interface {
+	Get(GrantsFilter) ([]domain.Grant, error)
+	// Invite gives the user with the email a role on the folder or
+	// notepad, existing grant gets the new role
+	Invite(g domain.Grant, email string) (domain.Grant, error)
+	// Accept accepts grant given to the user, it takes effect after that
+	Accept(id, userID int) (domain.Grant, error)
+	// Delete deletes grant, the user is either the one who got it,
+	// or the one who can share the object
+	Delete(id, userID int) error
+}
+
+// ImportsRepo deals with background jobs that import notes.
+type ImportsRepo interface {
+	Get(ImportsFilter) ([]domain.Import, error)
+	Create(domain.Import) (domain.Import, error)
+	// Claim gets the oldest pending import and marks it as running,
+	// returns domain.ErrNotFound if there is nothing to do
+	Claim() (domain.Import, error)
+	// Update saves status and results of the import
+	Update(domain.Import) (domain.Import, error)
+	AddError(domain.ImportError) error
+	// ImportNotepad creates notepad with its notes inside the folder,
+	// subfolders in the path are created if they don't exist
+	ImportNotepad(folderID int, path []string, n domain.Notepad, notes []domain.Note) (domain.Notepad, error)
+	// ImportFolder creates subfolders in the path inside the folder
+	// if they don't exist
+	ImportFolder(folderID int, path []string) (domain.Folder, error)
+	// ReserveIDs gets new IDs for the given numbers of notes and
+	// attachments, so they can be created with known IDs
+	ReserveIDs(notes, attachments int) ([]int, []int, error)
 }
 
 // FoldersFilter is a filter for searching foldres in repository.
 type FoldersFilter struct {
 	ID     *int
 	UserID *int
+	Page   *Page
+}
+
+// TreeFilter is a filter for getting tree of folders, notepads and notes.
+type TreeFilter struct {
+	UserID *int
+	// RootID is a folder to start from, nil for the whole tree
+	RootID *int
+	// Depth is a maximum number of folder levels, 0 for
+	// domain.MaxTreeDepth, which is also the upper limit
+	Depth int
+	// Notes are only included if asked
+	Notes bool
 }
 
 // NotepadsFilter is a filter for searching notepads in repository.
@@ -48,6 +203,7 @@ type NotepadsFilter struct {
 	ID       *int
 	UserID   *int
 	FolderID *int
+	Page     *Page
 }
 
 // NotesFilter is a filter for searching notes in repository.
@@ -55,4 +211,87 @@ type NotesFilter struct {
 	ID        *int
 	UserID    *int
 	NotepadID *int
+	// Query is a full-text search query for note title and text
+	Query *string
+	// Tags filters notes that have any of the tags, or all of them
+	// if AllTags is set
+	Tags    []string
+	AllTags bool
+	// LinksTo filters notes that have wiki links to the note
+	LinksTo *int
+	Page    *Page
+}
+
+// RevisionsFilter is a filter for searching note revisions in repository.
+type RevisionsFilter struct {
+	NoteID *int
+	UserID *int
+	Number *int
+}
+
+// LinksFilter is a filter for searching wiki links between notes
+// in repository.
+type LinksFilter struct {
+	// NoteID is an ID of the linking note
+	NoteID *int
+	UserID *int
+}
+
+// TagsFilter is a filter for searching tags in repository.
+type TagsFilter struct {
+	ID     *int
+	UserID *int
+}
+
+// AttachmentsFilter is a filter for searching attachments in repository.
+type AttachmentsFilter struct {
+	ID     *int
+	UserID *int
+	NoteID *int
+}
+
+// SharesFilter is a filter for searching shares in repository.
+type SharesFilter struct {
+	ID     *int
+	UserID *int
+	Token  *string
+	// Active skips expired shares
+	Active bool
+}
+
+// ImportsFilter is a filter for searching imports in repository.
+type ImportsFilter struct {
+	ID     *int
+	UserID *int
+}
+
+// SessionsFilter is a filter for searching sessions in repository.
+type SessionsFilter struct {
+	ID     *int
+	UserID *int
+}
+
+// PersonalTokensFilter is a filter for searching personal access
+// tokens in repository.
+type PersonalTokensFilter struct {
+	UserID *int
+	Hash   *string
+}
+
+// GrantsFilter is a filter for searching grants in repository.
+type GrantsFilter struct {
+	ID *int
+	// UserID filters grants given to the user
+	UserID *int
+	// ManagerID filters grants of objects that the user can share
+	ManagerID *int
+	FolderID  *int
+	NotepadID *int
+	Accepted  *bool
+}
+
+// TrashFilter is a filter for purging objects from trash.
+type TrashFilter struct {
+	UserID        *int
+	DeletedBefore *time.Time
 }
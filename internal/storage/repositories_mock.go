@@ -86,6 +86,323 @@ func (mr *MockUsersRepoMockRecorder) Update(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockUsersRepo)(nil).Update), arg0)
 }
 
+// UpdatePassword mocks base method
+func (m *MockUsersRepo) UpdatePassword(id int, hash string) error {
+	ret := m.ctrl.Call(m, "UpdatePassword", id, hash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePassword indicates an expected call of UpdatePassword
+func (mr *MockUsersRepoMockRecorder) UpdatePassword(id, hash interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockUsersRepo)(nil).UpdatePassword), id, hash)
+}
+
+// MockPasswordResetsRepo is a mock of PasswordResetsRepo interface
+type MockPasswordResetsRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockPasswordResetsRepoMockRecorder
+}
+
+// MockPasswordResetsRepoMockRecorder is the mock recorder for MockPasswordResetsRepo
+type MockPasswordResetsRepoMockRecorder struct {
+	mock *MockPasswordResetsRepo
+}
+
+// NewMockPasswordResetsRepo creates a new mock instance
+func NewMockPasswordResetsRepo(ctrl *gomock.Controller) *MockPasswordResetsRepo {
+	mock := &MockPasswordResetsRepo{ctrl: ctrl}
+	mock.recorder = &MockPasswordResetsRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockPasswordResetsRepo) EXPECT() *MockPasswordResetsRepoMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method
+func (m *MockPasswordResetsRepo) Create(arg0 auth.PasswordReset) (auth.PasswordReset, error) {
+	ret := m.ctrl.Call(m, "Create", arg0)
+	ret0, _ := ret[0].(auth.PasswordReset)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create
+func (mr *MockPasswordResetsRepoMockRecorder) Create(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockPasswordResetsRepo)(nil).Create), arg0)
+}
+
+// Use mocks base method
+func (m *MockPasswordResetsRepo) Use(hash string) (auth.PasswordReset, error) {
+	ret := m.ctrl.Call(m, "Use", hash)
+	ret0, _ := ret[0].(auth.PasswordReset)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Use indicates an expected call of Use
+func (mr *MockPasswordResetsRepoMockRecorder) Use(hash interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Use", reflect.TypeOf((*MockPasswordResetsRepo)(nil).Use), hash)
+}
+
+// DeleteExpired mocks base method
+func (m *MockPasswordResetsRepo) DeleteExpired() error {
+	ret := m.ctrl.Call(m, "DeleteExpired")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteExpired indicates an expected call of DeleteExpired
+func (mr *MockPasswordResetsRepoMockRecorder) DeleteExpired() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpired", reflect.TypeOf((*MockPasswordResetsRepo)(nil).DeleteExpired))
+}
+
+// MockSessionsRepo is a mock of SessionsRepo interface
+type MockSessionsRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockSessionsRepoMockRecorder
+}
+
+// MockSessionsRepoMockRecorder is the mock recorder for MockSessionsRepo
+type MockSessionsRepoMockRecorder struct {
+	mock *MockSessionsRepo
+}
+
+// NewMockSessionsRepo creates a new mock instance
+func NewMockSessionsRepo(ctrl *gomock.Controller) *MockSessionsRepo {
+	mock := &MockSessionsRepo{ctrl: ctrl}
+	mock.recorder = &MockSessionsRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockSessionsRepo) EXPECT() *MockSessionsRepoMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method
+func (m *MockSessionsRepo) Get(arg0 SessionsFilter) ([]auth.Session, error) {
+	ret := m.ctrl.Call(m, "Get", arg0)
+	ret0, _ := ret[0].([]auth.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get
+func (mr *MockSessionsRepoMockRecorder) Get(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockSessionsRepo)(nil).Get), arg0)
+}
+
+// Create mocks base method
+func (m *MockSessionsRepo) Create(arg0 auth.Session) (auth.Session, error) {
+	ret := m.ctrl.Call(m, "Create", arg0)
+	ret0, _ := ret[0].(auth.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create
+func (mr *MockSessionsRepoMockRecorder) Create(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockSessionsRepo)(nil).Create), arg0)
+}
+
+// Touch mocks base method
+func (m *MockSessionsRepo) Touch(id int, ip string) error {
+	ret := m.ctrl.Call(m, "Touch", id, ip)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Touch indicates an expected call of Touch
+func (mr *MockSessionsRepoMockRecorder) Touch(id, ip interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Touch", reflect.TypeOf((*MockSessionsRepo)(nil).Touch), id, ip)
+}
+
+// Delete mocks base method
+func (m *MockSessionsRepo) Delete(id, userID int) error {
+	ret := m.ctrl.Call(m, "Delete", id, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockSessionsRepoMockRecorder) Delete(id, userID interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockSessionsRepo)(nil).Delete), id, userID)
+}
+
+// DeleteOthers mocks base method
+func (m *MockSessionsRepo) DeleteOthers(id, userID int) ([]int, error) {
+	ret := m.ctrl.Call(m, "DeleteOthers", id, userID)
+	ret0, _ := ret[0].([]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteOthers indicates an expected call of DeleteOthers
+func (mr *MockSessionsRepoMockRecorder) DeleteOthers(id, userID interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOthers", reflect.TypeOf((*MockSessionsRepo)(nil).DeleteOthers), id, userID)
+}
+
+// DeleteExpired mocks base method
+func (m *MockSessionsRepo) DeleteExpired() error {
+	ret := m.ctrl.Call(m, "DeleteExpired")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteExpired indicates an expected call of DeleteExpired
+func (mr *MockSessionsRepoMockRecorder) DeleteExpired() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpired", reflect.TypeOf((*MockSessionsRepo)(nil).DeleteExpired))
+}
+
+// MockRefreshTokensRepo is a mock of RefreshTokensRepo interface
+type MockRefreshTokensRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockRefreshTokensRepoMockRecorder
+}
+
+// MockRefreshTokensRepoMockRecorder is the mock recorder for MockRefreshTokensRepo
+type MockRefreshTokensRepoMockRecorder struct {
+	mock *MockRefreshTokensRepo
+}
+
+// NewMockRefreshTokensRepo creates a new mock instance
+func NewMockRefreshTokensRepo(ctrl *gomock.Controller) *MockRefreshTokensRepo {
+	mock := &MockRefreshTokensRepo{ctrl: ctrl}
+	mock.recorder = &MockRefreshTokensRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockRefreshTokensRepo) EXPECT() *MockRefreshTokensRepoMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method
+func (m *MockRefreshTokensRepo) Create(arg0 auth.RefreshToken) (auth.RefreshToken, error) {
+	ret := m.ctrl.Call(m, "Create", arg0)
+	ret0, _ := ret[0].(auth.RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create
+func (mr *MockRefreshTokensRepoMockRecorder) Create(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRefreshTokensRepo)(nil).Create), arg0)
+}
+
+// Rotate mocks base method
+func (m *MockRefreshTokensRepo) Rotate(hash string, next auth.RefreshToken) (auth.RefreshToken, error) {
+	ret := m.ctrl.Call(m, "Rotate", hash, next)
+	ret0, _ := ret[0].(auth.RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Rotate indicates an expected call of Rotate
+func (mr *MockRefreshTokensRepoMockRecorder) Rotate(hash, next interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rotate", reflect.TypeOf((*MockRefreshTokensRepo)(nil).Rotate), hash, next)
+}
+
+// Revoke mocks base method
+func (m *MockRefreshTokensRepo) Revoke(hash string, userID int) error {
+	ret := m.ctrl.Call(m, "Revoke", hash, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Revoke indicates an expected call of Revoke
+func (mr *MockRefreshTokensRepoMockRecorder) Revoke(hash, userID interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockRefreshTokensRepo)(nil).Revoke), hash, userID)
+}
+
+// DeleteExpired mocks base method
+func (m *MockRefreshTokensRepo) DeleteExpired() error {
+	ret := m.ctrl.Call(m, "DeleteExpired")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteExpired indicates an expected call of DeleteExpired
+func (mr *MockRefreshTokensRepoMockRecorder) DeleteExpired() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpired", reflect.TypeOf((*MockRefreshTokensRepo)(nil).DeleteExpired))
+}
+
+// MockPersonalTokensRepo is a mock of PersonalTokensRepo interface
+type MockPersonalTokensRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockPersonalTokensRepoMockRecorder
+}
+
+// MockPersonalTokensRepoMockRecorder is the mock recorder for MockPersonalTokensRepo
+type MockPersonalTokensRepoMockRecorder struct {
+	mock *MockPersonalTokensRepo
+}
+
+// NewMockPersonalTokensRepo creates a new mock instance
+func NewMockPersonalTokensRepo(ctrl *gomock.Controller) *MockPersonalTokensRepo {
+	mock := &MockPersonalTokensRepo{ctrl: ctrl}
+	mock.recorder = &MockPersonalTokensRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockPersonalTokensRepo) EXPECT() *MockPersonalTokensRepoMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method
+func (m *MockPersonalTokensRepo) Get(arg0 PersonalTokensFilter) ([]auth.PersonalToken, error) {
+	ret := m.ctrl.Call(m, "Get", arg0)
+	ret0, _ := ret[0].([]auth.PersonalToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get
+func (mr *MockPersonalTokensRepoMockRecorder) Get(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockPersonalTokensRepo)(nil).Get), arg0)
+}
+
+// Create mocks base method
+func (m *MockPersonalTokensRepo) Create(arg0 auth.PersonalToken) (auth.PersonalToken, error) {
+	ret := m.ctrl.Call(m, "Create", arg0)
+	ret0, _ := ret[0].(auth.PersonalToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create
+func (mr *MockPersonalTokensRepoMockRecorder) Create(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockPersonalTokensRepo)(nil).Create), arg0)
+}
+
+// Delete mocks base method
+func (m *MockPersonalTokensRepo) Delete(id, userID int) error {
+	ret := m.ctrl.Call(m, "Delete", id, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockPersonalTokensRepoMockRecorder) Delete(id, userID interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockPersonalTokensRepo)(nil).Delete), id, userID)
+}
+
+// DeleteAll mocks base method
+func (m *MockPersonalTokensRepo) DeleteAll(userID int) error {
+	ret := m.ctrl.Call(m, "DeleteAll", userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAll indicates an expected call of DeleteAll
+func (mr *MockPersonalTokensRepoMockRecorder) DeleteAll(userID interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAll", reflect.TypeOf((*MockPersonalTokensRepo)(nil).DeleteAll), userID)
+}
+
 // MockFoldersRepo is a mock of FoldersRepo interface
 type MockFoldersRepo struct {
 	ctrl     *gomock.Controller
@@ -160,6 +477,32 @@ func (mr *MockFoldersRepoMockRecorder) Delete(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockFoldersRepo)(nil).Delete), arg0)
 }
 
+// Move mocks base method
+func (m *MockFoldersRepo) Move(arg0 domain.Folder) (domain.Folder, error) {
+	ret := m.ctrl.Call(m, "Move", arg0)
+	ret0, _ := ret[0].(domain.Folder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Move indicates an expected call of Move
+func (mr *MockFoldersRepoMockRecorder) Move(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Move", reflect.TypeOf((*MockFoldersRepo)(nil).Move), arg0)
+}
+
+// GetTree mocks base method
+func (m *MockFoldersRepo) GetTree(arg0 TreeFilter) ([]domain.FolderNode, error) {
+	ret := m.ctrl.Call(m, "GetTree", arg0)
+	ret0, _ := ret[0].([]domain.FolderNode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTree indicates an expected call of GetTree
+func (mr *MockFoldersRepoMockRecorder) GetTree(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTree", reflect.TypeOf((*MockFoldersRepo)(nil).GetTree), arg0)
+}
+
 // MockNotepadsRepo is a mock of NotepadsRepo interface
 type MockNotepadsRepo struct {
 	ctrl     *gomock.Controller
@@ -234,6 +577,19 @@ func (mr *MockNotepadsRepoMockRecorder) Delete(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockNotepadsRepo)(nil).Delete), arg0)
 }
 
+// Move mocks base method
+func (m *MockNotepadsRepo) Move(arg0 domain.Notepad) (domain.Notepad, error) {
+	ret := m.ctrl.Call(m, "Move", arg0)
+	ret0, _ := ret[0].(domain.Notepad)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Move indicates an expected call of Move
+func (mr *MockNotepadsRepoMockRecorder) Move(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Move", reflect.TypeOf((*MockNotepadsRepo)(nil).Move), arg0)
+}
+
 // MockNotesRepo is a mock of NotesRepo interface
 type MockNotesRepo struct {
 	ctrl     *gomock.Controller
@@ -270,6 +626,19 @@ func (mr *MockNotesRepoMockRecorder) Get(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockNotesRepo)(nil).Get), arg0)
 }
 
+// Search mocks base method
+func (m *MockNotesRepo) Search(arg0 NotesFilter) ([]domain.NoteHit, error) {
+	ret := m.ctrl.Call(m, "Search", arg0)
+	ret0, _ := ret[0].([]domain.NoteHit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search
+func (mr *MockNotesRepoMockRecorder) Search(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockNotesRepo)(nil).Search), arg0)
+}
+
 // Create mocks base method
 func (m *MockNotesRepo) Create(arg0 domain.Note) (domain.Note, error) {
 	ret := m.ctrl.Call(m, "Create", arg0)
@@ -307,3 +676,588 @@ func (m *MockNotesRepo) Delete(arg0 domain.Note) error {
 func (mr *MockNotesRepoMockRecorder) Delete(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockNotesRepo)(nil).Delete), arg0)
 }
+
+// Move mocks base method
+func (m *MockNotesRepo) Move(arg0 domain.Note) (domain.Note, error) {
+	ret := m.ctrl.Call(m, "Move", arg0)
+	ret0, _ := ret[0].(domain.Note)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Move indicates an expected call of Move
+func (mr *MockNotesRepoMockRecorder) Move(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Move", reflect.TypeOf((*MockNotesRepo)(nil).Move), arg0)
+}
+
+// GetRevisions mocks base method
+func (m *MockNotesRepo) GetRevisions(arg0 RevisionsFilter) ([]domain.NoteRevision, error) {
+	ret := m.ctrl.Call(m, "GetRevisions", arg0)
+	ret0, _ := ret[0].([]domain.NoteRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRevisions indicates an expected call of GetRevisions
+func (mr *MockNotesRepoMockRecorder) GetRevisions(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRevisions", reflect.TypeOf((*MockNotesRepo)(nil).GetRevisions), arg0)
+}
+
+// Restore mocks base method
+func (m *MockNotesRepo) Restore(n domain.Note, revision int) (domain.Note, error) {
+	ret := m.ctrl.Call(m, "Restore", n, revision)
+	ret0, _ := ret[0].(domain.Note)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Restore indicates an expected call of Restore
+func (mr *MockNotesRepoMockRecorder) Restore(n, revision interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockNotesRepo)(nil).Restore), n, revision)
+}
+
+// GetLinks mocks base method
+func (m *MockNotesRepo) GetLinks(arg0 LinksFilter) ([]domain.NoteLink, error) {
+	ret := m.ctrl.Call(m, "GetLinks", arg0)
+	ret0, _ := ret[0].([]domain.NoteLink)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLinks indicates an expected call of GetLinks
+func (mr *MockNotesRepoMockRecorder) GetLinks(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLinks", reflect.TypeOf((*MockNotesRepo)(nil).GetLinks), arg0)
+}
+
+// GetGraph mocks base method
+func (m *MockNotesRepo) GetGraph(userID int) (domain.NoteGraph, error) {
+	ret := m.ctrl.Call(m, "GetGraph", userID)
+	ret0, _ := ret[0].(domain.NoteGraph)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGraph indicates an expected call of GetGraph
+func (mr *MockNotesRepoMockRecorder) GetGraph(userID interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGraph", reflect.TypeOf((*MockNotesRepo)(nil).GetGraph), userID)
+}
+
+// MockTrashRepo is a mock of TrashRepo interface
+type MockTrashRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockTrashRepoMockRecorder
+}
+
+// MockTrashRepoMockRecorder is the mock recorder for MockTrashRepo
+type MockTrashRepoMockRecorder struct {
+	mock *MockTrashRepo
+}
+
+// NewMockTrashRepo creates a new mock instance
+func NewMockTrashRepo(ctrl *gomock.Controller) *MockTrashRepo {
+	mock := &MockTrashRepo{ctrl: ctrl}
+	mock.recorder = &MockTrashRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockTrashRepo) EXPECT() *MockTrashRepoMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method
+func (m *MockTrashRepo) Get(userID int) (domain.Trash, error) {
+	ret := m.ctrl.Call(m, "Get", userID)
+	ret0, _ := ret[0].(domain.Trash)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get
+func (mr *MockTrashRepoMockRecorder) Get(userID interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockTrashRepo)(nil).Get), userID)
+}
+
+// Restore mocks base method
+func (m *MockTrashRepo) Restore(arg0 domain.TrashItem) error {
+	ret := m.ctrl.Call(m, "Restore", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore
+func (mr *MockTrashRepoMockRecorder) Restore(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockTrashRepo)(nil).Restore), arg0)
+}
+
+// Purge mocks base method
+func (m *MockTrashRepo) Purge(arg0 domain.TrashItem) error {
+	ret := m.ctrl.Call(m, "Purge", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Purge indicates an expected call of Purge
+func (mr *MockTrashRepoMockRecorder) Purge(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockTrashRepo)(nil).Purge), arg0)
+}
+
+// Empty mocks base method
+func (m *MockTrashRepo) Empty(arg0 TrashFilter) error {
+	ret := m.ctrl.Call(m, "Empty", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Empty indicates an expected call of Empty
+func (mr *MockTrashRepoMockRecorder) Empty(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Empty", reflect.TypeOf((*MockTrashRepo)(nil).Empty), arg0)
+}
+
+// MockTagsRepo is a mock of TagsRepo interface
+type MockTagsRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockTagsRepoMockRecorder
+}
+
+// MockTagsRepoMockRecorder is the mock recorder for MockTagsRepo
+type MockTagsRepoMockRecorder struct {
+	mock *MockTagsRepo
+}
+
+// NewMockTagsRepo creates a new mock instance
+func NewMockTagsRepo(ctrl *gomock.Controller) *MockTagsRepo {
+	mock := &MockTagsRepo{ctrl: ctrl}
+	mock.recorder = &MockTagsRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockTagsRepo) EXPECT() *MockTagsRepoMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method
+func (m *MockTagsRepo) Get(arg0 TagsFilter) ([]domain.Tag, error) {
+	ret := m.ctrl.Call(m, "Get", arg0)
+	ret0, _ := ret[0].([]domain.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get
+func (mr *MockTagsRepoMockRecorder) Get(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockTagsRepo)(nil).Get), arg0)
+}
+
+// Create mocks base method
+func (m *MockTagsRepo) Create(arg0 domain.Tag) (domain.Tag, error) {
+	ret := m.ctrl.Call(m, "Create", arg0)
+	ret0, _ := ret[0].(domain.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create
+func (mr *MockTagsRepoMockRecorder) Create(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockTagsRepo)(nil).Create), arg0)
+}
+
+// Update mocks base method
+func (m *MockTagsRepo) Update(arg0 domain.Tag) (domain.Tag, error) {
+	ret := m.ctrl.Call(m, "Update", arg0)
+	ret0, _ := ret[0].(domain.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update
+func (mr *MockTagsRepoMockRecorder) Update(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockTagsRepo)(nil).Update), arg0)
+}
+
+// Merge mocks base method
+func (m *MockTagsRepo) Merge(from, to domain.Tag) (domain.Tag, error) {
+	ret := m.ctrl.Call(m, "Merge", from, to)
+	ret0, _ := ret[0].(domain.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Merge indicates an expected call of Merge
+func (mr *MockTagsRepoMockRecorder) Merge(from, to interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Merge", reflect.TypeOf((*MockTagsRepo)(nil).Merge), from, to)
+}
+
+// Delete mocks base method
+func (m *MockTagsRepo) Delete(arg0 domain.Tag) error {
+	ret := m.ctrl.Call(m, "Delete", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockTagsRepoMockRecorder) Delete(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockTagsRepo)(nil).Delete), arg0)
+}
+
+// MockAttachmentsRepo is a mock of AttachmentsRepo interface
+type MockAttachmentsRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockAttachmentsRepoMockRecorder
+}
+
+// MockAttachmentsRepoMockRecorder is the mock recorder for MockAttachmentsRepo
+type MockAttachmentsRepoMockRecorder struct {
+	mock *MockAttachmentsRepo
+}
+
+// NewMockAttachmentsRepo creates a new mock instance
+func NewMockAttachmentsRepo(ctrl *gomock.Controller) *MockAttachmentsRepo {
+	mock := &MockAttachmentsRepo{ctrl: ctrl}
+	mock.recorder = &MockAttachmentsRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockAttachmentsRepo) EXPECT() *MockAttachmentsRepoMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method
+func (m *MockAttachmentsRepo) Get(arg0 AttachmentsFilter) ([]domain.Attachment, error) {
+	ret := m.ctrl.Call(m, "Get", arg0)
+	ret0, _ := ret[0].([]domain.Attachment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get
+func (mr *MockAttachmentsRepoMockRecorder) Get(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockAttachmentsRepo)(nil).Get), arg0)
+}
+
+// Usage mocks base method
+func (m *MockAttachmentsRepo) Usage(userID int) (int64, error) {
+	ret := m.ctrl.Call(m, "Usage", userID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Usage indicates an expected call of Usage
+func (mr *MockAttachmentsRepoMockRecorder) Usage(userID interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Usage", reflect.TypeOf((*MockAttachmentsRepo)(nil).Usage), userID)
+}
+
+// Create mocks base method
+func (m *MockAttachmentsRepo) Create(a domain.Attachment, quota int64) (domain.Attachment, error) {
+	ret := m.ctrl.Call(m, "Create", a, quota)
+	ret0, _ := ret[0].(domain.Attachment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create
+func (mr *MockAttachmentsRepoMockRecorder) Create(a, quota interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAttachmentsRepo)(nil).Create), a, quota)
+}
+
+// Delete mocks base method
+func (m *MockAttachmentsRepo) Delete(arg0 domain.Attachment) error {
+	ret := m.ctrl.Call(m, "Delete", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockAttachmentsRepoMockRecorder) Delete(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockAttachmentsRepo)(nil).Delete), arg0)
+}
+
+// DeletedKeys mocks base method
+func (m *MockAttachmentsRepo) DeletedKeys(limit int) ([]string, error) {
+	ret := m.ctrl.Call(m, "DeletedKeys", limit)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeletedKeys indicates an expected call of DeletedKeys
+func (mr *MockAttachmentsRepoMockRecorder) DeletedKeys(limit interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletedKeys", reflect.TypeOf((*MockAttachmentsRepo)(nil).DeletedKeys), limit)
+}
+
+// ForgetKeys mocks base method
+func (m *MockAttachmentsRepo) ForgetKeys(arg0 []string) error {
+	ret := m.ctrl.Call(m, "ForgetKeys", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ForgetKeys indicates an expected call of ForgetKeys
+func (mr *MockAttachmentsRepoMockRecorder) ForgetKeys(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForgetKeys", reflect.TypeOf((*MockAttachmentsRepo)(nil).ForgetKeys), arg0)
+}
+
+// MockSharesRepo is a mock of SharesRepo interface
+type MockSharesRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockSharesRepoMockRecorder
+}
+
+// MockSharesRepoMockRecorder is the mock recorder for MockSharesRepo
+type MockSharesRepoMockRecorder struct {
+	mock *MockSharesRepo
+}
+
+// NewMockSharesRepo creates a new mock instance
+func NewMockSharesRepo(ctrl *gomock.Controller) *MockSharesRepo {
+	mock := &MockSharesRepo{ctrl: ctrl}
+	mock.recorder = &MockSharesRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockSharesRepo) EXPECT() *MockSharesRepoMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method
+func (m *MockSharesRepo) Get(arg0 SharesFilter) ([]domain.Share, error) {
+	ret := m.ctrl.Call(m, "Get", arg0)
+	ret0, _ := ret[0].([]domain.Share)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get
+func (mr *MockSharesRepoMockRecorder) Get(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockSharesRepo)(nil).Get), arg0)
+}
+
+// Create mocks base method
+func (m *MockSharesRepo) Create(arg0 domain.Share) (domain.Share, error) {
+	ret := m.ctrl.Call(m, "Create", arg0)
+	ret0, _ := ret[0].(domain.Share)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create
+func (mr *MockSharesRepoMockRecorder) Create(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockSharesRepo)(nil).Create), arg0)
+}
+
+// Delete mocks base method
+func (m *MockSharesRepo) Delete(arg0 domain.Share) error {
+	ret := m.ctrl.Call(m, "Delete", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockSharesRepoMockRecorder) Delete(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockSharesRepo)(nil).Delete), arg0)
+}
+
+// MockGrantsRepo is a mock of GrantsRepo interface
+type MockGrantsRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockGrantsRepoMockRecorder
+}
+
+// MockGrantsRepoMockRecorder is the mock recorder for MockGrantsRepo
+type MockGrantsRepoMockRecorder struct {
+	mock *MockGrantsRepo
+}
+
+// NewMockGrantsRepo creates a new mock instance
+func NewMockGrantsRepo(ctrl *gomock.Controller) *MockGrantsRepo {
+	mock := &MockGrantsRepo{ctrl: ctrl}
+	mock.recorder = &MockGrantsRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockGrantsRepo) EXPECT() *MockGrantsRepoMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method
+func (m *MockGrantsRepo) Get(arg0 GrantsFilter) ([]domain.Grant, error) {
+	ret := m.ctrl.Call(m, "Get", arg0)
+	ret0, _ := ret[0].([]domain.Grant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get
+func (mr *MockGrantsRepoMockRecorder) Get(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockGrantsRepo)(nil).Get), arg0)
+}
+
+// Invite mocks base method
+func (m *MockGrantsRepo) Invite(g domain.Grant, email string) (domain.Grant, error) {
+	ret := m.ctrl.Call(m, "Invite", g, email)
+	ret0, _ := ret[0].(domain.Grant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Invite indicates an expected call of Invite
+func (mr *MockGrantsRepoMockRecorder) Invite(g, email interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Invite", reflect.TypeOf((*MockGrantsRepo)(nil).Invite), g, email)
+}
+
+// Accept mocks base method
+func (m *MockGrantsRepo) Accept(id, userID int) (domain.Grant, error) {
+	ret := m.ctrl.Call(m, "Accept", id, userID)
+	ret0, _ := ret[0].(domain.Grant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Accept indicates an expected call of Accept
+func (mr *MockGrantsRepoMockRecorder) Accept(id, userID interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Accept", reflect.TypeOf((*MockGrantsRepo)(nil).Accept), id, userID)
+}
+
+// Delete mocks base method
+func (m *MockGrantsRepo) Delete(id, userID int) error {
+	ret := m.ctrl.Call(m, "Delete", id, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockGrantsRepoMockRecorder) Delete(id, userID interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockGrantsRepo)(nil).Delete), id, userID)
+}
+
+// MockImportsRepo is a mock of ImportsRepo interface
+type MockImportsRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockImportsRepoMockRecorder
+}
+
+// MockImportsRepoMockRecorder is the mock recorder for MockImportsRepo
+type MockImportsRepoMockRecorder struct {
+	mock *MockImportsRepo
+}
+
+// NewMockImportsRepo creates a new mock instance
+func NewMockImportsRepo(ctrl *gomock.Controller) *MockImportsRepo {
+	mock := &MockImportsRepo{ctrl: ctrl}
+	mock.recorder = &MockImportsRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockImportsRepo) EXPECT() *MockImportsRepoMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method
+func (m *MockImportsRepo) Get(arg0 ImportsFilter) ([]domain.Import, error) {
+	ret := m.ctrl.Call(m, "Get", arg0)
+	ret0, _ := ret[0].([]domain.Import)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get
+func (mr *MockImportsRepoMockRecorder) Get(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockImportsRepo)(nil).Get), arg0)
+}
+
+// Create mocks base method
+func (m *MockImportsRepo) Create(arg0 domain.Import) (domain.Import, error) {
+	ret := m.ctrl.Call(m, "Create", arg0)
+	ret0, _ := ret[0].(domain.Import)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create
+func (mr *MockImportsRepoMockRecorder) Create(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockImportsRepo)(nil).Create), arg0)
+}
+
+// Claim mocks base method
+func (m *MockImportsRepo) Claim() (domain.Import, error) {
+	ret := m.ctrl.Call(m, "Claim")
+	ret0, _ := ret[0].(domain.Import)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Claim indicates an expected call of Claim
+func (mr *MockImportsRepoMockRecorder) Claim() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Claim", reflect.TypeOf((*MockImportsRepo)(nil).Claim))
+}
+
+// Update mocks base method
+func (m *MockImportsRepo) Update(arg0 domain.Import) (domain.Import, error) {
+	ret := m.ctrl.Call(m, "Update", arg0)
+	ret0, _ := ret[0].(domain.Import)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update
+func (mr *MockImportsRepoMockRecorder) Update(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockImportsRepo)(nil).Update), arg0)
+}
+
+// AddError mocks base method
+func (m *MockImportsRepo) AddError(arg0 domain.ImportError) error {
+	ret := m.ctrl.Call(m, "AddError", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddError indicates an expected call of AddError
+func (mr *MockImportsRepoMockRecorder) AddError(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddError", reflect.TypeOf((*MockImportsRepo)(nil).AddError), arg0)
+}
+
+// ImportNotepad mocks base method
+func (m *MockImportsRepo) ImportNotepad(folderID int, path []string, n domain.Notepad, notes []domain.Note) (domain.Notepad, error) {
+	ret := m.ctrl.Call(m, "ImportNotepad", folderID, path, n, notes)
+	ret0, _ := ret[0].(domain.Notepad)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportNotepad indicates an expected call of ImportNotepad
+func (mr *MockImportsRepoMockRecorder) ImportNotepad(folderID, path, n, notes interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportNotepad", reflect.TypeOf((*MockImportsRepo)(nil).ImportNotepad), folderID, path, n, notes)
+}
+
+// ImportFolder mocks base method
+func (m *MockImportsRepo) ImportFolder(folderID int, path []string) (domain.Folder, error) {
+	ret := m.ctrl.Call(m, "ImportFolder", folderID, path)
+	ret0, _ := ret[0].(domain.Folder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportFolder indicates an expected call of ImportFolder
+func (mr *MockImportsRepoMockRecorder) ImportFolder(folderID, path interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportFolder", reflect.TypeOf((*MockImportsRepo)(nil).ImportFolder), folderID, path)
+}
+
+// ReserveIDs mocks base method
+func (m *MockImportsRepo) ReserveIDs(notes, attachments int) ([]int, []int, error) {
+	ret := m.ctrl.Call(m, "ReserveIDs", notes, attachments)
+	ret0, _ := ret[0].([]int)
+	ret1, _ := ret[1].([]int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ReserveIDs indicates an expected call of ReserveIDs
+func (mr *MockImportsRepoMockRecorder) ReserveIDs(notes, attachments interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveIDs", reflect.TypeOf((*MockImportsRepo)(nil).ReserveIDs), notes, attachments)
+}
@@ -0,0 +1,345 @@
+// Package s3 implements blob store on top of S3-compatible object storage.
+package s3
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/tetafro/nott-backend-go/internal/domain"
+	"github.com/tetafro/nott-backend-go/internal/storage"
+)
+
+const (
+	// partSize is a size of parts for multipart uploads. Objects
+	// that are smaller are uploaded with a single request.
+	partSize = 5 << 20
+	// requestTimeout limits time of a single request to the storage.
+	requestTimeout = 5 * time.Minute
+)
+
+// Config contains settings of S3-compatible storage.
+type Config struct {
+	// Endpoint is a storage URL, like https://s3.amazonaws.com
+	// or http://localhost:9000
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	// PathStyle puts bucket name to the URL path instead of the host
+	// name, it's usually required for MinIO and other stand-ins
+	PathStyle bool
+}
+
+// BlobStore keeps blobs as objects in S3 bucket.
+type BlobStore struct {
+	endpoint  *url.URL
+	bucket    string
+	pathStyle bool
+	signer    signer
+	client    *http.Client
+	now       func() time.Time
+}
+
+// NewBlobStore creates new blob store for the bucket.
+func NewBlobStore(cfg Config) (*BlobStore, error) {
+	u, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse endpoint")
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, errors.Errorf("invalid endpoint %q", cfg.Endpoint)
+	}
+	if cfg.Bucket == "" {
+		return nil, errors.New("empty bucket")
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	return &BlobStore{
+		endpoint:  u,
+		bucket:    cfg.Bucket,
+		pathStyle: cfg.PathStyle,
+		signer: signer{
+			accessKey: cfg.AccessKey,
+			secretKey: cfg.SecretKey,
+			region:    cfg.Region,
+		},
+		client: &http.Client{Timeout: requestTimeout},
+		now:    time.Now,
+	}, nil
+}
+
+// Put uploads blob. Data is streamed to the storage by parts, so large
+// blobs are never kept in memory entirely.
+func (s *BlobStore) Put(key string, r io.Reader) error {
+	buf := make([]byte, partSize)
+	n, err := io.ReadFull(r, buf)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		// Small blob, single request is enough
+		_, err = s.do(http.MethodPut, key, nil, nil, buf[:n])
+		return err
+	}
+	if err != nil {
+		return errors.Wrap(err, "read data")
+	}
+
+	var upload struct {
+		UploadID string `xml:"UploadId"`
+	}
+	resp, err := s.do(http.MethodPost, key, url.Values{"uploads": {""}}, nil, nil)
+	if err != nil {
+		return errors.Wrap(err, "create multipart upload")
+	}
+	if err = decodeXML(resp, &upload); err != nil {
+		return errors.Wrap(err, "create multipart upload")
+	}
+
+	if err = s.uploadParts(key, upload.UploadID, buf[:n], r); err != nil {
+		q := url.Values{"uploadId": {upload.UploadID}}
+		if _, abortErr := s.do(http.MethodDelete, key, q, nil, nil); abortErr != nil {
+			return errors.Wrapf(err, "abort upload: %v", abortErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// uploadParts uploads parts of multipart upload, and completes it.
+func (s *BlobStore) uploadParts(key, uploadID string, first []byte, r io.Reader) error {
+	type part struct {
+		Number int    `xml:"PartNumber"`
+		ETag   string `xml:"ETag"`
+	}
+	var complete struct {
+		XMLName xml.Name `xml:"CompleteMultipartUpload"`
+		Parts   []part   `xml:"Part"`
+	}
+
+	data := first
+	buf := make([]byte, partSize)
+	for num := 1; len(data) > 0; num++ {
+		q := url.Values{
+			"partNumber": {strconv.Itoa(num)},
+			"uploadId":   {uploadID},
+		}
+		resp, err := s.do(http.MethodPut, key, q, nil, data)
+		if err != nil {
+			return errors.Wrapf(err, "upload part %d", num)
+		}
+		complete.Parts = append(complete.Parts, part{Number: num, ETag: resp.Header.Get("ETag")})
+		closeBody(resp)
+
+		n, err := io.ReadFull(r, buf)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return errors.Wrap(err, "read data")
+		}
+		data = buf[:n]
+	}
+
+	body, err := xml.Marshal(complete)
+	if err != nil {
+		return errors.Wrap(err, "encode parts")
+	}
+	q := url.Values{"uploadId": {uploadID}}
+	resp, err := s.do(http.MethodPost, key, q, nil, body)
+	if err != nil {
+		return errors.Wrap(err, "complete multipart upload")
+	}
+	// Completion can fail after the response status is sent
+	var result struct {
+		XMLName xml.Name
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	if err = decodeXML(resp, &result); err != nil {
+		return errors.Wrap(err, "complete multipart upload")
+	}
+	if result.XMLName.Local == "Error" {
+		return errors.Errorf("complete multipart upload: %s: %s", result.Code, result.Message)
+	}
+	return nil
+}
+
+// Get opens blob for reading. Data is fetched lazily, seeking makes
+// a new range request.
+func (s *BlobStore) Get(key string) (storage.Blob, error) {
+	resp, err := s.do(http.MethodHead, key, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	closeBody(resp)
+	return &object{store: s, key: key, size: resp.ContentLength}, nil
+}
+
+// Delete deletes blob.
+func (s *BlobStore) Delete(key string) error {
+	resp, err := s.do(http.MethodDelete, key, nil, nil, nil)
+	if err == domain.ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	closeBody(resp)
+	return nil
+}
+
+// Presign makes temporary link for downloading blob directly
+// from the storage.
+func (s *BlobStore) Presign(key string, ttl time.Duration, h storage.BlobHeaders) (string, error) {
+	u := s.url(key)
+	q := url.Values{}
+	if h.ContentType != "" {
+		q.Set("response-content-type", h.ContentType)
+	}
+	if h.ContentDisposition != "" {
+		q.Set("response-content-disposition", h.ContentDisposition)
+	}
+	u.RawQuery = q.Encode()
+	s.signer.presign(u, ttl, s.now())
+	return u.String(), nil
+}
+
+// url makes URL of the object.
+func (s *BlobStore) url(key string) *url.URL {
+	u := *s.endpoint
+	path := strings.TrimSuffix(u.Path, "/")
+	if s.pathStyle {
+		path += "/" + s.bucket
+	} else {
+		u.Host = s.bucket + "." + u.Host
+	}
+	u.Path = path + "/" + key
+	// Escape the path the same way as it's signed
+	u.RawPath = uriEncode(u.Path, false)
+	return &u
+}
+
+// do makes signed request to the storage. Returns domain.ErrNotFound
+// if there is no such object, and error for other unsuccessful responses.
+func (s *BlobStore) do(
+	method, key string,
+	query url.Values,
+	header http.Header,
+	body []byte,
+) (*http.Response, error) {
+	u := s.url(key)
+	u.RawQuery = canonicalQuery(query)
+
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.Wrap(err, "make request")
+	}
+	if body == nil {
+		req.Body = nil
+		req.ContentLength = 0
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	payloadHash := emptyPayload
+	if len(body) > 0 {
+		payloadHash = sha256Hex(body)
+	}
+	s.signer.sign(req, payloadHash, s.now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "send request")
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		closeBody(resp)
+		return nil, domain.ErrNotFound
+	}
+	if resp.StatusCode >= 300 {
+		defer closeBody(resp)
+		var e struct {
+			Code    string `xml:"Code"`
+			Message string `xml:"Message"`
+		}
+		if xml.NewDecoder(resp.Body).Decode(&e) == nil && e.Code != "" {
+			return nil, errors.Errorf("storage error: %s: %s", e.Code, e.Message)
+		}
+		return nil, errors.Errorf("storage error: %s", resp.Status)
+	}
+	return resp, nil
+}
+
+// object is a blob from S3 storage.
+type object struct {
+	store  *BlobStore
+	key    string
+	size   int64
+	offset int64
+	body   io.ReadCloser
+}
+
+func (o *object) Read(p []byte) (int, error) {
+	if o.offset >= o.size {
+		return 0, io.EOF
+	}
+	if o.body == nil {
+		h := http.Header{"Range": {fmt.Sprintf("bytes=%d-", o.offset)}}
+		resp, err := o.store.do(http.MethodGet, o.key, nil, h, nil)
+		if err != nil {
+			return 0, err
+		}
+		o.body = resp.Body
+	}
+	n, err := o.body.Read(p)
+	o.offset += int64(n)
+	return n, err
+}
+
+func (o *object) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += o.offset
+	case io.SeekEnd:
+		offset += o.size
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+	if offset != o.offset && o.body != nil {
+		o.body.Close() // nolint: errcheck,gosec
+		o.body = nil
+	}
+	o.offset = offset
+	return offset, nil
+}
+
+func (o *object) Close() error {
+	if o.body == nil {
+		return nil
+	}
+	err := o.body.Close()
+	o.body = nil
+	return err
+}
+
+func decodeXML(resp *http.Response, v interface{}) error {
+	defer closeBody(resp)
+	return xml.NewDecoder(resp.Body).Decode(v)
+}
+
+// closeBody reads the rest of response body and closes it, so
+// the connection can be reused.
+func closeBody(resp *http.Response) {
+	io.Copy(ioutil.Discard, resp.Body) // nolint: errcheck,gosec
+	resp.Body.Close()                  // nolint: errcheck,gosec
+}
@@ -0,0 +1,238 @@
+package s3
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tetafro/nott-backend-go/internal/domain"
+	"github.com/tetafro/nott-backend-go/internal/storage"
+)
+
+func TestBlobStore(t *testing.T) {
+	fake := newFakeS3("attachments")
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	s, err := NewBlobStore(Config{
+		Endpoint:  srv.URL,
+		Bucket:    "attachments",
+		AccessKey: "access",
+		SecretKey: "secret",
+		PathStyle: true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+
+	testBlobStore(t, s)
+
+	t.Run("multipart upload is used for large blobs", func(t *testing.T) {
+		assert.Equal(t, 1, fake.multipart)
+	})
+}
+
+// TestBlobStoreS3 runs tests against real S3-compatible storage, e.g.
+// MinIO started with:
+//
+//	docker run -p 9000:9000 -e MINIO_ACCESS_KEY=access \
+//	  -e MINIO_SECRET_KEY=secretsecret minio/minio server /data
+//
+// The bucket must exist.
+func TestBlobStoreS3(t *testing.T) {
+	endpoint := os.Getenv("S3_TEST_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("S3_TEST_ENDPOINT is not set")
+	}
+	s, err := NewBlobStore(Config{
+		Endpoint:  endpoint,
+		Region:    os.Getenv("S3_TEST_REGION"),
+		Bucket:    os.Getenv("S3_TEST_BUCKET"),
+		AccessKey: os.Getenv("S3_TEST_ACCESS_KEY"),
+		SecretKey: os.Getenv("S3_TEST_SECRET_KEY"),
+		PathStyle: os.Getenv("S3_TEST_PATH_STYLE") == "true",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+
+	testBlobStore(t, s)
+}
+
+func testBlobStore(t *testing.T, s *BlobStore) {
+	key := "test/" + strconv.FormatInt(time.Now().UnixNano(), 10)
+
+	t.Run("put and get small blob", func(t *testing.T) {
+		assert.NoError(t, s.Put(key, strings.NewReader("hello, world")))
+
+		b, err := s.Get(key)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer b.Close() // nolint: errcheck
+
+		data, err := ioutil.ReadAll(b)
+		assert.NoError(t, err)
+		assert.Equal(t, "hello, world", string(data))
+
+		pos, err := b.Seek(7, io.SeekStart)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(7), pos)
+		data, err = ioutil.ReadAll(b)
+		assert.NoError(t, err)
+		assert.Equal(t, "world", string(data))
+
+		size, err := b.Seek(0, io.SeekEnd)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(12), size)
+	})
+
+	t.Run("put and get large blob", func(t *testing.T) {
+		large := bytes.Repeat([]byte("0123456789"), partSize/10+1000)
+		assert.NoError(t, s.Put(key+"-large", bytes.NewReader(large)))
+
+		b, err := s.Get(key + "-large")
+		if !assert.NoError(t, err) {
+			return
+		}
+		data, err := ioutil.ReadAll(b)
+		assert.NoError(t, err)
+		assert.NoError(t, b.Close())
+		assert.True(t, bytes.Equal(large, data))
+
+		assert.NoError(t, s.Delete(key+"-large"))
+	})
+
+	t.Run("download blob with presigned link", func(t *testing.T) {
+		link, err := s.Presign(key, time.Minute, storage.BlobHeaders{
+			ContentType:        "text/plain",
+			ContentDisposition: "attachment; filename=hello.txt",
+		})
+		assert.NoError(t, err)
+
+		resp, err := http.Get(link) // nolint: gosec
+		if !assert.NoError(t, err) {
+			return
+		}
+		data, err := ioutil.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		assert.NoError(t, resp.Body.Close())
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "hello, world", string(data))
+		assert.Equal(t, "attachment; filename=hello.txt", resp.Header.Get("Content-Disposition"))
+	})
+
+	t.Run("delete blob", func(t *testing.T) {
+		assert.NoError(t, s.Delete(key))
+		_, err := s.Get(key)
+		assert.Equal(t, domain.ErrNotFound, err)
+		assert.NoError(t, s.Delete(key))
+	})
+}
+
+// fakeS3 is a minimal in-memory S3 stand-in. It only supports path-style
+// requests to a single bucket, and doesn't check signatures.
+type fakeS3 struct {
+	bucket string
+
+	mu        sync.Mutex
+	objects   map[string][]byte
+	uploads   map[string]map[int][]byte
+	multipart int
+}
+
+func newFakeS3(bucket string) *fakeS3 {
+	return &fakeS3{
+		bucket:  bucket,
+		objects: map[string][]byte{},
+		uploads: map[string]map[int][]byte{},
+	}
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	q := req.URL.Query()
+	presigned := q.Get("X-Amz-Signature") != ""
+	if !presigned && !strings.HasPrefix(req.Header.Get("Authorization"), signAlgorithm) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	prefix := "/" + f.bucket + "/"
+	if !strings.HasPrefix(req.URL.Path, prefix) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	key := strings.TrimPrefix(req.URL.Path, prefix)
+	body, _ := ioutil.ReadAll(req.Body) // nolint: errcheck
+
+	switch {
+	case req.Method == http.MethodPost && q["uploads"] != nil:
+		id := strconv.Itoa(len(f.uploads) + 1)
+		f.uploads[id] = map[int][]byte{}
+		f.multipart++
+		fmt.Fprintf(w, "<InitiateMultipartUploadResult><UploadId>%s</UploadId></InitiateMultipartUploadResult>", id)
+	case req.Method == http.MethodPut && q.Get("uploadId") != "":
+		num, _ := strconv.Atoi(q.Get("partNumber")) // nolint: errcheck
+		f.uploads[q.Get("uploadId")][num] = body
+		w.Header().Set("ETag", `"`+strconv.Itoa(num)+`"`)
+	case req.Method == http.MethodPost && q.Get("uploadId") != "":
+		var complete struct {
+			Parts []struct {
+				Number int `xml:"PartNumber"`
+			} `xml:"Part"`
+		}
+		if err := xml.Unmarshal(body, &complete); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		parts := f.uploads[q.Get("uploadId")]
+		nums := []int{}
+		for _, p := range complete.Parts {
+			nums = append(nums, p.Number)
+		}
+		sort.Ints(nums)
+		var data []byte
+		for _, n := range nums {
+			data = append(data, parts[n]...)
+		}
+		f.objects[key] = data
+		delete(f.uploads, q.Get("uploadId"))
+		fmt.Fprint(w, "<CompleteMultipartUploadResult></CompleteMultipartUploadResult>")
+	case req.Method == http.MethodPut:
+		f.objects[key] = body
+	case req.Method == http.MethodGet || req.Method == http.MethodHead:
+		data, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if v := q.Get("response-content-disposition"); v != "" {
+			w.Header().Set("Content-Disposition", v)
+		}
+		http.ServeContent(w, req, key, time.Time{}, bytes.NewReader(data))
+	case req.Method == http.MethodDelete:
+		if _, ok := f.objects[key]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
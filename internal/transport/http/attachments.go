@@ -0,0 +1,421 @@
+package httpapi
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/tetafro/nott-backend-go/internal/auth"
+	"github.com/tetafro/nott-backend-go/internal/domain"
+	"github.com/tetafro/nott-backend-go/internal/storage"
+)
+
+// presignTTL is a time to live of direct links to blob store.
+// Links are made for every download, so it can be short.
+const presignTTL = 5 * time.Minute
+
+// multipartOverhead is a size of multipart form data that is allowed
+// in addition to the file itself.
+const multipartOverhead = 1 << 20
+
+// AttachmentLimits limits size of attachments.
+type AttachmentLimits struct {
+	// MaxSize is a maximum size of a file in bytes
+	MaxSize int64
+	// Quota is a maximum total size of files of a user in bytes,
+	// 0 for no limit
+	Quota int64
+}
+
+// AttachmentLinks makes signed download links for attachments, so
+// they can be used in rendered notes, where the Authorization header
+// is not available.
+type AttachmentLinks struct {
+	signer  *auth.LinkSigner
+	baseURL string
+}
+
+// NewAttachmentLinks creates new links maker. Base URL is an external
+// address of the API, like https://example.com/api/v1.
+func NewAttachmentLinks(signer *auth.LinkSigner, baseURL string) *AttachmentLinks {
+	return &AttachmentLinks{signer: signer, baseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+// URL makes download URL of the attachment for the user.
+func (l *AttachmentLinks) URL(id, userID int) string {
+	exp, sig := l.signer.Sign(attachmentObject(id), userID)
+	q := url.Values{}
+	q.Set("user", strconv.Itoa(userID))
+	q.Set("expires", strconv.FormatInt(exp, 10))
+	q.Set("signature", sig)
+	return fmt.Sprintf("%s/attachments/%d/content?%s", l.baseURL, id, q.Encode())
+}
+
+// verify checks signature of the download link, and returns ID
+// of the user that the link was made for.
+func (l *AttachmentLinks) verify(req *http.Request, id int) (int, error) {
+	q := req.URL.Query()
+	userID, err := strconv.Atoi(q.Get("user"))
+	if err != nil {
+		return 0, errors.New("invalid user")
+	}
+	exp, err := strconv.ParseInt(q.Get("expires"), 10, 64)
+	if err != nil {
+		return 0, errors.New("invalid expiration time")
+	}
+	if err = l.signer.Verify(attachmentObject(id), userID, exp, q.Get("signature")); err != nil {
+		return 0, err
+	}
+	return userID, nil
+}
+
+// ShareURL makes download URL of the attachment of the shared note.
+// The link works only while the share is active.
+func (l *AttachmentLinks) ShareURL(id int, s domain.Share) string {
+	var until time.Time
+	if s.ExpiresAt != nil {
+		until = *s.ExpiresAt
+	}
+	exp, sig := l.signer.SignUntil(shareAttachmentObject(id, s), s.UserID, until)
+	q := url.Values{}
+	q.Set("expires", strconv.FormatInt(exp, 10))
+	q.Set("signature", sig)
+	return fmt.Sprintf("%s/public/%s/attachments/%d/content?%s", l.baseURL, s.Token, id, q.Encode())
+}
+
+// verifyShare checks signature of the download link of the attachment
+// of the shared note.
+func (l *AttachmentLinks) verifyShare(req *http.Request, id int, s domain.Share) error {
+	q := req.URL.Query()
+	exp, err := strconv.ParseInt(q.Get("expires"), 10, 64)
+	if err != nil {
+		return errors.New("invalid expiration time")
+	}
+	return l.signer.Verify(shareAttachmentObject(id, s), s.UserID, exp, q.Get("signature"))
+}
+
+func attachmentObject(id int) string {
+	return "attachment/" + strconv.Itoa(id)
+}
+
+func shareAttachmentObject(id int, s domain.Share) string {
+	return "share/" + strconv.Itoa(s.ID) + "/" + attachmentObject(id)
+}
+
+// AttachmentsController handles HTTP API requests.
+type AttachmentsController struct {
+	repo   storage.AttachmentsRepo
+	blobs  storage.BlobStore
+	links  *AttachmentLinks
+	limits AttachmentLimits
+	log    logrus.FieldLogger
+}
+
+// NewAttachmentsController creates new controller.
+func NewAttachmentsController(
+	repo storage.AttachmentsRepo,
+	blobs storage.BlobStore,
+	links *AttachmentLinks,
+	limits AttachmentLimits,
+	log logrus.FieldLogger,
+) *AttachmentsController {
+	return &AttachmentsController{
+		repo:   repo,
+		blobs:  blobs,
+		links:  links,
+		limits: limits,
+		log:    log,
+	}
+}
+
+// GetList handles request for getting attachments of a note.
+func (c *AttachmentsController) GetList(w http.ResponseWriter, req *http.Request) {
+	userID := getUserID(req)
+	noteID, err := getID(req)
+	if err != nil {
+		notFound(w)
+		return
+	}
+
+	attachments, err := c.repo.Get(storage.AttachmentsFilter{UserID: &userID, NoteID: &noteID})
+	if err != nil {
+		c.log.Errorf("Failed to get attachments: %v", err)
+		internalServerError(w)
+		return
+	}
+	for i := range attachments {
+		attachments[i].URL = c.links.URL(attachments[i].ID, userID)
+	}
+
+	respond(w, http.StatusOK, attachments)
+}
+
+// Upload handles request for attaching file to a note. The file is
+// expected in the "file" field of multipart form.
+func (c *AttachmentsController) Upload(w http.ResponseWriter, req *http.Request) {
+	userID := getUserID(req)
+	noteID, err := getID(req)
+	if err != nil {
+		notFound(w)
+		return
+	}
+
+	limit := c.limits.MaxSize
+	if c.limits.Quota > 0 {
+		used, err := c.repo.Usage(userID)
+		if err != nil {
+			c.log.Errorf("Failed to get attachments usage: %v", err)
+			internalServerError(w)
+			return
+		}
+		if c.limits.Quota-used < limit {
+			limit = c.limits.Quota - used
+		}
+	}
+	if limit <= 0 {
+		respond(w, http.StatusRequestEntityTooLarge, domain.ErrQuotaExceeded.Error())
+		return
+	}
+
+	req.Body = http.MaxBytesReader(w, req.Body, limit+multipartOverhead)
+	mr, err := req.MultipartReader()
+	if err != nil {
+		badRequest(w, "multipart form expected")
+		return
+	}
+	var part io.Reader
+	var name string
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			badRequest(w, "invalid multipart form")
+			return
+		}
+		if p.FormName() == "file" {
+			part, name = p, filepath.Base(p.FileName())
+			break
+		}
+	}
+	if part == nil {
+		badRequest(w, "file is missing")
+		return
+	}
+	if name == "" || name == "." || name == string(filepath.Separator) {
+		badRequest(w, "file name is missing")
+		return
+	}
+
+	// Content type is detected by the content, not taken from the client
+	head := make([]byte, 512)
+	n, err := io.ReadFull(part, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		badRequest(w, "failed to read file")
+		return
+	}
+	head = head[:n]
+
+	key, err := storage.NewBlobKey(userID)
+	if err != nil {
+		c.log.Errorf("Failed to make blob key: %v", err)
+		internalServerError(w)
+		return
+	}
+	body := &countingReader{r: io.LimitReader(io.MultiReader(bytes.NewReader(head), part), limit+1)}
+	if err = c.blobs.Put(key, body); err != nil {
+		c.log.Errorf("Failed to save file: %v", err)
+		c.deleteBlob(key)
+		internalServerError(w)
+		return
+	}
+	if body.n > limit {
+		c.deleteBlob(key)
+		respond(w, http.StatusRequestEntityTooLarge, "file is too large")
+		return
+	}
+
+	a := domain.Attachment{
+		UserID:      userID,
+		NoteID:      noteID,
+		Name:        name,
+		ContentType: http.DetectContentType(head),
+		Size:        body.n,
+		Key:         key,
+	}
+	a, err = c.repo.Create(a, c.limits.Quota)
+	if err != nil {
+		c.deleteBlob(key)
+	}
+	if err == domain.ErrNotFound {
+		notFound(w)
+		return
+	}
+	if err == domain.ErrForbidden {
+		forbidden(w)
+		return
+	}
+	if err == domain.ErrQuotaExceeded {
+		respond(w, http.StatusRequestEntityTooLarge, err.Error())
+		return
+	}
+	if err != nil {
+		c.log.Errorf("Failed to create attachment: %v", err)
+		internalServerError(w)
+		return
+	}
+	a.URL = c.links.URL(a.ID, userID)
+
+	respond(w, http.StatusCreated, a)
+}
+
+// GetOne handles request for getting attachment info by id.
+func (c *AttachmentsController) GetOne(w http.ResponseWriter, req *http.Request) {
+	userID := getUserID(req)
+	id, err := getID(req)
+	if err != nil {
+		notFound(w)
+		return
+	}
+
+	attachments, err := c.repo.Get(storage.AttachmentsFilter{ID: &id, UserID: &userID})
+	if err != nil {
+		c.log.Errorf("Failed to get attachment: %v", err)
+		internalServerError(w)
+		return
+	}
+	if len(attachments) == 0 {
+		notFound(w)
+		return
+	}
+	a := attachments[0]
+	a.URL = c.links.URL(a.ID, userID)
+
+	respond(w, http.StatusOK, a)
+}
+
+// Download handles request for getting contents of the attached file.
+// The request is authenticated by a signed link, range requests are
+// supported.
+func (c *AttachmentsController) Download(w http.ResponseWriter, req *http.Request) {
+	id, err := getID(req)
+	if err != nil {
+		notFound(w)
+		return
+	}
+	userID, err := c.links.verify(req, id)
+	if err != nil {
+		unauthorized(w)
+		return
+	}
+
+	attachments, err := c.repo.Get(storage.AttachmentsFilter{ID: &id, UserID: &userID})
+	if err != nil {
+		c.log.Errorf("Failed to get attachment: %v", err)
+		internalServerError(w)
+		return
+	}
+	if len(attachments) == 0 {
+		notFound(w)
+		return
+	}
+	serveAttachment(w, req, c.blobs, attachments[0], c.log)
+}
+
+// serveAttachment sends content of the attachment, or redirects
+// to the blob store if it can make direct links.
+func serveAttachment(
+	w http.ResponseWriter,
+	req *http.Request,
+	blobs storage.BlobStore,
+	a domain.Attachment,
+	log logrus.FieldLogger,
+) {
+	disposition := "attachment"
+	if a.Inline() {
+		disposition = "inline"
+	}
+	disposition = mime.FormatMediaType(disposition, map[string]string{"filename": a.Name})
+
+	// Let the client download the file from the store directly
+	// if it's possible
+	if p, ok := blobs.(storage.BlobPresigner); ok {
+		h := storage.BlobHeaders{ContentType: a.ContentType, ContentDisposition: disposition}
+		u, err := p.Presign(a.Key, presignTTL, h)
+		if err != nil {
+			log.Errorf("Failed to presign file link: %v", err)
+			internalServerError(w)
+			return
+		}
+		http.Redirect(w, req, u, http.StatusTemporaryRedirect)
+		return
+	}
+
+	blob, err := blobs.Get(a.Key)
+	if err == domain.ErrNotFound {
+		notFound(w)
+		return
+	}
+	if err != nil {
+		log.Errorf("Failed to get file: %v", err)
+		internalServerError(w)
+		return
+	}
+	defer blob.Close() // nolint: errcheck
+
+	w.Header().Set("Content-Type", a.ContentType)
+	w.Header().Set("Content-Disposition", disposition)
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; sandbox")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private, max-age=3600")
+	http.ServeContent(w, req, a.Name, a.CreatedAt, blob)
+}
+
+// Delete handles request for deleting attachment.
+func (c *AttachmentsController) Delete(w http.ResponseWriter, req *http.Request) {
+	userID := getUserID(req)
+	id, err := getID(req)
+	if err != nil {
+		notFound(w)
+		return
+	}
+
+	a := domain.Attachment{ID: id, UserID: userID}
+	if err = c.repo.Delete(a); err != nil {
+		c.log.Errorf("Failed to delete attachment: %v", err)
+		internalServerError(w)
+		return
+	}
+
+	respond(w, http.StatusNoContent, nil)
+}
+
+// deleteBlob deletes blob of the attachment that failed to be created.
+func (c *AttachmentsController) deleteBlob(key string) {
+	if err := c.blobs.Delete(key); err != nil {
+		c.log.Errorf("Failed to delete file: %v", err)
+	}
+}
+
+// countingReader counts bytes that have been read.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	return n, err
+}
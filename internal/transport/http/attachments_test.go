@@ -0,0 +1,278 @@
+package httpapi
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tetafro/nott-backend-go/internal/auth"
+	"github.com/tetafro/nott-backend-go/internal/domain"
+	"github.com/tetafro/nott-backend-go/internal/storage"
+)
+
+func TestAttachmentsController(t *testing.T) {
+	log := logrus.New()
+	log.Out = ioutil.Discard
+
+	user := auth.User{ID: 1}
+	links := NewAttachmentLinks(auth.NewLinkSigner("qwerty"), "https://example.com/api/v1")
+	limits := AttachmentLimits{MaxSize: 100, Quota: 1000}
+
+	// upload makes multipart form with a file
+	upload := func(name, content string) (*bytes.Buffer, string) {
+		body := &bytes.Buffer{}
+		mw := multipart.NewWriter(body)
+		fw, err := mw.CreateFormFile("file", name)
+		assert.NoError(t, err)
+		_, err = fw.Write([]byte(content))
+		assert.NoError(t, err)
+		assert.NoError(t, mw.Close())
+		return body, mw.FormDataContentType()
+	}
+
+	t.Run("Upload file", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		noteID := 10
+		var key string
+
+		repoMock := storage.NewMockAttachmentsRepo(ctrl)
+		repoMock.EXPECT().Usage(user.ID).Return(int64(0), nil)
+		repoMock.EXPECT().
+			Create(gomock.Any(), limits.Quota).
+			DoAndReturn(func(a domain.Attachment, quota int64) (domain.Attachment, error) {
+				assert.Equal(t, key, a.Key)
+				a.Key = ""
+				assert.Equal(t, domain.Attachment{
+					UserID:      user.ID,
+					NoteID:      noteID,
+					Name:        "log.txt",
+					ContentType: "text/plain; charset=utf-8",
+					Size:        12,
+				}, a)
+				a.ID = 20
+				return a, nil
+			})
+		blobsMock := storage.NewMockBlobStore(ctrl)
+		blobsMock.EXPECT().
+			Put(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(k string, r io.Reader) error {
+				key = k
+				data, err := ioutil.ReadAll(r)
+				assert.NoError(t, err)
+				assert.Equal(t, "hello, world", string(data))
+				return nil
+			})
+
+		c := NewAttachmentsController(repoMock, blobsMock, links, limits, log)
+
+		body, contentType := upload("log.txt", "hello, world")
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/", body)
+		req.Header.Set("Content-Type", contentType)
+		req = addUserID(req, user.ID)
+		req = addID(req, noteID)
+
+		c.Upload(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusCreated)
+		assert.True(t, strings.HasPrefix(key, "1/"))
+
+		data, err := ioutil.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		assert.NoError(t, resp.Body.Close())
+		assert.Contains(t, string(data), `"url":"https://example.com/api/v1/attachments/20/content?`)
+	})
+
+	t.Run("Fail to upload too large file", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repoMock := storage.NewMockAttachmentsRepo(ctrl)
+		repoMock.EXPECT().Usage(user.ID).Return(int64(990), nil)
+		blobsMock := storage.NewMockBlobStore(ctrl)
+		blobsMock.EXPECT().
+			Put(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(k string, r io.Reader) error {
+				_, err := ioutil.ReadAll(r)
+				return err
+			})
+		blobsMock.EXPECT().Delete(gomock.Any()).Return(nil)
+
+		c := NewAttachmentsController(repoMock, blobsMock, links, limits, log)
+
+		body, contentType := upload("log.txt", "more than ten bytes")
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/", body)
+		req.Header.Set("Content-Type", contentType)
+		req = addUserID(req, user.ID)
+		req = addID(req, 10)
+
+		c.Upload(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusRequestEntityTooLarge)
+	})
+
+	t.Run("Fail to upload without file", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repoMock := storage.NewMockAttachmentsRepo(ctrl)
+		repoMock.EXPECT().Usage(user.ID).Return(int64(0), nil)
+		blobsMock := storage.NewMockBlobStore(ctrl)
+
+		c := NewAttachmentsController(repoMock, blobsMock, links, limits, log)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString("{}"))
+		req.Header.Set("Content-Type", "application/json")
+		req = addUserID(req, user.ID)
+		req = addID(req, 10)
+
+		c.Upload(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusBadRequest)
+	})
+
+	t.Run("Download part of file", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		id := 20
+		a := domain.Attachment{
+			ID:          id,
+			UserID:      user.ID,
+			NoteID:      10,
+			Name:        "log.txt",
+			ContentType: "text/plain; charset=utf-8",
+			Size:        12,
+			Key:         "1/abc",
+			CreatedAt:   time.Now(),
+		}
+
+		repoMock := storage.NewMockAttachmentsRepo(ctrl)
+		repoMock.EXPECT().
+			Get(storage.AttachmentsFilter{ID: &id, UserID: &user.ID}).
+			Return([]domain.Attachment{a}, nil)
+		blobsMock := storage.NewMockBlobStore(ctrl)
+		blobsMock.EXPECT().
+			Get("1/abc").
+			Return(testBlob{bytes.NewReader([]byte("hello, world"))}, nil)
+
+		c := NewAttachmentsController(repoMock, blobsMock, links, limits, log)
+
+		u, err := url.Parse(links.URL(id, user.ID))
+		assert.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, u.RequestURI(), nil)
+		req.Header.Set("Range", "bytes=7-")
+		req = addID(req, id)
+
+		c.Download(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusPartialContent)
+		assert.Equal(t, "text/plain; charset=utf-8", resp.Header.Get("Content-Type"))
+		assert.Equal(t, `inline; filename=log.txt`, resp.Header.Get("Content-Disposition"))
+
+		data, err := ioutil.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		assert.NoError(t, resp.Body.Close())
+		assert.Equal(t, "world", string(data))
+	})
+
+	t.Run("Redirect to presigned link", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		id := 20
+		a := domain.Attachment{
+			ID:          id,
+			UserID:      user.ID,
+			NoteID:      10,
+			Name:        "data.zip",
+			ContentType: "application/zip",
+			Size:        12,
+			Key:         "1/abc",
+			CreatedAt:   time.Now(),
+		}
+
+		repoMock := storage.NewMockAttachmentsRepo(ctrl)
+		repoMock.EXPECT().
+			Get(storage.AttachmentsFilter{ID: &id, UserID: &user.ID}).
+			Return([]domain.Attachment{a}, nil)
+		presignerMock := storage.NewMockBlobPresigner(ctrl)
+		presignerMock.EXPECT().
+			Presign("1/abc", presignTTL, storage.BlobHeaders{
+				ContentType:        "application/zip",
+				ContentDisposition: "attachment; filename=data.zip",
+			}).
+			Return("https://s3.example.com/bucket/1/abc?X-Amz-Signature=xxx", nil)
+		blobs := struct {
+			*storage.MockBlobStore
+			*storage.MockBlobPresigner
+		}{storage.NewMockBlobStore(ctrl), presignerMock}
+
+		c := NewAttachmentsController(repoMock, blobs, links, limits, log)
+
+		u, err := url.Parse(links.URL(id, user.ID))
+		assert.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, u.RequestURI(), nil)
+		req = addID(req, id)
+
+		c.Download(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusTemporaryRedirect)
+		assert.Equal(t,
+			"https://s3.example.com/bucket/1/abc?X-Amz-Signature=xxx",
+			resp.Header.Get("Location"))
+	})
+
+	t.Run("Fail to download with invalid signature", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repoMock := storage.NewMockAttachmentsRepo(ctrl)
+		blobsMock := storage.NewMockBlobStore(ctrl)
+
+		c := NewAttachmentsController(repoMock, blobsMock, links, limits, log)
+
+		u, err := url.Parse(links.URL(20, user.ID))
+		assert.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, u.RequestURI(), nil)
+		req = addID(req, 21)
+
+		c.Download(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusUnauthorized)
+	})
+}
+
+// testBlob is an in-memory blob.
+type testBlob struct {
+	*bytes.Reader
+}
+
+func (testBlob) Close() error { return nil }
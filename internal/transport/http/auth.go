@@ -8,19 +8,20 @@ import (
 
 	"github.com/tetafro/nott-backend-go/internal/auth"
 	"github.com/tetafro/nott-backend-go/internal/domain"
+	"github.com/tetafro/nott-backend-go/internal/markdown"
 	"github.com/tetafro/nott-backend-go/internal/storage"
 )
 
 // AuthController handles HTTP API requests.
 type AuthController struct {
-	users   storage.UsersRepo
-	tokener auth.Tokener
-	log     logrus.FieldLogger
+	users    storage.UsersRepo
+	sessions *Sessions
+	log      logrus.FieldLogger
 }
 
 // NewAuthController creates new controller.
-func NewAuthController(u storage.UsersRepo, t auth.Tokener, log logrus.FieldLogger) *AuthController {
-	return &AuthController{users: u, tokener: t, log: log}
+func NewAuthController(u storage.UsersRepo, s *Sessions, log logrus.FieldLogger) *AuthController {
+	return &AuthController{users: u, sessions: s, log: log}
 }
 
 // Register handles request for registering new users.
@@ -44,7 +45,7 @@ func (c *AuthController) Register(w http.ResponseWriter, req *http.Request) {
 	}
 
 	// Create user in the repository
-	user := auth.User{Email: body.Email}
+	user := auth.User{Email: body.Email, Theme: markdown.DefaultTheme}
 	user.Password, err = auth.HashPassword(body.Password)
 	if err != nil {
 		c.log.Errorf("Failed to hash password: %v", err)
@@ -58,10 +59,10 @@ func (c *AuthController) Register(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	// Generate token
-	t, err := c.tokener.Issue(user)
+	// Start session
+	t, err := c.sessions.Start(user, req)
 	if err != nil {
-		c.log.Errorf("Failed to issue token: %v", err)
+		c.log.Errorf("Failed to start session: %v", err)
 		internalServerError(w)
 		return
 	}
@@ -94,10 +95,10 @@ func (c *AuthController) Login(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	// Generate token
-	t, err := c.tokener.Issue(user)
+	// Start session
+	t, err := c.sessions.Start(user, req)
 	if err != nil {
-		c.log.Errorf("Failed to issue token: %v", err)
+		c.log.Errorf("Failed to start session: %v", err)
 		internalServerError(w)
 		return
 	}
@@ -105,6 +106,55 @@ func (c *AuthController) Login(w http.ResponseWriter, req *http.Request) {
 	respond(w, http.StatusOK, t)
 }
 
+// Refresh handles request for getting new access token using refresh
+// token. The refresh token is rotated, so it can only be used once.
+func (c *AuthController) Refresh(w http.ResponseWriter, req *http.Request) {
+	var body refreshRequest
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		badRequest(w, "invalid json")
+		return
+	}
+	defer req.Body.Close()
+
+	if body.RefreshToken == "" {
+		badRequest(w, "refresh token cannot be empty")
+		return
+	}
+
+	t, err := c.sessions.Refresh(body.RefreshToken, req)
+	if err == domain.ErrNotFound {
+		unauthorized(w)
+		return
+	}
+	if err == auth.ErrTokenReused {
+		c.log.Warnf("Refresh token reused, its family is revoked")
+		unauthorized(w)
+		return
+	}
+	if err != nil {
+		c.log.Errorf("Failed to refresh session: %v", err)
+		internalServerError(w)
+		return
+	}
+
+	respond(w, http.StatusOK, t)
+}
+
+// Logout handles request for logging out. The current session
+// is ended, so both its access and refresh tokens are revoked.
+func (c *AuthController) Logout(w http.ResponseWriter, req *http.Request) {
+	userID := getUserID(req)
+
+	err := c.sessions.End(getSessionID(req), userID)
+	if err != nil && err != domain.ErrNotFound {
+		c.log.Errorf("Failed to end session: %v", err)
+		internalServerError(w)
+		return
+	}
+
+	respond(w, http.StatusNoContent, nil)
+}
+
 // GetProfile handles request for getting current logged in user.
 func (c *AuthController) GetProfile(w http.ResponseWriter, req *http.Request) {
 	userID := getUserID(req)
@@ -140,6 +190,13 @@ func (c *AuthController) UpdateProfile(w http.ResponseWriter, req *http.Request)
 		badRequest(w, "invalid user: "+err.Error())
 		return
 	}
+	if user.Theme == "" {
+		user.Theme = markdown.DefaultTheme
+	}
+	if !markdown.ValidTheme(user.Theme) {
+		badRequest(w, "invalid user: unknown theme")
+		return
+	}
 
 	user, err = c.users.Update(user)
 	if err == domain.ErrNotFound {
@@ -159,3 +216,7 @@ type authRequest struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
 }
+
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
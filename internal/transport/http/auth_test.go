@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/pkg/errors"
@@ -15,6 +16,7 @@ import (
 
 	"github.com/tetafro/nott-backend-go/internal/auth"
 	"github.com/tetafro/nott-backend-go/internal/domain"
+	"github.com/tetafro/nott-backend-go/internal/markdown"
 	"github.com/tetafro/nott-backend-go/internal/storage"
 )
 
@@ -26,6 +28,8 @@ func TestAuthController(t *testing.T) {
 	hash := "$2a$14$EsnwEn3C6cxQUWXvUpJ6S.XsJSku11hTSULXn8NEIG1diGcGEgrii"
 	user := auth.User{ID: 1, Email: "bob@example.com", Password: hash}
 	token := auth.Token{AccessToken: "qwerty123", ExpiresAt: 10}
+	// Requests are made by httptest package
+	session := auth.Session{UserID: user.ID, IP: "192.0.2.1"}
 
 	t.Run("Registrater new user", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
@@ -35,10 +39,19 @@ func TestAuthController(t *testing.T) {
 		usersRepoMock.EXPECT().GetByEmail(user.Email).Return(auth.User{}, domain.ErrNotFound)
 		usersRepoMock.EXPECT().Create(gomock.Any()).Return(user, nil)
 
+		sessionsRepoMock := storage.NewMockSessionsRepo(ctrl)
+		sessionsRepoMock.EXPECT().Create(session).Return(auth.Session{ID: 5, UserID: user.ID}, nil)
+
 		tokenerMock := auth.NewMockTokener(ctrl)
-		tokenerMock.EXPECT().Issue(user).Return(token, nil)
+		tokenerMock.EXPECT().Issue(user, 5).Return(token, nil)
+		tokenerMock.EXPECT().IssueRefresh().Return("refresh", auth.RefreshToken{Family: "f"}, nil)
+
+		refreshRepoMock := storage.NewMockRefreshTokensRepo(ctrl)
+		refreshRepoMock.EXPECT().Create(auth.RefreshToken{UserID: user.ID, SessionID: 5, Family: "f"}).
+			Return(auth.RefreshToken{ID: 1, UserID: user.ID, SessionID: 5, Family: "f"}, nil)
 
-		c := NewAuthController(usersRepoMock, tokenerMock, log)
+		sessions := NewSessions(sessionsRepoMock, refreshRepoMock, nil, tokenerMock, time.Minute)
+		c := NewAuthController(usersRepoMock, sessions, log)
 
 		payload, err := json.Marshal(authRequest{Email: user.Email, Password: password})
 		assert.NoError(t, err)
@@ -61,7 +74,8 @@ func TestAuthController(t *testing.T) {
 		assert.JSONEq(t, string(body), `{
 			"data": {
 				"access_token": "qwerty123",
-				"expires_at": 10
+				"expires_at": 10,
+				"refresh_token": "refresh"
 			}
 		}`)
 	})
@@ -72,9 +86,7 @@ func TestAuthController(t *testing.T) {
 
 		usersRepoMock := storage.NewMockUsersRepo(ctrl)
 
-		tokenerMock := auth.NewMockTokener(ctrl)
-
-		c := NewAuthController(usersRepoMock, tokenerMock, log)
+		c := NewAuthController(usersRepoMock, nil, log)
 
 		payload := []byte("{malformed-json:")
 
@@ -95,9 +107,7 @@ func TestAuthController(t *testing.T) {
 		usersRepoMock := storage.NewMockUsersRepo(ctrl)
 		usersRepoMock.EXPECT().GetByEmail(user.Email).Return(auth.User{ID: 1}, nil)
 
-		tokenerMock := auth.NewMockTokener(ctrl)
-
-		c := NewAuthController(usersRepoMock, tokenerMock, log)
+		c := NewAuthController(usersRepoMock, nil, log)
 
 		payload, err := json.Marshal(authRequest{Email: user.Email, Password: password})
 		assert.NoError(t, err)
@@ -120,9 +130,7 @@ func TestAuthController(t *testing.T) {
 		usersRepoMock.EXPECT().GetByEmail(user.Email).Return(auth.User{}, domain.ErrNotFound)
 		usersRepoMock.EXPECT().Create(gomock.Any()).Return(auth.User{}, errors.New("error"))
 
-		tokenerMock := auth.NewMockTokener(ctrl)
-
-		c := NewAuthController(usersRepoMock, tokenerMock, log)
+		c := NewAuthController(usersRepoMock, nil, log)
 
 		payload, err := json.Marshal(authRequest{Email: user.Email, Password: password})
 		assert.NoError(t, err)
@@ -144,10 +152,19 @@ func TestAuthController(t *testing.T) {
 		usersRepoMock := storage.NewMockUsersRepo(ctrl)
 		usersRepoMock.EXPECT().GetByEmail(user.Email).Return(user, nil)
 
+		sessionsRepoMock := storage.NewMockSessionsRepo(ctrl)
+		sessionsRepoMock.EXPECT().Create(session).Return(auth.Session{ID: 5, UserID: user.ID}, nil)
+
 		tokenerMock := auth.NewMockTokener(ctrl)
-		tokenerMock.EXPECT().Issue(user).Return(token, nil)
+		tokenerMock.EXPECT().Issue(user, 5).Return(token, nil)
+		tokenerMock.EXPECT().IssueRefresh().Return("refresh", auth.RefreshToken{Family: "f"}, nil)
+
+		refreshRepoMock := storage.NewMockRefreshTokensRepo(ctrl)
+		refreshRepoMock.EXPECT().Create(auth.RefreshToken{UserID: user.ID, SessionID: 5, Family: "f"}).
+			Return(auth.RefreshToken{ID: 1, UserID: user.ID, SessionID: 5, Family: "f"}, nil)
 
-		c := NewAuthController(usersRepoMock, tokenerMock, log)
+		sessions := NewSessions(sessionsRepoMock, refreshRepoMock, nil, tokenerMock, time.Minute)
+		c := NewAuthController(usersRepoMock, sessions, log)
 
 		payload, err := json.Marshal(authRequest{Email: user.Email, Password: password})
 		assert.NoError(t, err)
@@ -170,7 +187,8 @@ func TestAuthController(t *testing.T) {
 		assert.JSONEq(t, string(body), `{
 			"data": {
 				"access_token": "qwerty123",
-				"expires_at": 10
+				"expires_at": 10,
+				"refresh_token": "refresh"
 			}
 		}`)
 	})
@@ -181,9 +199,7 @@ func TestAuthController(t *testing.T) {
 
 		usersRepoMock := storage.NewMockUsersRepo(ctrl)
 
-		tokenerMock := auth.NewMockTokener(ctrl)
-
-		c := NewAuthController(usersRepoMock, tokenerMock, log)
+		c := NewAuthController(usersRepoMock, nil, log)
 
 		payload := []byte("{malformed-json:")
 
@@ -204,9 +220,7 @@ func TestAuthController(t *testing.T) {
 		usersRepoMock := storage.NewMockUsersRepo(ctrl)
 		usersRepoMock.EXPECT().GetByEmail(user.Email).Return(auth.User{}, errors.New("error"))
 
-		tokenerMock := auth.NewMockTokener(ctrl)
-
-		c := NewAuthController(usersRepoMock, tokenerMock, log)
+		c := NewAuthController(usersRepoMock, nil, log)
 
 		payload, err := json.Marshal(authRequest{Email: user.Email, Password: password})
 		assert.NoError(t, err)
@@ -228,10 +242,14 @@ func TestAuthController(t *testing.T) {
 		usersRepoMock := storage.NewMockUsersRepo(ctrl)
 		usersRepoMock.EXPECT().GetByEmail(user.Email).Return(user, nil)
 
+		sessionsRepoMock := storage.NewMockSessionsRepo(ctrl)
+		sessionsRepoMock.EXPECT().Create(session).Return(auth.Session{ID: 5, UserID: user.ID}, nil)
+
 		tokenerMock := auth.NewMockTokener(ctrl)
-		tokenerMock.EXPECT().Issue(user).Return(auth.Token{}, errors.New("error"))
+		tokenerMock.EXPECT().Issue(user, 5).Return(auth.Token{}, errors.New("error"))
 
-		c := NewAuthController(usersRepoMock, tokenerMock, log)
+		sessions := NewSessions(sessionsRepoMock, nil, nil, tokenerMock, time.Minute)
+		c := NewAuthController(usersRepoMock, sessions, log)
 
 		payload, err := json.Marshal(authRequest{Email: user.Email, Password: password})
 		assert.NoError(t, err)
@@ -250,14 +268,12 @@ func TestAuthController(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
-		user := auth.User{ID: 10, Email: "bob@example.com"}
+		user := auth.User{ID: 10, Email: "bob@example.com", Theme: "monokai"}
 
 		userRepoMock := storage.NewMockUsersRepo(ctrl)
 		userRepoMock.EXPECT().GetByID(user.ID).Return(user, nil)
 
-		tokenerMock := auth.NewMockTokener(ctrl)
-
-		c := NewAuthController(userRepoMock, tokenerMock, log)
+		c := NewAuthController(userRepoMock, nil, log)
 
 		url := "/"
 		w := httptest.NewRecorder()
@@ -278,7 +294,8 @@ func TestAuthController(t *testing.T) {
 		assert.JSONEq(t, string(body), `{
 			"data": {
 				"id": 10,
-				"email": "bob@example.com"
+				"email": "bob@example.com",
+				"theme": "monokai"
 			}
 		}`)
 	})
@@ -287,14 +304,12 @@ func TestAuthController(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
-		user := auth.User{ID: 10, Email: "bob@example.com"}
+		user := auth.User{ID: 10, Email: "bob@example.com", Theme: "monokai"}
 
 		userRepoMock := storage.NewMockUsersRepo(ctrl)
 		userRepoMock.EXPECT().Update(user).Return(user, nil)
 
-		tokenerMock := auth.NewMockTokener(ctrl)
-
-		c := NewAuthController(userRepoMock, tokenerMock, log)
+		c := NewAuthController(userRepoMock, nil, log)
 
 		payload, err := json.Marshal(user)
 		assert.NoError(t, err)
@@ -318,23 +333,64 @@ func TestAuthController(t *testing.T) {
 		assert.JSONEq(t, string(body), `{
 			"data": {
 				"id": 10,
-				"email": "bob@example.com"
+				"email": "bob@example.com",
+				"theme": "monokai"
 			}
 		}`)
 	})
 
+	t.Run("Update profile with default theme", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		user := auth.User{ID: 10, Email: "bob@example.com", Theme: markdown.DefaultTheme}
+
+		userRepoMock := storage.NewMockUsersRepo(ctrl)
+		userRepoMock.EXPECT().Update(user).Return(user, nil)
+
+		c := NewAuthController(userRepoMock, nil, log)
+
+		url := "/"
+		payload := `{"email": "bob@example.com"}`
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, url, bytes.NewBufferString(payload))
+		req = addUserID(req, user.ID)
+
+		c.UpdateProfile(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusOK)
+	})
+
+	t.Run("Update profile with unknown theme", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		userRepoMock := storage.NewMockUsersRepo(ctrl)
+		c := NewAuthController(userRepoMock, nil, log)
+
+		url := "/"
+		payload := `{"email": "bob@example.com", "theme": "unknown"}`
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, url, bytes.NewBufferString(payload))
+		req = addUserID(req, 10)
+
+		c.UpdateProfile(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusBadRequest)
+	})
+
 	t.Run("Fail to update profile", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
-		user := auth.User{Email: "bob@example.com"}
+		user := auth.User{Email: "bob@example.com", Theme: markdown.DefaultTheme}
 
 		userRepoMock := storage.NewMockUsersRepo(ctrl)
 		userRepoMock.EXPECT().Update(user).Return(auth.User{}, errors.New("error"))
 
-		tokenerMock := auth.NewMockTokener(ctrl)
-
-		c := NewAuthController(userRepoMock, tokenerMock, log)
+		c := NewAuthController(userRepoMock, nil, log)
 
 		payload, err := json.Marshal(user)
 		assert.NoError(t, err)
@@ -349,4 +405,132 @@ func TestAuthController(t *testing.T) {
 		resp := w.Result()
 		assert.Equal(t, resp.StatusCode, http.StatusInternalServerError)
 	})
+
+	t.Run("Refresh token", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		next := auth.RefreshToken{Family: "new", Hash: auth.HashToken("next")}
+
+		tokenerMock := auth.NewMockTokener(ctrl)
+		tokenerMock.EXPECT().IssueRefresh().Return("next", next, nil)
+		tokenerMock.EXPECT().Issue(auth.User{ID: user.ID}, 5).Return(token, nil)
+
+		refreshRepoMock := storage.NewMockRefreshTokensRepo(ctrl)
+		refreshRepoMock.EXPECT().Rotate(auth.HashToken("prev"), next).Return(
+			auth.RefreshToken{ID: 2, UserID: user.ID, SessionID: 5, Family: "old", Hash: next.Hash}, nil,
+		)
+
+		sessionsRepoMock := storage.NewMockSessionsRepo(ctrl)
+		sessionsRepoMock.EXPECT().Touch(5, "192.0.2.1").Return(nil)
+
+		sessions := NewSessions(sessionsRepoMock, refreshRepoMock, nil, tokenerMock, time.Minute)
+		c := NewAuthController(nil, sessions, log)
+
+		url := "/"
+		payload := `{"refresh_token": "prev"}`
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, url, bytes.NewBufferString(payload))
+
+		c.Refresh(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		body, err := ioutil.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		assert.NoError(t, resp.Body.Close())
+
+		assert.JSONEq(t, `{
+			"data": {
+				"access_token": "qwerty123",
+				"expires_at": 10,
+				"refresh_token": "next"
+			}
+		}`, string(body))
+	})
+
+	t.Run("Fail to refresh invalid or reused token", func(t *testing.T) {
+		for _, rotateErr := range []error{domain.ErrNotFound, auth.ErrTokenReused} {
+			ctrl := gomock.NewController(t)
+
+			tokenerMock := auth.NewMockTokener(ctrl)
+			tokenerMock.EXPECT().IssueRefresh().Return("next", auth.RefreshToken{}, nil)
+
+			refreshRepoMock := storage.NewMockRefreshTokensRepo(ctrl)
+			refreshRepoMock.EXPECT().Rotate(auth.HashToken("prev"), auth.RefreshToken{}).
+				Return(auth.RefreshToken{}, rotateErr)
+
+			sessions := NewSessions(nil, refreshRepoMock, nil, tokenerMock, time.Minute)
+			c := NewAuthController(nil, sessions, log)
+
+			url := "/"
+			payload := `{"refresh_token": "prev"}`
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, url, bytes.NewBufferString(payload))
+
+			c.Refresh(w, req)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code, rotateErr.Error())
+
+			ctrl.Finish()
+		}
+	})
+
+	t.Run("Fail to refresh empty token", func(t *testing.T) {
+		c := NewAuthController(nil, nil, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, url, bytes.NewBufferString(`{}`))
+
+		c.Refresh(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Logout", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		sessionsRepoMock := storage.NewMockSessionsRepo(ctrl)
+		sessionsRepoMock.EXPECT().Delete(5, user.ID).Return(nil)
+
+		denylistMock := auth.NewMockDenylist(ctrl)
+		denylistMock.EXPECT().Revoke("session:5", gomock.Any()).Return(nil)
+
+		sessions := NewSessions(sessionsRepoMock, nil, denylistMock, nil, time.Minute)
+		c := NewAuthController(nil, sessions, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, url, nil)
+		req = addUserID(req, user.ID)
+		req = addSessionID(req, 5)
+
+		c.Logout(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("Logout from ended session", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		sessionsRepoMock := storage.NewMockSessionsRepo(ctrl)
+		sessionsRepoMock.EXPECT().Delete(5, user.ID).Return(domain.ErrNotFound)
+
+		sessions := NewSessions(sessionsRepoMock, nil, nil, nil, time.Minute)
+		c := NewAuthController(nil, sessions, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, url, nil)
+		req = addUserID(req, user.ID)
+		req = addSessionID(req, 5)
+
+		c.Logout(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
 }
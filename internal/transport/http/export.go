@@ -0,0 +1,164 @@
+package httpapi
+
+import (
+	"mime"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/tetafro/nott-backend-go/internal/archive"
+	"github.com/tetafro/nott-backend-go/internal/domain"
+	"github.com/tetafro/nott-backend-go/internal/storage"
+)
+
+// exportPageSize is a number of notes that are read from repository
+// at once during export.
+const exportPageSize = 100
+
+// ExportController handles HTTP API requests.
+type ExportController struct {
+	folders     storage.FoldersRepo
+	notepads    storage.NotepadsRepo
+	notes       storage.NotesRepo
+	attachments storage.AttachmentsRepo
+	blobs       storage.BlobStore
+	log         logrus.FieldLogger
+}
+
+// NewExportController creates new controller.
+func NewExportController(
+	folders storage.FoldersRepo,
+	notepads storage.NotepadsRepo,
+	notes storage.NotesRepo,
+	attachments storage.AttachmentsRepo,
+	blobs storage.BlobStore,
+	log logrus.FieldLogger,
+) *ExportController {
+	return &ExportController{
+		folders:     folders,
+		notepads:    notepads,
+		notes:       notes,
+		attachments: attachments,
+		blobs:       blobs,
+		log:         log,
+	}
+}
+
+// Export handles request for exporting all data available to the user
+// as a zip archive. The archive is streamed: notes are read from
+// repository page by page and written right away, and attached files
+// are copied from the blob store one by one.
+func (c *ExportController) Export(w http.ResponseWriter, req *http.Request) {
+	userID := getUserID(req)
+
+	folders, err := c.folders.Get(storage.FoldersFilter{UserID: &userID})
+	if err != nil {
+		c.log.Errorf("Failed to get folders: %v", err)
+		internalServerError(w)
+		return
+	}
+	notepads, err := c.notepads.Get(storage.NotepadsFilter{UserID: &userID})
+	if err != nil {
+		c.log.Errorf("Failed to get notepads: %v", err)
+		internalServerError(w)
+		return
+	}
+	attachments, err := c.attachments.Get(storage.AttachmentsFilter{UserID: &userID})
+	if err != nil {
+		c.log.Errorf("Failed to get attachments: %v", err)
+		internalServerError(w)
+		return
+	}
+
+	paths := archive.NewPaths()
+	manifest := archive.NewManifest(paths, folders, notepads, attachments)
+
+	name := "nott-export-" + manifest.ExportedAt.Format("2006-01-02") + ".zip"
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition",
+		mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+	w.WriteHeader(http.StatusOK)
+
+	// Status is already sent, so errors can only be logged, the client
+	// gets a broken archive
+	if err = c.write(w, userID, paths, manifest, attachments); err != nil {
+		c.log.Errorf("Failed to export data: %v", err)
+	}
+}
+
+func (c *ExportController) write(
+	w http.ResponseWriter,
+	userID int,
+	paths *archive.Paths,
+	manifest archive.Manifest,
+	attachments []domain.Attachment,
+) error {
+	aw := archive.NewWriter(w)
+
+	if err := aw.WriteManifest(manifest); err != nil {
+		return errors.Wrap(err, "write manifest")
+	}
+	for _, n := range manifest.Notepads {
+		if err := c.writeNotes(aw, userID, paths, n); err != nil {
+			return errors.Wrapf(err, "write notes of notepad %d", n.ID)
+		}
+	}
+	for i, a := range manifest.Attachments {
+		if err := c.writeAttachment(aw, a.Path, attachments[i]); err != nil {
+			return errors.Wrapf(err, "write attachment %d", a.ID)
+		}
+	}
+
+	return aw.Close()
+}
+
+// writeNotes writes notes of the notepad reading them page by page.
+func (c *ExportController) writeNotes(
+	aw *archive.Writer,
+	userID int,
+	paths *archive.Paths,
+	notepad archive.Notepad,
+) error {
+	page := &storage.Page{
+		Limit: exportPageSize,
+		Sort:  storage.Sort{Field: storage.SortCreatedAt},
+	}
+	for {
+		notes, err := c.notes.Get(storage.NotesFilter{
+			UserID:    &userID,
+			NotepadID: &notepad.ID,
+			Page:      page,
+		})
+		if err != nil {
+			return errors.Wrap(err, "get notes")
+		}
+		for _, n := range notes {
+			if err = aw.WriteNote(paths.Make(notepad.Path, n.Title, ".md"), n); err != nil {
+				return err
+			}
+		}
+		if !pageFull(page, len(notes)) {
+			return nil
+		}
+		last := notes[len(notes)-1]
+		after := storage.NewCursor(page.Sort, last.ID, last.Title, last.CreatedAt, last.UpdatedAt)
+		page.After = &after
+	}
+}
+
+// writeAttachment copies attached file from the blob store. Files that
+// are missing in the store are skipped.
+func (c *ExportController) writeAttachment(aw *archive.Writer, path string, a domain.Attachment) error {
+	blob, err := c.blobs.Get(a.Key)
+	if err == domain.ErrNotFound {
+		c.log.Warnf("File of attachment %d is missing", a.ID)
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "get file")
+	}
+	defer blob.Close() // nolint: errcheck
+
+	return aw.WriteFile(path, blob)
+}
@@ -0,0 +1,129 @@
+package httpapi
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tetafro/nott-backend-go/internal/archive"
+	"github.com/tetafro/nott-backend-go/internal/auth"
+	"github.com/tetafro/nott-backend-go/internal/domain"
+	"github.com/tetafro/nott-backend-go/internal/storage"
+)
+
+func TestExportController(t *testing.T) {
+	log := logrus.New()
+	log.Out = ioutil.Discard
+
+	user := auth.User{ID: 1}
+	created := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run("Export data", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		notepadID := 10
+		folders := []domain.Folder{{ID: 1, UserID: user.ID, Title: "Work", CreatedAt: created}}
+		notepads := []domain.Notepad{{ID: notepadID, UserID: user.ID, FolderID: 1, Title: "Plans", CreatedAt: created}}
+		notes := []domain.Note{
+			{ID: 20, UserID: user.ID, NotepadID: notepadID, Title: "Q1", Text: "Hello", CreatedAt: created},
+			{ID: 21, UserID: user.ID, NotepadID: notepadID, Title: "Q1", Text: "World", CreatedAt: created},
+		}
+		attachments := []domain.Attachment{
+			{ID: 30, UserID: user.ID, NoteID: 20, Name: "a.txt", Key: "key", CreatedAt: created},
+		}
+
+		foldersMock := storage.NewMockFoldersRepo(ctrl)
+		foldersMock.EXPECT().Get(storage.FoldersFilter{UserID: &user.ID}).Return(folders, nil)
+		notepadsMock := storage.NewMockNotepadsRepo(ctrl)
+		notepadsMock.EXPECT().Get(storage.NotepadsFilter{UserID: &user.ID}).Return(notepads, nil)
+		notesMock := storage.NewMockNotesRepo(ctrl)
+		notesMock.EXPECT().Get(storage.NotesFilter{
+			UserID:    &user.ID,
+			NotepadID: &notepadID,
+			Page: &storage.Page{
+				Limit: exportPageSize,
+				Sort:  storage.Sort{Field: storage.SortCreatedAt},
+			},
+		}).Return(notes, nil)
+		attachmentsMock := storage.NewMockAttachmentsRepo(ctrl)
+		attachmentsMock.EXPECT().Get(storage.AttachmentsFilter{UserID: &user.ID}).Return(attachments, nil)
+		blobsMock := storage.NewMockBlobStore(ctrl)
+		blobsMock.EXPECT().Get("key").Return(testBlob{bytes.NewReader([]byte("file"))}, nil)
+
+		c := NewExportController(foldersMock, notepadsMock, notesMock, attachmentsMock, blobsMock, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req = addUserID(req, user.ID)
+
+		c.Export(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusOK)
+		assert.Equal(t, "application/zip", resp.Header.Get("Content-Type"))
+		assert.Regexp(t, `^attachment; filename=nott-export-\d{4}-\d{2}-\d{2}\.zip$`,
+			resp.Header.Get("Content-Disposition"))
+
+		body, err := ioutil.ReadAll(resp.Body)
+		assert.NoError(t, err)
+
+		err = resp.Body.Close()
+		assert.NoError(t, err)
+
+		r, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+		assert.NoError(t, err)
+
+		var names []string
+		for _, f := range r.File {
+			names = append(names, f.Name)
+		}
+		sort.Strings(names)
+		assert.Equal(t, []string{
+			"Work/",
+			"Work/Plans/",
+			"Work/Plans/Q1 (2).md",
+			"Work/Plans/Q1.md",
+			archive.AttachmentsDir + "/30-a.txt",
+			archive.ManifestName,
+		}, names)
+	})
+
+	t.Run("Fail to export data", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		foldersMock := storage.NewMockFoldersRepo(ctrl)
+		foldersMock.EXPECT().Get(gomock.Any()).Return(nil, errors.New("error"))
+
+		c := NewExportController(
+			foldersMock,
+			storage.NewMockNotepadsRepo(ctrl),
+			storage.NewMockNotesRepo(ctrl),
+			storage.NewMockAttachmentsRepo(ctrl),
+			storage.NewMockBlobStore(ctrl),
+			log,
+		)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req = addUserID(req, user.ID)
+
+		c.Export(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusInternalServerError)
+	})
+}
@@ -2,7 +2,9 @@ package httpapi
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/sirupsen/logrus"
 
@@ -24,15 +26,26 @@ func NewFoldersController(repo storage.FoldersRepo, log logrus.FieldLogger) *Fol
 // GetList handles request for getting folders.
 func (c *FoldersController) GetList(w http.ResponseWriter, req *http.Request) {
 	userID := getUserID(req)
+	page, err := getPage(req)
+	if err != nil {
+		badRequest(w, err.Error())
+		return
+	}
 
-	folders, err := c.repo.Get(storage.FoldersFilter{UserID: &userID})
+	folders, err := c.repo.Get(storage.FoldersFilter{UserID: &userID, Page: page})
 	if err != nil {
 		c.log.Errorf("Failed to get folders: %v", err)
 		internalServerError(w)
 		return
 	}
 
-	respond(w, http.StatusOK, folders)
+	var next string
+	if pageFull(page, len(folders)) {
+		last := folders[len(folders)-1]
+		next = storage.NewCursor(page.Sort, last.ID, last.Title, last.CreatedAt, last.UpdatedAt).Encode()
+	}
+
+	respondPage(w, http.StatusOK, folders, next)
 }
 
 // Create handles request for creating folder.
@@ -47,6 +60,8 @@ func (c *FoldersController) Create(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 	f.UserID = userID
+	// Objects are moved to trash only by deleting them
+	f.DeletedAt = nil
 
 	if err = f.Validate(); err != nil {
 		badRequest(w, "invalid folder: "+err.Error())
@@ -54,6 +69,14 @@ func (c *FoldersController) Create(w http.ResponseWriter, req *http.Request) {
 	}
 
 	f, err = c.repo.Create(f)
+	if err == domain.ErrForbidden {
+		forbidden(w)
+		return
+	}
+	if err == domain.ErrParentNotFound {
+		respond(w, http.StatusNotFound, "parent not found")
+		return
+	}
 	if err != nil {
 		c.log.Errorf("Failed to create folder: %v", err)
 		internalServerError(w)
@@ -103,6 +126,8 @@ func (c *FoldersController) Update(w http.ResponseWriter, req *http.Request) {
 	}
 	f.ID = id
 	f.UserID = userID
+	// Objects are moved to trash only by deleting them
+	f.DeletedAt = nil
 
 	if err = f.Validate(); err != nil {
 		badRequest(w, "invalid folder: "+err.Error())
@@ -114,6 +139,18 @@ func (c *FoldersController) Update(w http.ResponseWriter, req *http.Request) {
 		notFound(w)
 		return
 	}
+	if err == domain.ErrForbidden {
+		forbidden(w)
+		return
+	}
+	if err == domain.ErrParentNotFound {
+		respond(w, http.StatusNotFound, "parent not found")
+		return
+	}
+	if err == domain.ErrCycle {
+		badRequest(w, err.Error())
+		return
+	}
 	if err != nil {
 		c.log.Errorf("Failed to update folder: %v", err)
 		internalServerError(w)
@@ -123,6 +160,47 @@ func (c *FoldersController) Update(w http.ResponseWriter, req *http.Request) {
 	respond(w, http.StatusOK, f)
 }
 
+// Move handles request for moving folder into another folder.
+func (c *FoldersController) Move(w http.ResponseWriter, req *http.Request) {
+	userID := getUserID(req)
+	id, err := getID(req)
+	if err != nil {
+		notFound(w)
+		return
+	}
+
+	f := domain.Folder{}
+	if err = json.NewDecoder(req.Body).Decode(&f); err != nil {
+		badRequest(w, "invalid json")
+		return
+	}
+
+	f, err = c.repo.Move(domain.Folder{ID: id, UserID: userID, ParentID: f.ParentID})
+	if err == domain.ErrNotFound {
+		notFound(w)
+		return
+	}
+	if err == domain.ErrForbidden {
+		forbidden(w)
+		return
+	}
+	if err == domain.ErrParentNotFound {
+		respond(w, http.StatusNotFound, "parent not found")
+		return
+	}
+	if err == domain.ErrCycle {
+		badRequest(w, err.Error())
+		return
+	}
+	if err != nil {
+		c.log.Errorf("Failed to move folder: %v", err)
+		internalServerError(w)
+		return
+	}
+
+	respond(w, http.StatusOK, f)
+}
+
 // Delete handles request for deleting folder.
 func (c *FoldersController) Delete(w http.ResponseWriter, req *http.Request) {
 	userID := getUserID(req)
@@ -133,7 +211,12 @@ func (c *FoldersController) Delete(w http.ResponseWriter, req *http.Request) {
 	}
 
 	f := domain.Folder{ID: id, UserID: userID}
-	if err = c.repo.Delete(f); err != nil {
+	err = c.repo.Delete(f)
+	if err == domain.ErrForbidden {
+		forbidden(w)
+		return
+	}
+	if err != nil {
 		c.log.Errorf("Failed to update folder: %v", err)
 		internalServerError(w)
 		return
@@ -141,3 +224,41 @@ func (c *FoldersController) Delete(w http.ResponseWriter, req *http.Request) {
 
 	respond(w, http.StatusNoContent, nil)
 }
+
+// GetTree handles request for getting tree of folders, notepads
+// and notes.
+func (c *FoldersController) GetTree(w http.ResponseWriter, req *http.Request) {
+	userID := getUserID(req)
+	query := req.URL.Query()
+
+	f := storage.TreeFilter{UserID: &userID, Notes: query.Get("notes") == "true"}
+	if root := query.Get("root"); root != "" {
+		id, err := strconv.Atoi(root)
+		if err != nil {
+			badRequest(w, "Root must be an integer number")
+			return
+		}
+		f.RootID = &id
+	}
+	if depth := query.Get("depth"); depth != "" {
+		d, err := strconv.Atoi(depth)
+		if err != nil || d < 1 || d > domain.MaxTreeDepth {
+			badRequest(w, fmt.Sprintf("Depth must be an integer number from 1 to %d", domain.MaxTreeDepth))
+			return
+		}
+		f.Depth = d
+	}
+
+	tree, err := c.repo.GetTree(f)
+	if err == domain.ErrNotFound {
+		notFound(w)
+		return
+	}
+	if err != nil {
+		c.log.Errorf("Failed to get tree: %v", err)
+		internalServerError(w)
+		return
+	}
+
+	respond(w, http.StatusOK, tree)
+}
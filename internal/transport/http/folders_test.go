@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/pkg/errors"
@@ -77,6 +78,83 @@ func TestFoldersController(t *testing.T) {
 		}`)
 	})
 
+	t.Run("Get folders page", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		folders := []domain.Folder{
+			{ID: 15, UserID: user.ID, Title: "Folder B"},
+			{ID: 10, UserID: user.ID, Title: "Folder A"},
+		}
+		after := storage.NewCursor(storage.Sort{Field: storage.SortTitle, Desc: true}, 20, "Folder C", time.Time{}, nil)
+		next := storage.NewCursor(storage.Sort{Field: storage.SortTitle, Desc: true}, 10, "Folder A", time.Time{}, nil)
+		page := storage.Page{
+			Limit: 2,
+			Sort:  storage.Sort{Field: storage.SortTitle, Desc: true},
+			After: &after,
+		}
+
+		repoMock := storage.NewMockFoldersRepo(ctrl)
+		repoMock.EXPECT().Get(
+			storage.FoldersFilter{UserID: &user.ID, Page: &page},
+		).Return(folders, nil)
+
+		c := NewFoldersController(repoMock, log)
+
+		url := "/?limit=2&sort=-title&cursor=" + after.Encode()
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req = addUserID(req, user.ID)
+
+		c.GetList(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusOK)
+
+		body, err := ioutil.ReadAll(resp.Body)
+		assert.NoError(t, err)
+
+		err = resp.Body.Close()
+		assert.NoError(t, err)
+
+		assert.JSONEq(t, string(body), `{
+			"data": [
+				{
+					"id": 15,
+					"user_id": 1,
+					"parent_id": null,
+					"title": "Folder B"
+				},
+				{
+					"id": 10,
+					"user_id": 1,
+					"parent_id": null,
+					"title": "Folder A"
+				}
+			],
+			"next_cursor": "`+next.Encode()+`"
+		}`)
+	})
+
+	t.Run("Fail to get folders with invalid sort", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repoMock := storage.NewMockFoldersRepo(ctrl)
+
+		c := NewFoldersController(repoMock, log)
+
+		url := "/?sort=text"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req = addUserID(req, user.ID)
+
+		c.GetList(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusBadRequest)
+	})
+
 	t.Run("Fail to get folders", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
@@ -302,6 +380,36 @@ func TestFoldersController(t *testing.T) {
 		}`)
 	})
 
+	t.Run("Ignore deletion time on folder update", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		id := 10
+		folder := domain.Folder{ID: id, UserID: user.ID, ParentID: Int(30), Title: "Folder 10"}
+
+		repoMock := storage.NewMockFoldersRepo(ctrl)
+		repoMock.EXPECT().Update(folder).Return(folder, nil)
+
+		c := NewFoldersController(repoMock, log)
+
+		deleted := folder
+		deleted.DeletedAt = &time.Time{}
+		payload, err := json.Marshal(deleted)
+		assert.NoError(t, err)
+		assert.Contains(t, string(payload), "deleted_at")
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, url, bytes.NewBuffer(payload))
+		req = addUserID(req, user.ID)
+		req = addID(req, id)
+
+		c.Update(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusOK)
+	})
+
 	t.Run("Fail to update folder", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
@@ -353,6 +461,30 @@ func TestFoldersController(t *testing.T) {
 		assert.Equal(t, resp.StatusCode, http.StatusNoContent)
 	})
 
+	t.Run("Fail to delete folder with viewer role", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		id := 10
+		folder := domain.Folder{ID: id, UserID: user.ID}
+
+		repoMock := storage.NewMockFoldersRepo(ctrl)
+		repoMock.EXPECT().Delete(folder).Return(domain.ErrForbidden)
+
+		c := NewFoldersController(repoMock, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodDelete, url, nil)
+		req = addUserID(req, user.ID)
+		req = addID(req, id)
+
+		c.Delete(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusForbidden)
+	})
+
 	t.Run("Fail to delete folder", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
@@ -376,4 +508,189 @@ func TestFoldersController(t *testing.T) {
 		resp := w.Result()
 		assert.Equal(t, resp.StatusCode, http.StatusInternalServerError)
 	})
+
+	t.Run("Get tree", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		root := 10
+		tree := []domain.FolderNode{
+			{
+				ID:            root,
+				Title:         "Folder 10",
+				NotepadsCount: 1,
+				Folders:       []domain.FolderNode{},
+				Notepads: []domain.NotepadNode{
+					{
+						ID:         20,
+						FolderID:   root,
+						Title:      "Notepad 20",
+						NotesCount: 1,
+						Notes:      []domain.NoteNode{{ID: 30, NotepadID: 20, Title: "Note 30"}},
+					},
+				},
+			},
+		}
+
+		repoMock := storage.NewMockFoldersRepo(ctrl)
+		repoMock.EXPECT().GetTree(
+			storage.TreeFilter{UserID: &user.ID, RootID: &root, Depth: 2, Notes: true},
+		).Return(tree, nil)
+
+		c := NewFoldersController(repoMock, log)
+
+		url := "/?root=10&depth=2&notes=true"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req = addUserID(req, user.ID)
+
+		c.GetTree(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusOK)
+
+		body, err := ioutil.ReadAll(resp.Body)
+		assert.NoError(t, err)
+
+		err = resp.Body.Close()
+		assert.NoError(t, err)
+
+		assert.JSONEq(t, string(body), `{
+			"data": [
+				{
+					"id": 10,
+					"parent_id": null,
+					"title": "Folder 10",
+					"folders_count": 0,
+					"notepads_count": 1,
+					"folders": [],
+					"notepads": [
+						{
+							"id": 20,
+							"folder_id": 10,
+							"title": "Notepad 20",
+							"notes_count": 1,
+							"notes": [
+								{
+									"id": 30,
+									"notepad_id": 20,
+									"title": "Note 30"
+								}
+							]
+						}
+					]
+				}
+			]
+		}`)
+	})
+
+	t.Run("Fail to get tree from non-existing folder", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		root := 10
+
+		repoMock := storage.NewMockFoldersRepo(ctrl)
+		repoMock.EXPECT().GetTree(
+			storage.TreeFilter{UserID: &user.ID, RootID: &root},
+		).Return(nil, domain.ErrNotFound)
+
+		c := NewFoldersController(repoMock, log)
+
+		url := "/?root=10"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req = addUserID(req, user.ID)
+
+		c.GetTree(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusNotFound)
+	})
+
+	t.Run("Fail to get tree with invalid depth", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repoMock := storage.NewMockFoldersRepo(ctrl)
+
+		c := NewFoldersController(repoMock, log)
+
+		for _, url := range []string{"/?depth=0", "/?depth=101"} {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, url, nil)
+			req = addUserID(req, user.ID)
+
+			c.GetTree(w, req)
+
+			resp := w.Result()
+			assert.Equal(t, resp.StatusCode, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("Move folder", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		id, parentID := 10, 20
+		folder := domain.Folder{ID: id, UserID: user.ID, ParentID: &parentID, Title: "Folder 10"}
+
+		repoMock := storage.NewMockFoldersRepo(ctrl)
+		repoMock.EXPECT().
+			Move(domain.Folder{ID: id, UserID: user.ID, ParentID: &parentID}).
+			Return(folder, nil)
+
+		c := NewFoldersController(repoMock, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, url, bytes.NewBufferString(`{"parent_id":20}`))
+		req = addUserID(req, user.ID)
+		req = addID(req, id)
+
+		c.Move(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusOK)
+
+		body, err := ioutil.ReadAll(resp.Body)
+		assert.NoError(t, err)
+
+		err = resp.Body.Close()
+		assert.NoError(t, err)
+
+		assert.JSONEq(t, string(body), `{
+			"data": {
+				"id": 10,
+				"user_id": 1,
+				"parent_id": 20,
+				"title": "Folder 10"
+			}
+		}`)
+	})
+
+	t.Run("Fail to move folder into its subfolder", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		id, parentID := 10, 20
+
+		repoMock := storage.NewMockFoldersRepo(ctrl)
+		repoMock.EXPECT().
+			Move(domain.Folder{ID: id, UserID: user.ID, ParentID: &parentID}).
+			Return(domain.Folder{}, domain.ErrCycle)
+
+		c := NewFoldersController(repoMock, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, url, bytes.NewBufferString(`{"parent_id":20}`))
+		req = addUserID(req, user.ID)
+		req = addID(req, id)
+
+		c.Move(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusBadRequest)
+	})
 }
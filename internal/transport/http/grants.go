@@ -0,0 +1,185 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/tetafro/nott-backend-go/internal/domain"
+	"github.com/tetafro/nott-backend-go/internal/storage"
+)
+
+// GrantsController handles HTTP API requests.
+type GrantsController struct {
+	repo storage.GrantsRepo
+	log  logrus.FieldLogger
+}
+
+// NewGrantsController creates new controller.
+func NewGrantsController(repo storage.GrantsRepo, log logrus.FieldLogger) *GrantsController {
+	return &GrantsController{repo: repo, log: log}
+}
+
+// GetList handles request for getting grants of objects that
+// the user can share, optionally filtered by folder or notepad.
+func (c *GrantsController) GetList(w http.ResponseWriter, req *http.Request) {
+	userID := getUserID(req)
+
+	folderID := req.URL.Query().Get("folder_id")
+	notepadID := req.URL.Query().Get("notepad_id")
+
+	f := storage.GrantsFilter{ManagerID: &userID}
+
+	if folderID != "" {
+		fid, err := strconv.Atoi(folderID)
+		if err != nil {
+			badRequest(w, "Folder ID must be an integer number")
+			return
+		}
+		f.FolderID = &fid
+	}
+	if notepadID != "" {
+		nid, err := strconv.Atoi(notepadID)
+		if err != nil {
+			badRequest(w, "Notepad ID must be an integer number")
+			return
+		}
+		f.NotepadID = &nid
+	}
+
+	grants, err := c.repo.Get(f)
+	if err != nil {
+		c.log.Errorf("Failed to get grants: %v", err)
+		internalServerError(w)
+		return
+	}
+
+	respond(w, http.StatusOK, grants)
+}
+
+// Create handles request for inviting another user to a folder
+// or a notepad.
+func (c *GrantsController) Create(w http.ResponseWriter, req *http.Request) {
+	userID := getUserID(req)
+
+	var body struct {
+		FolderID  *int   `json:"folder_id"`
+		NotepadID *int   `json:"notepad_id"`
+		Email     string `json:"email"`
+		Role      string `json:"role"`
+	}
+	err := json.NewDecoder(req.Body).Decode(&body)
+	if err != nil {
+		badRequest(w, "invalid json")
+		return
+	}
+	if body.Email == "" {
+		badRequest(w, "invalid grant: email cannot be empty")
+		return
+	}
+
+	g := domain.Grant{
+		FolderID:  body.FolderID,
+		NotepadID: body.NotepadID,
+		InvitedBy: userID,
+	}
+	if err = g.Role.UnmarshalText([]byte(body.Role)); err != nil {
+		badRequest(w, "invalid grant: "+err.Error())
+		return
+	}
+	if err = g.Validate(); err != nil {
+		badRequest(w, "invalid grant: "+err.Error())
+		return
+	}
+
+	g, err = c.repo.Invite(g, body.Email)
+	if err == domain.ErrNotFound {
+		notFound(w)
+		return
+	}
+	if err == domain.ErrForbidden {
+		forbidden(w)
+		return
+	}
+	if err == domain.ErrUserNotFound || err == domain.ErrGrantToOwner {
+		badRequest(w, err.Error())
+		return
+	}
+	if err != nil {
+		c.log.Errorf("Failed to create grant: %v", err)
+		internalServerError(w)
+		return
+	}
+
+	respond(w, http.StatusCreated, g)
+}
+
+// Delete handles request for deleting grant. It's used both to revoke
+// access of another user, and to decline invitation or leave a shared
+// folder or notepad.
+func (c *GrantsController) Delete(w http.ResponseWriter, req *http.Request) {
+	userID := getUserID(req)
+	id, err := getID(req)
+	if err != nil {
+		notFound(w)
+		return
+	}
+
+	if err = c.repo.Delete(id, userID); err != nil {
+		c.log.Errorf("Failed to delete grant: %v", err)
+		internalServerError(w)
+		return
+	}
+
+	respond(w, http.StatusNoContent, nil)
+}
+
+// GetInvites handles request for getting invitations of the user
+// that are not accepted yet.
+func (c *GrantsController) GetInvites(w http.ResponseWriter, req *http.Request) {
+	c.getGranted(w, req, false)
+}
+
+// GetShared handles request for getting folders and notepads
+// of other users that are shared with the user.
+func (c *GrantsController) GetShared(w http.ResponseWriter, req *http.Request) {
+	c.getGranted(w, req, true)
+}
+
+func (c *GrantsController) getGranted(w http.ResponseWriter, req *http.Request, accepted bool) {
+	userID := getUserID(req)
+
+	grants, err := c.repo.Get(storage.GrantsFilter{UserID: &userID, Accepted: &accepted})
+	if err != nil {
+		c.log.Errorf("Failed to get grants: %v", err)
+		internalServerError(w)
+		return
+	}
+
+	respond(w, http.StatusOK, grants)
+}
+
+// Accept handles request for accepting invitation.
+func (c *GrantsController) Accept(w http.ResponseWriter, req *http.Request) {
+	userID := getUserID(req)
+	id, err := getID(req)
+	if err != nil {
+		notFound(w)
+		return
+	}
+
+	g, err := c.repo.Accept(id, userID)
+	if err == domain.ErrNotFound {
+		notFound(w)
+		return
+	}
+	if err != nil {
+		c.log.Errorf("Failed to accept grant: %v", err)
+		internalServerError(w)
+		return
+	}
+
+	respond(w, http.StatusOK, g)
+}
@@ -0,0 +1,371 @@
+package httpapi
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tetafro/nott-backend-go/internal/auth"
+	"github.com/tetafro/nott-backend-go/internal/domain"
+	"github.com/tetafro/nott-backend-go/internal/storage"
+)
+
+func TestGrantsController(t *testing.T) {
+	log := logrus.New()
+	log.Out = ioutil.Discard
+
+	user := auth.User{ID: 1}
+	created := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run("Get grants of folder", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		folderID := 20
+		grants := []domain.Grant{{
+			ID:        10,
+			UserID:    2,
+			FolderID:  &folderID,
+			Role:      domain.RoleEditor,
+			InvitedBy: user.ID,
+			Title:     "Work",
+			CreatedAt: created,
+		}}
+
+		repoMock := storage.NewMockGrantsRepo(ctrl)
+		repoMock.EXPECT().
+			Get(storage.GrantsFilter{ManagerID: &user.ID, FolderID: &folderID}).
+			Return(grants, nil)
+
+		c := NewGrantsController(repoMock, log)
+
+		url := "/?folder_id=20"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req = addUserID(req, user.ID)
+
+		c.GetList(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusOK)
+
+		body, err := ioutil.ReadAll(resp.Body)
+		assert.NoError(t, err)
+
+		err = resp.Body.Close()
+		assert.NoError(t, err)
+
+		assert.JSONEq(t, string(body), `{
+			"data": [
+				{
+					"id": 10,
+					"user_id": 2,
+					"folder_id": 20,
+					"role": "editor",
+					"invited_by": 1,
+					"title": "Work",
+					"accepted_at": null,
+					"created_at": "2020-01-02T03:04:05Z"
+				}
+			]
+		}`)
+	})
+
+	t.Run("Invite user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		notepadID := 30
+		grant := domain.Grant{NotepadID: &notepadID, Role: domain.RoleViewer, InvitedBy: user.ID}
+
+		repoMock := storage.NewMockGrantsRepo(ctrl)
+		repoMock.EXPECT().
+			Invite(grant, "bob@example.com").
+			DoAndReturn(func(g domain.Grant, email string) (domain.Grant, error) {
+				g.ID = 10
+				g.UserID = 2
+				g.Title = "Recipes"
+				g.CreatedAt = created
+				return g, nil
+			})
+
+		c := NewGrantsController(repoMock, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		data := `{"notepad_id":30,"email":"bob@example.com","role":"viewer"}`
+		req := httptest.NewRequest(http.MethodPost, url, bytes.NewBufferString(data))
+		req = addUserID(req, user.ID)
+
+		c.Create(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusCreated)
+
+		body, err := ioutil.ReadAll(resp.Body)
+		assert.NoError(t, err)
+
+		err = resp.Body.Close()
+		assert.NoError(t, err)
+
+		assert.JSONEq(t, string(body), `{
+			"data": {
+				"id": 10,
+				"user_id": 2,
+				"notepad_id": 30,
+				"role": "viewer",
+				"invited_by": 1,
+				"title": "Recipes",
+				"accepted_at": null,
+				"created_at": "2020-01-02T03:04:05Z"
+			}
+		}`)
+	})
+
+	t.Run("Fail to invite with invalid request", func(t *testing.T) {
+		cases := map[string]string{
+			"unknown role":  `{"folder_id":20,"email":"bob@example.com","role":"admin"}`,
+			"no email":      `{"folder_id":20,"role":"viewer"}`,
+			"no object":     `{"email":"bob@example.com","role":"viewer"}`,
+			"two objects":   `{"folder_id":20,"notepad_id":30,"email":"bob@example.com","role":"viewer"}`,
+			"invalid json":  `{"folder_id":`,
+			"role is empty": `{"folder_id":20,"email":"bob@example.com"}`,
+		}
+		for name, data := range cases {
+			t.Run(name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				repoMock := storage.NewMockGrantsRepo(ctrl)
+
+				c := NewGrantsController(repoMock, log)
+
+				url := "/"
+				w := httptest.NewRecorder()
+				req := httptest.NewRequest(http.MethodPost, url, bytes.NewBufferString(data))
+				req = addUserID(req, user.ID)
+
+				c.Create(w, req)
+
+				resp := w.Result()
+				assert.Equal(t, resp.StatusCode, http.StatusBadRequest)
+			})
+		}
+	})
+
+	t.Run("Fail to invite", func(t *testing.T) {
+		cases := map[error]int{
+			domain.ErrNotFound:     http.StatusNotFound,
+			domain.ErrForbidden:    http.StatusForbidden,
+			domain.ErrUserNotFound: http.StatusBadRequest,
+			domain.ErrGrantToOwner: http.StatusBadRequest,
+		}
+		for repoErr, code := range cases {
+			t.Run(repoErr.Error(), func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				repoMock := storage.NewMockGrantsRepo(ctrl)
+				repoMock.EXPECT().Invite(gomock.Any(), "bob@example.com").Return(domain.Grant{}, repoErr)
+
+				c := NewGrantsController(repoMock, log)
+
+				url := "/"
+				w := httptest.NewRecorder()
+				data := `{"folder_id":20,"email":"bob@example.com","role":"editor"}`
+				req := httptest.NewRequest(http.MethodPost, url, bytes.NewBufferString(data))
+				req = addUserID(req, user.ID)
+
+				c.Create(w, req)
+
+				resp := w.Result()
+				assert.Equal(t, resp.StatusCode, code)
+			})
+		}
+	})
+
+	t.Run("Get invites", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		accepted := false
+
+		repoMock := storage.NewMockGrantsRepo(ctrl)
+		repoMock.EXPECT().
+			Get(storage.GrantsFilter{UserID: &user.ID, Accepted: &accepted}).
+			Return([]domain.Grant{}, nil)
+
+		c := NewGrantsController(repoMock, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req = addUserID(req, user.ID)
+
+		c.GetInvites(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusOK)
+	})
+
+	t.Run("Accept invite", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		id, folderID := 10, 20
+		grant := domain.Grant{
+			ID:         id,
+			UserID:     user.ID,
+			FolderID:   &folderID,
+			Role:       domain.RoleEditor,
+			InvitedBy:  2,
+			Title:      "Work",
+			AcceptedAt: &created,
+			CreatedAt:  created,
+		}
+
+		repoMock := storage.NewMockGrantsRepo(ctrl)
+		repoMock.EXPECT().Accept(id, user.ID).Return(grant, nil)
+
+		c := NewGrantsController(repoMock, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, url, nil)
+		req = addUserID(req, user.ID)
+		req = addID(req, id)
+
+		c.Accept(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusOK)
+
+		body, err := ioutil.ReadAll(resp.Body)
+		assert.NoError(t, err)
+
+		err = resp.Body.Close()
+		assert.NoError(t, err)
+
+		assert.JSONEq(t, string(body), `{
+			"data": {
+				"id": 10,
+				"user_id": 1,
+				"folder_id": 20,
+				"role": "editor",
+				"invited_by": 2,
+				"title": "Work",
+				"accepted_at": "2020-01-02T03:04:05Z",
+				"created_at": "2020-01-02T03:04:05Z"
+			}
+		}`)
+	})
+
+	t.Run("Fail to accept non-existing invite", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		id := 10
+
+		repoMock := storage.NewMockGrantsRepo(ctrl)
+		repoMock.EXPECT().Accept(id, user.ID).Return(domain.Grant{}, domain.ErrNotFound)
+
+		c := NewGrantsController(repoMock, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, url, nil)
+		req = addUserID(req, user.ID)
+		req = addID(req, id)
+
+		c.Accept(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusNotFound)
+	})
+
+	t.Run("Get shared with me", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		accepted := true
+		folderID := 20
+		grants := []domain.Grant{{
+			ID:         10,
+			UserID:     user.ID,
+			FolderID:   &folderID,
+			Role:       domain.RoleViewer,
+			InvitedBy:  2,
+			Title:      "Work",
+			AcceptedAt: &created,
+			CreatedAt:  created,
+		}}
+
+		repoMock := storage.NewMockGrantsRepo(ctrl)
+		repoMock.EXPECT().
+			Get(storage.GrantsFilter{UserID: &user.ID, Accepted: &accepted}).
+			Return(grants, nil)
+
+		c := NewGrantsController(repoMock, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req = addUserID(req, user.ID)
+
+		c.GetShared(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusOK)
+
+		body, err := ioutil.ReadAll(resp.Body)
+		assert.NoError(t, err)
+
+		err = resp.Body.Close()
+		assert.NoError(t, err)
+
+		assert.JSONEq(t, string(body), `{
+			"data": [
+				{
+					"id": 10,
+					"user_id": 1,
+					"folder_id": 20,
+					"role": "viewer",
+					"invited_by": 2,
+					"title": "Work",
+					"accepted_at": "2020-01-02T03:04:05Z",
+					"created_at": "2020-01-02T03:04:05Z"
+				}
+			]
+		}`)
+	})
+
+	t.Run("Delete grant", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		id := 10
+
+		repoMock := storage.NewMockGrantsRepo(ctrl)
+		repoMock.EXPECT().Delete(id, user.ID).Return(nil)
+
+		c := NewGrantsController(repoMock, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodDelete, url, nil)
+		req = addUserID(req, user.ID)
+		req = addID(req, id)
+
+		c.Delete(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusNoContent)
+	})
+}
@@ -0,0 +1,149 @@
+package httpapi
+
+import (
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/tetafro/nott-backend-go/internal/domain"
+	"github.com/tetafro/nott-backend-go/internal/storage"
+)
+
+// ImportsController handles HTTP API requests.
+type ImportsController struct {
+	repo    storage.ImportsRepo
+	blobs   storage.BlobStore
+	maxSize int64
+	log     logrus.FieldLogger
+}
+
+// NewImportsController creates new controller. Max size is a maximum
+// size of an imported file in bytes.
+func NewImportsController(
+	repo storage.ImportsRepo,
+	blobs storage.BlobStore,
+	maxSize int64,
+	log logrus.FieldLogger,
+) *ImportsController {
+	return &ImportsController{repo: repo, blobs: blobs, maxSize: maxSize, log: log}
+}
+
+// Create handles request for importing notes from a file. The file
+// is saved to the blob store and imported in background, the status
+// of the import is available by its ID. Format of the file is taken
+// from the query, Evernote exports are also recognized by extension,
+// and zip archives of markdown files are the default.
+func (c *ImportsController) Create(w http.ResponseWriter, req *http.Request) {
+	userID := getUserID(req)
+
+	req.Body = http.MaxBytesReader(w, req.Body, c.maxSize+multipartOverhead)
+	mr, err := req.MultipartReader()
+	if err != nil {
+		badRequest(w, "multipart form expected")
+		return
+	}
+	var part io.Reader
+	var name string
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			badRequest(w, "invalid multipart form")
+			return
+		}
+		if p.FormName() == "file" {
+			part, name = p, filepath.Base(p.FileName())
+			break
+		}
+	}
+	if part == nil {
+		badRequest(w, "file is missing")
+		return
+	}
+	if name == "." || name == string(filepath.Separator) {
+		name = ""
+	}
+
+	format := req.URL.Query().Get("format")
+	if format == "" {
+		format = domain.ImportMarkdown
+		if strings.EqualFold(filepath.Ext(name), ".enex") {
+			format = domain.ImportENEX
+		}
+	}
+
+	key, err := storage.NewBlobKey(userID)
+	if err != nil {
+		c.log.Errorf("Failed to make blob key: %v", err)
+		internalServerError(w)
+		return
+	}
+	i := domain.Import{
+		UserID: userID,
+		Format: format,
+		Name:   name,
+		Key:    key,
+		Status: domain.ImportPending,
+	}
+	if err = i.Validate(); err != nil {
+		badRequest(w, "invalid import: "+err.Error())
+		return
+	}
+
+	body := &countingReader{r: io.LimitReader(part, c.maxSize+1)}
+	if err = c.blobs.Put(key, body); err != nil {
+		c.log.Errorf("Failed to save file: %v", err)
+		c.deleteBlob(key)
+		internalServerError(w)
+		return
+	}
+	if body.n > c.maxSize {
+		c.deleteBlob(key)
+		respond(w, http.StatusRequestEntityTooLarge, "file is too large")
+		return
+	}
+
+	i, err = c.repo.Create(i)
+	if err != nil {
+		c.log.Errorf("Failed to create import: %v", err)
+		c.deleteBlob(key)
+		internalServerError(w)
+		return
+	}
+
+	respond(w, http.StatusAccepted, i)
+}
+
+// GetOne handles request for getting status of import.
+func (c *ImportsController) GetOne(w http.ResponseWriter, req *http.Request) {
+	userID := getUserID(req)
+	id, err := getID(req)
+	if err != nil {
+		notFound(w)
+		return
+	}
+
+	imports, err := c.repo.Get(storage.ImportsFilter{ID: &id, UserID: &userID})
+	if err != nil {
+		c.log.Errorf("Failed to get import: %v", err)
+		internalServerError(w)
+		return
+	}
+	if len(imports) == 0 {
+		notFound(w)
+		return
+	}
+
+	respond(w, http.StatusOK, imports[0])
+}
+
+func (c *ImportsController) deleteBlob(key string) {
+	if err := c.blobs.Delete(key); err != nil {
+		c.log.Errorf("Failed to delete file: %v", err)
+	}
+}
@@ -0,0 +1,219 @@
+package httpapi
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tetafro/nott-backend-go/internal/auth"
+	"github.com/tetafro/nott-backend-go/internal/domain"
+	"github.com/tetafro/nott-backend-go/internal/storage"
+)
+
+func TestImportsController(t *testing.T) {
+	log := logrus.New()
+	log.Out = ioutil.Discard
+
+	user := auth.User{ID: 1}
+
+	// upload makes multipart form with a file
+	upload := func(name, content string) (*bytes.Buffer, string) {
+		body := &bytes.Buffer{}
+		mw := multipart.NewWriter(body)
+		fw, err := mw.CreateFormFile("file", name)
+		assert.NoError(t, err)
+		_, err = fw.Write([]byte(content))
+		assert.NoError(t, err)
+		assert.NoError(t, mw.Close())
+		return body, mw.FormDataContentType()
+	}
+
+	t.Run("Create import", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var key string
+
+		blobsMock := storage.NewMockBlobStore(ctrl)
+		blobsMock.EXPECT().
+			Put(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(k string, r io.Reader) error {
+				key = k
+				data, err := ioutil.ReadAll(r)
+				assert.NoError(t, err)
+				assert.Equal(t, "<en-export/>", string(data))
+				return nil
+			})
+		repoMock := storage.NewMockImportsRepo(ctrl)
+		repoMock.EXPECT().
+			Create(gomock.Any()).
+			DoAndReturn(func(i domain.Import) (domain.Import, error) {
+				assert.Equal(t, domain.Import{
+					UserID: user.ID,
+					Format: domain.ImportENEX,
+					Name:   "Notebook.enex",
+					Key:    key,
+					Status: domain.ImportPending,
+				}, i)
+				i.ID = 10
+				return i, nil
+			})
+
+		c := NewImportsController(repoMock, blobsMock, 100, log)
+
+		body, contentType := upload("Notebook.enex", "<en-export/>")
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/", body)
+		req.Header.Set("Content-Type", contentType)
+		req = addUserID(req, user.ID)
+
+		c.Create(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+		data, err := ioutil.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		assert.NoError(t, resp.Body.Close())
+		assert.Contains(t, string(data), `"id":10`)
+		assert.Contains(t, string(data), `"status":"pending"`)
+		assert.NotContains(t, string(data), key)
+	})
+
+	t.Run("Fail to create import with unknown format", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		c := NewImportsController(
+			storage.NewMockImportsRepo(ctrl),
+			storage.NewMockBlobStore(ctrl),
+			100,
+			log,
+		)
+
+		body, contentType := upload("notes.zip", "zip")
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/?format=docx", body)
+		req.Header.Set("Content-Type", contentType)
+		req = addUserID(req, user.ID)
+
+		c.Create(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("Fail to create import of too large file", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		blobsMock := storage.NewMockBlobStore(ctrl)
+		blobsMock.EXPECT().
+			Put(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(k string, r io.Reader) error {
+				_, err := ioutil.ReadAll(r)
+				return err
+			})
+		blobsMock.EXPECT().Delete(gomock.Any()).Return(nil)
+
+		c := NewImportsController(storage.NewMockImportsRepo(ctrl), blobsMock, 10, log)
+
+		body, contentType := upload("notes.zip", "more than ten bytes")
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/?format=joplin", body)
+		req.Header.Set("Content-Type", contentType)
+		req = addUserID(req, user.ID)
+
+		c.Create(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+	})
+
+	t.Run("Get import", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		id := 10
+		repoMock := storage.NewMockImportsRepo(ctrl)
+		repoMock.EXPECT().
+			Get(storage.ImportsFilter{ID: &id, UserID: &user.ID}).
+			Return([]domain.Import{{
+				ID:     id,
+				UserID: user.ID,
+				Status: domain.ImportDone,
+				Notes:  2,
+				Errors: []domain.ImportError{{Item: "a.png", Error: "not a markdown file"}},
+			}}, nil)
+
+		c := NewImportsController(repoMock, storage.NewMockBlobStore(ctrl), 100, log)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req = addUserID(req, user.ID)
+		req = addID(req, id)
+
+		c.GetOne(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		data, err := ioutil.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		assert.NoError(t, resp.Body.Close())
+		assert.Contains(t, string(data), `"notes":2`)
+		assert.Contains(t, string(data), `"errors":[{"item":"a.png","error":"not a markdown file"}]`)
+	})
+
+	t.Run("Fail to get import of another user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		id := 10
+		repoMock := storage.NewMockImportsRepo(ctrl)
+		repoMock.EXPECT().
+			Get(storage.ImportsFilter{ID: &id, UserID: &user.ID}).
+			Return([]domain.Import{}, nil)
+
+		c := NewImportsController(repoMock, storage.NewMockBlobStore(ctrl), 100, log)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req = addUserID(req, user.ID)
+		req = addID(req, id)
+
+		c.GetOne(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("Fail to get import", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repoMock := storage.NewMockImportsRepo(ctrl)
+		repoMock.EXPECT().Get(gomock.Any()).Return(nil, errors.New("error"))
+
+		c := NewImportsController(repoMock, storage.NewMockBlobStore(ctrl), 100, log)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req = addUserID(req, user.ID)
+		req = addID(req, 10)
+
+		c.GetOne(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	})
+}
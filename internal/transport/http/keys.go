@@ -0,0 +1,36 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/tetafro/nott-backend-go/internal/auth"
+)
+
+// keysCacheControl allows caching public keys for a short time, so new
+// keys are picked up by clients soon after rotation.
+const keysCacheControl = "public, max-age=300"
+
+// KeysController handles HTTP API requests.
+type KeysController struct {
+	keys *auth.Keyring
+	log  logrus.FieldLogger
+}
+
+// NewKeysController creates new controller.
+func NewKeysController(keys *auth.Keyring, log logrus.FieldLogger) *KeysController {
+	return &KeysController{keys: keys, log: log}
+}
+
+// GetJWKS handles request for getting public keys, that verify access
+// tokens, in JWK Set format. The set is not wrapped in data field,
+// because clients expect it as is.
+func (c *KeysController) GetJWKS(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", keysCacheControl)
+	if err := json.NewEncoder(w).Encode(c.keys.JWKS()); err != nil {
+		c.log.Errorf("Failed to write response: %v", err)
+	}
+}
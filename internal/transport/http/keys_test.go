@@ -0,0 +1,31 @@
+package httpapi
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tetafro/nott-backend-go/internal/auth"
+)
+
+func TestKeysController(t *testing.T) {
+	log := logrus.New()
+	log.Out = ioutil.Discard
+
+	t.Run("Get public keys", func(t *testing.T) {
+		c := NewKeysController(auth.NewHMACKeyring("qwerty"), log)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+		c.GetJWKS(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, keysCacheControl, w.Header().Get("Cache-Control"))
+		assert.JSONEq(t, `{"keys":[]}`, w.Body.String())
+	})
+}
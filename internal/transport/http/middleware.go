@@ -4,17 +4,33 @@ import (
 	"context"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
 	"github.com/tetafro/nott-backend-go/internal/auth"
+	"github.com/tetafro/nott-backend-go/internal/storage"
 )
 
 // userIDKey is a key for user id value inside request context.
 type userIDKey struct{}
 
-// NewAuthMiddleware creates middleware that authenticates users.
-func NewAuthMiddleware(tokener auth.Tokener, log logrus.FieldLogger) func(http.Handler) http.Handler {
+// sessionIDKey is a key for session id value inside request context.
+type sessionIDKey struct{}
+
+// scopesKey is a key for scopes of personal access token inside
+// request context.
+type scopesKey struct{}
+
+// NewAuthMiddleware creates middleware that authenticates users
+// by access tokens of sessions or by personal access tokens. Revoked
+// and expired tokens are rejected.
+func NewAuthMiddleware(
+	tokener auth.Tokener,
+	personalTokens storage.PersonalTokensRepo,
+	denylist auth.Denylist,
+	log logrus.FieldLogger,
+) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 			token := getToken(req)
@@ -22,12 +38,62 @@ func NewAuthMiddleware(tokener auth.Tokener, log logrus.FieldLogger) func(http.H
 				unauthorized(w)
 				return
 			}
-			id, err := tokener.Parse(token)
+
+			if strings.HasPrefix(token, auth.PersonalTokenPrefix) {
+				hash := auth.HashToken(token)
+				tt, err := personalTokens.Get(storage.PersonalTokensFilter{Hash: &hash})
+				if err != nil {
+					log.Errorf("Failed to get personal token: %v", err)
+					internalServerError(w)
+					return
+				}
+				if len(tt) == 0 || tt[0].Expired(time.Now()) {
+					unauthorized(w)
+					return
+				}
+				req = addUserID(req, tt[0].UserID)
+				req = addScopes(req, tt[0].Scopes)
+				next.ServeHTTP(w, req)
+				return
+			}
+
+			claims, err := tokener.Parse(token)
 			if err != nil {
 				unauthorized(w)
 				return
 			}
-			req = addUserID(req, id)
+			// Either the token itself or its whole session can be revoked
+			for _, key := range []string{claims.ID, auth.SessionKey(claims.SessionID)} {
+				revoked, err := denylist.Revoked(key)
+				if err != nil {
+					log.Errorf("Failed to check token: %v", err)
+					internalServerError(w)
+					return
+				}
+				if revoked {
+					unauthorized(w)
+					return
+				}
+			}
+			req = addUserID(req, claims.UserID)
+			req = addSessionID(req, claims.SessionID)
+			next.ServeHTTP(w, req)
+		})
+	}
+}
+
+// NewScopesMiddleware creates middleware that allows requests with
+// personal access tokens only if the tokens have all the scopes.
+// Routes without scopes are available only to sessions, that have
+// full access.
+func NewScopesMiddleware(scopes ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			granted, ok := getScopes(req)
+			if ok && (len(scopes) == 0 || !granted.Has(scopes...)) {
+				forbidden(w)
+				return
+			}
 			next.ServeHTTP(w, req)
 		})
 	}
@@ -53,6 +119,35 @@ func getUserID(req *http.Request) int {
 	return id
 }
 
+// addSessionID adds session id to request context.
+func addSessionID(req *http.Request, id int) *http.Request {
+	ctx := req.Context()
+	ctx = context.WithValue(ctx, sessionIDKey{}, id)
+	return req.WithContext(ctx)
+}
+
+// getSessionID extracts session id from request context, it's 0
+// if the request is not authenticated with a session.
+func getSessionID(req *http.Request) int {
+	id, _ := req.Context().Value(sessionIDKey{}).(int)
+	return id
+}
+
+// addScopes adds scopes of personal access token to request context.
+func addScopes(req *http.Request, scopes auth.Scopes) *http.Request {
+	ctx := req.Context()
+	ctx = context.WithValue(ctx, scopesKey{}, scopes)
+	return req.WithContext(ctx)
+}
+
+// getScopes extracts scopes of personal access token from request
+// context, ok is false if the request is not authenticated with
+// a personal access token.
+func getScopes(req *http.Request) (scopes auth.Scopes, ok bool) {
+	scopes, ok = req.Context().Value(scopesKey{}).(auth.Scopes)
+	return scopes, ok
+}
+
 // getToken gets token from HTTP header.
 // Header format (RFC2617):
 // Authorization: Token token="abcd1234"
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/pkg/errors"
@@ -12,6 +13,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/tetafro/nott-backend-go/internal/auth"
+	"github.com/tetafro/nott-backend-go/internal/storage"
 )
 
 func TestAddUser(t *testing.T) {
@@ -45,13 +47,18 @@ func TestAuthMiddleware(t *testing.T) {
 		defer ctrl.Finish()
 
 		tokenerMock := auth.NewMockTokener(ctrl)
-		tokenerMock.EXPECT().Parse("qwerty").Return(user.ID, nil)
+		tokenerMock.EXPECT().Parse("qwerty").Return(auth.Claims{UserID: user.ID, SessionID: 5, ID: "abc"}, nil)
 
-		mw := NewAuthMiddleware(tokenerMock, log)
+		denylistMock := auth.NewMockDenylist(ctrl)
+		denylistMock.EXPECT().Revoked("abc").Return(false, nil)
+		denylistMock.EXPECT().Revoked("session:5").Return(false, nil)
+
+		mw := NewAuthMiddleware(tokenerMock, nil, denylistMock, log)
 
 		h := func(w http.ResponseWriter, r *http.Request) {
-			// Check user in request context
+			// Check user and session in request context
 			assert.Equal(t, user.ID, getUserID(r))
+			assert.Equal(t, 5, getSessionID(r))
 
 			w.Write([]byte("ok")) // nolint
 		}
@@ -74,7 +81,7 @@ func TestAuthMiddleware(t *testing.T) {
 
 		tokenerMock := auth.NewMockTokener(ctrl)
 
-		mw := NewAuthMiddleware(tokenerMock, log)
+		mw := NewAuthMiddleware(tokenerMock, nil, nil, log)
 
 		h := func(w http.ResponseWriter, r *http.Request) {
 			// Won't get here
@@ -97,7 +104,7 @@ func TestAuthMiddleware(t *testing.T) {
 
 		tokenerMock := auth.NewMockTokener(ctrl)
 
-		mw := NewAuthMiddleware(tokenerMock, log)
+		mw := NewAuthMiddleware(tokenerMock, nil, nil, log)
 
 		h := func(w http.ResponseWriter, r *http.Request) {
 			// Won't get here
@@ -121,9 +128,9 @@ func TestAuthMiddleware(t *testing.T) {
 		defer ctrl.Finish()
 
 		tokenerMock := auth.NewMockTokener(ctrl)
-		tokenerMock.EXPECT().Parse("wrong-token").Return(0, errors.New("error"))
+		tokenerMock.EXPECT().Parse("wrong-token").Return(auth.Claims{}, errors.New("error"))
 
-		mw := NewAuthMiddleware(tokenerMock, log)
+		mw := NewAuthMiddleware(tokenerMock, nil, nil, log)
 
 		h := func(w http.ResponseWriter, r *http.Request) {
 			// Won't get here
@@ -141,4 +148,206 @@ func TestAuthMiddleware(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
 	})
+
+	t.Run("Fail to authorize user with revoked token", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		tokenerMock := auth.NewMockTokener(ctrl)
+		tokenerMock.EXPECT().Parse("qwerty").Return(auth.Claims{UserID: user.ID, SessionID: 5, ID: "abc"}, nil)
+
+		denylistMock := auth.NewMockDenylist(ctrl)
+		denylistMock.EXPECT().Revoked("abc").Return(true, nil)
+
+		mw := NewAuthMiddleware(tokenerMock, nil, denylistMock, log)
+
+		h := func(w http.ResponseWriter, r *http.Request) {
+			// Won't get here
+			w.Write([]byte("ok")) // nolint
+		}
+		ts := httptest.NewServer(mw(http.HandlerFunc(h)))
+		defer ts.Close()
+
+		req, err := http.NewRequest(http.MethodGet, ts.URL, nil)
+		assert.NoError(t, err)
+
+		req.Header.Add("Authorization", `Token token="qwerty"`)
+
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("Fail to authorize user with ended session", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		tokenerMock := auth.NewMockTokener(ctrl)
+		tokenerMock.EXPECT().Parse("qwerty").Return(auth.Claims{UserID: user.ID, SessionID: 5, ID: "abc"}, nil)
+
+		denylistMock := auth.NewMockDenylist(ctrl)
+		denylistMock.EXPECT().Revoked("abc").Return(false, nil)
+		denylistMock.EXPECT().Revoked("session:5").Return(true, nil)
+
+		mw := NewAuthMiddleware(tokenerMock, nil, denylistMock, log)
+
+		h := func(w http.ResponseWriter, r *http.Request) {
+			// Won't get here
+			w.Write([]byte("ok")) // nolint
+		}
+		ts := httptest.NewServer(mw(http.HandlerFunc(h)))
+		defer ts.Close()
+
+		req, err := http.NewRequest(http.MethodGet, ts.URL, nil)
+		assert.NoError(t, err)
+
+		req.Header.Add("Authorization", `Token token="qwerty"`)
+
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+	t.Run("Authorize user by personal token", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		hash := auth.HashToken("nott_qwerty")
+		tokensMock := storage.NewMockPersonalTokensRepo(ctrl)
+		tokensMock.EXPECT().
+			Get(storage.PersonalTokensFilter{Hash: &hash}).
+			Return([]auth.PersonalToken{{ID: 1, UserID: user.ID, Scopes: auth.Scopes{"notes:read"}}}, nil)
+
+		mw := NewAuthMiddleware(nil, tokensMock, nil, log)
+
+		h := func(w http.ResponseWriter, r *http.Request) {
+			// Check user and scopes in request context
+			assert.Equal(t, user.ID, getUserID(r))
+			assert.Equal(t, 0, getSessionID(r))
+			scopes, ok := getScopes(r)
+			assert.True(t, ok)
+			assert.Equal(t, auth.Scopes{"notes:read"}, scopes)
+
+			w.Write([]byte("ok")) // nolint
+		}
+		ts := httptest.NewServer(mw(http.HandlerFunc(h)))
+		defer ts.Close()
+
+		req, err := http.NewRequest(http.MethodGet, ts.URL, nil)
+		assert.NoError(t, err)
+
+		req.Header.Add("Authorization", `Token token="nott_qwerty"`)
+
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("Fail to authorize user with unknown personal token", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		tokensMock := storage.NewMockPersonalTokensRepo(ctrl)
+		tokensMock.EXPECT().Get(gomock.Any()).Return([]auth.PersonalToken{}, nil)
+
+		mw := NewAuthMiddleware(nil, tokensMock, nil, log)
+
+		h := func(w http.ResponseWriter, r *http.Request) {
+			// Won't get here
+			w.Write([]byte("ok")) // nolint
+		}
+		ts := httptest.NewServer(mw(http.HandlerFunc(h)))
+		defer ts.Close()
+
+		req, err := http.NewRequest(http.MethodGet, ts.URL, nil)
+		assert.NoError(t, err)
+
+		req.Header.Add("Authorization", `Token token="nott_qwerty"`)
+
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("Fail to authorize user with expired personal token", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		expired := time.Now().Add(-time.Minute)
+		tokensMock := storage.NewMockPersonalTokensRepo(ctrl)
+		tokensMock.EXPECT().
+			Get(gomock.Any()).
+			Return([]auth.PersonalToken{{ID: 1, UserID: user.ID, ExpiresAt: &expired}}, nil)
+
+		mw := NewAuthMiddleware(nil, tokensMock, nil, log)
+
+		h := func(w http.ResponseWriter, r *http.Request) {
+			// Won't get here
+			w.Write([]byte("ok")) // nolint
+		}
+		ts := httptest.NewServer(mw(http.HandlerFunc(h)))
+		defer ts.Close()
+
+		req, err := http.NewRequest(http.MethodGet, ts.URL, nil)
+		assert.NoError(t, err)
+
+		req.Header.Add("Authorization", `Token token="nott_qwerty"`)
+
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+}
+
+func TestScopesMiddleware(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok")) // nolint
+	})
+
+	testCases := []struct {
+		name   string
+		scopes []string
+		// granted are scopes of personal token, nil for session
+		granted auth.Scopes
+		code    int
+	}{
+		{
+			name:   "session",
+			scopes: []string{auth.ScopeNotesWrite},
+			code:   http.StatusOK,
+		},
+		{
+			name: "session only route",
+			code: http.StatusOK,
+		},
+		{
+			name:    "personal token with scope",
+			scopes:  []string{auth.ScopeNotesRead},
+			granted: auth.Scopes{auth.ScopeFoldersRead, auth.ScopeNotesRead},
+			code:    http.StatusOK,
+		},
+		{
+			name:    "personal token without scope",
+			scopes:  []string{auth.ScopeNotesRead, auth.ScopeFoldersRead},
+			granted: auth.Scopes{auth.ScopeNotesRead},
+			code:    http.StatusForbidden,
+		},
+		{
+			name:    "personal token on session only route",
+			granted: auth.Scopes{auth.ScopeNotesRead},
+			code:    http.StatusForbidden,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.granted != nil {
+				req = addScopes(req, tc.granted)
+			}
+
+			NewScopesMiddleware(tc.scopes...)(h).ServeHTTP(w, req)
+
+			assert.Equal(t, tc.code, w.Code)
+		})
+	}
 }
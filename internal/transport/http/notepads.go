@@ -24,15 +24,26 @@ func NewNotepadsController(repo storage.NotepadsRepo, log logrus.FieldLogger) *N
 // GetList handles request for getting notepads.
 func (c *NotepadsController) GetList(w http.ResponseWriter, req *http.Request) {
 	userID := getUserID(req)
+	page, err := getPage(req)
+	if err != nil {
+		badRequest(w, err.Error())
+		return
+	}
 
-	notepads, err := c.repo.Get(storage.NotepadsFilter{UserID: &userID})
+	notepads, err := c.repo.Get(storage.NotepadsFilter{UserID: &userID, Page: page})
 	if err != nil {
 		c.log.Errorf("Failed to get notepads: %v", err)
 		internalServerError(w)
 		return
 	}
 
-	respond(w, http.StatusOK, notepads)
+	var next string
+	if pageFull(page, len(notepads)) {
+		last := notepads[len(notepads)-1]
+		next = storage.NewCursor(page.Sort, last.ID, last.Title, last.CreatedAt, last.UpdatedAt).Encode()
+	}
+
+	respondPage(w, http.StatusOK, notepads, next)
 }
 
 // GetOne handles request for getting notepad by id.
@@ -70,6 +81,8 @@ func (c *NotepadsController) Create(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 	n.UserID = userID
+	// Objects are moved to trash only by deleting them
+	n.DeletedAt = nil
 
 	if err = n.Validate(); err != nil {
 		badRequest(w, "invalid notepad"+err.Error())
@@ -77,6 +90,14 @@ func (c *NotepadsController) Create(w http.ResponseWriter, req *http.Request) {
 	}
 
 	n, err = c.repo.Create(n)
+	if err == domain.ErrForbidden {
+		forbidden(w)
+		return
+	}
+	if err == domain.ErrParentNotFound {
+		respond(w, http.StatusNotFound, "parent not found")
+		return
+	}
 	if err != nil {
 		c.log.Errorf("Failed to create notepad: %v", err)
 		internalServerError(w)
@@ -102,6 +123,8 @@ func (c *NotepadsController) Update(w http.ResponseWriter, req *http.Request) {
 	}
 	n.ID = id
 	n.UserID = userID
+	// Objects are moved to trash only by deleting them
+	n.DeletedAt = nil
 
 	if err = n.Validate(); err != nil {
 		badRequest(w, "invalid notepad"+err.Error())
@@ -113,6 +136,14 @@ func (c *NotepadsController) Update(w http.ResponseWriter, req *http.Request) {
 		notFound(w)
 		return
 	}
+	if err == domain.ErrForbidden {
+		forbidden(w)
+		return
+	}
+	if err == domain.ErrParentNotFound {
+		respond(w, http.StatusNotFound, "parent not found")
+		return
+	}
 	if err != nil {
 		c.log.Errorf("Failed to update notepad: %v", err)
 		internalServerError(w)
@@ -122,6 +153,47 @@ func (c *NotepadsController) Update(w http.ResponseWriter, req *http.Request) {
 	respond(w, http.StatusOK, n)
 }
 
+// Move handles request for moving notepad into another folder.
+func (c *NotepadsController) Move(w http.ResponseWriter, req *http.Request) {
+	userID := getUserID(req)
+	id, err := getID(req)
+	if err != nil {
+		notFound(w)
+		return
+	}
+
+	n := domain.Notepad{}
+	if err = json.NewDecoder(req.Body).Decode(&n); err != nil {
+		badRequest(w, "invalid json")
+		return
+	}
+	if n.FolderID == 0 {
+		badRequest(w, "folder id cannot be empty")
+		return
+	}
+
+	n, err = c.repo.Move(domain.Notepad{ID: id, UserID: userID, FolderID: n.FolderID})
+	if err == domain.ErrNotFound {
+		notFound(w)
+		return
+	}
+	if err == domain.ErrForbidden {
+		forbidden(w)
+		return
+	}
+	if err == domain.ErrParentNotFound {
+		respond(w, http.StatusNotFound, "parent not found")
+		return
+	}
+	if err != nil {
+		c.log.Errorf("Failed to move notepad: %v", err)
+		internalServerError(w)
+		return
+	}
+
+	respond(w, http.StatusOK, n)
+}
+
 // Delete handles request for deleting notepad.
 func (c *NotepadsController) Delete(w http.ResponseWriter, req *http.Request) {
 	userID := getUserID(req)
@@ -132,7 +204,12 @@ func (c *NotepadsController) Delete(w http.ResponseWriter, req *http.Request) {
 	}
 
 	n := domain.Notepad{ID: id, UserID: userID}
-	if err = c.repo.Delete(n); err != nil {
+	err = c.repo.Delete(n)
+	if err == domain.ErrForbidden {
+		forbidden(w)
+		return
+	}
+	if err != nil {
 		c.log.Errorf("Failed to update notepad: %v", err)
 		internalServerError(w)
 		return
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/pkg/errors"
@@ -299,6 +300,63 @@ func TestNotepadsController(t *testing.T) {
 		}`)
 	})
 
+	t.Run("Fail to move notepad to folder of another user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		id := 10
+		notepad := domain.Notepad{ID: id, UserID: user.ID, FolderID: 30, Title: "Notepad 10"}
+
+		repoMock := storage.NewMockNotepadsRepo(ctrl)
+		repoMock.EXPECT().Update(notepad).Return(domain.Notepad{}, domain.ErrParentNotFound)
+
+		c := NewNotepadsController(repoMock, log)
+
+		payload, err := json.Marshal(notepad)
+		assert.NoError(t, err)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, url, bytes.NewBuffer(payload))
+		req = addUserID(req, user.ID)
+		req = addID(req, id)
+
+		c.Update(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusNotFound)
+	})
+
+	t.Run("Ignore deletion time on notepad update", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		id := 10
+		notepad := domain.Notepad{ID: id, UserID: user.ID, FolderID: 30, Title: "Notepad 10"}
+
+		repoMock := storage.NewMockNotepadsRepo(ctrl)
+		repoMock.EXPECT().Update(notepad).Return(notepad, nil)
+
+		c := NewNotepadsController(repoMock, log)
+
+		deleted := notepad
+		deleted.DeletedAt = &time.Time{}
+		payload, err := json.Marshal(deleted)
+		assert.NoError(t, err)
+		assert.Contains(t, string(payload), "deleted_at")
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, url, bytes.NewBuffer(payload))
+		req = addUserID(req, user.ID)
+		req = addID(req, id)
+
+		c.Update(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusOK)
+	})
+
 	t.Run("Fail to update notepad", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
@@ -373,4 +431,65 @@ func TestNotepadsController(t *testing.T) {
 		resp := w.Result()
 		assert.Equal(t, resp.StatusCode, http.StatusInternalServerError)
 	})
+
+	t.Run("Move notepad", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		id := 10
+		notepad := domain.Notepad{ID: id, UserID: user.ID, FolderID: 30, Title: "Notepad 10"}
+
+		repoMock := storage.NewMockNotepadsRepo(ctrl)
+		repoMock.EXPECT().
+			Move(domain.Notepad{ID: id, UserID: user.ID, FolderID: 30}).
+			Return(notepad, nil)
+
+		c := NewNotepadsController(repoMock, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, url, bytes.NewBufferString(`{"folder_id":30}`))
+		req = addUserID(req, user.ID)
+		req = addID(req, id)
+
+		c.Move(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusOK)
+
+		body, err := ioutil.ReadAll(resp.Body)
+		assert.NoError(t, err)
+
+		err = resp.Body.Close()
+		assert.NoError(t, err)
+
+		assert.JSONEq(t, string(body), `{
+			"data": {
+				"id": 10,
+				"user_id": 1,
+				"folder_id": 30,
+				"title": "Notepad 10"
+			}
+		}`)
+	})
+
+	t.Run("Fail to move notepad without folder", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repoMock := storage.NewMockNotepadsRepo(ctrl)
+
+		c := NewNotepadsController(repoMock, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, url, bytes.NewBufferString(`{}`))
+		req = addUserID(req, user.ID)
+		req = addID(req, 10)
+
+		c.Move(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusBadRequest)
+	})
 }
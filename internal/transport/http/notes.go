@@ -2,8 +2,10 @@ package httpapi
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/sirupsen/logrus"
 
@@ -14,21 +16,39 @@ import (
 
 // NotesController handles HTTP API requests.
 type NotesController struct {
-	repo storage.NotesRepo
-	log  logrus.FieldLogger
+	repo     storage.NotesRepo
+	links    *AttachmentLinks
+	renderer markdown.Renderer
+	log      logrus.FieldLogger
 }
 
-// NewNotesController creates new controller.
-func NewNotesController(repo storage.NotesRepo, log logrus.FieldLogger) *NotesController {
-	return &NotesController{repo: repo, log: log}
+// NewNotesController creates new controller. Links are used for
+// resolving attachments in rendered notes, they are left as is
+// if links are nil. Notes are rendered with default config
+// if renderer is nil.
+func NewNotesController(
+	repo storage.NotesRepo,
+	links *AttachmentLinks,
+	renderer markdown.Renderer,
+	log logrus.FieldLogger,
+) *NotesController {
+	if renderer == nil {
+		renderer = markdown.NewCommonMark(markdown.DefaultConfig())
+	}
+	return &NotesController{repo: repo, links: links, renderer: renderer, log: log}
 }
 
 // GetList handles request for getting notes.
 func (c *NotesController) GetList(w http.ResponseWriter, req *http.Request) {
 	userID := getUserID(req)
 	notepadID := req.URL.Query().Get("notepad_id")
+	page, err := getPage(req)
+	if err != nil {
+		badRequest(w, err.Error())
+		return
+	}
 
-	f := storage.NotesFilter{UserID: &userID}
+	f := storage.NotesFilter{UserID: &userID, Page: page}
 
 	if notepadID != "" {
 		nid, err := strconv.Atoi(notepadID)
@@ -38,6 +58,17 @@ func (c *NotesController) GetList(w http.ResponseWriter, req *http.Request) {
 		}
 		f.NotepadID = &nid
 	}
+	if tags := req.URL.Query().Get("tags"); tags != "" {
+		f.Tags = domain.NormalizeTags(strings.Split(tags, ","))
+		switch req.URL.Query().Get("tags_match") {
+		case "", "any":
+		case "all":
+			f.AllTags = true
+		default:
+			badRequest(w, "Tags match must be either any or all")
+			return
+		}
+	}
 
 	notes, err := c.repo.Get(f)
 	if err != nil {
@@ -46,7 +77,32 @@ func (c *NotesController) GetList(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	respond(w, http.StatusOK, notes)
+	var next string
+	if pageFull(page, len(notes)) {
+		last := notes[len(notes)-1]
+		next = storage.NewCursor(page.Sort, last.ID, last.Title, last.CreatedAt, last.UpdatedAt).Encode()
+	}
+
+	respondPage(w, http.StatusOK, notes, next)
+}
+
+// Search handles request for full-text search over notes.
+func (c *NotesController) Search(w http.ResponseWriter, req *http.Request) {
+	userID := getUserID(req)
+	query := strings.TrimSpace(req.URL.Query().Get("q"))
+	if query == "" {
+		badRequest(w, "Search query cannot be empty")
+		return
+	}
+
+	hits, err := c.repo.Search(storage.NotesFilter{UserID: &userID, Query: &query})
+	if err != nil {
+		c.log.Errorf("Failed to search notes: %v", err)
+		internalServerError(w)
+		return
+	}
+
+	respond(w, http.StatusOK, hits)
 }
 
 // GetOne handles request for getting note by id.
@@ -57,6 +113,11 @@ func (c *NotesController) GetOne(w http.ResponseWriter, req *http.Request) {
 		notFound(w)
 		return
 	}
+	opts, err := getRenderOptions(req)
+	if err != nil {
+		badRequest(w, err.Error())
+		return
+	}
 
 	notes, err := c.repo.Get(storage.NotesFilter{ID: &id, UserID: &userID})
 	if err != nil {
@@ -70,12 +131,77 @@ func (c *NotesController) GetOne(w http.ResponseWriter, req *http.Request) {
 	}
 	n := notes[0]
 
+	// Links are resolved only if there may be any, so plain notes
+	// don't need another query
+	var links []domain.NoteLink
+	if strings.Contains(n.Text, "[[") {
+		links, err = c.repo.GetLinks(storage.LinksFilter{NoteID: &id, UserID: &userID})
+		if err != nil {
+			c.log.Errorf("Failed to get note links: %v", err)
+			internalServerError(w)
+			return
+		}
+	}
+
 	// Render markdown to HTML
-	n.HTML = markdown.Render(n.Text)
+	opts.NoteURL = noteURL(links)
+	opts.LinksKey = linksKey(links)
+	if c.links != nil {
+		opts.AttachmentURL = func(id int) string {
+			return c.links.URL(id, userID)
+		}
+	}
+	n.HTML = c.renderer.Render(n.Text, opts)
 
 	respond(w, http.StatusOK, n)
 }
 
+// GetBacklinks handles request for getting notes that have wiki links
+// to the note.
+func (c *NotesController) GetBacklinks(w http.ResponseWriter, req *http.Request) {
+	userID := getUserID(req)
+	id, err := getID(req)
+	if err != nil {
+		notFound(w)
+		return
+	}
+
+	notes, err := c.repo.Get(storage.NotesFilter{ID: &id, UserID: &userID})
+	if err != nil {
+		c.log.Errorf("Failed to get note: %v", err)
+		internalServerError(w)
+		return
+	}
+	if len(notes) == 0 {
+		notFound(w)
+		return
+	}
+
+	notes, err = c.repo.Get(storage.NotesFilter{UserID: &userID, LinksTo: &id})
+	if err != nil {
+		c.log.Errorf("Failed to get backlinks: %v", err)
+		internalServerError(w)
+		return
+	}
+
+	respond(w, http.StatusOK, notes)
+}
+
+// GetGraph handles request for getting graph of user's notes
+// connected by wiki links. Broken links are included.
+func (c *NotesController) GetGraph(w http.ResponseWriter, req *http.Request) {
+	userID := getUserID(req)
+
+	graph, err := c.repo.GetGraph(userID)
+	if err != nil {
+		c.log.Errorf("Failed to get notes graph: %v", err)
+		internalServerError(w)
+		return
+	}
+
+	respond(w, http.StatusOK, graph)
+}
+
 // Create handles request for creating note.
 func (c *NotesController) Create(w http.ResponseWriter, req *http.Request) {
 	userID := getUserID(req)
@@ -88,6 +214,8 @@ func (c *NotesController) Create(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 	n.UserID = userID
+	// Objects are moved to trash only by deleting them
+	n.DeletedAt = nil
 
 	if err = n.Validate(); err != nil {
 		badRequest(w, "invalid note"+err.Error())
@@ -95,6 +223,14 @@ func (c *NotesController) Create(w http.ResponseWriter, req *http.Request) {
 	}
 
 	n, err = c.repo.Create(n)
+	if err == domain.ErrForbidden {
+		forbidden(w)
+		return
+	}
+	if err == domain.ErrParentNotFound {
+		respond(w, http.StatusNotFound, "parent not found")
+		return
+	}
 	if err != nil {
 		c.log.Errorf("Failed to create note: %v", err)
 		internalServerError(w)
@@ -120,6 +256,8 @@ func (c *NotesController) Update(w http.ResponseWriter, req *http.Request) {
 	}
 	n.ID = id
 	n.UserID = userID
+	// Objects are moved to trash only by deleting them
+	n.DeletedAt = nil
 
 	if err = n.Validate(); err != nil {
 		badRequest(w, "invalid note"+err.Error())
@@ -131,6 +269,14 @@ func (c *NotesController) Update(w http.ResponseWriter, req *http.Request) {
 		notFound(w)
 		return
 	}
+	if err == domain.ErrForbidden {
+		forbidden(w)
+		return
+	}
+	if err == domain.ErrParentNotFound {
+		respond(w, http.StatusNotFound, "parent not found")
+		return
+	}
 	if err != nil {
 		c.log.Errorf("Failed to update note: %v", err)
 		internalServerError(w)
@@ -140,6 +286,47 @@ func (c *NotesController) Update(w http.ResponseWriter, req *http.Request) {
 	respond(w, http.StatusOK, n)
 }
 
+// Move handles request for moving note into another notepad.
+func (c *NotesController) Move(w http.ResponseWriter, req *http.Request) {
+	userID := getUserID(req)
+	id, err := getID(req)
+	if err != nil {
+		notFound(w)
+		return
+	}
+
+	n := domain.Note{}
+	if err = json.NewDecoder(req.Body).Decode(&n); err != nil {
+		badRequest(w, "invalid json")
+		return
+	}
+	if n.NotepadID == 0 {
+		badRequest(w, "notepad id cannot be empty")
+		return
+	}
+
+	n, err = c.repo.Move(domain.Note{ID: id, UserID: userID, NotepadID: n.NotepadID})
+	if err == domain.ErrNotFound {
+		notFound(w)
+		return
+	}
+	if err == domain.ErrForbidden {
+		forbidden(w)
+		return
+	}
+	if err == domain.ErrParentNotFound {
+		respond(w, http.StatusNotFound, "parent not found")
+		return
+	}
+	if err != nil {
+		c.log.Errorf("Failed to move note: %v", err)
+		internalServerError(w)
+		return
+	}
+
+	respond(w, http.StatusOK, n)
+}
+
 // Delete handles request for deleting note.
 func (c *NotesController) Delete(w http.ResponseWriter, req *http.Request) {
 	userID := getUserID(req)
@@ -150,7 +337,12 @@ func (c *NotesController) Delete(w http.ResponseWriter, req *http.Request) {
 	}
 
 	n := domain.Note{ID: id, UserID: userID}
-	if err = c.repo.Delete(n); err != nil {
+	err = c.repo.Delete(n)
+	if err == domain.ErrForbidden {
+		forbidden(w)
+		return
+	}
+	if err != nil {
 		c.log.Errorf("Failed to update note: %v", err)
 		internalServerError(w)
 		return
@@ -158,3 +350,179 @@ func (c *NotesController) Delete(w http.ResponseWriter, req *http.Request) {
 
 	respond(w, http.StatusNoContent, nil)
 }
+
+// GetRevisions handles request for getting list of note revisions.
+func (c *NotesController) GetRevisions(w http.ResponseWriter, req *http.Request) {
+	userID := getUserID(req)
+	id, err := getID(req)
+	if err != nil {
+		notFound(w)
+		return
+	}
+
+	revs, err := c.repo.GetRevisions(storage.RevisionsFilter{NoteID: &id, UserID: &userID})
+	if err != nil {
+		c.log.Errorf("Failed to get revisions: %v", err)
+		internalServerError(w)
+		return
+	}
+	if len(revs) == 0 {
+		notFound(w)
+		return
+	}
+
+	respond(w, http.StatusOK, revs)
+}
+
+// GetRevision handles request for getting note revision by its number.
+func (c *NotesController) GetRevision(w http.ResponseWriter, req *http.Request) {
+	userID := getUserID(req)
+	id, err := getID(req)
+	if err != nil {
+		notFound(w)
+		return
+	}
+	num, err := getIntParam(req, "rev")
+	if err != nil {
+		notFound(w)
+		return
+	}
+
+	revs, err := c.repo.GetRevisions(storage.RevisionsFilter{
+		NoteID: &id,
+		UserID: &userID,
+		Number: &num,
+	})
+	if err != nil {
+		c.log.Errorf("Failed to get revision: %v", err)
+		internalServerError(w)
+		return
+	}
+	if len(revs) == 0 {
+		notFound(w)
+		return
+	}
+
+	respond(w, http.StatusOK, revs[0])
+}
+
+// GetDiff handles request for getting unified diff between
+// two note revisions.
+func (c *NotesController) GetDiff(w http.ResponseWriter, req *http.Request) {
+	userID := getUserID(req)
+	id, err := getID(req)
+	if err != nil {
+		notFound(w)
+		return
+	}
+	from, err := strconv.Atoi(req.URL.Query().Get("from"))
+	if err != nil {
+		badRequest(w, "Revision numbers must be integer numbers")
+		return
+	}
+	to, err := strconv.Atoi(req.URL.Query().Get("to"))
+	if err != nil {
+		badRequest(w, "Revision numbers must be integer numbers")
+		return
+	}
+
+	revs, err := c.repo.GetRevisions(storage.RevisionsFilter{NoteID: &id, UserID: &userID})
+	if err != nil {
+		c.log.Errorf("Failed to get revisions: %v", err)
+		internalServerError(w)
+		return
+	}
+	var fromRev, toRev *domain.NoteRevision
+	for i := range revs {
+		if revs[i].Number == from {
+			fromRev = &revs[i]
+		}
+		if revs[i].Number == to {
+			toRev = &revs[i]
+		}
+	}
+	if fromRev == nil || toRev == nil {
+		notFound(w)
+		return
+	}
+
+	diff, err := domain.DiffRevisions(*fromRev, *toRev)
+	if err != nil {
+		c.log.Errorf("Failed to make diff: %v", err)
+		internalServerError(w)
+		return
+	}
+
+	respond(w, http.StatusOK, diffResponse{From: from, To: to, Diff: diff})
+}
+
+// Restore handles request for restoring note from its revision.
+func (c *NotesController) Restore(w http.ResponseWriter, req *http.Request) {
+	userID := getUserID(req)
+	id, err := getID(req)
+	if err != nil {
+		notFound(w)
+		return
+	}
+	num, err := getIntParam(req, "rev")
+	if err != nil {
+		notFound(w)
+		return
+	}
+
+	n, err := c.repo.Restore(domain.Note{ID: id, UserID: userID}, num)
+	if err == domain.ErrNotFound {
+		notFound(w)
+		return
+	}
+	if err == domain.ErrForbidden {
+		forbidden(w)
+		return
+	}
+	if err != nil {
+		c.log.Errorf("Failed to restore note: %v", err)
+		internalServerError(w)
+		return
+	}
+
+	respond(w, http.StatusOK, n)
+}
+
+type diffResponse struct {
+	From int    `json:"from"`
+	To   int    `json:"to"`
+	Diff string `json:"diff"`
+}
+
+// noteURL makes resolver of wiki links from the list of resolved links.
+// Notes are linked by their paths in the client application.
+func noteURL(links []domain.NoteLink) markdown.NoteURL {
+	return func(wl markdown.WikiLink) (string, string, bool) {
+		for _, l := range links {
+			if l.TargetID == nil {
+				continue
+			}
+			if (wl.ID != 0 && l.LinkID != nil && *l.LinkID == wl.ID) ||
+				(wl.ID == 0 && strings.EqualFold(l.LinkTitle, wl.Title)) {
+				return "/notes/" + strconv.Itoa(*l.TargetID), l.TargetTitle, true
+			}
+		}
+		return "", "", false
+	}
+}
+
+// linksKey makes key of the resolved links for the render cache.
+func linksKey(links []domain.NoteLink) string {
+	var b strings.Builder
+	for _, l := range links {
+		if l.TargetID == nil {
+			continue
+		}
+		var id int
+		if l.LinkID != nil {
+			id = *l.LinkID
+		}
+		fmt.Fprintf(&b, "%d %q %d %q\n", id, l.LinkTitle, *l.TargetID, l.TargetTitle)
+	}
+	return b.String()
+}
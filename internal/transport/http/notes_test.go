@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/pkg/errors"
@@ -15,6 +16,7 @@ import (
 
 	"github.com/tetafro/nott-backend-go/internal/auth"
 	"github.com/tetafro/nott-backend-go/internal/domain"
+	"github.com/tetafro/nott-backend-go/internal/markdown"
 	"github.com/tetafro/nott-backend-go/internal/storage"
 )
 
@@ -41,7 +43,7 @@ func TestNotesNController(t *testing.T) {
 			storage.NotesFilter{UserID: &user.ID, NotepadID: Int(10)},
 		).Return(notes, nil)
 
-		c := NewNotesController(repoMock, log)
+		c := NewNotesController(repoMock, nil, nil, log)
 
 		url := "/?notepad_id=10"
 		w := httptest.NewRecorder()
@@ -88,7 +90,7 @@ func TestNotesNController(t *testing.T) {
 			storage.NotesFilter{UserID: &user.ID},
 		).Return(nil, errors.New("error"))
 
-		c := NewNotesController(repoMock, log)
+		c := NewNotesController(repoMock, nil, nil, log)
 
 		url := "/"
 		w := httptest.NewRecorder()
@@ -101,6 +103,100 @@ func TestNotesNController(t *testing.T) {
 		assert.Equal(t, resp.StatusCode, http.StatusInternalServerError)
 	})
 
+	t.Run("Search notes", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		query := "hello"
+		hits := []domain.NoteHit{
+			{
+				Note:    domain.Note{ID: 10, UserID: user.ID, NotepadID: 30, Title: "Note 10", Text: "Hello"},
+				Rank:    0.5,
+				Snippet: "<mark>Hello</mark>",
+			},
+		}
+
+		repoMock := storage.NewMockNotesRepo(ctrl)
+		repoMock.EXPECT().Search(
+			storage.NotesFilter{UserID: &user.ID, Query: &query},
+		).Return(hits, nil)
+
+		c := NewNotesController(repoMock, nil, nil, log)
+
+		url := "/?q=hello"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req = addUserID(req, user.ID)
+
+		c.Search(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusOK)
+
+		body, err := ioutil.ReadAll(resp.Body)
+		assert.NoError(t, err)
+
+		err = resp.Body.Close()
+		assert.NoError(t, err)
+
+		assert.JSONEq(t, string(body), `{
+			"data": [
+				{
+					"id": 10,
+					"user_id": 1,
+					"notepad_id": 30,
+					"title": "Note 10",
+					"text": "Hello",
+					"rank": 0.5,
+					"snippet": "\u003cmark\u003eHello\u003c/mark\u003e"
+				}
+			]
+		}`)
+	})
+
+	t.Run("Fail to search notes with empty query", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repoMock := storage.NewMockNotesRepo(ctrl)
+
+		c := NewNotesController(repoMock, nil, nil, log)
+
+		url := "/?q=+"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req = addUserID(req, user.ID)
+
+		c.Search(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusBadRequest)
+	})
+
+	t.Run("Fail to search notes", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		query := "hello"
+
+		repoMock := storage.NewMockNotesRepo(ctrl)
+		repoMock.EXPECT().Search(
+			storage.NotesFilter{UserID: &user.ID, Query: &query},
+		).Return(nil, errors.New("error"))
+
+		c := NewNotesController(repoMock, nil, nil, log)
+
+		url := "/?q=hello"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req = addUserID(req, user.ID)
+
+		c.Search(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusInternalServerError)
+	})
+
 	t.Run("Create note", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
@@ -111,7 +207,7 @@ func TestNotesNController(t *testing.T) {
 		repoMock := storage.NewMockNotesRepo(ctrl)
 		repoMock.EXPECT().Create(note).Return(note, nil)
 
-		c := NewNotesController(repoMock, log)
+		c := NewNotesController(repoMock, nil, nil, log)
 
 		payload, err := json.Marshal(note)
 		assert.NoError(t, err)
@@ -143,6 +239,31 @@ func TestNotesNController(t *testing.T) {
 		}`)
 	})
 
+	t.Run("Fail to create note in notepad of another user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		note := domain.Note{UserID: user.ID, NotepadID: 30, Title: "Note 10", Text: "Hello"}
+
+		repoMock := storage.NewMockNotesRepo(ctrl)
+		repoMock.EXPECT().Create(note).Return(domain.Note{}, domain.ErrParentNotFound)
+
+		c := NewNotesController(repoMock, nil, nil, log)
+
+		payload, err := json.Marshal(note)
+		assert.NoError(t, err)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, url, bytes.NewBuffer(payload))
+		req = addUserID(req, user.ID)
+
+		c.Create(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusNotFound)
+	})
+
 	t.Run("Fail to create note", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
@@ -153,7 +274,7 @@ func TestNotesNController(t *testing.T) {
 		repoMock := storage.NewMockNotesRepo(ctrl)
 		repoMock.EXPECT().Update(note).Return(domain.Note{}, errors.New("error"))
 
-		c := NewNotesController(repoMock, log)
+		c := NewNotesController(repoMock, nil, nil, log)
 
 		payload, err := json.Marshal(note)
 		assert.NoError(t, err)
@@ -184,7 +305,7 @@ func TestNotesNController(t *testing.T) {
 			storage.NotesFilter{ID: &id, UserID: &user.ID},
 		).Return(notes, nil)
 
-		c := NewNotesController(repoMock, log)
+		c := NewNotesController(repoMock, nil, nil, log)
 
 		url := "/"
 		w := httptest.NewRecorder()
@@ -215,18 +336,28 @@ func TestNotesNController(t *testing.T) {
 		}`)
 	})
 
-	t.Run("Fail to get note by non-existing id", func(t *testing.T) {
+	t.Run("Get note with wiki links", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
-		id := 10
+		id, targetID := 10, 20
+		notes := []domain.Note{
+			{ID: id, UserID: user.ID, NotepadID: 30, Title: "Note 10", Text: "[[Note 20]] [[Note 30]]"},
+		}
+		links := []domain.NoteLink{
+			{NoteID: id, LinkTitle: "Note 20", TargetID: &targetID, TargetTitle: "Note 20"},
+			{NoteID: id, LinkTitle: "Note 30", Broken: true},
+		}
 
 		repoMock := storage.NewMockNotesRepo(ctrl)
 		repoMock.EXPECT().Get(
 			storage.NotesFilter{ID: &id, UserID: &user.ID},
-		).Return(nil, nil)
+		).Return(notes, nil)
+		repoMock.EXPECT().GetLinks(
+			storage.LinksFilter{NoteID: &id, UserID: &user.ID},
+		).Return(links, nil)
 
-		c := NewNotesController(repoMock, log)
+		c := NewNotesController(repoMock, nil, nil, log)
 
 		url := "/"
 		w := httptest.NewRecorder()
@@ -237,21 +368,90 @@ func TestNotesNController(t *testing.T) {
 		c.GetOne(w, req)
 
 		resp := w.Result()
-		assert.Equal(t, resp.StatusCode, http.StatusNotFound)
+		assert.Equal(t, resp.StatusCode, http.StatusOK)
+
+		body, err := ioutil.ReadAll(resp.Body)
+		assert.NoError(t, err)
+
+		err = resp.Body.Close()
+		assert.NoError(t, err)
+
+		assert.JSONEq(t, string(body), `{
+			"data": {
+				"id": 10,
+				"user_id": 1,
+				"notepad_id": 30,
+				"title": "Note 10",
+				"text": "[[Note 20]] [[Note 30]]",
+				"html": "\u003cp\u003e\u003ca class=\"note-link\" href=\"/notes/20\"\u003eNote 20\u003c/a\u003e \u003cspan class=\"note-link broken\"\u003eNote 30\u003c/span\u003e\u003c/p\u003e\n"
+			}
+		}`)
 	})
 
-	t.Run("Fail to get note by id", func(t *testing.T) {
+	t.Run("Get note with renamed wiki link target", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		id, targetID := 10, 20
+		notes := []domain.Note{
+			{ID: id, UserID: user.ID, NotepadID: 30, Title: "Note 10", Text: "[[note:20]]"},
+		}
+		link := func(title string) []domain.NoteLink {
+			return []domain.NoteLink{
+				{NoteID: id, LinkID: &targetID, TargetID: &targetID, TargetTitle: title},
+			}
+		}
+
+		repoMock := storage.NewMockNotesRepo(ctrl)
+		repoMock.EXPECT().Get(
+			storage.NotesFilter{ID: &id, UserID: &user.ID},
+		).Return(notes, nil).Times(2)
+		gomock.InOrder(
+			repoMock.EXPECT().GetLinks(
+				storage.LinksFilter{NoteID: &id, UserID: &user.ID},
+			).Return(link("Note 20"), nil),
+			repoMock.EXPECT().GetLinks(
+				storage.LinksFilter{NoteID: &id, UserID: &user.ID},
+			).Return(link("Renamed"), nil),
+		)
+
+		renderer := markdown.NewCachedRenderer(
+			markdown.NewCommonMark(markdown.DefaultConfig()),
+			markdown.NewMemoryCache(10),
+		)
+		c := NewNotesController(repoMock, nil, renderer, log)
+
+		for _, title := range []string{"Note 20", "Renamed"} {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req = addUserID(req, user.ID)
+			req = addID(req, id)
+
+			c.GetOne(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Contains(t, w.Body.String(), `href=\"/notes/20\"\u003e`+title+`\u003c/a\u003e`)
+		}
+	})
+
+	t.Run("Get backlinks of note", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
 		id := 10
+		notes := []domain.Note{
+			{ID: 20, UserID: user.ID, NotepadID: 30, Title: "Note 20", Text: "See [[Note 10]]"},
+		}
 
 		repoMock := storage.NewMockNotesRepo(ctrl)
 		repoMock.EXPECT().Get(
 			storage.NotesFilter{ID: &id, UserID: &user.ID},
-		).Return(nil, errors.New("error"))
+		).Return([]domain.Note{{ID: id}}, nil)
+		repoMock.EXPECT().Get(
+			storage.NotesFilter{UserID: &user.ID, LinksTo: &id},
+		).Return(notes, nil)
 
-		c := NewNotesController(repoMock, log)
+		c := NewNotesController(repoMock, nil, nil, log)
 
 		url := "/"
 		w := httptest.NewRecorder()
@@ -259,34 +459,82 @@ func TestNotesNController(t *testing.T) {
 		req = addUserID(req, user.ID)
 		req = addID(req, id)
 
-		c.GetOne(w, req)
+		c.GetBacklinks(w, req)
 
 		resp := w.Result()
-		assert.Equal(t, resp.StatusCode, http.StatusInternalServerError)
+		assert.Equal(t, resp.StatusCode, http.StatusOK)
+
+		body, err := ioutil.ReadAll(resp.Body)
+		assert.NoError(t, err)
+
+		err = resp.Body.Close()
+		assert.NoError(t, err)
+
+		assert.JSONEq(t, string(body), `{
+			"data": [
+				{
+					"id": 20,
+					"user_id": 1,
+					"notepad_id": 30,
+					"title": "Note 20",
+					"text": "See [[Note 10]]"
+				}
+			]
+		}`)
 	})
 
-	t.Run("Update note", func(t *testing.T) {
+	t.Run("Fail to get backlinks of non-existing note", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
 		id := 10
-		note := domain.Note{ID: id, UserID: user.ID, NotepadID: 30, Title: "Note 10", Text: "Hello"}
 
 		repoMock := storage.NewMockNotesRepo(ctrl)
-		repoMock.EXPECT().Update(note).Return(note, nil)
-
-		c := NewNotesController(repoMock, log)
+		repoMock.EXPECT().Get(
+			storage.NotesFilter{ID: &id, UserID: &user.ID},
+		).Return(nil, nil)
 
-		payload, err := json.Marshal(note)
-		assert.NoError(t, err)
+		c := NewNotesController(repoMock, nil, nil, log)
 
 		url := "/"
 		w := httptest.NewRecorder()
-		req := httptest.NewRequest(http.MethodPut, url, bytes.NewBuffer(payload))
+		req := httptest.NewRequest(http.MethodGet, url, nil)
 		req = addUserID(req, user.ID)
 		req = addID(req, id)
 
-		c.Update(w, req)
+		c.GetBacklinks(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusNotFound)
+	})
+
+	t.Run("Get notes graph", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		targetID := 20
+		graph := domain.NoteGraph{
+			Notes: []domain.NoteNode{
+				{ID: 10, NotepadID: 30, Title: "Note 10"},
+				{ID: 20, NotepadID: 30, Title: "Note 20"},
+			},
+			Links: []domain.NoteLink{
+				{NoteID: 10, LinkID: &targetID, TargetID: &targetID, TargetTitle: "Note 20"},
+				{NoteID: 20, LinkTitle: "Missing", Broken: true},
+			},
+		}
+
+		repoMock := storage.NewMockNotesRepo(ctrl)
+		repoMock.EXPECT().GetGraph(user.ID).Return(graph, nil)
+
+		c := NewNotesController(repoMock, nil, nil, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req = addUserID(req, user.ID)
+
+		c.GetGraph(w, req)
 
 		resp := w.Result()
 		assert.Equal(t, resp.StatusCode, http.StatusOK)
@@ -299,87 +547,681 @@ func TestNotesNController(t *testing.T) {
 
 		assert.JSONEq(t, string(body), `{
 			"data": {
-				"id": 10,
-				"user_id": 1,
-				"notepad_id": 30,
-				"title": "Note 10",
-				"text": "Hello"
+				"notes": [
+					{"id": 10, "notepad_id": 30, "title": "Note 10"},
+					{"id": 20, "notepad_id": 30, "title": "Note 20"}
+				],
+				"links": [
+					{
+						"note_id": 10,
+						"link_id": 20,
+						"target_id": 20,
+						"target_title": "Note 20",
+						"broken": false
+					},
+					{
+						"note_id": 20,
+						"link_title": "Missing",
+						"target_id": null,
+						"broken": true
+					}
+				]
 			}
 		}`)
 	})
 
-	t.Run("Fail to update note", func(t *testing.T) {
+	t.Run("Get note with raw html", func(t *testing.T) {
+		text := `Hello, <b onclick="alert(1)">world</b>`
+		for mode, html := range map[string]string{
+			"":         `<p>Hello, <b>world</b></p>` + "\n",
+			"sanitize": `<p>Hello, <b>world</b></p>` + "\n",
+			"escape":   `<p>Hello, &lt;b onclick=&quot;alert(1)&quot;&gt;world&lt;/b&gt;</p>` + "\n",
+		} {
+			ctrl := gomock.NewController(t)
+
+			id := 10
+			notes := []domain.Note{
+				{ID: id, UserID: user.ID, NotepadID: 30, Title: "Note 10", Text: text},
+			}
+
+			repoMock := storage.NewMockNotesRepo(ctrl)
+			repoMock.EXPECT().Get(
+				storage.NotesFilter{ID: &id, UserID: &user.ID},
+			).Return(notes, nil)
+
+			c := NewNotesController(repoMock, nil, nil, log)
+
+			url := "/?raw_html=" + mode
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, url, nil)
+			req = addUserID(req, user.ID)
+			req = addID(req, id)
+
+			c.GetOne(w, req)
+
+			resp := w.Result()
+			assert.Equal(t, resp.StatusCode, http.StatusOK)
+
+			var body struct {
+				Data domain.Note `json:"data"`
+			}
+			assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+			assert.NoError(t, resp.Body.Close())
+			assert.Equal(t, html, body.Data.HTML, mode)
+
+			ctrl.Finish()
+		}
+	})
+
+	t.Run("Fail to get note with invalid raw html mode", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
-		id := 10
-		note := domain.Note{ID: id, UserID: user.ID, NotepadID: 30, Title: "Note 10", Text: "Hello"}
+		c := NewNotesController(storage.NewMockNotesRepo(ctrl), nil, nil, log)
 
-		repoMock := storage.NewMockNotesRepo(ctrl)
-		repoMock.EXPECT().Update(note).Return(domain.Note{}, errors.New("error"))
+		url := "/?raw_html=keep"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req = addUserID(req, user.ID)
+		req = addID(req, 10)
 
-		c := NewNotesController(repoMock, log)
+		c.GetOne(w, req)
 
-		payload, err := json.Marshal(note)
-		assert.NoError(t, err)
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusBadRequest)
+	})
 
-		url := "/"
+	t.Run("Get note with theme", func(t *testing.T) {
+		text := "```go\nx := 1\n```"
+		for theme, re := range map[string]string{
+			"":        `^<pre class="chroma"><span class="nx">x</span>`,
+			"monokai": `^<pre style="color:#f8f8f2;background-color:#272822">`,
+		} {
+			ctrl := gomock.NewController(t)
+
+			id := 10
+			notes := []domain.Note{
+				{ID: id, UserID: user.ID, NotepadID: 30, Title: "Note 10", Text: text},
+			}
+
+			repoMock := storage.NewMockNotesRepo(ctrl)
+			repoMock.EXPECT().Get(
+				storage.NotesFilter{ID: &id, UserID: &user.ID},
+			).Return(notes, nil)
+
+			c := NewNotesController(repoMock, nil, nil, log)
+
+			url := "/?theme=" + theme
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, url, nil)
+			req = addUserID(req, user.ID)
+			req = addID(req, id)
+
+			c.GetOne(w, req)
+
+			resp := w.Result()
+			assert.Equal(t, resp.StatusCode, http.StatusOK)
+
+			var body struct {
+				Data domain.Note `json:"data"`
+			}
+			assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+			assert.NoError(t, resp.Body.Close())
+			assert.Regexp(t, re, body.Data.HTML, theme)
+
+			ctrl.Finish()
+		}
+	})
+
+	t.Run("Fail to get note with unknown theme", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		c := NewNotesController(storage.NewMockNotesRepo(ctrl), nil, nil, log)
+
+		url := "/?theme=unknown"
 		w := httptest.NewRecorder()
-		req := httptest.NewRequest(http.MethodPut, url, bytes.NewBuffer(payload))
+		req := httptest.NewRequest(http.MethodGet, url, nil)
 		req = addUserID(req, user.ID)
-		req = addID(req, id)
+		req = addID(req, 10)
 
-		c.Update(w, req)
+		c.GetOne(w, req)
 
 		resp := w.Result()
-		assert.Equal(t, resp.StatusCode, http.StatusInternalServerError)
+		assert.Equal(t, resp.StatusCode, http.StatusBadRequest)
 	})
 
-	t.Run("Delete note", func(t *testing.T) {
+	t.Run("Fail to get note by non-existing id", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
 		id := 10
-		note := domain.Note{ID: id, UserID: user.ID}
 
 		repoMock := storage.NewMockNotesRepo(ctrl)
-		repoMock.EXPECT().Delete(note).Return(nil)
+		repoMock.EXPECT().Get(
+			storage.NotesFilter{ID: &id, UserID: &user.ID},
+		).Return(nil, nil)
 
-		c := NewNotesController(repoMock, log)
+		c := NewNotesController(repoMock, nil, nil, log)
 
 		url := "/"
 		w := httptest.NewRecorder()
-		req := httptest.NewRequest(http.MethodDelete, url, nil)
+		req := httptest.NewRequest(http.MethodGet, url, nil)
 		req = addUserID(req, user.ID)
 		req = addID(req, id)
 
-		c.Delete(w, req)
+		c.GetOne(w, req)
 
 		resp := w.Result()
-		assert.Equal(t, resp.StatusCode, http.StatusNoContent)
+		assert.Equal(t, resp.StatusCode, http.StatusNotFound)
 	})
 
-	t.Run("Fail to delete note", func(t *testing.T) {
+	t.Run("Fail to get note by id", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
 		id := 10
-		note := domain.Note{ID: id, UserID: user.ID}
 
 		repoMock := storage.NewMockNotesRepo(ctrl)
-		repoMock.EXPECT().Delete(note).Return(errors.New("error"))
+		repoMock.EXPECT().Get(
+			storage.NotesFilter{ID: &id, UserID: &user.ID},
+		).Return(nil, errors.New("error"))
 
-		c := NewNotesController(repoMock, log)
+		c := NewNotesController(repoMock, nil, nil, log)
 
 		url := "/"
 		w := httptest.NewRecorder()
-		req := httptest.NewRequest(http.MethodPut, url, nil)
+		req := httptest.NewRequest(http.MethodGet, url, nil)
 		req = addUserID(req, user.ID)
 		req = addID(req, id)
 
-		c.Delete(w, req)
+		c.GetOne(w, req)
 
 		resp := w.Result()
 		assert.Equal(t, resp.StatusCode, http.StatusInternalServerError)
 	})
+
+	t.Run("Update note", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		id := 10
+		note := domain.Note{ID: id, UserID: user.ID, NotepadID: 30, Title: "Note 10", Text: "Hello"}
+
+		repoMock := storage.NewMockNotesRepo(ctrl)
+		repoMock.EXPECT().Update(note).Return(note, nil)
+
+		c := NewNotesController(repoMock, nil, nil, log)
+
+		payload, err := json.Marshal(note)
+		assert.NoError(t, err)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, url, bytes.NewBuffer(payload))
+		req = addUserID(req, user.ID)
+		req = addID(req, id)
+
+		c.Update(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusOK)
+
+		body, err := ioutil.ReadAll(resp.Body)
+		assert.NoError(t, err)
+
+		err = resp.Body.Close()
+		assert.NoError(t, err)
+
+		assert.JSONEq(t, string(body), `{
+			"data": {
+				"id": 10,
+				"user_id": 1,
+				"notepad_id": 30,
+				"title": "Note 10",
+				"text": "Hello"
+			}
+		}`)
+	})
+
+	t.Run("Ignore deletion time on note update", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		id := 10
+		note := domain.Note{ID: id, UserID: user.ID, NotepadID: 30, Title: "Note 10", Text: "Hello"}
+
+		repoMock := storage.NewMockNotesRepo(ctrl)
+		repoMock.EXPECT().Update(note).Return(note, nil)
+
+		c := NewNotesController(repoMock, nil, nil, log)
+
+		deleted := note
+		deleted.DeletedAt = &time.Time{}
+		payload, err := json.Marshal(deleted)
+		assert.NoError(t, err)
+		assert.Contains(t, string(payload), "deleted_at")
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, url, bytes.NewBuffer(payload))
+		req = addUserID(req, user.ID)
+		req = addID(req, id)
+
+		c.Update(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusOK)
+	})
+
+	t.Run("Fail to update note", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		id := 10
+		note := domain.Note{ID: id, UserID: user.ID, NotepadID: 30, Title: "Note 10", Text: "Hello"}
+
+		repoMock := storage.NewMockNotesRepo(ctrl)
+		repoMock.EXPECT().Update(note).Return(domain.Note{}, errors.New("error"))
+
+		c := NewNotesController(repoMock, nil, nil, log)
+
+		payload, err := json.Marshal(note)
+		assert.NoError(t, err)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, url, bytes.NewBuffer(payload))
+		req = addUserID(req, user.ID)
+		req = addID(req, id)
+
+		c.Update(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusInternalServerError)
+	})
+
+	t.Run("Fail to update note with viewer role", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		id := 10
+		note := domain.Note{ID: id, UserID: user.ID, NotepadID: 30, Title: "Note 10", Text: "Hello"}
+
+		repoMock := storage.NewMockNotesRepo(ctrl)
+		repoMock.EXPECT().Update(note).Return(domain.Note{}, domain.ErrForbidden)
+
+		c := NewNotesController(repoMock, nil, nil, log)
+
+		payload, err := json.Marshal(note)
+		assert.NoError(t, err)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, url, bytes.NewBuffer(payload))
+		req = addUserID(req, user.ID)
+		req = addID(req, id)
+
+		c.Update(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusForbidden)
+	})
+
+	t.Run("Delete note", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		id := 10
+		note := domain.Note{ID: id, UserID: user.ID}
+
+		repoMock := storage.NewMockNotesRepo(ctrl)
+		repoMock.EXPECT().Delete(note).Return(nil)
+
+		c := NewNotesController(repoMock, nil, nil, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodDelete, url, nil)
+		req = addUserID(req, user.ID)
+		req = addID(req, id)
+
+		c.Delete(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusNoContent)
+	})
+
+	t.Run("Fail to delete note", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		id := 10
+		note := domain.Note{ID: id, UserID: user.ID}
+
+		repoMock := storage.NewMockNotesRepo(ctrl)
+		repoMock.EXPECT().Delete(note).Return(errors.New("error"))
+
+		c := NewNotesController(repoMock, nil, nil, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, url, nil)
+		req = addUserID(req, user.ID)
+		req = addID(req, id)
+
+		c.Delete(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusInternalServerError)
+	})
+
+	t.Run("Get note revisions", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		id := 10
+		revs := []domain.NoteRevision{
+			{ID: 2, NoteID: id, UserID: user.ID, Number: 2, Title: "Note 10", Text: "Hello, world"},
+			{ID: 1, NoteID: id, UserID: user.ID, Number: 1, Title: "Note 10", Text: "Hello"},
+		}
+
+		repoMock := storage.NewMockNotesRepo(ctrl)
+		repoMock.EXPECT().GetRevisions(
+			storage.RevisionsFilter{NoteID: &id, UserID: &user.ID},
+		).Return(revs, nil)
+
+		c := NewNotesController(repoMock, nil, nil, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req = addUserID(req, user.ID)
+		req = addID(req, id)
+
+		c.GetRevisions(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusOK)
+
+		body, err := ioutil.ReadAll(resp.Body)
+		assert.NoError(t, err)
+
+		err = resp.Body.Close()
+		assert.NoError(t, err)
+
+		assert.JSONEq(t, string(body), `{
+			"data": [
+				{
+					"id": 2,
+					"note_id": 10,
+					"user_id": 1,
+					"number": 2,
+					"title": "Note 10",
+					"text": "Hello, world",
+					"created_at": "0001-01-01T00:00:00Z"
+				},
+				{
+					"id": 1,
+					"note_id": 10,
+					"user_id": 1,
+					"number": 1,
+					"title": "Note 10",
+					"text": "Hello",
+					"created_at": "0001-01-01T00:00:00Z"
+				}
+			]
+		}`)
+	})
+
+	t.Run("Fail to get revisions of non-existing note", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		id := 10
+
+		repoMock := storage.NewMockNotesRepo(ctrl)
+		repoMock.EXPECT().GetRevisions(
+			storage.RevisionsFilter{NoteID: &id, UserID: &user.ID},
+		).Return(nil, nil)
+
+		c := NewNotesController(repoMock, nil, nil, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req = addUserID(req, user.ID)
+		req = addID(req, id)
+
+		c.GetRevisions(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusNotFound)
+	})
+
+	t.Run("Get note revision", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		id := 10
+		num := 1
+		revs := []domain.NoteRevision{
+			{ID: 1, NoteID: id, UserID: user.ID, Number: num, Title: "Note 10", Text: "Hello"},
+		}
+
+		repoMock := storage.NewMockNotesRepo(ctrl)
+		repoMock.EXPECT().GetRevisions(
+			storage.RevisionsFilter{NoteID: &id, UserID: &user.ID, Number: &num},
+		).Return(revs, nil)
+
+		c := NewNotesController(repoMock, nil, nil, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req = addUserID(req, user.ID)
+		req = addID(req, id)
+		req = addIntParam(req, "rev", num)
+
+		c.GetRevision(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusOK)
+	})
+
+	t.Run("Get diff between note revisions", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		id := 10
+		revs := []domain.NoteRevision{
+			{ID: 2, NoteID: id, UserID: user.ID, Number: 2, Title: "Note 10", Text: "Hello, world"},
+			{ID: 1, NoteID: id, UserID: user.ID, Number: 1, Title: "Note 10", Text: "Hello"},
+		}
+
+		repoMock := storage.NewMockNotesRepo(ctrl)
+		repoMock.EXPECT().GetRevisions(
+			storage.RevisionsFilter{NoteID: &id, UserID: &user.ID},
+		).Return(revs, nil)
+
+		c := NewNotesController(repoMock, nil, nil, log)
+
+		url := "/?from=1&to=2"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req = addUserID(req, user.ID)
+		req = addID(req, id)
+
+		c.GetDiff(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusOK)
+
+		body, err := ioutil.ReadAll(resp.Body)
+		assert.NoError(t, err)
+
+		err = resp.Body.Close()
+		assert.NoError(t, err)
+
+		assert.JSONEq(t, string(body), `{
+			"data": {
+				"from": 1,
+				"to": 2,
+				"diff": "--- revision 1\t0001-01-01T00:00:00Z\n+++ revision 2\t0001-01-01T00:00:00Z\n`+
+			`@@ -1 +1 @@\n-Hello\n+Hello, world\n"
+			}
+		}`)
+	})
+
+	t.Run("Fail to get diff with non-existing revision", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		id := 10
+		revs := []domain.NoteRevision{
+			{ID: 1, NoteID: id, UserID: user.ID, Number: 1, Title: "Note 10", Text: "Hello"},
+		}
+
+		repoMock := storage.NewMockNotesRepo(ctrl)
+		repoMock.EXPECT().GetRevisions(
+			storage.RevisionsFilter{NoteID: &id, UserID: &user.ID},
+		).Return(revs, nil)
+
+		c := NewNotesController(repoMock, nil, nil, log)
+
+		url := "/?from=1&to=5"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req = addUserID(req, user.ID)
+		req = addID(req, id)
+
+		c.GetDiff(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusNotFound)
+	})
+
+	t.Run("Restore note revision", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		id := 10
+		num := 1
+		note := domain.Note{ID: id, UserID: user.ID, NotepadID: 30, Title: "Note 10", Text: "Hello"}
+
+		repoMock := storage.NewMockNotesRepo(ctrl)
+		repoMock.EXPECT().Restore(domain.Note{ID: id, UserID: user.ID}, num).Return(note, nil)
+
+		c := NewNotesController(repoMock, nil, nil, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, url, nil)
+		req = addUserID(req, user.ID)
+		req = addID(req, id)
+		req = addIntParam(req, "rev", num)
+
+		c.Restore(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusOK)
+	})
+
+	t.Run("Fail to restore non-existing note revision", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		id := 10
+		num := 5
+
+		repoMock := storage.NewMockNotesRepo(ctrl)
+		repoMock.EXPECT().Restore(domain.Note{ID: id, UserID: user.ID}, num).
+			Return(domain.Note{}, domain.ErrNotFound)
+
+		c := NewNotesController(repoMock, nil, nil, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, url, nil)
+		req = addUserID(req, user.ID)
+		req = addID(req, id)
+		req = addIntParam(req, "rev", num)
+
+		c.Restore(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusNotFound)
+	})
+
+	t.Run("Fail to move note to notepad of another user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		id := 10
+
+		repoMock := storage.NewMockNotesRepo(ctrl)
+		repoMock.EXPECT().
+			Move(domain.Note{ID: id, UserID: user.ID, NotepadID: 30}).
+			Return(domain.Note{}, domain.ErrParentNotFound)
+
+		c := NewNotesController(repoMock, nil, nil, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, url, bytes.NewBufferString(`{"notepad_id":30}`))
+		req = addUserID(req, user.ID)
+		req = addID(req, id)
+
+		c.Move(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusNotFound)
+	})
+
+	t.Run("Get notes by tags", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		notes := []domain.Note{
+			{ID: 10, UserID: user.ID, NotepadID: 30, Title: "Note 10", Tags: []string{"todo", "work"}},
+		}
+
+		repoMock := storage.NewMockNotesRepo(ctrl)
+		repoMock.EXPECT().Get(storage.NotesFilter{
+			UserID:  &user.ID,
+			Tags:    []string{"todo", "work"},
+			AllTags: true,
+		}).Return(notes, nil)
+
+		c := NewNotesController(repoMock, nil, nil, log)
+
+		url := "/?tags=Work,%23todo&tags_match=all"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req = addUserID(req, user.ID)
+
+		c.GetList(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusOK)
+
+		body, err := ioutil.ReadAll(resp.Body)
+		assert.NoError(t, err)
+
+		err = resp.Body.Close()
+		assert.NoError(t, err)
+
+		assert.JSONEq(t, string(body), `{
+			"data": [
+				{
+					"id": 10,
+					"user_id": 1,
+					"notepad_id": 30,
+					"title": "Note 10",
+					"text": "",
+					"tags": ["todo", "work"]
+				}
+			]
+		}`)
+	})
 }
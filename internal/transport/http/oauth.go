@@ -9,6 +9,7 @@ import (
 
 	"github.com/tetafro/nott-backend-go/internal/auth"
 	"github.com/tetafro/nott-backend-go/internal/domain"
+	"github.com/tetafro/nott-backend-go/internal/markdown"
 	"github.com/tetafro/nott-backend-go/internal/storage"
 )
 
@@ -16,7 +17,7 @@ import (
 type OAuthController struct {
 	providers map[string]*auth.OAuthProvider
 	users     storage.UsersRepo
-	tokener   auth.Tokener
+	sessions  *Sessions
 	log       logrus.FieldLogger
 }
 
@@ -24,10 +25,10 @@ type OAuthController struct {
 func NewOAuthController(
 	p map[string]*auth.OAuthProvider,
 	u storage.UsersRepo,
-	t auth.Tokener,
+	s *Sessions,
 	log logrus.FieldLogger,
 ) *OAuthController {
-	return &OAuthController{providers: p, users: u, tokener: t, log: log}
+	return &OAuthController{providers: p, users: u, sessions: s, log: log}
 }
 
 // Providers handles request for getting list of currently
@@ -65,17 +66,24 @@ func (c *OAuthController) Github(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	token, err := c.handleUser(email)
+	user, err := c.handleUser(email)
 	if err != nil {
 		c.log.Errorf("Failed to handle user: %v", err)
 		internalServerError(w)
 		return
 	}
 
+	token, err := c.sessions.Start(user, req)
+	if err != nil {
+		c.log.Errorf("Failed to start session: %v", err)
+		internalServerError(w)
+		return
+	}
+
 	respond(w, http.StatusOK, token)
 }
 
-func (c *OAuthController) handleUser(email string) (auth.Token, error) {
+func (c *OAuthController) handleUser(email string) (auth.User, error) {
 	// Get or create user
 	u, err := c.users.GetByEmail(email)
 	switch err {
@@ -83,22 +91,15 @@ func (c *OAuthController) handleUser(email string) (auth.Token, error) {
 		// Got existing user, proceed
 	case domain.ErrNotFound:
 		// Create new user
-		u, err = c.users.Create(auth.User{Email: email})
+		u, err = c.users.Create(auth.User{Email: email, Theme: markdown.DefaultTheme})
 		if err != nil {
-			return auth.Token{}, errors.Wrap(err, "create user")
+			return auth.User{}, errors.Wrap(err, "create user")
 		}
 	default:
 		// Unexpected error
-		return auth.Token{}, errors.Wrap(err, "get user")
+		return auth.User{}, errors.Wrap(err, "get user")
 	}
-
-	// Generate token
-	t, err := c.tokener.Issue(u)
-	if err != nil {
-		return auth.Token{}, errors.Wrap(err, "issue token")
-	}
-
-	return t, nil
+	return u, nil
 }
 
 type oauthRequest struct {
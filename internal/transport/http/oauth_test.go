@@ -13,6 +13,7 @@ import (
 
 	"github.com/tetafro/nott-backend-go/internal/auth"
 	"github.com/tetafro/nott-backend-go/internal/domain"
+	"github.com/tetafro/nott-backend-go/internal/markdown"
 	"github.com/tetafro/nott-backend-go/internal/storage"
 )
 
@@ -62,16 +63,11 @@ func TestOAuthController(t *testing.T) {
 		usersRepoMock := storage.NewMockUsersRepo(ctrl)
 		usersRepoMock.EXPECT().GetByEmail(user.Email).Return(user, nil)
 
-		tokenerMock := auth.NewMockTokener(ctrl)
-		tokenerMock.EXPECT().Issue(user).Return(
-			auth.Token{AccessToken: "qwerty", ExpiresAt: 10}, nil,
-		)
-
-		c := NewOAuthController(nil, usersRepoMock, tokenerMock, log)
+		c := NewOAuthController(nil, usersRepoMock, nil, log)
 
-		token, err := c.handleUser(user.Email)
+		u, err := c.handleUser(user.Email)
 		assert.NoError(t, err)
-		assert.Equal(t, "qwerty", token.AccessToken)
+		assert.Equal(t, user, u)
 	})
 
 	t.Run("Handle new user", func(t *testing.T) {
@@ -82,18 +78,13 @@ func TestOAuthController(t *testing.T) {
 
 		usersRepoMock := storage.NewMockUsersRepo(ctrl)
 		usersRepoMock.EXPECT().GetByEmail(user.Email).Return(auth.User{}, domain.ErrNotFound)
-		usersRepoMock.EXPECT().Create(auth.User{Email: user.Email}).Return(user, nil)
+		usersRepoMock.EXPECT().Create(auth.User{Email: user.Email, Theme: markdown.DefaultTheme}).Return(user, nil)
 
-		tokenerMock := auth.NewMockTokener(ctrl)
-		tokenerMock.EXPECT().Issue(user).Return(
-			auth.Token{AccessToken: "qwerty", ExpiresAt: 10}, nil,
-		)
+		c := NewOAuthController(nil, usersRepoMock, nil, log)
 
-		c := NewOAuthController(nil, usersRepoMock, tokenerMock, log)
-
-		token, err := c.handleUser(user.Email)
+		u, err := c.handleUser(user.Email)
 		assert.NoError(t, err)
-		assert.Equal(t, "qwerty", token.AccessToken)
+		assert.Equal(t, user, u)
 	})
 
 	t.Run("Fail to handle user", func(t *testing.T) {
@@ -105,9 +96,7 @@ func TestOAuthController(t *testing.T) {
 		usersRepoMock := storage.NewMockUsersRepo(ctrl)
 		usersRepoMock.EXPECT().GetByEmail(user.Email).Return(auth.User{}, errors.New("error"))
 
-		tokenerMock := auth.NewMockTokener(ctrl)
-
-		c := NewOAuthController(nil, usersRepoMock, tokenerMock, log)
+		c := NewOAuthController(nil, usersRepoMock, nil, log)
 
 		_, err := c.handleUser(user.Email)
 		assert.Error(t, err)
@@ -0,0 +1,252 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/tetafro/nott-backend-go/internal/auth"
+	"github.com/tetafro/nott-backend-go/internal/domain"
+	"github.com/tetafro/nott-backend-go/internal/mail"
+	"github.com/tetafro/nott-backend-go/internal/storage"
+)
+
+// resetSubject is a subject of password reset messages.
+const resetSubject = "Password reset"
+
+// resetBody is a template of password reset messages.
+const resetBody = `Someone requested password reset for your account.
+
+Use the link to set new password, it's valid for %d minutes:
+%s
+
+If it wasn't you, ignore this message.
+`
+
+// resetsPerEmail is a maximum number of reset requests for one email
+// per hour, so the address can't be flooded with messages.
+const resetsPerEmail = 3
+
+// PasswordsController handles HTTP API requests.
+type PasswordsController struct {
+	users    storage.UsersRepo
+	resets   storage.PasswordResetsRepo
+	tokens   storage.PersonalTokensRepo
+	sessions *Sessions
+	mailer   mail.Mailer
+	// resetURL is an address of the page for setting new password,
+	// the token is added to its query
+	resetURL string
+	// emails limits reset requests for every email
+	emails *rateLimiter
+	log    logrus.FieldLogger
+}
+
+// NewPasswordsController creates new controller.
+func NewPasswordsController(
+	users storage.UsersRepo,
+	resets storage.PasswordResetsRepo,
+	tokens storage.PersonalTokensRepo,
+	sessions *Sessions,
+	mailer mail.Mailer,
+	resetURL string,
+	log logrus.FieldLogger,
+) *PasswordsController {
+	return &PasswordsController{
+		users:    users,
+		resets:   resets,
+		tokens:   tokens,
+		sessions: sessions,
+		mailer:   mailer,
+		resetURL: resetURL,
+		emails:   newRateLimiter(resetsPerEmail, time.Hour, time.Now),
+		log:      log,
+	}
+}
+
+// Change handles request for changing password of the user. Other
+// sessions of the user are ended.
+func (c *PasswordsController) Change(w http.ResponseWriter, req *http.Request) {
+	userID := getUserID(req)
+
+	var body struct {
+		CurrentPassword string `json:"current_password"`
+		NewPassword     string `json:"new_password"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		badRequest(w, "invalid json")
+		return
+	}
+	if err := auth.ValidatePassword(body.NewPassword); err != nil {
+		badRequest(w, "invalid password: "+err.Error())
+		return
+	}
+
+	user, err := c.users.GetByID(userID)
+	if err == domain.ErrNotFound {
+		notFound(w)
+		return
+	}
+	if err != nil {
+		c.log.Errorf("Failed to get user: %v", err)
+		internalServerError(w)
+		return
+	}
+	if !auth.CheckPassword(body.CurrentPassword, user.Password) {
+		badRequest(w, "invalid current password")
+		return
+	}
+
+	if err = c.setPassword(userID, body.NewPassword, getSessionID(req)); err != nil {
+		c.log.Errorf("Failed to change password: %v", err)
+		internalServerError(w)
+		return
+	}
+
+	respond(w, http.StatusNoContent, nil)
+}
+
+// Reset handles request for resetting forgotten password. A link
+// with reset token is sent to the email. The message is sent after
+// the response, so the response is the same for unknown emails,
+// and it doesn't take longer for known ones.
+func (c *PasswordsController) Reset(w http.ResponseWriter, req *http.Request) {
+	var body struct {
+		Email string `json:"email"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		badRequest(w, "invalid json")
+		return
+	}
+	if body.Email == "" {
+		badRequest(w, "email cannot be empty")
+		return
+	}
+	if ok, wait := c.emails.allow(strings.ToLower(body.Email)); !ok {
+		tooManyRequests(w, wait)
+		return
+	}
+
+	go c.sendReset(body.Email)
+
+	respond(w, http.StatusNoContent, nil)
+}
+
+// sendReset sends the link with new reset token to the user
+// with the email, if there is one.
+func (c *PasswordsController) sendReset(email string) {
+	user, err := c.users.GetByEmail(email)
+	if err == domain.ErrNotFound {
+		return
+	}
+	if err != nil {
+		c.log.Errorf("Failed to get user: %v", err)
+		return
+	}
+
+	token, pr, err := auth.NewPasswordReset(user.ID)
+	if err != nil {
+		c.log.Errorf("Failed to make reset token: %v", err)
+		return
+	}
+	if _, err = c.resets.Create(pr); err != nil {
+		c.log.Errorf("Failed to create reset token: %v", err)
+		return
+	}
+
+	msg, err := c.resetMessage(user.Email, token)
+	if err != nil {
+		c.log.Errorf("Failed to make reset message: %v", err)
+		return
+	}
+	if err = c.mailer.Send(msg); err != nil {
+		c.log.Errorf("Failed to send reset message: %v", err)
+	}
+}
+
+// ConfirmReset handles request for setting new password with reset
+// token. All sessions of the user are ended, and personal access
+// tokens are revoked, since they could be made by someone who took
+// over the account.
+func (c *PasswordsController) ConfirmReset(w http.ResponseWriter, req *http.Request) {
+	var body struct {
+		Token    string `json:"token"`
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		badRequest(w, "invalid json")
+		return
+	}
+	if body.Token == "" {
+		badRequest(w, "token cannot be empty")
+		return
+	}
+	if err := auth.ValidatePassword(body.Password); err != nil {
+		badRequest(w, "invalid password: "+err.Error())
+		return
+	}
+
+	pr, err := c.resets.Use(auth.HashToken(body.Token))
+	if err == domain.ErrNotFound {
+		badRequest(w, "invalid or expired token")
+		return
+	}
+	if err != nil {
+		c.log.Errorf("Failed to use reset token: %v", err)
+		internalServerError(w)
+		return
+	}
+
+	// Session ID 0 doesn't exist, so all sessions are ended
+	if err = c.setPassword(pr.UserID, body.Password, 0); err != nil {
+		c.log.Errorf("Failed to reset password: %v", err)
+		internalServerError(w)
+		return
+	}
+	if err = c.tokens.DeleteAll(pr.UserID); err != nil {
+		c.log.Errorf("Failed to revoke personal tokens: %v", err)
+		internalServerError(w)
+		return
+	}
+
+	respond(w, http.StatusNoContent, nil)
+}
+
+// setPassword sets new password of the user, and ends all sessions
+// except the given one.
+func (c *PasswordsController) setPassword(userID int, password string, sessionID int) error {
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return errors.Wrap(err, "hash password")
+	}
+	if err = c.users.UpdatePassword(userID, hash); err != nil {
+		return errors.Wrap(err, "update password")
+	}
+	if err = c.sessions.EndOthers(sessionID, userID); err != nil {
+		return errors.Wrap(err, "end sessions")
+	}
+	return nil
+}
+
+// resetMessage makes message with the link for setting new password.
+func (c *PasswordsController) resetMessage(email, token string) (mail.Message, error) {
+	u, err := url.Parse(c.resetURL)
+	if err != nil {
+		return mail.Message{}, errors.Wrap(err, "parse reset url")
+	}
+	q := u.Query()
+	q.Set("token", token)
+	u.RawQuery = q.Encode()
+
+	return mail.Message{
+		To:      email,
+		Subject: resetSubject,
+		Body:    fmt.Sprintf(resetBody, int(auth.PasswordResetTTL.Minutes()), u.String()),
+	}, nil
+}
@@ -0,0 +1,280 @@
+package httpapi
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tetafro/nott-backend-go/internal/auth"
+	"github.com/tetafro/nott-backend-go/internal/domain"
+	"github.com/tetafro/nott-backend-go/internal/mail"
+	"github.com/tetafro/nott-backend-go/internal/storage"
+)
+
+func TestPasswordsController(t *testing.T) {
+	log := logrus.New()
+	log.Out = ioutil.Discard
+
+	hash, err := auth.HashPassword("old-password")
+	assert.NoError(t, err)
+	user := auth.User{ID: 1, Email: "bob@example.com", Password: hash}
+	resetURL := "http://example.com/reset-password?lang=en"
+
+	t.Run("Change password", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		usersMock := storage.NewMockUsersRepo(ctrl)
+		usersMock.EXPECT().GetByID(user.ID).Return(user, nil)
+		usersMock.EXPECT().UpdatePassword(user.ID, gomock.Any()).DoAndReturn(
+			func(id int, hash string) error {
+				assert.True(t, auth.CheckPassword("new-password", hash))
+				return nil
+			},
+		)
+
+		sessionsRepoMock := storage.NewMockSessionsRepo(ctrl)
+		sessionsRepoMock.EXPECT().DeleteOthers(5, user.ID).Return([]int{6}, nil)
+
+		denylistMock := auth.NewMockDenylist(ctrl)
+		denylistMock.EXPECT().Revoke("session:6", gomock.Any()).Return(nil)
+
+		sessions := NewSessions(sessionsRepoMock, nil, denylistMock, nil, time.Minute)
+		c := NewPasswordsController(usersMock, nil, nil, sessions, nil, resetURL, log)
+
+		body := `{"current_password":"old-password","new_password":"new-password"}`
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req = addUserID(req, user.ID)
+		req = addSessionID(req, 5)
+
+		c.Change(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("Fail to change password with invalid current password", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		usersMock := storage.NewMockUsersRepo(ctrl)
+		usersMock.EXPECT().GetByID(user.ID).Return(user, nil)
+
+		c := NewPasswordsController(usersMock, nil, nil, nil, nil, resetURL, log)
+
+		body := `{"current_password":"wrong-password","new_password":"new-password"}`
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req = addUserID(req, user.ID)
+
+		c.Change(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"error":"invalid current password"}`, w.Body.String())
+	})
+
+	t.Run("Fail to change password because of input data", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		c := NewPasswordsController(storage.NewMockUsersRepo(ctrl), nil, nil, nil, nil, resetURL, log)
+
+		for body, msg := range map[string]string{
+			`{`: "invalid json",
+			`{"current_password":"old-password","new_password":"short"}`: "invalid password: password must have at least 8 characters",
+		} {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+			req = addUserID(req, user.ID)
+
+			c.Change(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.JSONEq(t, `{"error":"`+msg+`"}`, w.Body.String())
+		}
+	})
+
+	t.Run("Reset password", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		usersMock := storage.NewMockUsersRepo(ctrl)
+		usersMock.EXPECT().GetByEmail(user.Email).Return(user, nil)
+
+		var created auth.PasswordReset
+		resetsMock := storage.NewMockPasswordResetsRepo(ctrl)
+		resetsMock.EXPECT().Create(gomock.Any()).DoAndReturn(
+			func(pr auth.PasswordReset) (auth.PasswordReset, error) {
+				assert.Equal(t, user.ID, pr.UserID)
+				assert.WithinDuration(t, time.Now().Add(time.Hour), pr.ExpiresAt, time.Second)
+				created = pr
+				return pr, nil
+			},
+		)
+
+		// The message is sent after the response
+		sent := make(chan struct{})
+		mailerMock := mail.NewMockMailer(ctrl)
+		mailerMock.EXPECT().Send(gomock.Any()).DoAndReturn(func(msg mail.Message) error {
+			defer close(sent)
+			assert.Equal(t, user.Email, msg.To)
+			assert.Equal(t, "Password reset", msg.Subject)
+			assert.Contains(t, msg.Body, "valid for 60 minutes")
+
+			link := regexp.MustCompile(`http://example\.com/reset-password\?lang=en&token=(\S+)`).
+				FindStringSubmatch(msg.Body)
+			if assert.Len(t, link, 2) {
+				assert.Equal(t, created.Hash, auth.HashToken(link[1]))
+			}
+			return nil
+		})
+
+		c := NewPasswordsController(usersMock, resetsMock, nil, nil, mailerMock, resetURL, log)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"email":"bob@example.com"}`))
+
+		c.Reset(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		<-sent
+	})
+
+	t.Run("Reset password of unknown user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		checked := make(chan struct{})
+		usersMock := storage.NewMockUsersRepo(ctrl)
+		usersMock.EXPECT().GetByEmail("eve@example.com").DoAndReturn(
+			func(email string) (auth.User, error) {
+				close(checked)
+				return auth.User{}, domain.ErrNotFound
+			},
+		)
+
+		c := NewPasswordsController(usersMock, nil, nil, nil, nil, resetURL, log)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"email":"eve@example.com"}`))
+
+		c.Reset(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		<-checked
+	})
+
+	t.Run("Fail to reset password too often", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		checked := make(chan struct{}, 3)
+		usersMock := storage.NewMockUsersRepo(ctrl)
+		usersMock.EXPECT().GetByEmail(gomock.Any()).Times(3).DoAndReturn(
+			func(email string) (auth.User, error) {
+				checked <- struct{}{}
+				return auth.User{}, domain.ErrNotFound
+			},
+		)
+
+		c := NewPasswordsController(usersMock, nil, nil, nil, nil, resetURL, log)
+
+		// Emails are compared ignoring case
+		for _, email := range []string{"eve@example.com", "Eve@example.com", "EVE@example.com"} {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"email":"`+email+`"}`))
+			c.Reset(w, req)
+			assert.Equal(t, http.StatusNoContent, w.Code)
+			<-checked
+		}
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"email":"eve@example.com"}`))
+		c.Reset(w, req)
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.NotEmpty(t, w.Header().Get("Retry-After"))
+	})
+
+	t.Run("Confirm password reset", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		resetsMock := storage.NewMockPasswordResetsRepo(ctrl)
+		resetsMock.EXPECT().Use(auth.HashToken("abc")).Return(auth.PasswordReset{ID: 3, UserID: user.ID}, nil)
+
+		usersMock := storage.NewMockUsersRepo(ctrl)
+		usersMock.EXPECT().UpdatePassword(user.ID, gomock.Any()).DoAndReturn(
+			func(id int, hash string) error {
+				assert.True(t, auth.CheckPassword("new-password", hash))
+				return nil
+			},
+		)
+
+		// All sessions are ended
+		sessionsRepoMock := storage.NewMockSessionsRepo(ctrl)
+		sessionsRepoMock.EXPECT().DeleteOthers(0, user.ID).Return([]int{5}, nil)
+
+		denylistMock := auth.NewMockDenylist(ctrl)
+		denylistMock.EXPECT().Revoke("session:5", gomock.Any()).Return(nil)
+
+		// Personal access tokens are revoked
+		tokensMock := storage.NewMockPersonalTokensRepo(ctrl)
+		tokensMock.EXPECT().DeleteAll(user.ID).Return(nil)
+
+		sessions := NewSessions(sessionsRepoMock, nil, denylistMock, nil, time.Minute)
+		c := NewPasswordsController(usersMock, resetsMock, tokensMock, sessions, nil, resetURL, log)
+
+		body := `{"token":"abc","password":"new-password"}`
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+
+		c.ConfirmReset(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("Fail to confirm password reset with invalid token", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		resetsMock := storage.NewMockPasswordResetsRepo(ctrl)
+		resetsMock.EXPECT().Use(auth.HashToken("abc")).Return(auth.PasswordReset{}, domain.ErrNotFound)
+
+		c := NewPasswordsController(nil, resetsMock, nil, nil, nil, resetURL, log)
+
+		body := `{"token":"abc","password":"new-password"}`
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+
+		c.ConfirmReset(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"error":"invalid or expired token"}`, w.Body.String())
+	})
+
+	t.Run("Fail to confirm password reset because of input data", func(t *testing.T) {
+		c := NewPasswordsController(nil, nil, nil, nil, nil, resetURL, log)
+
+		for body, msg := range map[string]string{
+			`{`:                              "invalid json",
+			`{"password":"new-password"}`:    "token cannot be empty",
+			`{"token":"abc","password":"a"}`: "invalid password: password must have at least 8 characters",
+		} {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+
+			c.ConfirmReset(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.JSONEq(t, `{"error":"`+msg+`"}`, w.Body.String())
+		}
+	})
+}
@@ -0,0 +1,122 @@
+package httpapi
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi"
+)
+
+// RateLimitKey gets key of the request for rate limiting. Requests
+// with the same key share the limit.
+type RateLimitKey func(req *http.Request) string
+
+// ByUser makes requests of every user share the limit. The middleware
+// must go after authentication.
+func ByUser(req *http.Request) string {
+	return strconv.Itoa(getUserID(req))
+}
+
+// ByClient makes requests from every client address share the limit.
+func ByClient(req *http.Request) string {
+	return clientIP(req)
+}
+
+// ByClientAndParam makes requests from every client address with
+// the same value of the URL parameter share the limit.
+func ByClientAndParam(param string) RateLimitKey {
+	return func(req *http.Request) string {
+		return clientIP(req) + " " + chi.URLParam(req, param)
+	}
+}
+
+// NewRateLimitMiddleware creates middleware that limits number
+// of requests with every key to limit per period. Requests can go
+// in bursts up to the limit.
+func NewRateLimitMiddleware(limit int, period time.Duration, key RateLimitKey) func(http.Handler) http.Handler {
+	l := newRateLimiter(limit, period, time.Now)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			ok, wait := l.allow(key(req))
+			if !ok {
+				tooManyRequests(w, wait)
+				return
+			}
+			next.ServeHTTP(w, req)
+		})
+	}
+}
+
+// tooManyRequests responds with the time to wait before the next request.
+func tooManyRequests(w http.ResponseWriter, wait time.Duration) {
+	secs := int(math.Ceil(wait.Seconds()))
+	w.Header().Set("Retry-After", strconv.Itoa(secs))
+	respond(w, http.StatusTooManyRequests, "too many requests")
+}
+
+// rateLimiter is a token bucket for every key.
+type rateLimiter struct {
+	mu      sync.Mutex
+	burst   float64
+	rate    float64 // tokens per second
+	buckets map[string]*bucket
+	swept   time.Time
+	now     func() time.Time
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+func newRateLimiter(limit int, period time.Duration, now func() time.Time) *rateLimiter {
+	return &rateLimiter{
+		burst:   float64(limit),
+		rate:    float64(limit) / period.Seconds(),
+		buckets: map[string]*bucket{},
+		swept:   now(),
+		now:     now,
+	}
+}
+
+// allow takes a token for the key. It returns time to wait for
+// the next token if there are no tokens left.
+func (l *rateLimiter) allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, updated: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.updated).Seconds()*l.rate)
+	b.updated = now
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+		return false, wait
+	}
+	b.tokens--
+	return true, 0
+}
+
+// sweep removes buckets that are full by now, so the map doesn't grow
+// with every key that has ever been seen.
+func (l *rateLimiter) sweep(now time.Time) {
+	full := time.Duration(l.burst / l.rate * float64(time.Second))
+	if now.Sub(l.swept) < full {
+		return
+	}
+	for key, b := range l.buckets {
+		if now.Sub(b.updated) >= full {
+			delete(l.buckets, key)
+		}
+	}
+	l.swept = now
+}
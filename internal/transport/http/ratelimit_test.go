@@ -0,0 +1,73 @@
+package httpapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := newRateLimiter(2, time.Minute, func() time.Time { return now })
+
+	t.Run("Allow burst", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			ok, _ := l.allow("1")
+			assert.True(t, ok)
+		}
+		ok, wait := l.allow("1")
+		assert.False(t, ok)
+		assert.Equal(t, 30*time.Second, wait)
+
+		// Other keys have their own limits
+		ok, _ = l.allow("2")
+		assert.True(t, ok)
+	})
+
+	t.Run("Refill tokens", func(t *testing.T) {
+		now = now.Add(30 * time.Second)
+		ok, _ := l.allow("1")
+		assert.True(t, ok)
+		ok, _ = l.allow("1")
+		assert.False(t, ok)
+	})
+
+	t.Run("Remove full buckets", func(t *testing.T) {
+		now = now.Add(2 * time.Minute)
+		ok, _ := l.allow("3")
+		assert.True(t, ok)
+		assert.Len(t, l.buckets, 1)
+	})
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	mw := NewRateLimitMiddleware(1, time.Hour, ByUser)
+	h := mw(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req = addUserID(req, 1)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "3600", w.Header().Get("Retry-After"))
+}
+
+func TestRateLimitKeys(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req = addUserID(req, 10)
+	req = addParam(req, "token", "abc")
+
+	assert.Equal(t, "10", ByUser(req))
+	assert.Equal(t, "192.0.2.1", ByClient(req))
+	assert.Equal(t, "192.0.2.1 abc", ByClientAndParam("token")(req))
+}
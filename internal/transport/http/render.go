@@ -0,0 +1,96 @@
+package httpapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/tetafro/nott-backend-go/internal/markdown"
+)
+
+// RenderController handles HTTP API requests.
+type RenderController struct {
+	renderer *markdown.CommonMark
+	maxSize  int64
+	log      logrus.FieldLogger
+}
+
+// NewRenderController creates new controller. Max size is a maximum
+// size of a request in bytes.
+func NewRenderController(
+	renderer *markdown.CommonMark,
+	maxSize int64,
+	log logrus.FieldLogger,
+) *RenderController {
+	return &RenderController{renderer: renderer, maxSize: maxSize, log: log}
+}
+
+// renderRequest is a request for rendering markdown.
+type renderRequest struct {
+	Text    string           `json:"text"`
+	Theme   string           `json:"theme"`
+	RawHTML markdown.RawHTML `json:"raw_html"`
+	TOC     bool             `json:"toc"`
+}
+
+// Render handles request for rendering markdown to HTML without saving
+// it, e.g. for preview in editor. Attachments and wiki links are not
+// resolved, since the text doesn't belong to any note.
+func (c *RenderController) Render(w http.ResponseWriter, req *http.Request) {
+	body, err := readLimited(req.Body, c.maxSize)
+	if err == errTooLarge {
+		respond(w, http.StatusRequestEntityTooLarge, "request is too large")
+		return
+	}
+	if err != nil {
+		badRequest(w, "failed to read request")
+		return
+	}
+
+	var r renderRequest
+	if err = json.Unmarshal(body, &r); err != nil {
+		badRequest(w, "invalid json")
+		return
+	}
+	switch r.RawHTML {
+	case "":
+		r.RawHTML = markdown.RawHTMLSanitize
+	case markdown.RawHTMLSanitize, markdown.RawHTMLEscape:
+	default:
+		badRequest(w, "raw_html must be either "+
+			string(markdown.RawHTMLSanitize)+" or "+string(markdown.RawHTMLEscape))
+		return
+	}
+	if r.Theme != "" && !markdown.ValidTheme(r.Theme) {
+		badRequest(w, "unknown theme")
+		return
+	}
+
+	doc := c.renderer.RenderDocument(r.Text, markdown.Options{
+		RawHTML:         r.RawHTML,
+		Theme:           r.Theme,
+		TableOfContents: r.TOC,
+	})
+	respond(w, http.StatusOK, doc)
+}
+
+// errTooLarge is returned when request body exceeds the limit.
+var errTooLarge = errors.New("too large")
+
+// readLimited reads up to max bytes, it fails with errTooLarge
+// if there is more.
+func readLimited(r io.Reader, max int64) ([]byte, error) {
+	var buf bytes.Buffer
+	n, err := buf.ReadFrom(io.LimitReader(r, max+1))
+	if err != nil {
+		return nil, err
+	}
+	if n > max {
+		return nil, errTooLarge
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,87 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tetafro/nott-backend-go/internal/auth"
+	"github.com/tetafro/nott-backend-go/internal/markdown"
+)
+
+func TestRenderController(t *testing.T) {
+	log := logrus.New()
+	log.Out = ioutil.Discard
+
+	user := auth.User{ID: 1}
+	renderer := markdown.NewCommonMark(markdown.DefaultConfig())
+
+	render := func(c *RenderController, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req = addUserID(req, user.ID)
+		c.Render(w, req)
+		return w
+	}
+
+	t.Run("Render markdown", func(t *testing.T) {
+		c := NewRenderController(renderer, 1000, log)
+
+		w := render(c, `{"text": "# Title\n\nHello <b onclick=\"x\">world</b>", "toc": true}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var resp struct {
+			Data markdown.Document `json:"data"`
+		}
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		html := `<nav class="toc">` + "\n<ul>\n" +
+			`<li><a href="#title">Title</a></li>` + "\n</ul>\n</nav>\n" +
+			`<h1 id="title">Title</h1>` + "\n" +
+			"<p>Hello <b>world</b></p>\n"
+		assert.Equal(t, markdown.Document{
+			HTML:    html,
+			Outline: []markdown.Heading{{Level: 1, Title: "Title", ID: "title"}},
+			Stats:   markdown.Stats{Words: 3, Characters: 15, ReadingTime: 1},
+		}, resp.Data)
+	})
+
+	t.Run("Render markdown with escaped html and theme", func(t *testing.T) {
+		c := NewRenderController(renderer, 1000, log)
+
+		w := render(c, `{"text": "<b>x</b>\n\n    y", "raw_html": "escape", "theme": "monokai"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var resp struct {
+			Data markdown.Document `json:"data"`
+		}
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Regexp(t, `^<p>&lt;b&gt;x&lt;/b&gt;</p>\n`+
+			`<pre style="color:#f8f8f2;background-color:#272822">`, resp.Data.HTML)
+	})
+
+	t.Run("Fail to render too large request", func(t *testing.T) {
+		c := NewRenderController(renderer, 10, log)
+
+		w := render(c, `{"text": "Hello, world"}`)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	})
+
+	t.Run("Fail to render with invalid options", func(t *testing.T) {
+		c := NewRenderController(renderer, 1000, log)
+
+		for _, body := range []string{
+			`{"text": 1}`,
+			`{"text": "x", "raw_html": "keep"}`,
+			`{"text": "x", "theme": "unknown"}`,
+		} {
+			w := render(c, body)
+			assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		}
+	})
+}
@@ -6,17 +6,112 @@ import (
 	"strconv"
 
 	"github.com/go-chi/chi"
+	"github.com/pkg/errors"
+
+	"github.com/tetafro/nott-backend-go/internal/markdown"
+	"github.com/tetafro/nott-backend-go/internal/storage"
 )
 
+// maxPageLimit is a maximum number of objects in one list response.
+const maxPageLimit = 1000
+
 // getID extracts "id" parameter from path.
 func getID(req *http.Request) (int, error) {
-	return strconv.Atoi(chi.URLParam(req, "id"))
+	return getIntParam(req, "id")
+}
+
+// getIntParam extracts integer parameter from path.
+func getIntParam(req *http.Request, key string) (int, error) {
+	return strconv.Atoi(chi.URLParam(req, key))
+}
+
+// getPage extracts pagination parameters from query. Returns nil
+// if none of them is set.
+func getPage(req *http.Request) (*storage.Page, error) {
+	q := req.URL.Query()
+	limit, cursor, sort := q.Get("limit"), q.Get("cursor"), q.Get("sort")
+	if limit == "" && cursor == "" && sort == "" {
+		return nil, nil
+	}
+
+	var (
+		p   storage.Page
+		err error
+	)
+	if limit != "" {
+		p.Limit, err = strconv.Atoi(limit)
+		if err != nil {
+			return nil, errors.New("limit must be an integer number")
+		}
+		if p.Limit > maxPageLimit {
+			return nil, errors.Errorf("limit cannot be greater than %d", maxPageLimit)
+		}
+	}
+	p.Sort, err = storage.ParseSort(sort)
+	if err != nil {
+		return nil, err
+	}
+	if cursor != "" {
+		c, err := storage.DecodeCursor(cursor)
+		if err != nil {
+			return nil, errors.New("invalid cursor")
+		}
+		p.After = &c
+	}
+	if err = p.Validate(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// getRenderOptions extracts options of rendering markdown from query:
+// mode of handling HTML, which is sanitized by default, and theme
+// for inline styles of code, which is styled with classes by default.
+func getRenderOptions(req *http.Request) (markdown.Options, error) {
+	var opts markdown.Options
+
+	opts.RawHTML = markdown.RawHTML(req.URL.Query().Get("raw_html"))
+	switch opts.RawHTML {
+	case "":
+		opts.RawHTML = markdown.RawHTMLSanitize
+	case markdown.RawHTMLSanitize, markdown.RawHTMLEscape:
+	default:
+		return opts, errors.Errorf("raw_html must be either %s or %s",
+			markdown.RawHTMLSanitize, markdown.RawHTMLEscape)
+	}
+
+	opts.Theme = req.URL.Query().Get("theme")
+	if opts.Theme != "" && !markdown.ValidTheme(opts.Theme) {
+		return opts, errors.New("unknown theme")
+	}
+
+	return opts, nil
+}
+
+// pageFull checks if the page has reached its limit, which means
+// there may be more objects to get.
+func pageFull(p *storage.Page, n int) bool {
+	return p != nil && p.Limit > 0 && n >= p.Limit
 }
 
 // addID adds ID int parameter to request context.
 func addID(req *http.Request, id int) *http.Request {
-	rctx := chi.NewRouteContext()
-	rctx.URLParams.Add("id", strconv.Itoa(id))
-	ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
-	return req.WithContext(ctx)
+	return addIntParam(req, "id", id)
+}
+
+// addIntParam adds int parameter to request context.
+func addIntParam(req *http.Request, key string, val int) *http.Request {
+	return addParam(req, key, strconv.Itoa(val))
+}
+
+// addParam adds string parameter to request context.
+func addParam(req *http.Request, key, val string) *http.Request {
+	rctx, ok := req.Context().Value(chi.RouteCtxKey).(*chi.Context)
+	if !ok {
+		rctx = chi.NewRouteContext()
+		ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+		req = req.WithContext(ctx)
+	}
+	rctx.URLParams.Add(key, val)
+	return req
 }
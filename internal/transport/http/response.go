@@ -8,7 +8,8 @@ import (
 const contentType = "application/json; charset=utf-8"
 
 type dataResponse struct {
-	Data interface{} `json:"data"`
+	Data       interface{} `json:"data"`
+	NextCursor string      `json:"next_cursor,omitempty"`
 }
 
 type errorResponse struct {
@@ -16,6 +17,12 @@ type errorResponse struct {
 }
 
 func respond(w http.ResponseWriter, code int, data interface{}) {
+	respondPage(w, code, data, "")
+}
+
+// respondPage responds with data that is a part of a list, next
+// is a cursor for getting the next part.
+func respondPage(w http.ResponseWriter, code int, data interface{}, next string) {
 	if data == nil {
 		w.WriteHeader(code)
 		return
@@ -26,7 +33,7 @@ func respond(w http.ResponseWriter, code int, data interface{}) {
 
 	var resp interface{}
 	if code >= 200 && code <= 299 {
-		resp = dataResponse{Data: data}
+		resp = dataResponse{Data: data, NextCursor: next}
 	} else {
 		resp = errorResponse{Error: data.(string)}
 	}
@@ -48,6 +55,10 @@ func unauthorized(w http.ResponseWriter) {
 	respond(w, http.StatusUnauthorized, "unauthorized")
 }
 
+func forbidden(w http.ResponseWriter) {
+	respond(w, http.StatusForbidden, "forbidden")
+}
+
 func internalServerError(w http.ResponseWriter) {
 	respond(w, http.StatusInternalServerError, "internal server error")
 }
@@ -31,6 +31,26 @@ func TestRespond(t *testing.T) {
 		}`)
 	})
 
+	t.Run("respond with page", func(t *testing.T) {
+		w := httptest.NewRecorder()
+
+		respondPage(w, http.StatusOK, []int{1, 2}, "abc")
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusOK)
+
+		body, err := ioutil.ReadAll(resp.Body)
+		assert.NoError(t, err)
+
+		err = resp.Body.Close()
+		assert.NoError(t, err)
+
+		assert.JSONEq(t, string(body), `{
+			"data": [1, 2],
+			"next_cursor": "abc"
+		}`)
+	})
+
 	t.Run("respond with error", func(t *testing.T) {
 		w := httptest.NewRecorder()
 
@@ -0,0 +1,214 @@
+package httpapi
+
+import (
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/tetafro/nott-backend-go/internal/auth"
+	"github.com/tetafro/nott-backend-go/internal/domain"
+	"github.com/tetafro/nott-backend-go/internal/storage"
+)
+
+// maxUserAgentLength is a maximum length of user agent, that is kept
+// for sessions.
+const maxUserAgentLength = 512
+
+// Sessions starts and ends sessions of users, and issues tokens
+// for them.
+type Sessions struct {
+	repo          storage.SessionsRepo
+	refreshTokens storage.RefreshTokensRepo
+	denylist      auth.Denylist
+	tokener       auth.Tokener
+	// accessTTL is time to live of access tokens, access tokens
+	// of ended sessions are denied during this time
+	accessTTL time.Duration
+}
+
+// NewSessions creates new sessions manager.
+func NewSessions(
+	repo storage.SessionsRepo,
+	refreshTokens storage.RefreshTokensRepo,
+	denylist auth.Denylist,
+	tokener auth.Tokener,
+	accessTTL time.Duration,
+) *Sessions {
+	return &Sessions{
+		repo:          repo,
+		refreshTokens: refreshTokens,
+		denylist:      denylist,
+		tokener:       tokener,
+		accessTTL:     accessTTL,
+	}
+}
+
+// Start starts new session of the user on the device the request
+// comes from, and issues access token and refresh token.
+func (s *Sessions) Start(user auth.User, req *http.Request) (auth.Token, error) {
+	ua := req.UserAgent()
+	if len(ua) > maxUserAgentLength {
+		ua = ua[:maxUserAgentLength]
+	}
+	session, err := s.repo.Create(auth.Session{
+		UserID:    user.ID,
+		UserAgent: ua,
+		IP:        clientIP(req),
+	})
+	if err != nil {
+		return auth.Token{}, errors.Wrap(err, "create session")
+	}
+
+	t, err := s.tokener.Issue(user, session.ID)
+	if err != nil {
+		return auth.Token{}, errors.Wrap(err, "issue access token")
+	}
+	refresh, rt, err := s.tokener.IssueRefresh()
+	if err != nil {
+		return auth.Token{}, errors.Wrap(err, "issue refresh token")
+	}
+	rt.UserID = user.ID
+	rt.SessionID = session.ID
+	if _, err = s.refreshTokens.Create(rt); err != nil {
+		return auth.Token{}, errors.Wrap(err, "save refresh token")
+	}
+	t.RefreshToken = refresh
+	return t, nil
+}
+
+// Refresh continues the session of the refresh token, and issues
+// new access token and refresh token. Returns domain.ErrNotFound
+// if the token is invalid, and auth.ErrTokenReused if it has been
+// used before.
+func (s *Sessions) Refresh(refreshToken string, req *http.Request) (auth.Token, error) {
+	refresh, next, err := s.tokener.IssueRefresh()
+	if err != nil {
+		return auth.Token{}, errors.Wrap(err, "issue refresh token")
+	}
+	next, err = s.refreshTokens.Rotate(auth.HashToken(refreshToken), next)
+	if err != nil {
+		return auth.Token{}, err
+	}
+	if err = s.repo.Touch(next.SessionID, clientIP(req)); err != nil {
+		return auth.Token{}, errors.Wrap(err, "update session")
+	}
+
+	t, err := s.tokener.Issue(auth.User{ID: next.UserID}, next.SessionID)
+	if err != nil {
+		return auth.Token{}, errors.Wrap(err, "issue access token")
+	}
+	t.RefreshToken = refresh
+	return t, nil
+}
+
+// End ends the user's session, so its tokens stop working. Returns
+// domain.ErrNotFound if there is no such session.
+func (s *Sessions) End(id, userID int) error {
+	if err := s.repo.Delete(id, userID); err != nil {
+		return err
+	}
+	return s.deny(id)
+}
+
+// EndOthers ends all sessions of the user except the given one.
+func (s *Sessions) EndOthers(id, userID int) error {
+	ids, err := s.repo.DeleteOthers(id, userID)
+	if err != nil {
+		return errors.Wrap(err, "delete sessions")
+	}
+	for _, sid := range ids {
+		if err = s.deny(sid); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deny revokes access tokens of the ended session.
+func (s *Sessions) deny(id int) error {
+	err := s.denylist.Revoke(auth.SessionKey(id), time.Now().Add(s.accessTTL))
+	return errors.Wrap(err, "revoke access tokens")
+}
+
+// clientIP gets IP address of the client.
+func clientIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
+
+// SessionsController handles HTTP API requests.
+type SessionsController struct {
+	repo     storage.SessionsRepo
+	sessions *Sessions
+	log      logrus.FieldLogger
+}
+
+// NewSessionsController creates new controller.
+func NewSessionsController(
+	repo storage.SessionsRepo,
+	sessions *Sessions,
+	log logrus.FieldLogger,
+) *SessionsController {
+	return &SessionsController{repo: repo, sessions: sessions, log: log}
+}
+
+// GetList handles request for getting active sessions of the user.
+func (c *SessionsController) GetList(w http.ResponseWriter, req *http.Request) {
+	userID := getUserID(req)
+
+	ss, err := c.repo.Get(storage.SessionsFilter{UserID: &userID})
+	if err != nil {
+		c.log.Errorf("Failed to get sessions: %v", err)
+		internalServerError(w)
+		return
+	}
+	current := getSessionID(req)
+	for i := range ss {
+		ss[i].Current = ss[i].ID == current
+	}
+
+	respond(w, http.StatusOK, ss)
+}
+
+// Delete handles request for ending a session, e.g. on a lost device.
+func (c *SessionsController) Delete(w http.ResponseWriter, req *http.Request) {
+	userID := getUserID(req)
+	id, err := getID(req)
+	if err != nil {
+		notFound(w)
+		return
+	}
+
+	err = c.sessions.End(id, userID)
+	if err == domain.ErrNotFound {
+		notFound(w)
+		return
+	}
+	if err != nil {
+		c.log.Errorf("Failed to end session: %v", err)
+		internalServerError(w)
+		return
+	}
+
+	respond(w, http.StatusNoContent, nil)
+}
+
+// DeleteOthers handles request for ending all sessions of the user,
+// except the current one.
+func (c *SessionsController) DeleteOthers(w http.ResponseWriter, req *http.Request) {
+	userID := getUserID(req)
+
+	if err := c.sessions.EndOthers(getSessionID(req), userID); err != nil {
+		c.log.Errorf("Failed to end sessions: %v", err)
+		internalServerError(w)
+		return
+	}
+
+	respond(w, http.StatusNoContent, nil)
+}
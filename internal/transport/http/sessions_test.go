@@ -0,0 +1,165 @@
+package httpapi
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tetafro/nott-backend-go/internal/auth"
+	"github.com/tetafro/nott-backend-go/internal/domain"
+	"github.com/tetafro/nott-backend-go/internal/storage"
+)
+
+func TestSessionsController(t *testing.T) {
+	log := logrus.New()
+	log.Out = ioutil.Discard
+
+	user := auth.User{ID: 1}
+	seen := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run("Get list of sessions", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repoMock := storage.NewMockSessionsRepo(ctrl)
+		repoMock.EXPECT().Get(storage.SessionsFilter{UserID: &user.ID}).Return([]auth.Session{
+			{ID: 5, UserID: user.ID, UserAgent: "curl", IP: "192.0.2.1", LastSeenAt: seen, CreatedAt: seen},
+			{ID: 6, UserID: user.ID, UserAgent: "firefox", IP: "192.0.2.2", LastSeenAt: seen, CreatedAt: seen},
+		}, nil)
+
+		c := NewSessionsController(repoMock, nil, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req = addUserID(req, user.ID)
+		req = addSessionID(req, 6)
+
+		c.GetList(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{
+			"data": [
+				{
+					"id": 5,
+					"user_agent": "curl",
+					"ip": "192.0.2.1",
+					"last_seen_at": "2020-01-02T03:04:05Z",
+					"created_at": "2020-01-02T03:04:05Z",
+					"current": false
+				},
+				{
+					"id": 6,
+					"user_agent": "firefox",
+					"ip": "192.0.2.2",
+					"last_seen_at": "2020-01-02T03:04:05Z",
+					"created_at": "2020-01-02T03:04:05Z",
+					"current": true
+				}
+			]
+		}`, w.Body.String())
+	})
+
+	t.Run("Delete session", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repoMock := storage.NewMockSessionsRepo(ctrl)
+		repoMock.EXPECT().Delete(5, user.ID).Return(nil)
+
+		denylistMock := auth.NewMockDenylist(ctrl)
+		denylistMock.EXPECT().Revoke("session:5", gomock.Any()).DoAndReturn(
+			func(id string, exp time.Time) error {
+				assert.WithinDuration(t, time.Now().Add(time.Minute), exp, time.Second)
+				return nil
+			},
+		)
+
+		sessions := NewSessions(repoMock, nil, denylistMock, nil, time.Minute)
+		c := NewSessionsController(repoMock, sessions, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodDelete, url, nil)
+		req = addUserID(req, user.ID)
+		req = addID(req, 5)
+
+		c.Delete(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("Fail to delete non-existing session", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repoMock := storage.NewMockSessionsRepo(ctrl)
+		repoMock.EXPECT().Delete(5, user.ID).Return(domain.ErrNotFound)
+
+		sessions := NewSessions(repoMock, nil, nil, nil, time.Minute)
+		c := NewSessionsController(repoMock, sessions, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodDelete, url, nil)
+		req = addUserID(req, user.ID)
+		req = addID(req, 5)
+
+		c.Delete(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Delete other sessions", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repoMock := storage.NewMockSessionsRepo(ctrl)
+		repoMock.EXPECT().DeleteOthers(6, user.ID).Return([]int{5, 7}, nil)
+
+		denylistMock := auth.NewMockDenylist(ctrl)
+		denylistMock.EXPECT().Revoke("session:5", gomock.Any()).Return(nil)
+		denylistMock.EXPECT().Revoke("session:7", gomock.Any()).Return(nil)
+
+		sessions := NewSessions(repoMock, nil, denylistMock, nil, time.Minute)
+		c := NewSessionsController(repoMock, sessions, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodDelete, url, nil)
+		req = addUserID(req, user.ID)
+		req = addSessionID(req, 6)
+
+		c.DeleteOthers(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("Fail to delete other sessions", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repoMock := storage.NewMockSessionsRepo(ctrl)
+		repoMock.EXPECT().DeleteOthers(6, user.ID).Return(nil, errors.New("error"))
+
+		sessions := NewSessions(repoMock, nil, nil, nil, time.Minute)
+		c := NewSessionsController(repoMock, sessions, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodDelete, url, nil)
+		req = addUserID(req, user.ID)
+		req = addSessionID(req, 6)
+
+		c.DeleteOthers(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
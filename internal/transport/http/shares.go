@@ -0,0 +1,400 @@
+package httpapi
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/tetafro/nott-backend-go/internal/auth"
+	"github.com/tetafro/nott-backend-go/internal/domain"
+	"github.com/tetafro/nott-backend-go/internal/markdown"
+	"github.com/tetafro/nott-backend-go/internal/storage"
+)
+
+// sharePasswordHeader is a header with password for protected shares.
+const sharePasswordHeader = "X-Share-Password"
+
+// SharesController handles HTTP API requests.
+type SharesController struct {
+	repo        storage.SharesRepo
+	notepads    storage.NotepadsRepo
+	notes       storage.NotesRepo
+	attachments storage.AttachmentsRepo
+	blobs       storage.BlobStore
+	links       *AttachmentLinks
+	renderer    markdown.Renderer
+	baseURL     string
+	log         logrus.FieldLogger
+}
+
+// NewSharesController creates new controller. Base URL is an external
+// address of the API, like https://example.com/api/v1. Notes are
+// rendered with default config if renderer is nil.
+func NewSharesController(
+	repo storage.SharesRepo,
+	notepads storage.NotepadsRepo,
+	notes storage.NotesRepo,
+	attachments storage.AttachmentsRepo,
+	blobs storage.BlobStore,
+	links *AttachmentLinks,
+	renderer markdown.Renderer,
+	baseURL string,
+	log logrus.FieldLogger,
+) *SharesController {
+	if renderer == nil {
+		renderer = markdown.NewCommonMark(markdown.DefaultConfig())
+	}
+	return &SharesController{
+		repo:        repo,
+		notepads:    notepads,
+		notes:       notes,
+		attachments: attachments,
+		blobs:       blobs,
+		links:       links,
+		renderer:    renderer,
+		baseURL:     strings.TrimSuffix(baseURL, "/"),
+		log:         log,
+	}
+}
+
+// publicNote is a note as it's shown to anonymous users.
+type publicNote struct {
+	ID    int    `json:"id"`
+	Title string `json:"title"`
+	HTML  string `json:"html"`
+}
+
+// publicShare is a content of shared note or notepad.
+type publicShare struct {
+	Title string       `json:"title"`
+	Notes []publicNote `json:"notes"`
+}
+
+// GetList handles request for getting active shares of the user.
+func (c *SharesController) GetList(w http.ResponseWriter, req *http.Request) {
+	userID := getUserID(req)
+
+	shares, err := c.repo.Get(storage.SharesFilter{UserID: &userID, Active: true})
+	if err != nil {
+		c.log.Errorf("Failed to get shares: %v", err)
+		internalServerError(w)
+		return
+	}
+	for i := range shares {
+		shares[i].URL = c.url(shares[i])
+	}
+
+	respond(w, http.StatusOK, shares)
+}
+
+// Create handles request for sharing note or notepad.
+func (c *SharesController) Create(w http.ResponseWriter, req *http.Request) {
+	userID := getUserID(req)
+
+	var body struct {
+		NoteID    *int       `json:"note_id"`
+		NotepadID *int       `json:"notepad_id"`
+		Password  string     `json:"password"`
+		ExpiresAt *time.Time `json:"expires_at"`
+	}
+	err := json.NewDecoder(req.Body).Decode(&body)
+	if err != nil {
+		badRequest(w, "invalid json")
+		return
+	}
+	if body.ExpiresAt != nil && !body.ExpiresAt.After(time.Now()) {
+		badRequest(w, "expiration time must be in the future")
+		return
+	}
+	if body.ExpiresAt != nil {
+		utc := body.ExpiresAt.UTC()
+		body.ExpiresAt = &utc
+	}
+
+	s := domain.Share{
+		UserID:    userID,
+		NoteID:    body.NoteID,
+		NotepadID: body.NotepadID,
+		ExpiresAt: body.ExpiresAt,
+	}
+	s.Token, err = newShareToken()
+	if err != nil {
+		c.log.Errorf("Failed to make share token: %v", err)
+		internalServerError(w)
+		return
+	}
+	if body.Password != "" {
+		s.Password, err = auth.HashSharePassword(body.Password)
+		if err != nil {
+			c.log.Errorf("Failed to hash password: %v", err)
+			internalServerError(w)
+			return
+		}
+	}
+
+	if err = s.Validate(); err != nil {
+		badRequest(w, "invalid share: "+err.Error())
+		return
+	}
+
+	s, err = c.repo.Create(s)
+	if err == domain.ErrNotFound {
+		notFound(w)
+		return
+	}
+	if err != nil {
+		c.log.Errorf("Failed to create share: %v", err)
+		internalServerError(w)
+		return
+	}
+	s.URL = c.url(s)
+
+	respond(w, http.StatusCreated, s)
+}
+
+// Delete handles request for revoking share.
+func (c *SharesController) Delete(w http.ResponseWriter, req *http.Request) {
+	userID := getUserID(req)
+	id, err := getID(req)
+	if err != nil {
+		notFound(w)
+		return
+	}
+
+	s := domain.Share{ID: id, UserID: userID}
+	if err = c.repo.Delete(s); err != nil {
+		c.log.Errorf("Failed to delete share: %v", err)
+		internalServerError(w)
+		return
+	}
+
+	respond(w, http.StatusNoContent, nil)
+}
+
+// GetPublic handles anonymous request for getting shared note
+// or notepad rendered to HTML. Notes of a notepad are paginated.
+func (c *SharesController) GetPublic(w http.ResponseWriter, req *http.Request) {
+	s, ok := c.getPublicShare(w, req)
+	if !ok {
+		return
+	}
+	if s.Protected() {
+		password := req.Header.Get(sharePasswordHeader)
+		if password == "" {
+			respond(w, http.StatusUnauthorized, "password required")
+			return
+		}
+		if !auth.CheckPassword(password, s.Password) {
+			respond(w, http.StatusUnauthorized, "invalid password")
+			return
+		}
+	}
+
+	opts, err := getRenderOptions(req)
+	if err != nil {
+		badRequest(w, err.Error())
+		return
+	}
+
+	if s.NoteID != nil {
+		c.getPublicNote(w, s, opts)
+	} else {
+		c.getPublicNotepad(w, req, s, opts)
+	}
+}
+
+// GetPublicAttachment handles anonymous request for getting contents
+// of the attachment of shared note. The request is authenticated by
+// a signed link from the rendered note, so password is not required.
+func (c *SharesController) GetPublicAttachment(w http.ResponseWriter, req *http.Request) {
+	id, err := getID(req)
+	if err != nil {
+		notFound(w)
+		return
+	}
+	s, ok := c.getPublicShare(w, req)
+	if !ok {
+		return
+	}
+	if err = c.links.verifyShare(req, id, s); err != nil {
+		unauthorized(w)
+		return
+	}
+
+	attachments, err := c.attachments.Get(storage.AttachmentsFilter{ID: &id, UserID: &s.UserID})
+	if err != nil {
+		c.log.Errorf("Failed to get attachment: %v", err)
+		internalServerError(w)
+		return
+	}
+	if len(attachments) == 0 {
+		notFound(w)
+		return
+	}
+	a := attachments[0]
+	if s.NoteID != nil && *s.NoteID != a.NoteID {
+		notFound(w)
+		return
+	}
+
+	// The note may have been deleted or moved out of the shared
+	// notepad after the link was made
+	notes, err := c.notes.Get(storage.NotesFilter{
+		ID:        &a.NoteID,
+		UserID:    &s.UserID,
+		NotepadID: s.NotepadID,
+	})
+	if err != nil {
+		c.log.Errorf("Failed to get note: %v", err)
+		internalServerError(w)
+		return
+	}
+	if len(notes) == 0 {
+		notFound(w)
+		return
+	}
+
+	serveAttachment(w, req, c.blobs, a, c.log)
+}
+
+// getPublicShare gets active share by the token from the URL. Response
+// is sent if the share is not found.
+func (c *SharesController) getPublicShare(w http.ResponseWriter, req *http.Request) (domain.Share, bool) {
+	token := chi.URLParam(req, "token")
+
+	shares, err := c.repo.Get(storage.SharesFilter{Token: &token, Active: true})
+	if err != nil {
+		c.log.Errorf("Failed to get share: %v", err)
+		internalServerError(w)
+		return domain.Share{}, false
+	}
+	if len(shares) == 0 {
+		notFound(w)
+		return domain.Share{}, false
+	}
+	return shares[0], true
+}
+
+func (c *SharesController) getPublicNote(w http.ResponseWriter, s domain.Share, opts markdown.Options) {
+	notes, err := c.notes.Get(storage.NotesFilter{ID: s.NoteID, UserID: &s.UserID})
+	if err != nil {
+		c.log.Errorf("Failed to get note: %v", err)
+		internalServerError(w)
+		return
+	}
+	if len(notes) == 0 {
+		notFound(w)
+		return
+	}
+	n := notes[0]
+
+	pn, err := c.render(n, s, opts)
+	if err != nil {
+		c.log.Errorf("Failed to render note: %v", err)
+		internalServerError(w)
+		return
+	}
+
+	respond(w, http.StatusOK, publicShare{Title: n.Title, Notes: []publicNote{pn}})
+}
+
+func (c *SharesController) getPublicNotepad(
+	w http.ResponseWriter,
+	req *http.Request,
+	s domain.Share,
+	opts markdown.Options,
+) {
+	page, err := getPage(req)
+	if err != nil {
+		badRequest(w, err.Error())
+		return
+	}
+
+	notepads, err := c.notepads.Get(storage.NotepadsFilter{ID: s.NotepadID, UserID: &s.UserID})
+	if err != nil {
+		c.log.Errorf("Failed to get notepad: %v", err)
+		internalServerError(w)
+		return
+	}
+	if len(notepads) == 0 {
+		notFound(w)
+		return
+	}
+
+	notes, err := c.notes.Get(storage.NotesFilter{
+		NotepadID: s.NotepadID,
+		UserID:    &s.UserID,
+		Page:      page,
+	})
+	if err != nil {
+		c.log.Errorf("Failed to get notes: %v", err)
+		internalServerError(w)
+		return
+	}
+
+	share := publicShare{Title: notepads[0].Title, Notes: make([]publicNote, len(notes))}
+	for i, n := range notes {
+		share.Notes[i], err = c.render(n, s, opts)
+		if err != nil {
+			c.log.Errorf("Failed to render note: %v", err)
+			internalServerError(w)
+			return
+		}
+	}
+
+	var next string
+	if pageFull(page, len(notes)) {
+		last := notes[len(notes)-1]
+		next = storage.NewCursor(page.Sort, last.ID, last.Title, last.CreatedAt, last.UpdatedAt).Encode()
+	}
+
+	respondPage(w, http.StatusOK, share, next)
+}
+
+// render renders note of the share to HTML. Only attachments of the note
+// itself are linked, links to other attachments of the owner are left
+// unresolved.
+func (c *SharesController) render(n domain.Note, s domain.Share, opts markdown.Options) (publicNote, error) {
+	if c.links != nil {
+		attachments, err := c.attachments.Get(storage.AttachmentsFilter{UserID: &s.UserID, NoteID: &n.ID})
+		if err != nil {
+			return publicNote{}, errors.Wrap(err, "get attachments")
+		}
+		ids := make(map[int]bool, len(attachments))
+		for _, a := range attachments {
+			ids[a.ID] = true
+		}
+		opts.AttachmentURL = func(id int) string {
+			if !ids[id] {
+				return ""
+			}
+			return c.links.ShareURL(id, s)
+		}
+	}
+	return publicNote{
+		ID:    n.ID,
+		Title: n.Title,
+		HTML:  c.renderer.Render(n.Text, opts),
+	}, nil
+}
+
+// url makes public link of the share.
+func (c *SharesController) url(s domain.Share) string {
+	return c.baseURL + "/public/" + s.Token
+}
+
+// newShareToken makes random token for public link.
+func newShareToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
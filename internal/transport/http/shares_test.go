@@ -0,0 +1,575 @@
+package httpapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tetafro/nott-backend-go/internal/auth"
+	"github.com/tetafro/nott-backend-go/internal/domain"
+	"github.com/tetafro/nott-backend-go/internal/storage"
+)
+
+func TestSharesController(t *testing.T) {
+	log := logrus.New()
+	log.Out = ioutil.Discard
+
+	user := auth.User{ID: 1}
+	baseURL := "https://example.com/api/v1"
+	links := NewAttachmentLinks(auth.NewLinkSigner("qwerty"), baseURL)
+
+	t.Run("Get shares", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		noteID, notepadID := 20, 30
+		created := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+		shares := []domain.Share{
+			{ID: 10, UserID: user.ID, Token: "abc", NoteID: &noteID, CreatedAt: created},
+			{ID: 11, UserID: user.ID, Token: "def", NotepadID: &notepadID, CreatedAt: created},
+		}
+
+		repoMock := storage.NewMockSharesRepo(ctrl)
+		repoMock.EXPECT().
+			Get(storage.SharesFilter{UserID: &user.ID, Active: true}).
+			Return(shares, nil)
+
+		c := NewSharesController(repoMock, nil, nil, nil, nil, nil, nil, baseURL, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req = addUserID(req, user.ID)
+
+		c.GetList(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusOK)
+
+		body, err := ioutil.ReadAll(resp.Body)
+		assert.NoError(t, err)
+
+		err = resp.Body.Close()
+		assert.NoError(t, err)
+
+		assert.JSONEq(t, string(body), `{
+			"data": [
+				{
+					"id": 10,
+					"user_id": 1,
+					"token": "abc",
+					"note_id": 20,
+					"url": "https://example.com/api/v1/public/abc",
+					"created_at": "2020-01-02T03:04:05Z"
+				},
+				{
+					"id": 11,
+					"user_id": 1,
+					"token": "def",
+					"notepad_id": 30,
+					"url": "https://example.com/api/v1/public/def",
+					"created_at": "2020-01-02T03:04:05Z"
+				}
+			]
+		}`)
+	})
+
+	t.Run("Create share", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		noteID := 20
+		expires := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+
+		repoMock := storage.NewMockSharesRepo(ctrl)
+		repoMock.EXPECT().
+			Create(gomock.Any()).
+			DoAndReturn(func(s domain.Share) (domain.Share, error) {
+				assert.NotEmpty(t, s.Token)
+				assert.Equal(t, user.ID, s.UserID)
+				assert.Equal(t, &noteID, s.NoteID)
+				assert.Nil(t, s.NotepadID)
+				assert.Equal(t, expires, *s.ExpiresAt)
+				assert.False(t, s.Protected())
+				s.ID = 10
+				return s, nil
+			})
+
+		c := NewSharesController(repoMock, nil, nil, nil, nil, nil, nil, baseURL, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		local := expires.In(time.FixedZone("MSK", 3*60*60))
+		data := `{"note_id":20,"expires_at":"` + local.Format(time.RFC3339) + `"}`
+		req := httptest.NewRequest(http.MethodPost, url, bytes.NewBufferString(data))
+		req = addUserID(req, user.ID)
+
+		c.Create(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusCreated)
+
+		var body struct {
+			Data domain.Share `json:"data"`
+		}
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		assert.NoError(t, resp.Body.Close())
+		assert.Equal(t, 10, body.Data.ID)
+		assert.Equal(t, baseURL+"/public/"+body.Data.Token, body.Data.URL)
+	})
+
+	t.Run("Fail to create invalid share", func(t *testing.T) {
+		past := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+		cases := map[string]string{
+			"no object":      `{}`,
+			"two objects":    `{"note_id":20,"notepad_id":30}`,
+			"expired":        `{"note_id":20,"expires_at":"` + past + `"}`,
+			"malformed json": `{"note_id":`,
+		}
+		for name, data := range cases {
+			t.Run(name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				repoMock := storage.NewMockSharesRepo(ctrl)
+
+				c := NewSharesController(repoMock, nil, nil, nil, nil, nil, nil, baseURL, log)
+
+				url := "/"
+				w := httptest.NewRecorder()
+				req := httptest.NewRequest(http.MethodPost, url, bytes.NewBufferString(data))
+				req = addUserID(req, user.ID)
+
+				c.Create(w, req)
+
+				resp := w.Result()
+				assert.Equal(t, resp.StatusCode, http.StatusBadRequest)
+			})
+		}
+	})
+
+	t.Run("Fail to share missing note", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repoMock := storage.NewMockSharesRepo(ctrl)
+		repoMock.EXPECT().Create(gomock.Any()).Return(domain.Share{}, domain.ErrNotFound)
+
+		c := NewSharesController(repoMock, nil, nil, nil, nil, nil, nil, baseURL, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, url, bytes.NewBufferString(`{"note_id":20}`))
+		req = addUserID(req, user.ID)
+
+		c.Create(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusNotFound)
+	})
+
+	t.Run("Delete share", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		id := 10
+
+		repoMock := storage.NewMockSharesRepo(ctrl)
+		repoMock.EXPECT().Delete(domain.Share{ID: id, UserID: user.ID}).Return(nil)
+
+		c := NewSharesController(repoMock, nil, nil, nil, nil, nil, nil, baseURL, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodDelete, url, nil)
+		req = addUserID(req, user.ID)
+		req = addID(req, id)
+
+		c.Delete(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusNoContent)
+	})
+
+	t.Run("Get public note", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		token := "abc"
+		noteID := 20
+		share := domain.Share{ID: 10, UserID: user.ID, Token: token, NoteID: &noteID}
+		note := domain.Note{ID: noteID, UserID: user.ID, NotepadID: 30, Title: "Note", Text: "Hello"}
+
+		repoMock := storage.NewMockSharesRepo(ctrl)
+		repoMock.EXPECT().
+			Get(storage.SharesFilter{Token: &token, Active: true}).
+			Return([]domain.Share{share}, nil)
+		notesMock := storage.NewMockNotesRepo(ctrl)
+		notesMock.EXPECT().
+			Get(storage.NotesFilter{ID: &noteID, UserID: &user.ID}).
+			Return([]domain.Note{note}, nil)
+
+		c := NewSharesController(repoMock, nil, notesMock, nil, nil, nil, nil, baseURL, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req = addParam(req, "token", token)
+
+		c.GetPublic(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusOK)
+
+		body, err := ioutil.ReadAll(resp.Body)
+		assert.NoError(t, err)
+
+		err = resp.Body.Close()
+		assert.NoError(t, err)
+
+		assert.JSONEq(t, string(body), `{
+			"data": {
+				"title": "Note",
+				"notes": [{"id": 20, "title": "Note", "html": "<p>Hello</p>\n"}]
+			}
+		}`)
+	})
+
+	t.Run("Get public notepad", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		token := "abc"
+		notepadID := 30
+		share := domain.Share{ID: 10, UserID: user.ID, Token: token, NotepadID: &notepadID}
+		notepad := domain.Notepad{ID: notepadID, UserID: user.ID, FolderID: 40, Title: "Notepad"}
+		notes := []domain.Note{
+			{ID: 20, UserID: user.ID, NotepadID: notepadID, Title: "Note 20", Text: "One"},
+			{ID: 21, UserID: user.ID, NotepadID: notepadID, Title: "Note 21", Text: "Two"},
+		}
+
+		repoMock := storage.NewMockSharesRepo(ctrl)
+		repoMock.EXPECT().
+			Get(storage.SharesFilter{Token: &token, Active: true}).
+			Return([]domain.Share{share}, nil)
+		notepadsMock := storage.NewMockNotepadsRepo(ctrl)
+		notepadsMock.EXPECT().
+			Get(storage.NotepadsFilter{ID: &notepadID, UserID: &user.ID}).
+			Return([]domain.Notepad{notepad}, nil)
+		notesMock := storage.NewMockNotesRepo(ctrl)
+		notesMock.EXPECT().
+			Get(storage.NotesFilter{NotepadID: &notepadID, UserID: &user.ID}).
+			Return(notes, nil)
+
+		c := NewSharesController(repoMock, notepadsMock, notesMock, nil, nil, nil, nil, baseURL, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req = addParam(req, "token", token)
+
+		c.GetPublic(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusOK)
+
+		body, err := ioutil.ReadAll(resp.Body)
+		assert.NoError(t, err)
+
+		err = resp.Body.Close()
+		assert.NoError(t, err)
+
+		assert.JSONEq(t, string(body), `{
+			"data": {
+				"title": "Notepad",
+				"notes": [
+					{"id": 20, "title": "Note 20", "html": "<p>One</p>\n"},
+					{"id": 21, "title": "Note 21", "html": "<p>Two</p>\n"}
+				]
+			}
+		}`)
+	})
+
+	t.Run("Get protected public note", func(t *testing.T) {
+		hash, err := auth.HashPassword("secret")
+		assert.NoError(t, err)
+
+		token := "abc"
+		noteID := 20
+		share := domain.Share{ID: 10, UserID: user.ID, Token: token, NoteID: &noteID, Password: hash}
+		note := domain.Note{ID: noteID, UserID: user.ID, NotepadID: 30, Title: "Note"}
+
+		cases := map[string]struct {
+			password string
+			code     int
+		}{
+			"no password":    {password: "", code: http.StatusUnauthorized},
+			"wrong password": {password: "qwerty", code: http.StatusUnauthorized},
+			"right password": {password: "secret", code: http.StatusOK},
+		}
+		for name, tt := range cases {
+			t.Run(name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				repoMock := storage.NewMockSharesRepo(ctrl)
+				repoMock.EXPECT().
+					Get(storage.SharesFilter{Token: &token, Active: true}).
+					Return([]domain.Share{share}, nil)
+				notesMock := storage.NewMockNotesRepo(ctrl)
+				if tt.code == http.StatusOK {
+					notesMock.EXPECT().
+						Get(storage.NotesFilter{ID: &noteID, UserID: &user.ID}).
+						Return([]domain.Note{note}, nil)
+				}
+
+				c := NewSharesController(repoMock, nil, notesMock, nil, nil, nil, nil, baseURL, log)
+
+				url := "/"
+				w := httptest.NewRecorder()
+				req := httptest.NewRequest(http.MethodGet, url, nil)
+				if tt.password != "" {
+					req.Header.Set(sharePasswordHeader, tt.password)
+				}
+				req = addParam(req, "token", token)
+
+				c.GetPublic(w, req)
+
+				resp := w.Result()
+				assert.Equal(t, resp.StatusCode, tt.code)
+			})
+		}
+	})
+
+	t.Run("Fail to get unknown public share", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		token := "abc"
+
+		repoMock := storage.NewMockSharesRepo(ctrl)
+		repoMock.EXPECT().
+			Get(storage.SharesFilter{Token: &token, Active: true}).
+			Return([]domain.Share{}, nil)
+
+		c := NewSharesController(repoMock, nil, nil, nil, nil, nil, nil, baseURL, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req = addParam(req, "token", token)
+
+		c.GetPublic(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusNotFound)
+	})
+
+	t.Run("Link only attachments of public note", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		token := "abc"
+		noteID := 20
+		share := domain.Share{ID: 10, UserID: user.ID, Token: token, NoteID: &noteID}
+		note := domain.Note{
+			ID:        noteID,
+			UserID:    user.ID,
+			NotepadID: 30,
+			Title:     "Note",
+			Text:      "![own](attachment:50) ![other](attachment:51)",
+		}
+
+		repoMock := storage.NewMockSharesRepo(ctrl)
+		repoMock.EXPECT().
+			Get(storage.SharesFilter{Token: &token, Active: true}).
+			Return([]domain.Share{share}, nil)
+		notesMock := storage.NewMockNotesRepo(ctrl)
+		notesMock.EXPECT().
+			Get(storage.NotesFilter{ID: &noteID, UserID: &user.ID}).
+			Return([]domain.Note{note}, nil)
+		attachmentsMock := storage.NewMockAttachmentsRepo(ctrl)
+		attachmentsMock.EXPECT().
+			Get(storage.AttachmentsFilter{UserID: &user.ID, NoteID: &noteID}).
+			Return([]domain.Attachment{{ID: 50, UserID: user.ID, NoteID: noteID}}, nil)
+
+		c := NewSharesController(repoMock, nil, notesMock, attachmentsMock, nil, links, nil, baseURL, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req = addParam(req, "token", token)
+
+		c.GetPublic(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusOK)
+
+		body, err := ioutil.ReadAll(resp.Body)
+		assert.NoError(t, err)
+
+		err = resp.Body.Close()
+		assert.NoError(t, err)
+
+		assert.Contains(t, string(body), `src=\"https://example.com/api/v1/public/abc/attachments/50/content?`)
+		assert.Contains(t, string(body), `src=\"attachment:51\"`)
+		assert.NotContains(t, string(body), "user=")
+	})
+
+	t.Run("Get attachment of public note", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		token := "abc"
+		noteID, id := 20, 50
+		share := domain.Share{ID: 10, UserID: user.ID, Token: token, NoteID: &noteID}
+		a := domain.Attachment{
+			ID:          id,
+			UserID:      user.ID,
+			NoteID:      noteID,
+			Name:        "log.txt",
+			ContentType: "text/plain; charset=utf-8",
+			Key:         "1/abc",
+			CreatedAt:   time.Now(),
+		}
+
+		repoMock := storage.NewMockSharesRepo(ctrl)
+		repoMock.EXPECT().
+			Get(storage.SharesFilter{Token: &token, Active: true}).
+			Return([]domain.Share{share}, nil)
+		notesMock := storage.NewMockNotesRepo(ctrl)
+		notesMock.EXPECT().
+			Get(storage.NotesFilter{ID: &noteID, UserID: &user.ID}).
+			Return([]domain.Note{{ID: noteID, UserID: user.ID}}, nil)
+		attachmentsMock := storage.NewMockAttachmentsRepo(ctrl)
+		attachmentsMock.EXPECT().
+			Get(storage.AttachmentsFilter{ID: &id, UserID: &user.ID}).
+			Return([]domain.Attachment{a}, nil)
+		blobsMock := storage.NewMockBlobStore(ctrl)
+		blobsMock.EXPECT().
+			Get("1/abc").
+			Return(testBlob{bytes.NewReader([]byte("hello"))}, nil)
+
+		c := NewSharesController(repoMock, nil, notesMock, attachmentsMock, blobsMock, links, nil, baseURL, log)
+
+		u, err := url.Parse(links.ShareURL(id, share))
+		assert.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, u.RequestURI(), nil)
+		req = addParam(req, "token", token)
+		req = addID(req, id)
+
+		c.GetPublicAttachment(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusOK)
+
+		data, err := ioutil.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		assert.NoError(t, resp.Body.Close())
+		assert.Equal(t, "hello", string(data))
+	})
+
+	t.Run("Fail to get attachment of other note", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		token := "abc"
+		noteID, id := 20, 51
+		share := domain.Share{ID: 10, UserID: user.ID, Token: token, NoteID: &noteID}
+
+		repoMock := storage.NewMockSharesRepo(ctrl)
+		repoMock.EXPECT().
+			Get(storage.SharesFilter{Token: &token, Active: true}).
+			Return([]domain.Share{share}, nil)
+		attachmentsMock := storage.NewMockAttachmentsRepo(ctrl)
+		attachmentsMock.EXPECT().
+			Get(storage.AttachmentsFilter{ID: &id, UserID: &user.ID}).
+			Return([]domain.Attachment{{ID: id, UserID: user.ID, NoteID: 21}}, nil)
+
+		c := NewSharesController(repoMock, nil, nil, attachmentsMock, nil, links, nil, baseURL, log)
+
+		u, err := url.Parse(links.ShareURL(id, share))
+		assert.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, u.RequestURI(), nil)
+		req = addParam(req, "token", token)
+		req = addID(req, id)
+
+		c.GetPublicAttachment(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusNotFound)
+	})
+
+	t.Run("Fail to get attachment with invalid link", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		token := "abc"
+		noteID, id := 20, 50
+		share := domain.Share{ID: 10, UserID: user.ID, Token: token, NoteID: &noteID}
+		other := domain.Share{ID: 11, UserID: user.ID, Token: "def", NoteID: &noteID}
+
+		repoMock := storage.NewMockSharesRepo(ctrl)
+		repoMock.EXPECT().
+			Get(storage.SharesFilter{Token: &token, Active: true}).
+			Return([]domain.Share{share}, nil)
+
+		c := NewSharesController(repoMock, nil, nil, nil, nil, links, nil, baseURL, log)
+
+		// Link of the other share of the same note
+		u, err := url.Parse(links.ShareURL(id, other))
+		assert.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, u.RequestURI(), nil)
+		req = addParam(req, "token", token)
+		req = addID(req, id)
+
+		c.GetPublicAttachment(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusUnauthorized)
+	})
+
+	t.Run("Fail to get attachment of revoked share", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		token := "abc"
+		noteID, id := 20, 50
+		share := domain.Share{ID: 10, UserID: user.ID, Token: token, NoteID: &noteID}
+
+		repoMock := storage.NewMockSharesRepo(ctrl)
+		repoMock.EXPECT().
+			Get(storage.SharesFilter{Token: &token, Active: true}).
+			Return([]domain.Share{}, nil)
+
+		c := NewSharesController(repoMock, nil, nil, nil, nil, links, nil, baseURL, log)
+
+		u, err := url.Parse(links.ShareURL(id, share))
+		assert.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, u.RequestURI(), nil)
+		req = addParam(req, "token", token)
+		req = addID(req, id)
+
+		c.GetPublicAttachment(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusNotFound)
+	})
+}
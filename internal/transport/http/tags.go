@@ -0,0 +1,190 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/tetafro/nott-backend-go/internal/domain"
+	"github.com/tetafro/nott-backend-go/internal/storage"
+)
+
+// TagsController handles HTTP API requests.
+type TagsController struct {
+	repo storage.TagsRepo
+	log  logrus.FieldLogger
+}
+
+// NewTagsController creates new controller.
+func NewTagsController(repo storage.TagsRepo, log logrus.FieldLogger) *TagsController {
+	return &TagsController{repo: repo, log: log}
+}
+
+// GetList handles request for getting tags.
+func (c *TagsController) GetList(w http.ResponseWriter, req *http.Request) {
+	userID := getUserID(req)
+
+	tags, err := c.repo.Get(storage.TagsFilter{UserID: &userID})
+	if err != nil {
+		c.log.Errorf("Failed to get tags: %v", err)
+		internalServerError(w)
+		return
+	}
+
+	respond(w, http.StatusOK, tags)
+}
+
+// Create handles request for creating tag.
+func (c *TagsController) Create(w http.ResponseWriter, req *http.Request) {
+	userID := getUserID(req)
+
+	var err error
+
+	t := domain.Tag{}
+	if err = json.NewDecoder(req.Body).Decode(&t); err != nil {
+		badRequest(w, "invalid json")
+		return
+	}
+	t.UserID = userID
+	t.Name = domain.NormalizeTag(t.Name)
+
+	if err = t.Validate(); err != nil {
+		badRequest(w, "invalid tag: "+err.Error())
+		return
+	}
+
+	t, err = c.repo.Create(t)
+	if err == domain.ErrTagExists {
+		respond(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		c.log.Errorf("Failed to create tag: %v", err)
+		internalServerError(w)
+		return
+	}
+
+	respond(w, http.StatusCreated, t)
+}
+
+// GetOne handles request for getting tag by id.
+func (c *TagsController) GetOne(w http.ResponseWriter, req *http.Request) {
+	userID := getUserID(req)
+	id, err := getID(req)
+	if err != nil {
+		notFound(w)
+		return
+	}
+
+	tags, err := c.repo.Get(storage.TagsFilter{ID: &id, UserID: &userID})
+	if err != nil {
+		c.log.Errorf("Failed to get tag: %v", err)
+		internalServerError(w)
+		return
+	}
+	if len(tags) == 0 {
+		notFound(w)
+		return
+	}
+
+	respond(w, http.StatusOK, tags[0])
+}
+
+// Update handles request for renaming tag.
+func (c *TagsController) Update(w http.ResponseWriter, req *http.Request) {
+	userID := getUserID(req)
+	id, err := getID(req)
+	if err != nil {
+		notFound(w)
+		return
+	}
+
+	t := domain.Tag{}
+	if err = json.NewDecoder(req.Body).Decode(&t); err != nil {
+		badRequest(w, "invalid json")
+		return
+	}
+	t.ID = id
+	t.UserID = userID
+	t.Name = domain.NormalizeTag(t.Name)
+
+	if err = t.Validate(); err != nil {
+		badRequest(w, "invalid tag: "+err.Error())
+		return
+	}
+
+	t, err = c.repo.Update(t)
+	if err == domain.ErrNotFound {
+		notFound(w)
+		return
+	}
+	if err == domain.ErrTagExists {
+		respond(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		c.log.Errorf("Failed to update tag: %v", err)
+		internalServerError(w)
+		return
+	}
+
+	respond(w, http.StatusOK, t)
+}
+
+// Merge handles request for merging tag into another one.
+func (c *TagsController) Merge(w http.ResponseWriter, req *http.Request) {
+	userID := getUserID(req)
+	id, err := getID(req)
+	if err != nil {
+		notFound(w)
+		return
+	}
+
+	var body struct {
+		TargetID int `json:"target_id"`
+	}
+	if err = json.NewDecoder(req.Body).Decode(&body); err != nil {
+		badRequest(w, "invalid json")
+		return
+	}
+	if body.TargetID == 0 || body.TargetID == id {
+		badRequest(w, "target tag must be set and differ from the merged one")
+		return
+	}
+
+	t, err := c.repo.Merge(
+		domain.Tag{ID: id, UserID: userID},
+		domain.Tag{ID: body.TargetID, UserID: userID},
+	)
+	if err == domain.ErrNotFound {
+		notFound(w)
+		return
+	}
+	if err != nil {
+		c.log.Errorf("Failed to merge tags: %v", err)
+		internalServerError(w)
+		return
+	}
+
+	respond(w, http.StatusOK, t)
+}
+
+// Delete handles request for deleting tag.
+func (c *TagsController) Delete(w http.ResponseWriter, req *http.Request) {
+	userID := getUserID(req)
+	id, err := getID(req)
+	if err != nil {
+		notFound(w)
+		return
+	}
+
+	t := domain.Tag{ID: id, UserID: userID}
+	if err = c.repo.Delete(t); err != nil {
+		c.log.Errorf("Failed to delete tag: %v", err)
+		internalServerError(w)
+		return
+	}
+
+	respond(w, http.StatusNoContent, nil)
+}
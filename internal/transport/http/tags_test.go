@@ -0,0 +1,219 @@
+package httpapi
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tetafro/nott-backend-go/internal/auth"
+	"github.com/tetafro/nott-backend-go/internal/domain"
+	"github.com/tetafro/nott-backend-go/internal/storage"
+)
+
+func TestTagsController(t *testing.T) {
+	log := logrus.New()
+	log.Out = ioutil.Discard
+
+	user := auth.User{ID: 1}
+
+	t.Run("Get tags", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		tags := []domain.Tag{
+			{ID: 10, UserID: user.ID, Name: "todo"},
+			{ID: 15, UserID: user.ID, Name: "work"},
+		}
+
+		repoMock := storage.NewMockTagsRepo(ctrl)
+		repoMock.EXPECT().Get(storage.TagsFilter{UserID: &user.ID}).Return(tags, nil)
+
+		c := NewTagsController(repoMock, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req = addUserID(req, user.ID)
+
+		c.GetList(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusOK)
+
+		body, err := ioutil.ReadAll(resp.Body)
+		assert.NoError(t, err)
+
+		err = resp.Body.Close()
+		assert.NoError(t, err)
+
+		assert.JSONEq(t, string(body), `{
+			"data": [
+				{"id": 10, "user_id": 1, "name": "todo"},
+				{"id": 15, "user_id": 1, "name": "work"}
+			]
+		}`)
+	})
+
+	t.Run("Create tag", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repoMock := storage.NewMockTagsRepo(ctrl)
+		repoMock.EXPECT().
+			Create(domain.Tag{UserID: user.ID, Name: "todo"}).
+			Return(domain.Tag{ID: 10, UserID: user.ID, Name: "todo"}, nil)
+
+		c := NewTagsController(repoMock, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, url, bytes.NewBufferString(`{"name":"#ToDo"}`))
+		req = addUserID(req, user.ID)
+
+		c.Create(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusCreated)
+
+		body, err := ioutil.ReadAll(resp.Body)
+		assert.NoError(t, err)
+
+		err = resp.Body.Close()
+		assert.NoError(t, err)
+
+		assert.JSONEq(t, string(body), `{
+			"data": {"id": 10, "user_id": 1, "name": "todo"}
+		}`)
+	})
+
+	t.Run("Fail to create invalid tag", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repoMock := storage.NewMockTagsRepo(ctrl)
+
+		c := NewTagsController(repoMock, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, url, bytes.NewBufferString(`{"name":"to do"}`))
+		req = addUserID(req, user.ID)
+
+		c.Create(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusBadRequest)
+	})
+
+	t.Run("Fail to rename tag to existing name", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		id := 10
+
+		repoMock := storage.NewMockTagsRepo(ctrl)
+		repoMock.EXPECT().
+			Update(domain.Tag{ID: id, UserID: user.ID, Name: "work"}).
+			Return(domain.Tag{}, domain.ErrTagExists)
+
+		c := NewTagsController(repoMock, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, url, bytes.NewBufferString(`{"name":"work"}`))
+		req = addUserID(req, user.ID)
+		req = addID(req, id)
+
+		c.Update(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusConflict)
+	})
+
+	t.Run("Merge tags", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		id := 10
+		target := domain.Tag{ID: 15, UserID: user.ID, Name: "work"}
+
+		repoMock := storage.NewMockTagsRepo(ctrl)
+		repoMock.EXPECT().
+			Merge(domain.Tag{ID: id, UserID: user.ID}, domain.Tag{ID: 15, UserID: user.ID}).
+			Return(target, nil)
+
+		c := NewTagsController(repoMock, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, url, bytes.NewBufferString(`{"target_id":15}`))
+		req = addUserID(req, user.ID)
+		req = addID(req, id)
+
+		c.Merge(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusOK)
+
+		body, err := ioutil.ReadAll(resp.Body)
+		assert.NoError(t, err)
+
+		err = resp.Body.Close()
+		assert.NoError(t, err)
+
+		assert.JSONEq(t, string(body), `{
+			"data": {"id": 15, "user_id": 1, "name": "work"}
+		}`)
+	})
+
+	t.Run("Fail to merge tag into itself", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		id := 10
+
+		repoMock := storage.NewMockTagsRepo(ctrl)
+
+		c := NewTagsController(repoMock, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, url, bytes.NewBufferString(`{"target_id":10}`))
+		req = addUserID(req, user.ID)
+		req = addID(req, id)
+
+		c.Merge(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusBadRequest)
+	})
+
+	t.Run("Delete tag", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		id := 10
+
+		repoMock := storage.NewMockTagsRepo(ctrl)
+		repoMock.EXPECT().Delete(domain.Tag{ID: id, UserID: user.ID}).Return(nil)
+
+		c := NewTagsController(repoMock, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodDelete, url, nil)
+		req = addUserID(req, user.ID)
+		req = addID(req, id)
+
+		c.Delete(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusNoContent)
+	})
+}
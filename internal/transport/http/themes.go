@@ -0,0 +1,58 @@
+package httpapi
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi"
+	"github.com/sirupsen/logrus"
+
+	"github.com/tetafro/nott-backend-go/internal/markdown"
+)
+
+// themesCacheControl allows caching stylesheets, they change only
+// with new versions of the highlighter.
+const themesCacheControl = "public, max-age=86400"
+
+// ThemesController handles HTTP API requests.
+type ThemesController struct {
+	log logrus.FieldLogger
+}
+
+// NewThemesController creates new controller.
+func NewThemesController(log logrus.FieldLogger) *ThemesController {
+	return &ThemesController{log: log}
+}
+
+// GetList handles request for getting names of themes for highlighting
+// code.
+func (c *ThemesController) GetList(w http.ResponseWriter, req *http.Request) {
+	respond(w, http.StatusOK, markdown.Themes())
+}
+
+// GetCSS handles request for getting stylesheet of a theme, that styles
+// code in rendered notes. The file name is the name of the theme with
+// .css extension.
+func (c *ThemesController) GetCSS(w http.ResponseWriter, req *http.Request) {
+	file := chi.URLParam(req, "file")
+	if !strings.HasSuffix(file, ".css") {
+		notFound(w)
+		return
+	}
+	name := strings.TrimSuffix(file, ".css")
+	if !markdown.ValidTheme(name) {
+		notFound(w)
+		return
+	}
+
+	css, err := markdown.ThemeCSS(name)
+	if err != nil {
+		c.log.Errorf("Failed to make stylesheet: %v", err)
+		internalServerError(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/css; charset=utf-8")
+	w.Header().Set("Cache-Control", themesCacheControl)
+	w.Write([]byte(css)) // nolint: errcheck,gosec
+}
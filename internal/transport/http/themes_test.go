@@ -0,0 +1,53 @@
+package httpapi
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThemesController(t *testing.T) {
+	log := logrus.New()
+	log.Out = ioutil.Discard
+
+	c := NewThemesController(log)
+
+	t.Run("Get list of themes", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+		c.GetList(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"monokailight"`)
+	})
+
+	t.Run("Get stylesheet", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req = addParam(req, "file", "monokai.css")
+
+		c.GetCSS(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/css; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Equal(t, themesCacheControl, w.Header().Get("Cache-Control"))
+		assert.Contains(t, w.Body.String(), ".chroma {")
+	})
+
+	t.Run("Fail to get stylesheet", func(t *testing.T) {
+		for _, file := range []string{"unknown.css", "monokai", "monokai.js"} {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req = addParam(req, "file", file)
+
+			c.GetCSS(w, req)
+
+			assert.Equal(t, http.StatusNotFound, w.Code, file)
+		}
+	})
+}
@@ -0,0 +1,107 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/tetafro/nott-backend-go/internal/auth"
+	"github.com/tetafro/nott-backend-go/internal/domain"
+	"github.com/tetafro/nott-backend-go/internal/storage"
+)
+
+// TokensController handles HTTP API requests.
+type TokensController struct {
+	repo storage.PersonalTokensRepo
+	log  logrus.FieldLogger
+}
+
+// NewTokensController creates new controller.
+func NewTokensController(repo storage.PersonalTokensRepo, log logrus.FieldLogger) *TokensController {
+	return &TokensController{repo: repo, log: log}
+}
+
+// GetList handles request for getting personal access tokens
+// of the user.
+func (c *TokensController) GetList(w http.ResponseWriter, req *http.Request) {
+	userID := getUserID(req)
+
+	tt, err := c.repo.Get(storage.PersonalTokensFilter{UserID: &userID})
+	if err != nil {
+		c.log.Errorf("Failed to get personal tokens: %v", err)
+		internalServerError(w)
+		return
+	}
+
+	respond(w, http.StatusOK, tt)
+}
+
+// Create handles request for creating personal access token. The token
+// is in the response, it cannot be got later.
+func (c *TokensController) Create(w http.ResponseWriter, req *http.Request) {
+	userID := getUserID(req)
+
+	var body struct {
+		Name      string     `json:"name"`
+		Scopes    []string   `json:"scopes"`
+		ExpiresAt *time.Time `json:"expires_at"`
+	}
+	err := json.NewDecoder(req.Body).Decode(&body)
+	if err != nil {
+		badRequest(w, "invalid json")
+		return
+	}
+	if body.ExpiresAt != nil && !body.ExpiresAt.After(time.Now()) {
+		badRequest(w, "expiration time must be in the future")
+		return
+	}
+	if body.ExpiresAt != nil {
+		utc := body.ExpiresAt.UTC()
+		body.ExpiresAt = &utc
+	}
+
+	t, err := auth.NewPersonalToken(userID, body.Name, body.Scopes, body.ExpiresAt)
+	if err != nil {
+		c.log.Errorf("Failed to make personal token: %v", err)
+		internalServerError(w)
+		return
+	}
+	if err = t.Validate(); err != nil {
+		badRequest(w, "invalid token: "+err.Error())
+		return
+	}
+
+	t, err = c.repo.Create(t)
+	if err != nil {
+		c.log.Errorf("Failed to create personal token: %v", err)
+		internalServerError(w)
+		return
+	}
+
+	respond(w, http.StatusCreated, t)
+}
+
+// Delete handles request for revoking personal access token.
+func (c *TokensController) Delete(w http.ResponseWriter, req *http.Request) {
+	userID := getUserID(req)
+	id, err := getID(req)
+	if err != nil {
+		notFound(w)
+		return
+	}
+
+	err = c.repo.Delete(id, userID)
+	if err == domain.ErrNotFound {
+		notFound(w)
+		return
+	}
+	if err != nil {
+		c.log.Errorf("Failed to delete personal token: %v", err)
+		internalServerError(w)
+		return
+	}
+
+	respond(w, http.StatusNoContent, nil)
+}
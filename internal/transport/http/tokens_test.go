@@ -0,0 +1,186 @@
+package httpapi
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tetafro/nott-backend-go/internal/auth"
+	"github.com/tetafro/nott-backend-go/internal/domain"
+	"github.com/tetafro/nott-backend-go/internal/storage"
+)
+
+func TestTokensController(t *testing.T) {
+	log := logrus.New()
+	log.Out = ioutil.Discard
+
+	user := auth.User{ID: 1}
+	created := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run("Get list of tokens", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repoMock := storage.NewMockPersonalTokensRepo(ctrl)
+		repoMock.EXPECT().Get(storage.PersonalTokensFilter{UserID: &user.ID}).Return([]auth.PersonalToken{
+			{
+				ID:        5,
+				UserID:    user.ID,
+				Name:      "backup",
+				Scopes:    auth.Scopes{"notes:read", "folders:read"},
+				Hash:      "abc",
+				CreatedAt: created,
+			},
+		}, nil)
+
+		c := NewTokensController(repoMock, log)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req = addUserID(req, user.ID)
+
+		c.GetList(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{
+			"data": [
+				{
+					"id": 5,
+					"name": "backup",
+					"scopes": ["notes:read", "folders:read"],
+					"created_at": "2020-01-02T03:04:05Z"
+				}
+			]
+		}`, w.Body.String())
+	})
+
+	t.Run("Create token", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var token string
+		repoMock := storage.NewMockPersonalTokensRepo(ctrl)
+		repoMock.EXPECT().Create(gomock.Any()).DoAndReturn(
+			func(pt auth.PersonalToken) (auth.PersonalToken, error) {
+				assert.Equal(t, user.ID, pt.UserID)
+				assert.Equal(t, "backup", pt.Name)
+				assert.Equal(t, auth.Scopes{"notes:read"}, pt.Scopes)
+				assert.True(t, strings.HasPrefix(pt.Token, auth.PersonalTokenPrefix))
+				assert.Equal(t, auth.HashToken(pt.Token), pt.Hash)
+				assert.Equal(t, time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC), *pt.ExpiresAt)
+				token = pt.Token
+				pt.ID = 5
+				return pt, nil
+			},
+		)
+
+		c := NewTokensController(repoMock, log)
+
+		body := `{"name":"backup","scopes":["notes:read"],"expires_at":"2100-01-01T03:00:00+03:00"}`
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req = addUserID(req, user.ID)
+
+		c.Create(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"token":"`+token+`"`)
+		assert.NotContains(t, w.Body.String(), `"hash"`)
+	})
+
+	t.Run("Fail to create invalid token", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		c := NewTokensController(storage.NewMockPersonalTokensRepo(ctrl), log)
+
+		testCases := []struct {
+			body string
+			err  string
+		}{
+			{body: `{`, err: "invalid json"},
+			{body: `{"scopes":["notes:read"]}`, err: "invalid token: name cannot be empty"},
+			{body: `{"name":"backup"}`, err: "invalid token: scopes cannot be empty"},
+			{body: `{"name":"backup","scopes":["admin"]}`, err: `invalid token: unknown scope \"admin\"`},
+			{
+				body: `{"name":"backup","scopes":["notes:read"],"expires_at":"2000-01-01T00:00:00Z"}`,
+				err:  "expiration time must be in the future",
+			},
+		}
+		for _, tc := range testCases {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.body))
+			req = addUserID(req, user.ID)
+
+			c.Create(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.JSONEq(t, `{"error":"`+tc.err+`"}`, w.Body.String())
+		}
+	})
+
+	t.Run("Delete token", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repoMock := storage.NewMockPersonalTokensRepo(ctrl)
+		repoMock.EXPECT().Delete(5, user.ID).Return(nil)
+
+		c := NewTokensController(repoMock, log)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodDelete, "/", nil)
+		req = addUserID(req, user.ID)
+		req = addID(req, 5)
+
+		c.Delete(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("Fail to delete unknown token", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repoMock := storage.NewMockPersonalTokensRepo(ctrl)
+		repoMock.EXPECT().Delete(5, user.ID).Return(domain.ErrNotFound)
+
+		c := NewTokensController(repoMock, log)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodDelete, "/", nil)
+		req = addUserID(req, user.ID)
+		req = addID(req, 5)
+
+		c.Delete(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Fail to delete token with database error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repoMock := storage.NewMockPersonalTokensRepo(ctrl)
+		repoMock.EXPECT().Delete(5, user.ID).Return(errors.New("error"))
+
+		c := NewTokensController(repoMock, log)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodDelete, "/", nil)
+		req = addUserID(req, user.ID)
+		req = addID(req, 5)
+
+		c.Delete(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
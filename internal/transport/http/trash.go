@@ -0,0 +1,114 @@
+package httpapi
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/sirupsen/logrus"
+
+	"github.com/tetafro/nott-backend-go/internal/domain"
+	"github.com/tetafro/nott-backend-go/internal/storage"
+)
+
+// TrashController handles HTTP API requests.
+type TrashController struct {
+	repo storage.TrashRepo
+	log  logrus.FieldLogger
+}
+
+// NewTrashController creates new controller.
+func NewTrashController(repo storage.TrashRepo, log logrus.FieldLogger) *TrashController {
+	return &TrashController{repo: repo, log: log}
+}
+
+// GetList handles request for getting objects in trash.
+func (c *TrashController) GetList(w http.ResponseWriter, req *http.Request) {
+	userID := getUserID(req)
+
+	trash, err := c.repo.Get(userID)
+	if err != nil {
+		c.log.Errorf("Failed to get trash: %v", err)
+		internalServerError(w)
+		return
+	}
+
+	respond(w, http.StatusOK, trash)
+}
+
+// Restore handles request for restoring object from trash.
+func (c *TrashController) Restore(w http.ResponseWriter, req *http.Request) {
+	item, err := getTrashItem(req)
+	if err != nil {
+		notFound(w)
+		return
+	}
+
+	err = c.repo.Restore(item)
+	if err == domain.ErrNotFound {
+		notFound(w)
+		return
+	}
+	if err == domain.ErrParentDeleted {
+		badRequest(w, "Parent is in trash, restore it first")
+		return
+	}
+	if err != nil {
+		c.log.Errorf("Failed to restore %s: %v", item.Type, err)
+		internalServerError(w)
+		return
+	}
+
+	respond(w, http.StatusNoContent, nil)
+}
+
+// Purge handles request for deleting object from trash permanently.
+func (c *TrashController) Purge(w http.ResponseWriter, req *http.Request) {
+	item, err := getTrashItem(req)
+	if err != nil {
+		notFound(w)
+		return
+	}
+
+	err = c.repo.Purge(item)
+	if err == domain.ErrNotFound {
+		notFound(w)
+		return
+	}
+	if err != nil {
+		c.log.Errorf("Failed to purge %s: %v", item.Type, err)
+		internalServerError(w)
+		return
+	}
+
+	respond(w, http.StatusNoContent, nil)
+}
+
+// Empty handles request for deleting all objects from trash permanently.
+func (c *TrashController) Empty(w http.ResponseWriter, req *http.Request) {
+	userID := getUserID(req)
+
+	if err := c.repo.Empty(storage.TrashFilter{UserID: &userID}); err != nil {
+		c.log.Errorf("Failed to empty trash: %v", err)
+		internalServerError(w)
+		return
+	}
+
+	respond(w, http.StatusNoContent, nil)
+}
+
+// getTrashItem extracts trash item from request.
+func getTrashItem(req *http.Request) (domain.TrashItem, error) {
+	id, err := getID(req)
+	if err != nil {
+		return domain.TrashItem{}, err
+	}
+	item := domain.TrashItem{
+		Type:   chi.URLParam(req, "type"),
+		ID:     id,
+		UserID: getUserID(req),
+	}
+	if err = item.Validate(); err != nil {
+		return domain.TrashItem{}, err
+	}
+	return item, nil
+}
@@ -0,0 +1,260 @@
+package httpapi
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tetafro/nott-backend-go/internal/auth"
+	"github.com/tetafro/nott-backend-go/internal/domain"
+	"github.com/tetafro/nott-backend-go/internal/storage"
+)
+
+func TestTrashController(t *testing.T) {
+	log := logrus.New()
+	log.Out = ioutil.Discard
+
+	user := auth.User{ID: 1}
+
+	t.Run("Get trash", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		trash := domain.Trash{
+			Folders:  []domain.Folder{{ID: 10, UserID: user.ID, Title: "Folder 10"}},
+			Notepads: []domain.Notepad{},
+			Notes:    []domain.Note{{ID: 20, UserID: user.ID, NotepadID: 30, Title: "Note 20"}},
+		}
+
+		repoMock := storage.NewMockTrashRepo(ctrl)
+		repoMock.EXPECT().Get(user.ID).Return(trash, nil)
+
+		c := NewTrashController(repoMock, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req = addUserID(req, user.ID)
+
+		c.GetList(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusOK)
+
+		body, err := ioutil.ReadAll(resp.Body)
+		assert.NoError(t, err)
+
+		err = resp.Body.Close()
+		assert.NoError(t, err)
+
+		assert.JSONEq(t, string(body), `{
+			"data": {
+				"folders": [
+					{
+						"id": 10,
+						"user_id": 1,
+						"parent_id": null,
+						"title": "Folder 10"
+					}
+				],
+				"notepads": [],
+				"notes": [
+					{
+						"id": 20,
+						"user_id": 1,
+						"notepad_id": 30,
+						"title": "Note 20",
+						"text": ""
+					}
+				]
+			}
+		}`)
+	})
+
+	t.Run("Fail to get trash", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repoMock := storage.NewMockTrashRepo(ctrl)
+		repoMock.EXPECT().Get(user.ID).Return(domain.Trash{}, errors.New("error"))
+
+		c := NewTrashController(repoMock, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req = addUserID(req, user.ID)
+
+		c.GetList(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusInternalServerError)
+	})
+
+	t.Run("Restore folder", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		id := 10
+		item := domain.TrashItem{Type: domain.TrashFolder, ID: id, UserID: user.ID}
+
+		repoMock := storage.NewMockTrashRepo(ctrl)
+		repoMock.EXPECT().Restore(item).Return(nil)
+
+		c := NewTrashController(repoMock, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, url, nil)
+		req = addUserID(req, user.ID)
+		req = addParam(req, "type", "folder")
+		req = addID(req, id)
+
+		c.Restore(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusNoContent)
+	})
+
+	t.Run("Fail to restore note from deleted notepad", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		id := 10
+		item := domain.TrashItem{Type: domain.TrashNote, ID: id, UserID: user.ID}
+
+		repoMock := storage.NewMockTrashRepo(ctrl)
+		repoMock.EXPECT().Restore(item).Return(domain.ErrParentDeleted)
+
+		c := NewTrashController(repoMock, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, url, nil)
+		req = addUserID(req, user.ID)
+		req = addParam(req, "type", "note")
+		req = addID(req, id)
+
+		c.Restore(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusBadRequest)
+	})
+
+	t.Run("Fail to restore object of unknown type", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repoMock := storage.NewMockTrashRepo(ctrl)
+
+		c := NewTrashController(repoMock, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, url, nil)
+		req = addUserID(req, user.ID)
+		req = addParam(req, "type", "user")
+		req = addID(req, 10)
+
+		c.Restore(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusNotFound)
+	})
+
+	t.Run("Purge notepad", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		id := 10
+		item := domain.TrashItem{Type: domain.TrashNotepad, ID: id, UserID: user.ID}
+
+		repoMock := storage.NewMockTrashRepo(ctrl)
+		repoMock.EXPECT().Purge(item).Return(nil)
+
+		c := NewTrashController(repoMock, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodDelete, url, nil)
+		req = addUserID(req, user.ID)
+		req = addParam(req, "type", "notepad")
+		req = addID(req, id)
+
+		c.Purge(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusNoContent)
+	})
+
+	t.Run("Fail to purge non-existing notepad", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		id := 10
+		item := domain.TrashItem{Type: domain.TrashNotepad, ID: id, UserID: user.ID}
+
+		repoMock := storage.NewMockTrashRepo(ctrl)
+		repoMock.EXPECT().Purge(item).Return(domain.ErrNotFound)
+
+		c := NewTrashController(repoMock, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodDelete, url, nil)
+		req = addUserID(req, user.ID)
+		req = addParam(req, "type", "notepad")
+		req = addID(req, id)
+
+		c.Purge(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusNotFound)
+	})
+
+	t.Run("Empty trash", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repoMock := storage.NewMockTrashRepo(ctrl)
+		repoMock.EXPECT().Empty(storage.TrashFilter{UserID: &user.ID}).Return(nil)
+
+		c := NewTrashController(repoMock, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodDelete, url, nil)
+		req = addUserID(req, user.ID)
+
+		c.Empty(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusNoContent)
+	})
+
+	t.Run("Fail to empty trash", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repoMock := storage.NewMockTrashRepo(ctrl)
+		repoMock.EXPECT().Empty(storage.TrashFilter{UserID: &user.ID}).Return(errors.New("error"))
+
+		c := NewTrashController(repoMock, log)
+
+		url := "/"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodDelete, url, nil)
+		req = addUserID(req, user.ID)
+
+		c.Empty(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, resp.StatusCode, http.StatusInternalServerError)
+	})
+}
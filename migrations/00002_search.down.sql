@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP INDEX note_search_idx;
+DROP TRIGGER note_search_update ON "note";
+DROP FUNCTION note_search_update();
+ALTER TABLE "note" DROP COLUMN search;
+
+COMMIT;
@@ -0,0 +1,26 @@
+BEGIN;
+
+-- Notes can be written in any language, so the 'simple' configuration
+-- is used: no stemming, no stop words.
+ALTER TABLE "note" ADD COLUMN search TSVECTOR;
+
+UPDATE "note" SET search =
+    setweight(to_tsvector('simple', title), 'A') ||
+    setweight(to_tsvector('simple', text), 'B');
+
+CREATE FUNCTION note_search_update() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search :=
+        setweight(to_tsvector('simple', NEW.title), 'A') ||
+        setweight(to_tsvector('simple', NEW.text), 'B');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER note_search_update
+BEFORE INSERT OR UPDATE OF title, text ON "note"
+FOR EACH ROW EXECUTE PROCEDURE note_search_update();
+
+CREATE INDEX note_search_idx ON "note" USING GIN (search);
+
+COMMIT;
@@ -0,0 +1,22 @@
+BEGIN;
+
+CREATE TABLE "note_revision" (
+    id         SERIAL,
+    note_id    INTEGER NOT NULL,
+    user_id    INTEGER NOT NULL,
+    number     INTEGER NOT NULL,
+    title      VARCHAR NOT NULL,
+    text       VARCHAR NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE (note_id, number),
+    FOREIGN KEY (user_id) REFERENCES "user" (id),
+    FOREIGN KEY (note_id) REFERENCES "note" (id) ON DELETE CASCADE
+);
+
+-- Current state of existing notes becomes their first revision
+INSERT INTO "note_revision" (note_id, user_id, number, title, text, created_at)
+SELECT id, user_id, 1, title, text, COALESCE(updated_at, created_at)
+FROM "note";
+
+COMMIT;
@@ -0,0 +1,12 @@
+BEGIN;
+
+-- Objects in trash are gone for good
+DELETE FROM "note" WHERE deleted_at IS NOT NULL;
+DELETE FROM "notepad" WHERE deleted_at IS NOT NULL;
+DELETE FROM "folder" WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE "folder" DROP COLUMN deleted_at;
+ALTER TABLE "notepad" DROP COLUMN deleted_at;
+ALTER TABLE "note" DROP COLUMN deleted_at;
+
+COMMIT;
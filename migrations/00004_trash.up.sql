@@ -0,0 +1,11 @@
+BEGIN;
+
+ALTER TABLE "folder" ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE "notepad" ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE "note" ADD COLUMN deleted_at TIMESTAMP;
+
+CREATE INDEX folder_deleted_at_idx ON "folder" (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX notepad_deleted_at_idx ON "notepad" (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX note_deleted_at_idx ON "note" (deleted_at) WHERE deleted_at IS NOT NULL;
+
+COMMIT;
@@ -0,0 +1,18 @@
+BEGIN;
+
+ALTER TABLE "note" DROP CONSTRAINT note_notepad_id_fkey;
+ALTER TABLE "note" ADD CONSTRAINT note_notepad_id_fkey
+    FOREIGN KEY (notepad_id) REFERENCES "notepad" (id) ON DELETE CASCADE;
+
+ALTER TABLE "notepad" DROP CONSTRAINT notepad_folder_id_fkey;
+ALTER TABLE "notepad" ADD CONSTRAINT notepad_folder_id_fkey
+    FOREIGN KEY (folder_id) REFERENCES "folder" (id) ON DELETE CASCADE;
+
+ALTER TABLE "folder" DROP CONSTRAINT folder_parent_id_fkey;
+ALTER TABLE "folder" ADD CONSTRAINT folder_parent_id_fkey
+    FOREIGN KEY (parent_id) REFERENCES "folder" (id) ON DELETE CASCADE;
+
+ALTER TABLE "notepad" DROP CONSTRAINT notepad_id_user_id_key;
+ALTER TABLE "folder" DROP CONSTRAINT folder_id_user_id_key;
+
+COMMIT;
@@ -0,0 +1,72 @@
+BEGIN;
+
+-- Objects could be attached to parents of other users before, such
+-- objects stay with their owners. Folders are moved to the top level,
+-- notepads and notes are moved to a new "Recovered" folder and notepad
+-- of the owner.
+UPDATE "folder" f SET parent_id = NULL
+FROM "folder" p
+WHERE f.parent_id = p.id AND f.user_id <> p.user_id;
+
+CREATE TEMPORARY TABLE recovered (
+    user_id    INTEGER NOT NULL,
+    folder_id  INTEGER NOT NULL,
+    notepad_id INTEGER
+) ON COMMIT DROP;
+
+WITH misplaced AS (
+    SELECT n.user_id FROM "notepad" n
+    JOIN "folder" f ON f.id = n.folder_id
+    WHERE n.user_id <> f.user_id
+    UNION
+    SELECT n.user_id FROM "note" n
+    JOIN "notepad" p ON p.id = n.notepad_id
+    WHERE n.user_id <> p.user_id
+), created AS (
+    INSERT INTO "folder" (user_id, title, created_at)
+    SELECT user_id, 'Recovered', NOW() AT TIME ZONE 'UTC' FROM misplaced
+    RETURNING id, user_id
+)
+INSERT INTO recovered (user_id, folder_id)
+SELECT user_id, id FROM created;
+
+WITH created AS (
+    INSERT INTO "notepad" (user_id, folder_id, title, created_at)
+    SELECT r.user_id, r.folder_id, 'Recovered', NOW() AT TIME ZONE 'UTC'
+    FROM recovered r
+    WHERE EXISTS (
+        SELECT 1 FROM "note" n
+        JOIN "notepad" p ON p.id = n.notepad_id
+        WHERE n.user_id = r.user_id AND p.user_id <> n.user_id
+    )
+    RETURNING id, user_id
+)
+UPDATE recovered r SET notepad_id = c.id
+FROM created c
+WHERE r.user_id = c.user_id;
+
+UPDATE "notepad" n SET folder_id = r.folder_id
+FROM "folder" f, recovered r
+WHERE f.id = n.folder_id AND n.user_id <> f.user_id AND r.user_id = n.user_id;
+
+UPDATE "note" n SET notepad_id = r.notepad_id
+FROM "notepad" p, recovered r
+WHERE p.id = n.notepad_id AND n.user_id <> p.user_id AND r.user_id = n.user_id;
+
+-- Objects can only be attached to parents of the same user
+ALTER TABLE "folder" ADD UNIQUE (id, user_id);
+ALTER TABLE "notepad" ADD UNIQUE (id, user_id);
+
+ALTER TABLE "folder" DROP CONSTRAINT folder_parent_id_fkey;
+ALTER TABLE "folder" ADD CONSTRAINT folder_parent_id_fkey
+    FOREIGN KEY (parent_id, user_id) REFERENCES "folder" (id, user_id) ON DELETE CASCADE;
+
+ALTER TABLE "notepad" DROP CONSTRAINT notepad_folder_id_fkey;
+ALTER TABLE "notepad" ADD CONSTRAINT notepad_folder_id_fkey
+    FOREIGN KEY (folder_id, user_id) REFERENCES "folder" (id, user_id) ON DELETE CASCADE;
+
+ALTER TABLE "note" DROP CONSTRAINT note_notepad_id_fkey;
+ALTER TABLE "note" ADD CONSTRAINT note_notepad_id_fkey
+    FOREIGN KEY (notepad_id, user_id) REFERENCES "notepad" (id, user_id) ON DELETE CASCADE;
+
+COMMIT;
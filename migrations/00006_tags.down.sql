@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE "note_tag";
+DROP TABLE "tag";
+
+COMMIT;
@@ -0,0 +1,24 @@
+BEGIN;
+
+CREATE TABLE "tag" (
+    id         SERIAL,
+    user_id    INTEGER NOT NULL,
+    name       VARCHAR NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP,
+    PRIMARY KEY (id),
+    UNIQUE (user_id, name),
+    FOREIGN KEY (user_id) REFERENCES "user" (id)
+);
+
+CREATE TABLE "note_tag" (
+    note_id INTEGER NOT NULL,
+    tag_id  INTEGER NOT NULL,
+    PRIMARY KEY (note_id, tag_id),
+    FOREIGN KEY (note_id) REFERENCES "note" (id) ON DELETE CASCADE,
+    FOREIGN KEY (tag_id) REFERENCES "tag" (id) ON DELETE CASCADE
+);
+
+CREATE INDEX note_tag_tag_id_idx ON "note_tag" (tag_id);
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP TRIGGER attachment_deleted ON "attachment";
+DROP FUNCTION attachment_deleted();
+DROP TABLE "attachment_deleted";
+DROP TABLE "attachment";
+
+COMMIT;
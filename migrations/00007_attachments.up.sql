@@ -0,0 +1,42 @@
+BEGIN;
+
+CREATE TABLE "attachment" (
+    id           SERIAL,
+    user_id      INTEGER NOT NULL,
+    note_id      INTEGER NOT NULL,
+    name         VARCHAR NOT NULL,
+    content_type VARCHAR NOT NULL,
+    size         BIGINT NOT NULL,
+    key          VARCHAR NOT NULL,
+    created_at   TIMESTAMP NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE (key),
+    FOREIGN KEY (user_id) REFERENCES "user" (id),
+    FOREIGN KEY (note_id) REFERENCES "note" (id) ON DELETE CASCADE
+);
+
+CREATE INDEX attachment_note_id_idx ON "attachment" (note_id);
+CREATE INDEX attachment_user_id_idx ON "attachment" (user_id);
+
+-- Keys of deleted attachments, their blobs are removed from
+-- the blob store in background. Attachments can be deleted
+-- by cascade, so it's done with a trigger.
+CREATE TABLE "attachment_deleted" (
+    key        VARCHAR NOT NULL,
+    deleted_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (key)
+);
+
+CREATE FUNCTION attachment_deleted() RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO "attachment_deleted" (key, deleted_at)
+    VALUES (OLD.key, NOW())
+    ON CONFLICT DO NOTHING;
+    RETURN OLD;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER attachment_deleted AFTER DELETE ON "attachment"
+FOR EACH ROW EXECUTE PROCEDURE attachment_deleted();
+
+COMMIT;
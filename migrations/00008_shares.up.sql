@@ -0,0 +1,24 @@
+BEGIN;
+
+-- Public links that give anonymous read-only access to a note
+-- or to a notepad.
+CREATE TABLE "share" (
+    id         SERIAL,
+    user_id    INTEGER NOT NULL,
+    token      VARCHAR NOT NULL,
+    note_id    INTEGER,
+    notepad_id INTEGER,
+    password   VARCHAR,
+    expires_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE (token),
+    FOREIGN KEY (user_id) REFERENCES "user" (id),
+    FOREIGN KEY (note_id) REFERENCES "note" (id) ON DELETE CASCADE,
+    FOREIGN KEY (notepad_id) REFERENCES "notepad" (id) ON DELETE CASCADE,
+    CHECK ((note_id IS NULL) <> (notepad_id IS NULL))
+);
+
+CREATE INDEX share_user_id_idx ON "share" (user_id);
+
+COMMIT;
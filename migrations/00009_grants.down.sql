@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP FUNCTION shared_notepads(INTEGER);
+DROP FUNCTION shared_folders(INTEGER);
+DROP TABLE "grant";
+
+COMMIT;
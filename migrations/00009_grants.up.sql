@@ -0,0 +1,60 @@
+BEGIN;
+
+-- Access of other users to folders and notepads. Roles are:
+-- 1 - viewer, 2 - editor, 3 - owner. A grant on a folder applies
+-- to all its subfolders, notepads and notes.
+CREATE TABLE "grant" (
+    id          SERIAL,
+    user_id     INTEGER NOT NULL,
+    folder_id   INTEGER,
+    notepad_id  INTEGER,
+    role        SMALLINT NOT NULL,
+    invited_by  INTEGER NOT NULL,
+    accepted_at TIMESTAMP,
+    created_at  TIMESTAMP NOT NULL,
+    updated_at  TIMESTAMP,
+    PRIMARY KEY (id),
+    UNIQUE (user_id, folder_id),
+    UNIQUE (user_id, notepad_id),
+    FOREIGN KEY (user_id) REFERENCES "user" (id),
+    FOREIGN KEY (invited_by) REFERENCES "user" (id),
+    FOREIGN KEY (folder_id) REFERENCES "folder" (id) ON DELETE CASCADE,
+    FOREIGN KEY (notepad_id) REFERENCES "notepad" (id) ON DELETE CASCADE,
+    CHECK ((folder_id IS NULL) <> (notepad_id IS NULL)),
+    CHECK (role BETWEEN 1 AND 3)
+);
+
+CREATE INDEX grant_folder_id_idx ON "grant" (folder_id);
+CREATE INDEX grant_notepad_id_idx ON "grant" (notepad_id);
+
+-- Folders of other users that are available to the user, with the
+-- highest role granted on the folder or on one of its parents.
+CREATE FUNCTION shared_folders(uid INTEGER)
+RETURNS TABLE (id INTEGER, role SMALLINT) AS $$
+    WITH RECURSIVE tree AS (
+        SELECT g.folder_id AS id, g.role FROM "grant" g
+        WHERE g.user_id = uid AND g.folder_id IS NOT NULL
+            AND g.accepted_at IS NOT NULL
+        UNION ALL
+        SELECT f.id, t.role FROM "folder" f
+        JOIN tree t ON f.parent_id = t.id
+    )
+    SELECT tree.id, MAX(tree.role)::SMALLINT FROM tree GROUP BY tree.id
+$$ LANGUAGE SQL STABLE;
+
+-- Notepads of other users that are available to the user, either
+-- directly or through their folders.
+CREATE FUNCTION shared_notepads(uid INTEGER)
+RETURNS TABLE (id INTEGER, role SMALLINT) AS $$
+    SELECT s.id, MAX(s.role)::SMALLINT FROM (
+        SELECT g.notepad_id AS id, g.role FROM "grant" g
+        WHERE g.user_id = uid AND g.notepad_id IS NOT NULL
+            AND g.accepted_at IS NOT NULL
+        UNION ALL
+        SELECT n.id, f.role FROM "notepad" n
+        JOIN shared_folders(uid) f ON n.folder_id = f.id
+    ) s
+    GROUP BY s.id
+$$ LANGUAGE SQL STABLE;
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP VIEW "note_link_target";
+DROP TABLE "note_link";
+DROP INDEX note_user_id_title_idx;
+
+COMMIT;
@@ -0,0 +1,51 @@
+BEGIN;
+
+-- Wiki links between notes as they are written in the text: either
+-- [[note:123]] (link_id is set) or [[Note Title]] (link_title is set).
+-- Links are resolved on read, so they follow renames of target notes.
+CREATE TABLE "note_link" (
+    note_id    INTEGER NOT NULL,
+    link_id    INTEGER,
+    link_title VARCHAR,
+    FOREIGN KEY (note_id) REFERENCES "note" (id) ON DELETE CASCADE,
+    CHECK ((link_id IS NULL) <> (link_title IS NULL))
+);
+
+CREATE UNIQUE INDEX note_link_id_idx ON "note_link" (note_id, link_id)
+    WHERE link_id IS NOT NULL;
+CREATE UNIQUE INDEX note_link_title_idx ON "note_link" (note_id, lower(link_title))
+    WHERE link_title IS NOT NULL;
+CREATE INDEX note_user_id_title_idx ON "note" (user_id, lower(title));
+
+-- Links with their targets. Links are resolved among the notes of the
+-- owner of the linking note that are not in trash. If several notes
+-- have the same title, the oldest one is the target. Broken links have
+-- NULL in target_id.
+CREATE VIEW "note_link_target" AS
+SELECT DISTINCT ON (l.note_id, l.link_id, lower(l.link_title))
+    l.note_id,
+    l.link_id,
+    l.link_title,
+    t.id AS target_id,
+    t.title AS target_title
+FROM "note_link" l
+JOIN "note" n ON n.id = l.note_id
+LEFT JOIN "note" t ON t.user_id = n.user_id
+    AND t.deleted_at IS NULL
+    AND (t.id = l.link_id OR lower(t.title) = lower(l.link_title))
+ORDER BY l.note_id, l.link_id, lower(l.link_title), t.id;
+
+-- Links of existing notes. Unlike the parser of the application this
+-- doesn't skip code, links are fixed on the next update of the note.
+INSERT INTO "note_link" (note_id, link_id, link_title)
+SELECT DISTINCT ON (note_id, lower(link))
+    note_id,
+    CASE WHEN link ~ '^note:[1-9][0-9]{0,8}$' THEN substr(link, 6)::INTEGER END,
+    CASE WHEN link !~ '^note:[1-9][0-9]{0,8}$' THEN link END
+FROM (
+    SELECT id AS note_id, btrim(m[1]) AS link
+    FROM "note", regexp_matches(text, '\[\[([^][\n]+)\]\]', 'g') AS m
+) AS links
+WHERE link <> '';
+
+COMMIT;
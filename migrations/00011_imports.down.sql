@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE "import_error";
+DROP TABLE "import";
+
+COMMIT;
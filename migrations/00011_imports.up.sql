@@ -0,0 +1,40 @@
+BEGIN;
+
+-- Background jobs that import notes from uploaded files. The file
+-- is kept in the blob store until the job is finished.
+CREATE TABLE "import" (
+    id          SERIAL,
+    user_id     INTEGER NOT NULL,
+    format      VARCHAR NOT NULL,
+    name        VARCHAR NOT NULL,
+    key         VARCHAR NOT NULL,
+    status      VARCHAR NOT NULL,
+    folder_id   INTEGER,
+    notepads    INTEGER NOT NULL DEFAULT 0,
+    notes       INTEGER NOT NULL DEFAULT 0,
+    created_at  TIMESTAMP NOT NULL,
+    updated_at  TIMESTAMP,
+    finished_at TIMESTAMP,
+    PRIMARY KEY (id),
+    UNIQUE (key),
+    FOREIGN KEY (user_id) REFERENCES "user" (id),
+    FOREIGN KEY (folder_id) REFERENCES "folder" (id) ON DELETE SET NULL
+);
+
+CREATE INDEX import_user_id_idx ON "import" (user_id);
+CREATE INDEX import_status_idx ON "import" (status, created_at)
+WHERE status IN ('pending', 'running');
+
+-- Items that were skipped during import.
+CREATE TABLE "import_error" (
+    id        SERIAL,
+    import_id INTEGER NOT NULL,
+    item      VARCHAR NOT NULL,
+    error     VARCHAR NOT NULL,
+    PRIMARY KEY (id),
+    FOREIGN KEY (import_id) REFERENCES "import" (id) ON DELETE CASCADE
+);
+
+CREATE INDEX import_error_import_id_idx ON "import_error" (import_id);
+
+COMMIT;
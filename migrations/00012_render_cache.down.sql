@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP TABLE "render_cache";
+
+COMMIT;
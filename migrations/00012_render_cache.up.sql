@@ -0,0 +1,14 @@
+BEGIN;
+
+-- Rendered HTML of notes by hashes of their text and rendering
+-- options. It's only a cache, so it's not written to WAL.
+CREATE UNLOGGED TABLE "render_cache" (
+    key        VARCHAR NOT NULL,
+    html       TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (key)
+);
+
+CREATE INDEX render_cache_created_at_idx ON "render_cache" (created_at);
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE "user" DROP COLUMN theme;
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE "user" ADD COLUMN theme VARCHAR NOT NULL DEFAULT 'monokailight';
+
+COMMIT;
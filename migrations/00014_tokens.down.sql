@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE "revoked_token";
+DROP TABLE "refresh_token";
+
+COMMIT;
@@ -0,0 +1,31 @@
+BEGIN;
+
+-- Only hashes of refresh tokens are stored. Tokens are rotated on every
+-- use, all tokens of the same login share the family.
+CREATE TABLE "refresh_token" (
+    id         SERIAL,
+    user_id    INTEGER NOT NULL,
+    family     VARCHAR NOT NULL,
+    hash       VARCHAR NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at    TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE (hash),
+    FOREIGN KEY (user_id) REFERENCES "user" (id) ON DELETE CASCADE
+);
+
+CREATE INDEX refresh_token_family_idx ON "refresh_token" (family);
+CREATE INDEX refresh_token_expires_at_idx ON "refresh_token" (expires_at);
+
+-- IDs of revoked access tokens, they are kept until the tokens expire.
+CREATE TABLE "revoked_token" (
+    id         VARCHAR NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX revoked_token_expires_at_idx ON "revoked_token" (expires_at);
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE "refresh_token" DROP COLUMN session_id;
+DROP TABLE "session";
+
+COMMIT;
@@ -0,0 +1,24 @@
+BEGIN;
+
+CREATE TABLE "session" (
+    id           SERIAL,
+    user_id      INTEGER NOT NULL,
+    user_agent   VARCHAR NOT NULL,
+    ip           VARCHAR NOT NULL,
+    last_seen_at TIMESTAMP NOT NULL,
+    created_at   TIMESTAMP NOT NULL,
+    PRIMARY KEY (id),
+    FOREIGN KEY (user_id) REFERENCES "user" (id) ON DELETE CASCADE
+);
+
+CREATE INDEX session_user_id_idx ON "session" (user_id);
+
+-- Refresh tokens belong to sessions. Tokens issued before sessions
+-- can't be bound to them, so users have to log in again.
+DELETE FROM "refresh_token";
+ALTER TABLE "refresh_token"
+    ADD COLUMN session_id INTEGER NOT NULL
+    REFERENCES "session" (id) ON DELETE CASCADE;
+CREATE INDEX refresh_token_session_id_idx ON "refresh_token" (session_id);
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP TABLE "personal_token";
+
+COMMIT;
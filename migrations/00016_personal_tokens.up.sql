@@ -0,0 +1,20 @@
+BEGIN;
+
+-- Only hashes of personal access tokens are stored. Scopes are
+-- space-separated.
+CREATE TABLE "personal_token" (
+    id         SERIAL,
+    user_id    INTEGER NOT NULL,
+    name       VARCHAR(100) NOT NULL,
+    scopes     VARCHAR NOT NULL,
+    hash       VARCHAR NOT NULL,
+    expires_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE (hash),
+    FOREIGN KEY (user_id) REFERENCES "user" (id) ON DELETE CASCADE
+);
+
+CREATE INDEX personal_token_user_id_idx ON "personal_token" (user_id);
+
+COMMIT;
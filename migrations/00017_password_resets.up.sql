@@ -0,0 +1,19 @@
+BEGIN;
+
+-- Only hashes of password reset tokens are stored.
+CREATE TABLE "password_reset" (
+    id         SERIAL,
+    user_id    INTEGER NOT NULL,
+    hash       VARCHAR NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at    TIMESTAMP,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE (hash),
+    FOREIGN KEY (user_id) REFERENCES "user" (id) ON DELETE CASCADE
+);
+
+CREATE INDEX password_reset_user_id_idx ON "password_reset" (user_id);
+CREATE INDEX password_reset_expires_at_idx ON "password_reset" (expires_at);
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+-- Broken cycles are not restored
+
+COMMIT;
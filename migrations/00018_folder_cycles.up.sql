@@ -0,0 +1,21 @@
+BEGIN;
+
+-- Folders could be moved into themselves or their subfolders before,
+-- such cycles are broken by moving the folder with the smallest ID
+-- in every cycle to the top level.
+WITH RECURSIVE walk (start_id, id, path) AS (
+    SELECT id, parent_id, ARRAY[id] FROM "folder"
+    WHERE parent_id IS NOT NULL
+    UNION ALL
+    SELECT w.start_id, f.parent_id, w.path || f.id
+    FROM walk w
+    JOIN "folder" f ON f.id = w.id
+    WHERE f.parent_id IS NOT NULL AND NOT f.id = ANY(w.path)
+)
+UPDATE "folder" SET parent_id = NULL, updated_at = NOW() AT TIME ZONE 'UTC'
+WHERE id IN (
+    SELECT start_id FROM walk
+    WHERE id = start_id AND start_id = (SELECT MIN(x) FROM unnest(path) AS x)
+);
+
+COMMIT;
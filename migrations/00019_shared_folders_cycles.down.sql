@@ -0,0 +1,16 @@
+BEGIN;
+
+CREATE OR REPLACE FUNCTION shared_folders(uid INTEGER)
+RETURNS TABLE (id INTEGER, role SMALLINT) AS $$
+    WITH RECURSIVE tree AS (
+        SELECT g.folder_id AS id, g.role FROM "grant" g
+        WHERE g.user_id = uid AND g.folder_id IS NOT NULL
+            AND g.accepted_at IS NOT NULL
+        UNION ALL
+        SELECT f.id, t.role FROM "folder" f
+        JOIN tree t ON f.parent_id = t.id
+    )
+    SELECT tree.id, MAX(tree.role)::SMALLINT FROM tree GROUP BY tree.id
+$$ LANGUAGE SQL STABLE;
+
+COMMIT;
@@ -0,0 +1,19 @@
+BEGIN;
+
+-- Folders that are already on the path from the granted folder are
+-- not visited again, so access checks end even if there is a cycle.
+CREATE OR REPLACE FUNCTION shared_folders(uid INTEGER)
+RETURNS TABLE (id INTEGER, role SMALLINT) AS $$
+    WITH RECURSIVE tree AS (
+        SELECT g.folder_id AS id, g.role, ARRAY[g.folder_id] AS path FROM "grant" g
+        WHERE g.user_id = uid AND g.folder_id IS NOT NULL
+            AND g.accepted_at IS NOT NULL
+        UNION ALL
+        SELECT f.id, t.role, t.path || f.id FROM "folder" f
+        JOIN tree t ON f.parent_id = t.id
+        WHERE NOT f.id = ANY(t.path)
+    )
+    SELECT tree.id, MAX(tree.role)::SMALLINT FROM tree GROUP BY tree.id
+$$ LANGUAGE SQL STABLE;
+
+COMMIT;
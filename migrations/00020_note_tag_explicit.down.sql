@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE "note_tag" DROP COLUMN explicit;
+
+COMMIT;
@@ -0,0 +1,15 @@
+BEGIN;
+
+-- Tags that are only parsed from #hashtags of the note text are
+-- detached when the hashtags are removed, explicit ones are kept.
+ALTER TABLE "note_tag" ADD COLUMN explicit BOOLEAN NOT NULL DEFAULT TRUE;
+
+-- Existing tags can't be told apart, so they are taken as parsed ones
+-- if the text has the hashtag (code in the text is not skipped here).
+UPDATE "note_tag" nt SET explicit = FALSE
+FROM "note" n, "tag" t
+WHERE n.id = nt.note_id
+    AND t.id = nt.tag_id
+    AND n.text ~* ('(^|[^[:alnum:]_&/#])#' || t.name || '[/-]*($|[^[:alnum:]_/-])');
+
+COMMIT;